@@ -0,0 +1,246 @@
+package csvprocessor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// AggregateFunc represents the function used to aggregate the values of a column within a group.
+type AggregateFunc int
+
+const (
+	// AggSum adds the numeric values in the group.
+	AggSum AggregateFunc = iota
+	// AggCount counts the rows in the group with a value in the column, like COUNT(column) in SQL.
+	// Use a column that is never empty, Eg: a group column, to count all the rows in the group.
+	AggCount
+	// AggMin finds the smallest numeric value in the group.
+	AggMin
+	// AggMax finds the largest numeric value in the group.
+	AggMax
+	// AggAvg finds the average of the numeric values in the group.
+	AggAvg
+)
+
+// String returns the name of the aggregate function, it is used to name the output column.
+func (f AggregateFunc) String() string {
+	switch f {
+	case AggSum:
+		return "sum"
+	case AggCount:
+		return "count"
+	case AggMin:
+		return "min"
+	case AggMax:
+		return "max"
+	case AggAvg:
+		return "avg"
+	default:
+		return "unknown"
+	}
+}
+
+// Aggregation represents an aggregate function applied on a column for each group.
+type Aggregation struct {
+	// Column is the index of the column that is aggregated.
+	Column int
+	// Func is the aggregate function.
+	Func AggregateFunc
+	// Name is the name of the output column, if empty it defaults to "<func>_<column name>".
+	Name string
+}
+
+// Aggregator is a CsvReader that groups the rows of the underlying reader by the given columns,
+// and returns one row per group with the values of the group columns followed by the aggregated values.
+// Values that are not valid numbers are ignored by sum, min, max and avg.
+// Groups are returned in the order they were first seen in the input.
+//
+// The entire input is consumed on the first call to Read, and only the aggregates of each group are held in memory.
+type Aggregator struct {
+	reader       CsvReader
+	groupBy      []int
+	aggregations []Aggregation
+	hasHeader    bool
+
+	// Unexported fields
+	aggregated bool
+	header     []string
+	groups     map[string]*group
+	order      []*group
+	err        error
+	budget     *memoryBudget // bounds the groups, see WithMaxMemory().
+}
+
+// group holds the running aggregates of a group.
+type group struct {
+	key    []string
+	values []aggregate
+}
+
+// aggregate holds the running aggregate of a column.
+type aggregate struct {
+	count         int // no. of numeric values, or no. of values for AggCount.
+	sum, min, max float64
+}
+
+// NewAggregator creates an Aggregator that groups the rows from reader by the groupBy columns.
+// If hasHeader is true, the first row is treated as header and used to name the output columns.
+func NewAggregator(reader CsvReader, groupBy []int, hasHeader bool, aggregations ...Aggregation) *Aggregator {
+	return &Aggregator{
+		reader:       reader,
+		groupBy:      groupBy,
+		aggregations: aggregations,
+		hasHeader:    hasHeader,
+		groups:       make(map[string]*group),
+	}
+}
+
+// Read returns the next aggregated row.
+func (a *Aggregator) Read() ([]string, error) {
+	if !a.aggregated {
+		a.aggregated = true
+		a.err = a.aggregate()
+		if a.err == nil && a.header != nil {
+			return a.outputHeader(), nil
+		}
+	}
+
+	if a.err != nil {
+		return nil, a.err
+	}
+
+	if len(a.order) == 0 {
+		return nil, io.EOF
+	}
+
+	current := a.order[0]
+	a.order = a.order[1:]
+	return a.outputRow(current), nil
+}
+
+func (a *Aggregator) aggregate() error {
+	for {
+		row, err := readWaiting(a.reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if a.hasHeader && a.header == nil {
+			a.header = append([]string(nil), row...)
+			continue
+		}
+
+		// without group columns, all the rows belong to a single group.
+		key := ""
+		if len(a.groupBy) > 0 {
+			key = rowKey(row, a.groupBy)
+		}
+
+		current, ok := a.groups[key]
+		if !ok {
+			current = &group{values: make([]aggregate, len(a.aggregations))}
+			for _, column := range a.groupBy {
+				current.key = append(current.key, valueAt(row, column))
+			}
+
+			// the map key, the group key and 32 bytes for each aggregate.
+			if !a.budget.reserve(int64(len(key)) + rowSize(current.key) + int64(32*len(a.aggregations))) {
+				return a.budget.exceeded("WithAggregation()")
+			}
+
+			a.groups[key] = current
+			a.order = append(a.order, current)
+		}
+
+		for i, aggregation := range a.aggregations {
+			if aggregation.Func == AggCount {
+				if valueAt(row, aggregation.Column) != "" {
+					current.values[i].count++
+				}
+
+				continue
+			}
+
+			val, err := strconv.ParseFloat(strings.TrimSpace(valueAt(row, aggregation.Column)), 64)
+			if err != nil {
+				continue
+			}
+
+			current.values[i].add(val)
+		}
+	}
+}
+
+func (a *Aggregator) outputHeader() []string {
+	header := make([]string, 0, len(a.groupBy)+len(a.aggregations))
+	for _, column := range a.groupBy {
+		header = append(header, valueAt(a.header, column))
+	}
+
+	for _, aggregation := range a.aggregations {
+		name := aggregation.Name
+		if name == "" {
+			name = fmt.Sprintf("%s_%s", aggregation.Func, valueAt(a.header, aggregation.Column))
+		}
+
+		header = append(header, name)
+	}
+
+	return header
+}
+
+func (a *Aggregator) outputRow(current *group) []string {
+	row := make([]string, 0, len(current.key)+len(a.aggregations))
+	row = append(row, current.key...)
+
+	for i, aggregation := range a.aggregations {
+		val := current.values[i]
+		if aggregation.Func == AggCount {
+			row = append(row, strconv.Itoa(val.count))
+			continue
+		}
+
+		if val.count == 0 {
+			// no numeric values in this group.
+			row = append(row, "")
+			continue
+		}
+
+		var result float64
+		switch aggregation.Func {
+		case AggSum:
+			result = val.sum
+		case AggMin:
+			result = val.min
+		case AggMax:
+			result = val.max
+		case AggAvg:
+			result = val.sum / float64(val.count)
+		case AggCount:
+		}
+
+		row = append(row, strconv.FormatFloat(result, 'f', -1, 64))
+	}
+
+	return row
+}
+
+func (v *aggregate) add(val float64) {
+	if v.count == 0 || val < v.min {
+		v.min = val
+	}
+
+	if v.count == 0 || val > v.max {
+		v.max = val
+	}
+
+	v.sum += val
+	v.count++
+}
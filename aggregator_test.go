@@ -0,0 +1,112 @@
+package csvprocessor_test
+
+import (
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+const ordersCSV = `customer,item,amount
+c1,pen,10
+c2,book,25.5
+c1,book,30
+c3,pen,x
+c2,pen,4.5
+`
+
+func TestAggregator(t *testing.T) {
+	type args struct {
+		groupBy      []int
+		hasHeader    bool
+		aggregations []csvprocessor.Aggregation
+	}
+	tests := []struct {
+		name string
+		args args
+		want [][]string
+	}{
+		{
+			name: "Test aggregations by customer",
+			args: args{
+				groupBy:   []int{0},
+				hasHeader: true,
+				aggregations: []csvprocessor.Aggregation{
+					{Column: 2, Func: csvprocessor.AggSum},
+					{Func: csvprocessor.AggCount, Name: "orders"},
+					{Column: 2, Func: csvprocessor.AggMin},
+					{Column: 2, Func: csvprocessor.AggMax},
+					{Column: 2, Func: csvprocessor.AggAvg},
+				},
+			},
+			want: [][]string{
+				{"customer", "sum_amount", "orders", "min_amount", "max_amount", "avg_amount"},
+				{"c1", "40", "2", "10", "30", "20"},
+				{"c2", "30", "2", "4.5", "25.5", "15"},
+				{"c3", "", "1", "", "", ""},
+			},
+		},
+		{
+			name: "Test aggregation without group columns",
+			args: args{
+				groupBy:      []int{},
+				hasHeader:    true,
+				aggregations: []csvprocessor.Aggregation{{Func: csvprocessor.AggCount}},
+			},
+			want: [][]string{{"count_customer"}, {"5"}},
+		},
+		{
+			name: "Test aggregation without header",
+			args: args{
+				groupBy:      []int{1},
+				hasHeader:    false,
+				aggregations: []csvprocessor.Aggregation{{Func: csvprocessor.AggCount}},
+			},
+			want: [][]string{{"item", "1"}, {"pen", "3"}, {"book", "2"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregator := csvprocessor.NewAggregator(csv.NewReader(strings.NewReader(ordersCSV)), tt.args.groupBy, tt.args.hasHeader, tt.args.aggregations...)
+			actual := readAll(t, aggregator)
+			if !reflect.DeepEqual(actual, tt.want) {
+				t.Errorf("Aggregator.Read() = %v, want %v", actual, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessor_WithAggregation(t *testing.T) {
+	output, err := processString(t, ordersCSV,
+		csvprocessor.WithAggregation([]int{1}, csvprocessor.Aggregation{Column: 2, Func: csvprocessor.AggSum, Name: "total"}),
+		csvprocessor.WithSortBy([]int{0}, csvprocessor.Ascending),
+	)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	want := "item,total\nbook,55.5\npen,14.5\n"
+	if output != want {
+		t.Errorf("Process() output = %q, want %q", output, want)
+	}
+}
+
+func TestProcessor_WithAggregation_Count(t *testing.T) {
+	output, err := processString(t, "customer,item,amount\nc1,pen,10\nc1,book,\nc2,pen,5\n",
+		csvprocessor.WithAggregation([]int{0},
+			csvprocessor.Aggregation{Column: 0, Func: csvprocessor.AggCount},
+			csvprocessor.Aggregation{Column: 2, Func: csvprocessor.AggCount},
+		),
+	)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	// the rows without an amount are not counted in count_amount.
+	want := "customer,count_customer,count_amount\nc1,2,1\nc2,1,1\n"
+	if output != want {
+		t.Errorf("Process() output = %q, want %q", output, want)
+	}
+}
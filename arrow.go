@@ -0,0 +1,196 @@
+package csvprocessor
+
+import (
+	"errors"
+)
+
+// DefaultBatchSize is the default no. of rows in the batches written by RecordBatchWriter.
+const DefaultBatchSize = 1024
+
+// ErrSchemaMismatch is returned by RecordBatchReader when the columns of a batch do not match the columns of the first batch.
+var ErrSchemaMismatch = errors.New("csvprocessor: columns of the record batch do not match the first batch")
+
+// RecordBatch represents a column-wise batch of rows, Eg: an Arrow record.
+// The package does not read or write the Arrow format and IPC streams, wrap the arrow.Record of the Arrow library to implement it.
+type RecordBatch interface {
+	// Columns returns the names of the columns.
+	Columns() []string
+	// NumRows returns the no. of rows in the batch.
+	NumRows() int
+	// Value returns the value of the given column in the given row, nil for null values.
+	Value(column, row int) any
+}
+
+// RecordBatchSource returns the batches to be processed, Eg: from an Arrow IPC stream reader.
+type RecordBatchSource interface {
+	// Next returns the next batch, and io.EOF after the last batch.
+	Next() (RecordBatch, error)
+}
+
+// RecordBatchSink receives the batches written by RecordBatchWriter, Eg: to convert them to Arrow records for an IPC stream writer.
+// The batch is reused after Write returns, so the sink must not retain it.
+type RecordBatchSink interface {
+	Write(batch *ColumnBatch) error
+}
+
+// ColumnBatch is a RecordBatch with string values stored column-wise.
+type ColumnBatch struct {
+	// Names contains the column names.
+	Names []string
+	// Values contains the values of each column, Values[column][row].
+	Values [][]string
+}
+
+// Columns returns the column names.
+func (b *ColumnBatch) Columns() []string {
+	return b.Names
+}
+
+// NumRows returns the no. of rows in the batch.
+func (b *ColumnBatch) NumRows() int {
+	if len(b.Values) == 0 {
+		return 0
+	}
+
+	return len(b.Values[0])
+}
+
+// Value returns the value of the given column in the given row.
+func (b *ColumnBatch) Value(column, row int) any {
+	return b.Values[column][row]
+}
+
+// RecordBatchReader is a CsvReader that reads the rows of the batches from a RecordBatchSource.
+// The first row returned is the header with the column names of the first batch, all the batches must have the same columns.
+// The values are formatted like ParquetReader.
+type RecordBatchReader struct {
+	source RecordBatchSource
+
+	// Unexported fields
+	header []string
+	batch  RecordBatch
+	next   int // index of the next row in batch.
+	err    error
+}
+
+// NewRecordBatchReader creates a RecordBatchReader for the given source.
+func NewRecordBatchReader(source RecordBatchSource) *RecordBatchReader {
+	return &RecordBatchReader{source: source}
+}
+
+// Read returns the header first and then the rows of the batches.
+func (r *RecordBatchReader) Read() ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	for r.batch == nil || r.next >= r.batch.NumRows() {
+		batch, err := r.source.Next()
+		if err != nil {
+			r.err = err
+			return nil, err
+		}
+
+		r.batch, r.next = batch, 0
+		if r.header == nil {
+			r.header = append([]string(nil), batch.Columns()...)
+			return append([]string(nil), r.header...), nil
+		}
+
+		if !equalRows(r.header, batch.Columns()) {
+			r.err = ErrSchemaMismatch
+			return nil, r.err
+		}
+	}
+
+	row := make([]string, len(r.header))
+	for i := range row {
+		row[i] = formatValue(r.batch.Value(i, r.next))
+	}
+
+	r.next++
+	return row, nil
+}
+
+// RecordBatchWriter is a CsvWriter that groups the rows into column-wise batches and writes them to a RecordBatchSink.
+// The header is used as the column names, Close() must be called to write the last batch.
+type RecordBatchWriter struct {
+	sink      RecordBatchSink
+	batchSize int
+
+	// Unexported fields
+	batch ColumnBatch
+	rows  int // no. of rows in the current batch.
+	err   error
+}
+
+// NewRecordBatchWriter creates a RecordBatchWriter that writes batches of batchSize rows to the sink.
+// If batchSize is <= 0, DefaultBatchSize is used.
+func NewRecordBatchWriter(sink RecordBatchSink, batchSize int) *RecordBatchWriter {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	return &RecordBatchWriter{sink: sink, batchSize: batchSize}
+}
+
+// WriteHeader sets the column names of the batches.
+// If WriteHeader is not called, the columns are named column_1, column_2 and so on.
+func (w *RecordBatchWriter) WriteHeader(header []string) error {
+	w.batch.Names = append([]string(nil), header...)
+	w.batch.Values = make([][]string, len(header))
+	return nil
+}
+
+// Write adds the row to the current batch, the batch is written to the sink when it is full.
+// The missing fields are added as empty strings and the extra fields are dropped.
+func (w *RecordBatchWriter) Write(record []string) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	if w.batch.Names == nil {
+		_ = w.WriteHeader(JSONKeys(make([]string, len(record))))
+	}
+
+	for i := range w.batch.Values {
+		value := ""
+		if i < len(record) {
+			value = record[i]
+		}
+
+		w.batch.Values[i] = append(w.batch.Values[i], value)
+	}
+
+	w.rows++
+	if w.rows >= w.batchSize {
+		w.Flush()
+	}
+
+	return w.err
+}
+
+// Flush writes the current batch to the sink, if it has any rows.
+func (w *RecordBatchWriter) Flush() {
+	if w.err != nil || w.rows == 0 {
+		return
+	}
+
+	w.err = w.sink.Write(&w.batch)
+	for i := range w.batch.Values {
+		w.batch.Values[i] = w.batch.Values[i][:0]
+	}
+
+	w.rows = 0
+}
+
+// Error reports any error that has occurred during a previous Write or Flush.
+func (w *RecordBatchWriter) Error() error {
+	return w.err
+}
+
+// Close writes the last batch to the sink.
+func (w *RecordBatchWriter) Close() error {
+	w.Flush()
+	return w.err
+}
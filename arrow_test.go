@@ -0,0 +1,117 @@
+package csvprocessor_test
+
+import (
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// batchSource is an in-memory RecordBatchSource.
+type batchSource struct {
+	batches []csvprocessor.RecordBatch
+}
+
+func (b *batchSource) Next() (csvprocessor.RecordBatch, error) {
+	if len(b.batches) == 0 {
+		return nil, io.EOF
+	}
+
+	batch := b.batches[0]
+	b.batches = b.batches[1:]
+	return batch, nil
+}
+
+// typedBatch is a row-wise RecordBatch with typed values.
+type typedBatch struct {
+	columns []string
+	rows    [][]any
+}
+
+func (b typedBatch) Columns() []string         { return b.columns }
+func (b typedBatch) NumRows() int              { return len(b.rows) }
+func (b typedBatch) Value(column, row int) any { return b.rows[row][column] }
+
+// batchSink records copies of the batches written to it.
+type batchSink struct {
+	batches []csvprocessor.ColumnBatch
+}
+
+func (b *batchSink) Write(batch *csvprocessor.ColumnBatch) error {
+	values := make([][]string, len(batch.Values))
+	for i, column := range batch.Values {
+		values[i] = append([]string(nil), column...)
+	}
+
+	b.batches = append(b.batches, csvprocessor.ColumnBatch{Names: batch.Names, Values: values})
+	return nil
+}
+
+func TestRecordBatchReader(t *testing.T) {
+	columns := []string{"id", "amount"}
+	source := &batchSource{batches: []csvprocessor.RecordBatch{
+		typedBatch{columns: columns, rows: [][]any{{int64(1), 2.5}, {int64(2), nil}}},
+		typedBatch{columns: columns},
+		&csvprocessor.ColumnBatch{Names: columns, Values: [][]string{{"3"}, {"7"}}},
+	}}
+
+	want := [][]string{{"id", "amount"}, {"1", "2.5"}, {"2", ""}, {"3", "7"}}
+	if got := readAll(t, csvprocessor.NewRecordBatchReader(source)); !reflect.DeepEqual(got, want) {
+		t.Errorf("RecordBatchReader rows = %q, want %q", got, want)
+	}
+}
+
+func TestRecordBatchReader_SchemaMismatch(t *testing.T) {
+	reader := csvprocessor.NewRecordBatchReader(&batchSource{batches: []csvprocessor.RecordBatch{
+		typedBatch{columns: []string{"id"}},
+		typedBatch{columns: []string{"name"}, rows: [][]any{{"x"}}},
+	}})
+
+	if _, err := reader.Read(); err != nil {
+		t.Fatalf("Read() header error = %v", err)
+	}
+
+	if _, err := reader.Read(); !errors.Is(err, csvprocessor.ErrSchemaMismatch) {
+		t.Errorf("Read() error = %v, want %v", err, csvprocessor.ErrSchemaMismatch)
+	}
+}
+
+func TestRecordBatchWriter(t *testing.T) {
+	sink := &batchSink{}
+	source := &batchSource{batches: []csvprocessor.RecordBatch{
+		typedBatch{columns: []string{"id", "name"}, rows: [][]any{{1, "a"}, {2, "b"}, {3, "c"}, {4, "d"}, {5, "e"}}},
+	}}
+
+	proc, err := csvprocessor.New(
+		csvprocessor.WithReader(csvprocessor.NewRecordBatchReader(source)),
+		csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(io.Discard), nil
+		}),
+		csvprocessor.WithWriterFactory(func(io.Writer) csvprocessor.CsvWriter {
+			return csvprocessor.NewRecordBatchWriter(sink, 2)
+		}),
+		csvprocessor.WithChunkSize(math.MaxInt32),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	names := []string{"id", "name"}
+	want := []csvprocessor.ColumnBatch{
+		{Names: names, Values: [][]string{{"1", "2"}, {"a", "b"}}},
+		{Names: names, Values: [][]string{{"3", "4"}, {"c", "d"}}},
+		{Names: names, Values: [][]string{{"5"}, {"e"}}},
+	}
+
+	if !reflect.DeepEqual(sink.batches, want) {
+		t.Errorf("batches = %v, want %v", sink.batches, want)
+	}
+}
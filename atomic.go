@@ -0,0 +1,95 @@
+package csvprocessor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// atomicSuffix is appended to the name of an output file while it is written, see WithAtomicWrites().
+const atomicSuffix = ".tmp"
+
+// WithAtomicWrites writes each output file of WithOutputFileFormat() and WithZipOutput() to a temp file named with a ".tmp" suffix,
+// and renames it to the name of the output file once it is flushed and closed, so that the consumers watching the output directory
+// never see a partially written file. An existing file is handled as set by WithOutputConflictPolicy(),
+// with ConflictAppend, it is copied to the temp file before the rows are appended.
+// If the processing fails, the temp file of the incomplete chunk is removed.
+func WithAtomicWrites(enabled bool) Option {
+	return func(c *Processor) error {
+		c.atomicWrites = enabled
+		return nil
+	}
+}
+
+// atomicFile is an output file that is written to a temp file, which is renamed to the name of the output file on Close().
+type atomicFile struct {
+	file *os.File
+	name string
+}
+
+// createAtomicFile creates the temp file of the output file,
+// if appendFile is true, the content of the existing output file is copied to it so that the rows are appended.
+func createAtomicFile(name string, perm os.FileMode, appendFile bool) (*atomicFile, error) {
+	file, err := os.OpenFile(name+atomicSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm) //nolint:nosnakecase
+	if err != nil {
+		return nil, err
+	}
+
+	f := &atomicFile{file: file, name: name}
+	if appendFile {
+		if err := copyExisting(file, name); err != nil {
+			f.Abort()
+			return nil, fmt.Errorf("csvprocessor: error while copying the existing output file: %w", err)
+		}
+	}
+
+	return f, nil
+}
+
+// copyExisting copies the content of the file with the given name to w, if the file exists.
+func copyExisting(w io.Writer, name string) error {
+	existing, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer existing.Close()
+	_, err = io.Copy(w, existing)
+	return err
+}
+
+func (f *atomicFile) Write(p []byte) (int, error) {
+	return f.file.Write(p)
+}
+
+// Name returns the name of the output file, and not that of the temp file.
+func (f *atomicFile) Name() string {
+	return f.name
+}
+
+// Close closes the temp file and renames it to the name of the output file.
+func (f *atomicFile) Close() error {
+	if err := f.file.Close(); err != nil {
+		_ = os.Remove(f.file.Name())
+		return err
+	}
+
+	if err := os.Rename(f.file.Name(), f.name); err != nil {
+		_ = os.Remove(f.file.Name())
+		return fmt.Errorf("csvprocessor: error while renaming the output file: %w", err)
+	}
+
+	return nil
+}
+
+// Abort removes the temp file, the output file is not created.
+func (f *atomicFile) Abort() {
+	_ = f.file.Close()
+	_ = os.Remove(f.file.Name())
+}
@@ -0,0 +1,119 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithAtomicWrites(t *testing.T) {
+	errRow := errors.New("invalid row")
+	chunkFiles := func(dir string) csvprocessor.Option {
+		return csvprocessor.WithOutputFileFormat(filepath.Join(dir, "out_%d.csv"))
+	}
+	zipArchive := func(dir string) csvprocessor.Option {
+		return csvprocessor.WithZipOutput(filepath.Join(dir, "out.zip"))
+	}
+
+	tests := []struct {
+		name          string
+		output        func(dir string) csvprocessor.Option
+		failAt        string
+		wantWhileOpen []string
+		want          []string
+	}{
+		{
+			name:          "Test chunk files",
+			output:        chunkFiles,
+			wantWhileOpen: []string{"out_1.csv", "out_2.csv.tmp"},
+			want:          []string{"out_1.csv", "out_2.csv"},
+		},
+		{
+			name:          "Test chunk files with error",
+			output:        chunkFiles,
+			failAt:        "4",
+			wantWhileOpen: []string{"out_1.csv", "out_2.csv.tmp"},
+			want:          []string{"out_1.csv"},
+		},
+		{
+			name:          "Test zip archive",
+			output:        zipArchive,
+			wantWhileOpen: []string{"out.zip.tmp"},
+			want:          []string{"out.zip"},
+		},
+		{
+			name:          "Test zip archive with error",
+			output:        zipArchive,
+			failAt:        "4",
+			wantWhileOpen: []string{"out.zip.tmp"},
+			want:          nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var whileOpen []string
+			proc, err := csvprocessor.New(
+				csvprocessor.WithInputReader(strings.NewReader("id\n1\n2\n3\n4\n")),
+				csvprocessor.WithChunkSize(2),
+				tt.output(dir),
+				csvprocessor.WithAtomicWrites(true),
+				csvprocessor.WithChunkHooks(func(chunk csvprocessor.ChunkInfo) error {
+					if chunk.ID == 2 {
+						whileOpen = listFiles(t, dir)
+					}
+
+					return nil
+				}, nil),
+				csvprocessor.WithTransformer(func(_ context.Context, row []string) []string {
+					if row[0] == tt.failAt {
+						// the processor returns the panics in the transformers as errors.
+						panic(errRow)
+					}
+
+					return row
+				}),
+				csvprocessor.WithLogger(t.Logf),
+			)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if err := proc.Process(); tt.failAt == "" && err != nil || tt.failAt != "" && !errors.Is(err, errRow) {
+				t.Fatalf("Process() error = %v, fail at row %q", err, tt.failAt)
+			}
+
+			if !reflect.DeepEqual(whileOpen, tt.wantWhileOpen) {
+				t.Errorf("output files while writing chunk 2 = %v, want %v", whileOpen, tt.wantWhileOpen)
+			}
+
+			if got := listFiles(t, dir); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("output files = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func listFiles(tb testing.TB, dir string) []string {
+	tb.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		tb.Fatalf("ReadDir() error = %v", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	sort.Strings(names)
+	return names
+}
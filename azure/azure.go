@@ -0,0 +1,72 @@
+// Package azure reads the input from and streams the output chunks to Azure Blob Storage.
+//
+// The blobs are downloaded and uploaded through the Client interface, which an azblob.Client from
+// github.com/Azure/azure-sdk-for-go/sdk/storage/azblob satisfies with a small adapter, Eg: with Client.DownloadStream and Client.UploadStream.
+// The authentication is configured while creating the azblob client,
+// Eg: azblob.NewClientWithNoCredential() with a SAS URL or azblob.NewClient() with azidentity.NewDefaultAzureCredential().
+package azure
+
+import (
+	"context"
+	"io"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+	"github.com/sivaramasubramanian/csvprocessor/internal/upload"
+)
+
+// Client represents the Azure Blob Storage operations used by this package.
+type Client interface {
+	// DownloadStream returns the content of the blob, the content is read as a stream.
+	DownloadStream(ctx context.Context, container, blob string) (io.ReadCloser, error)
+	// UploadStream uploads the content read from body till EOF as a block blob.
+	UploadStream(ctx context.Context, container, blob string, body io.Reader) error
+}
+
+// AzureFileReader sets the blob as the input of the processor, the blob is streamed without downloading it to disk.
+// The blob is opened at the first read, so that it is not left open if the processor is not created or run.
+func AzureFileReader(client Client, container, blob string) csvprocessor.Option { //nolint:revive
+	return csvprocessor.WithInputReader(&blobReader{client: client, container: container, blob: blob})
+}
+
+// AzureChunkGenerator returns an OutputChunkGenerator that streams each chunk to a block blob as it is produced.
+// The name of each blob is generated from nameFormat using csvprocessor.ChunkName(), Eg: "exports/orders_%03d.csv".
+// The upload completes when the chunk is closed, and is aborted if the processing fails.
+func AzureChunkGenerator(client Client, container, nameFormat string) csvprocessor.OutputChunkGenerator { //nolint:revive
+	return func(chunkID int) (io.WriteCloser, error) {
+		blob := csvprocessor.ChunkName(nameFormat, chunkID)
+		return upload.NewWriter(func(body io.Reader) error {
+			return client.UploadStream(context.Background(), container, blob, body)
+		}), nil
+	}
+}
+
+// blobReader is the content of a blob, it is opened at the first read.
+type blobReader struct {
+	client          Client
+	container, blob string
+
+	// Unexported fields
+	body io.ReadCloser
+	err  error
+}
+
+func (b *blobReader) Read(p []byte) (int, error) {
+	if b.body == nil && b.err == nil {
+		b.body, b.err = b.client.DownloadStream(context.Background(), b.container, b.blob)
+	}
+
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	return b.body.Read(p)
+}
+
+// Close closes the blob if it was opened.
+func (b *blobReader) Close() error {
+	if b.body == nil {
+		return nil
+	}
+
+	return b.body.Close()
+}
@@ -0,0 +1,128 @@
+package azure_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+	"github.com/sivaramasubramanian/csvprocessor/azure"
+)
+
+// fakeAzure is an in-memory Client.
+type fakeAzure struct {
+	mu        sync.Mutex
+	objects   map[string]string
+	downloads int
+}
+
+func (f *fakeAzure) DownloadStream(_ context.Context, container, blob string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.downloads++
+	content, ok := f.objects[container+"/"+blob]
+	if !ok {
+		return nil, fmt.Errorf("BlobNotFound: %s/%s", container, blob)
+	}
+
+	return io.NopCloser(bytes.NewBufferString(content)), nil
+}
+
+func (f *fakeAzure) UploadStream(_ context.Context, container, blob string, body io.Reader) error {
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[container+"/"+blob] = string(content)
+	return nil
+}
+
+func TestAzure(t *testing.T) {
+	client := &fakeAzure{objects: map[string]string{"in/orders.csv": "id\n1\n2\n3\n"}}
+
+	proc, err := csvprocessor.New(
+		azure.AzureFileReader(client, "in", "orders.csv"),
+		csvprocessor.WithWriterGenerator(azure.AzureChunkGenerator(client, "out", "orders/part_%02d.csv")),
+		csvprocessor.WithChunkSize(2),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	want := map[string]string{
+		"in/orders.csv":          "id\n1\n2\n3\n",
+		"out/orders/part_01.csv": "id\n1\n2\n",
+		"out/orders/part_02.csv": "id\n3\n",
+	}
+	if !reflect.DeepEqual(client.objects, want) {
+		t.Errorf("objects = %q, want %q", client.objects, want)
+	}
+}
+
+func TestAzure_Abort(t *testing.T) {
+	client := &fakeAzure{objects: map[string]string{"in/orders.csv": "id\n1\n2\n3\n"}}
+	errBadRow := errors.New("bad row")
+
+	proc, err := csvprocessor.New(
+		azure.AzureFileReader(client, "in", "orders.csv"),
+		csvprocessor.WithWriterGenerator(azure.AzureChunkGenerator(client, "out", "part_%02d.csv")),
+		csvprocessor.WithChunkSize(10),
+		csvprocessor.WithTransformer(func(ctx context.Context, row []string) []string {
+			if row[0] == "3" {
+				panic(errBadRow)
+			}
+
+			return row
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); !errors.Is(err, errBadRow) {
+		t.Fatalf("Process() error = %v, want %v", err, errBadRow)
+	}
+
+	if _, ok := client.objects["out/part_01.csv"]; ok {
+		t.Errorf("partial chunk was uploaded")
+	}
+}
+
+func TestAzureFileReader_Error(t *testing.T) {
+	client := &fakeAzure{objects: map[string]string{}}
+	proc, err := csvprocessor.New(
+		azure.AzureFileReader(client, "in", "missing.csv"),
+		csvprocessor.WithWriterGenerator(azure.AzureChunkGenerator(client, "out", "part_%02d.csv")),
+		csvprocessor.WithChunkSize(2),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err == nil {
+		t.Errorf("Process() error = nil, want an error for missing object")
+	}
+
+	// the blob is not opened if the processor is not created.
+	client = &fakeAzure{objects: map[string]string{"in/orders.csv": "id\n1\n"}}
+	if _, err := csvprocessor.New(azure.AzureFileReader(client, "in", "orders.csv")); err == nil {
+		t.Errorf("New() error = nil, want an error for the missing generator")
+	}
+
+	if client.downloads != 0 {
+		t.Errorf("DownloadStream() called %d times, want 0", client.downloads)
+	}
+}
@@ -0,0 +1,64 @@
+package csvprocessor
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// bloomFilter is a space efficient probabilistic set used for approximate membership checks.
+// It can report false positives but never false negatives.
+type bloomFilter struct {
+	bits   []uint64
+	size   uint64 // no. of bits in the filter
+	hashes uint64 // no. of hash functions
+}
+
+// newBloomFilter creates a bloomFilter sized for the expected no. of items and the given false positive rate.
+func newBloomFilter(expectedItems int, falsePositiveRate float64) *bloomFilter {
+	if expectedItems <= 0 {
+		expectedItems = 1
+	}
+
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+
+	n := float64(expectedItems)
+	size := math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	hashes := math.Max(1, math.Round(size/n*math.Ln2))
+
+	return &bloomFilter{
+		bits:   make([]uint64, (uint64(size)+63)/64),
+		size:   uint64(size),
+		hashes: uint64(hashes),
+	}
+}
+
+// addIfAbsent adds the key to the filter and reports whether it was (probably) already present.
+func (b *bloomFilter) addIfAbsent(key string) bool {
+	h1, h2 := bloomHashes(key)
+	present := true
+
+	for i := uint64(0); i < b.hashes; i++ {
+		bit := (h1 + i*h2) % b.size
+		word, mask := bit/64, uint64(1)<<(bit%64)
+		if b.bits[word]&mask == 0 {
+			present = false
+			b.bits[word] |= mask
+		}
+	}
+
+	return present
+}
+
+// bloomHashes returns two independent hashes of the key used for double hashing.
+func bloomHashes(key string) (uint64, uint64) {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(key))
+	h1 := hash.Sum64()
+
+	_, _ = hash.Write([]byte{0})
+	h2 := hash.Sum64() | 1
+
+	return h1, h2
+}
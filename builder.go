@@ -0,0 +1,197 @@
+package csvprocessor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ProcessorBuilder builds a Processor using chained method calls, as an alternative to New() for complex pipelines. Eg:
+//
+//	proc, err := csvprocessor.Builder().
+//		FromFile("orders.csv").
+//		Delimiter(';').
+//		Transform(csvprocessor.AddRowNoTransformer("S.No")).
+//		ChunkRows(10000).
+//		ToFiles("orders_%03d.csv").
+//		Build()
+//
+// Each call is validated as it is made, and the first error is returned from Build() with the call that caused it,
+// Eg: `csvprocessor: Builder.ChunkRows(0): ...`. The calls after an error are ignored.
+type ProcessorBuilder struct {
+	processor    Processor
+	transformers []CsvRowTransformer
+	err          error
+}
+
+// Builder returns a new ProcessorBuilder.
+func Builder() *ProcessorBuilder {
+	return &ProcessorBuilder{processor: defaultProcessor}
+}
+
+// apply applies the option, and records the error with the call that caused it.
+func (b *ProcessorBuilder) apply(call string, opt Option) *ProcessorBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	if err := opt(&b.processor); err != nil {
+		b.err = fmt.Errorf("csvprocessor: Builder.%s: %w", call, err)
+	}
+
+	return b
+}
+
+// FromFile reads the input from the files in the given order, see WithFileReaders().
+func (b *ProcessorBuilder) FromFile(paths ...string) *ProcessorBuilder {
+	return b.apply(fmt.Sprintf("FromFile(%s)", quoteAll(paths)), WithFileReaders(paths...))
+}
+
+// FromGlob reads the input from the files matching the pattern, see WithInputGlob().
+func (b *ProcessorBuilder) FromGlob(pattern string) *ProcessorBuilder {
+	return b.apply(fmt.Sprintf("FromGlob(%q)", pattern), WithInputGlob(pattern))
+}
+
+// FromReader reads the input from the stream, see WithInputReader().
+func (b *ProcessorBuilder) FromReader(r io.Reader) *ProcessorBuilder {
+	return b.apply("FromReader()", WithInputReader(r))
+}
+
+// FromStdin reads the input from the standard input, see WithStdin().
+func (b *ProcessorBuilder) FromStdin() *ProcessorBuilder {
+	return b.apply("FromStdin()", WithStdin())
+}
+
+// Delimiter sets the field delimiter of the input, see WithInputDelimiter().
+func (b *ProcessorBuilder) Delimiter(delimiter rune) *ProcessorBuilder {
+	return b.apply(fmt.Sprintf("Delimiter(%q)", delimiter), WithInputDelimiter(delimiter))
+}
+
+// Encoding sets the character encoding of the input, see WithInputEncoding().
+func (b *ProcessorBuilder) Encoding(name string) *ProcessorBuilder {
+	return b.apply(fmt.Sprintf("Encoding(%q)", name), WithInputEncoding(name))
+}
+
+// NoHeader is used when the input does not have a header row, see SkipHeaders().
+func (b *ProcessorBuilder) NoHeader() *ProcessorBuilder {
+	return b.apply("NoHeader()", SkipHeaders(true))
+}
+
+// Transform adds the transformers, all the transformers are applied to each row in the order they are added. See ChainTransformers().
+func (b *ProcessorBuilder) Transform(transformers ...CsvRowTransformer) *ProcessorBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	for i, transformer := range transformers {
+		if transformer == nil {
+			b.err = fmt.Errorf("csvprocessor: Builder.Transform(): transformer %d is nil", i+1)
+			return b
+		}
+	}
+
+	b.transformers = append(b.transformers, transformers...)
+	return b
+}
+
+// ChunkRows sets the no. of rows in each chunk, see WithChunkSize().
+func (b *ProcessorBuilder) ChunkRows(n int) *ProcessorBuilder {
+	return b.apply(fmt.Sprintf("ChunkRows(%d)", n), func(c *Processor) error {
+		if n <= 0 {
+			return ErrInvalidChunkSize
+		}
+
+		return WithChunkSize(n)(c)
+	})
+}
+
+// ToFiles writes each chunk to a file named using the format, see WithOutputFileFormat().
+func (b *ProcessorBuilder) ToFiles(format string) *ProcessorBuilder {
+	return b.apply(fmt.Sprintf("ToFiles(%q)", format), WithOutputFileFormat(format))
+}
+
+// ToZip writes the chunks as entries of a zip archive, see WithZipOutput().
+func (b *ProcessorBuilder) ToZip(path string) *ProcessorBuilder {
+	return b.apply(fmt.Sprintf("ToZip(%q)", path), WithZipOutput(path))
+}
+
+// ToWriter writes all the chunks to w one after another, w is not closed. Eg: to write a single output to os.Stdout.
+func (b *ProcessorBuilder) ToWriter(w io.Writer) *ProcessorBuilder {
+	return b.apply("ToWriter()", func(c *Processor) error {
+		if w == nil {
+			return ErrOutputWriterNil
+		}
+
+		return WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return NoOpCloser(w), nil
+		})(c)
+	})
+}
+
+// ToGenerator writes each chunk to the writer returned by the generator, see WithWriterGenerator().
+func (b *ProcessorBuilder) ToGenerator(generator OutputChunkGenerator) *ProcessorBuilder {
+	return b.apply("ToGenerator()", func(c *Processor) error {
+		if generator == nil {
+			return ErrOutputWriterNil
+		}
+
+		return WithWriterGenerator(generator)(c)
+	})
+}
+
+// Logger sets the logger, see WithLogger().
+func (b *ProcessorBuilder) Logger(logger Logger) *ProcessorBuilder {
+	return b.apply("Logger()", WithLogger(logger))
+}
+
+// With applies the options, for the customizations that do not have a builder method.
+func (b *ProcessorBuilder) With(opts ...Option) *ProcessorBuilder {
+	for i, opt := range opts {
+		b.apply(fmt.Sprintf("With(option %d)", i+1), opt)
+	}
+
+	return b
+}
+
+// Build returns the Processor, or the first error from the calls.
+// If a required call is missing, the error says which calls can be used. The input files are closed if there is an error.
+// The options can refer to the Processor they are applied to, so the built Processor is the builder's own and not a copy.
+func (b *ProcessorBuilder) Build() (*Processor, error) {
+	processor := &b.processor
+	if b.err != nil {
+		_ = processor.closeInputs()
+		return nil, b.err
+	}
+
+	if len(b.transformers) > 0 {
+		processor.rowTransformer = ChainTransformers(b.transformers...)
+	}
+
+	if _, err := validate(processor); err != nil {
+		_ = processor.closeInputs()
+
+		hint := "invalid processor"
+		switch {
+		case errors.Is(err, ErrInputReaderNil):
+			hint = "no input, call FromFile(), FromGlob(), FromReader() or FromStdin()"
+		case errors.Is(err, ErrOutputChunkGeneratorNotSet):
+			hint = "no output, call ToFiles(), ToZip(), ToWriter() or ToGenerator()"
+		case errors.Is(err, ErrInvalidChunkSize):
+			hint = "no chunk size, call ChunkRows()"
+		}
+
+		return nil, fmt.Errorf("csvprocessor: Builder.Build(): %s: %w", hint, err)
+	}
+
+	return processor, nil
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,175 @@
+package csvprocessor_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestBuilder(t *testing.T) {
+	var output strings.Builder
+	proc, err := csvprocessor.Builder().
+		FromReader(strings.NewReader("id;name\n1;alice\n2;NULL\n")).
+		Delimiter(';').
+		Transform(csvprocessor.ReplaceValuesTransformer(map[string]string{"NULL": ""})).
+		Transform(csvprocessor.AddRowNoTransformer("S.No")).
+		ChunkRows(10).
+		ToWriter(&output).
+		Logger(t.Logf).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if want := "S.No,id,name\n1,1,alice\n2,2,\n"; output.String() != want {
+		t.Errorf("Process() output = %q, want %q", output.String(), want)
+	}
+}
+
+func TestBuilder_OutputFileFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		format string
+		opt    csvprocessor.Option
+		want   map[string]string
+	}{
+		{
+			name:   "Test pre-scan total chunks",
+			input:  "id\n1\n2\n3\n4\n5\n",
+			format: "o_%d_of_%d.csv",
+			opt:    csvprocessor.WithPreScan(true),
+			want:   map[string]string{"o_1_of_3.csv": "id\n1\n2\n", "o_2_of_3.csv": "id\n3\n4\n", "o_3_of_3.csv": "id\n5\n"},
+		},
+		{
+			name:   "Test time window",
+			input:  "id,at\n1,2024-03-01 10:00:00\n2,2024-03-02 09:00:00\n",
+			format: "dt=%[2]s_%[1]d.csv",
+			opt:    csvprocessor.WithChunkByTimeColumn(1, "2006-01-02 15:04:05", 24*time.Hour),
+			want: map[string]string{
+				"dt=2024-03-01_1.csv": "id,at\n1,2024-03-01 10:00:00\n",
+				"dt=2024-03-02_2.csv": "id,at\n2,2024-03-02 09:00:00\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			input := filepath.Join(dir, "input.csv")
+			if err := os.WriteFile(input, []byte(tt.input), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			proc, err := csvprocessor.Builder().
+				FromFile(input).
+				ChunkRows(2).
+				ToFiles(filepath.Join(dir, tt.format)).
+				With(tt.opt).
+				Logger(t.Logf).
+				Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+
+			if err := proc.Process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			for name, want := range tt.want {
+				got, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(got) != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.csv")
+
+	tests := []struct {
+		name        string
+		builder     *csvprocessor.ProcessorBuilder
+		wantErr     error
+		wantMessage string
+	}{
+		{
+			name:        "invalid chunk size",
+			builder:     csvprocessor.Builder().FromStdin().ChunkRows(0).ToFiles("out_%d.csv"),
+			wantErr:     csvprocessor.ErrInvalidChunkSize,
+			wantMessage: "Builder.ChunkRows(0)",
+		},
+		{
+			name:        "first error is returned",
+			builder:     csvprocessor.Builder().FromStdin().Delimiter('"').ChunkRows(0),
+			wantErr:     csvprocessor.ErrInvalidDelimiter,
+			wantMessage: `Builder.Delimiter('"')`,
+		},
+		{
+			name:        "missing file",
+			builder:     csvprocessor.Builder().FromFile(missing),
+			wantMessage: "Builder.FromFile(",
+		},
+		{
+			name:        "nil transformer",
+			builder:     csvprocessor.Builder().FromStdin().Transform(nil),
+			wantMessage: "Builder.Transform(): transformer 1 is nil",
+		},
+		{
+			name:        "missing input",
+			builder:     csvprocessor.Builder().ChunkRows(1).ToFiles("out_%d.csv"),
+			wantErr:     csvprocessor.ErrInputReaderNil,
+			wantMessage: "no input, call FromFile()",
+		},
+		{
+			name:        "missing output",
+			builder:     csvprocessor.Builder().FromStdin().ChunkRows(1),
+			wantErr:     csvprocessor.ErrOutputChunkGeneratorNotSet,
+			wantMessage: "no output, call ToFiles()",
+		},
+		{
+			name:        "missing chunk size",
+			builder:     csvprocessor.Builder().FromStdin().ToWriter(&strings.Builder{}),
+			wantErr:     csvprocessor.ErrInvalidChunkSize,
+			wantMessage: "no chunk size, call ChunkRows()",
+		},
+		{
+			name:        "option",
+			builder:     csvprocessor.Builder().FromStdin().With(csvprocessor.WithChunkSize(1), csvprocessor.WithSkipRows(-1)),
+			wantErr:     csvprocessor.ErrInvalidRowCount,
+			wantMessage: "Builder.With(option 2)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			if err == nil {
+				t.Fatal("Build() error = nil")
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Build() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !strings.Contains(err.Error(), tt.wantMessage) {
+				t.Errorf("Build() error = %q, want it to contain %q", err, tt.wantMessage)
+			}
+		})
+	}
+}
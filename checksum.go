@@ -0,0 +1,89 @@
+package csvprocessor
+
+import (
+	"crypto/md5"  //nolint:gosec
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// Supported checksum algorithms, see WithChunkChecksums().
+const (
+	ChecksumMD5    = "md5"
+	ChecksumSHA1   = "sha1"
+	ChecksumSHA256 = "sha256"
+	ChecksumSHA512 = "sha512"
+)
+
+// ErrUnknownChecksum is returned when the checksum algorithm is not supported.
+var ErrUnknownChecksum = errors.New("csvprocessor: unknown checksum algorithm")
+
+var checksumAlgorithms = map[string]func() hash.Hash{
+	ChecksumMD5:    md5.New,
+	ChecksumSHA1:   sha1.New,
+	ChecksumSHA256: sha256.New,
+	ChecksumSHA512: sha512.New,
+}
+
+// WithChunkChecksums computes the checksums of each chunk as it is written, Eg: for integrity checks when the chunks are shipped.
+// The hex encoded digests are available in ChunkInfo.Checksums by the name of the algorithm, in Stats() and in the chunk hooks (see WithChunkHooks()). Eg:
+//
+//	csvprocessor.WithChunkChecksums(csvprocessor.ChecksumSHA256)
+//	...
+//	for _, chunk := range proc.Stats().Chunks {
+//		fmt.Printf("%s  %s\n", chunk.Checksums[csvprocessor.ChecksumSHA256], chunk.Name)
+//	}
+//
+// The checksums are computed on the bytes written to the writer returned by the OutputChunkGenerator, i.e. after the output encoding.
+func WithChunkChecksums(algorithms ...string) Option {
+	return func(c *Processor) error {
+		for _, algorithm := range algorithms {
+			if _, ok := checksumAlgorithms[algorithm]; !ok {
+				return fmt.Errorf("%w: %q", ErrUnknownChecksum, algorithm)
+			}
+		}
+
+		c.checksums = algorithms
+		return nil
+	}
+}
+
+// chunkHashes computes the checksums of a chunk.
+type chunkHashes struct {
+	algorithms []string
+	hashes     []hash.Hash
+}
+
+func newChunkHashes(algorithms []string) *chunkHashes {
+	h := &chunkHashes{algorithms: algorithms, hashes: make([]hash.Hash, len(algorithms))}
+	for i, algorithm := range algorithms {
+		h.hashes[i] = checksumAlgorithms[algorithm]()
+	}
+
+	return h
+}
+
+// tee returns a writer that writes to w and to the hashes.
+func (h *chunkHashes) tee(w io.Writer) io.Writer {
+	writers := []io.Writer{w}
+	for _, hash := range h.hashes {
+		writers = append(writers, hash)
+	}
+
+	return io.MultiWriter(writers...)
+}
+
+// sums returns the hex encoded digests by the name of the algorithm.
+func (h *chunkHashes) sums() map[string]string {
+	sums := make(map[string]string, len(h.hashes))
+	for i, hash := range h.hashes {
+		sums[h.algorithms[i]] = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	return sums
+}
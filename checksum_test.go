@@ -0,0 +1,90 @@
+package csvprocessor_test
+
+import (
+	"crypto/md5" //nolint:gosec
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithChunkChecksums(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []csvprocessor.Option
+	}{
+		{
+			name: "csv",
+		},
+		{
+			name: "output encoding",
+			opts: []csvprocessor.Option{csvprocessor.WithOutputEncoding("utf16"), csvprocessor.WithOutputBOM(true)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFormat := filepath.Join(t.TempDir(), "out_%d.csv")
+			opts := append([]csvprocessor.Option{
+				csvprocessor.WithInputReader(strings.NewReader("id,name\n1,é\n2,b\n3,c\n")),
+				csvprocessor.WithChunkSize(2),
+				csvprocessor.WithOutputFileFormat(outputFormat),
+				csvprocessor.WithChunkChecksums(csvprocessor.ChecksumMD5, csvprocessor.ChecksumSHA256),
+				csvprocessor.WithLogger(t.Logf),
+			}, tt.opts...)
+			proc, err := csvprocessor.New(opts...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if err := proc.Process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			chunks := proc.Stats().Chunks
+			if len(chunks) != 2 {
+				t.Fatalf("len(Stats().Chunks) = %d, want 2", len(chunks))
+			}
+
+			for _, chunk := range chunks {
+				data, err := os.ReadFile(csvprocessor.ChunkName(outputFormat, chunk.ID))
+				if err != nil {
+					t.Fatalf("ReadFile() error = %v", err)
+				}
+
+				md5Sum := md5.Sum(data) //nolint:gosec
+				sha256Sum := sha256.Sum256(data)
+				want := map[string]string{
+					csvprocessor.ChecksumMD5:    hex.EncodeToString(md5Sum[:]),
+					csvprocessor.ChecksumSHA256: hex.EncodeToString(sha256Sum[:]),
+				}
+				for algorithm, sum := range want {
+					if chunk.Checksums[algorithm] != sum {
+						t.Errorf("chunk %d: Checksums[%q] = %q, want %q", chunk.ID, algorithm, chunk.Checksums[algorithm], sum)
+					}
+				}
+
+				if chunk.Bytes != int64(len(data)) {
+					t.Errorf("chunk %d: Bytes = %d, want %d", chunk.ID, chunk.Bytes, len(data))
+				}
+			}
+		})
+	}
+}
+
+func TestWithChunkChecksumsError(t *testing.T) {
+	_, err := csvprocessor.New(
+		csvprocessor.WithStdin(),
+		csvprocessor.WithChunkSize(2),
+		csvprocessor.WithOutputFileFormat("out_%d.csv"),
+		csvprocessor.WithChunkChecksums("crc32"),
+	)
+	if !errors.Is(err, csvprocessor.ErrUnknownChecksum) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrUnknownChecksum)
+	}
+}
@@ -0,0 +1,98 @@
+// Command csvproc splits, transforms, validates, profiles and merges CSV files using the csvprocessor package.
+// Pipelines described by a config file (see csvprocessor.Config) can be run using the run command.
+//
+// Usage:
+//
+//	csvproc split [flags] [files...]      split the input into chunks
+//	csvproc transform [flags] [files...]  transform the input into a single output
+//	csvproc merge [flags] files...        merge the inputs (or -glob, -manifest) into a single output, the header is written once
+//	csvproc validate [flags] [files...]   validate the input against a schema
+//	csvproc profile [flags] [files...]    print the data profile of the input
+//	csvproc schema [flags] [files...]     infer the schema of the input, in the format read by validate
+//	csvproc run -config file [files...]   run the pipeline described by a JSON config file
+//	csvproc transformers                  list the transformers that can be used in a config file
+//
+// The input is read from the given files in order, or from the standard input if no files are given or the file is "-".
+// Run "csvproc <command> -h" for the flags of each command.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `csvproc splits, transforms, validates, profiles and merges CSV files.
+
+Usage:
+  csvproc split [flags] [files...]      split the input into chunks
+  csvproc transform [flags] [files...]  transform the input into a single output
+  csvproc merge [flags] files...        merge the inputs (or -glob, -manifest) into a single output, the header is written once
+  csvproc validate [flags] [files...]   validate the input against a schema
+  csvproc profile [flags] [files...]    print the data profile of the input
+  csvproc schema [flags] [files...]     infer the schema of the input, in the format read by validate
+  csvproc run -config file [files...]   run the pipeline described by a JSON config file
+  csvproc transformers                  list the transformers that can be used in a config file
+
+The input is read from the given files, or from the standard input if no files are given or the file is "-".
+Run "csvproc <command> -h" for the flags of each command.
+`
+
+// exit codes.
+const (
+	exitOK     = 0
+	exitFailed = 1 // processing failed or the input is not valid.
+	exitUsage  = 2 // invalid command or flags.
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command in args and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	var err error
+	switch args[0] {
+	case "split":
+		err = runProcess(commandSplit, args[1:], stdin, stdout, stderr)
+	case "transform":
+		err = runProcess(commandTransform, args[1:], stdin, stdout, stderr)
+	case "merge":
+		err = runProcess(commandMerge, args[1:], stdin, stdout, stderr)
+	case "validate":
+		err = runValidate(args[1:], stdin, stdout, stderr)
+	case "profile":
+		err = runProfile(args[1:], stdin, stdout, stderr)
+	case "schema":
+		err = runSchema(args[1:], stdin, stdout, stderr)
+	case "run":
+		err = runConfig(args[1:], stdin, stderr)
+	case "transformers":
+		listTransformers(stdout)
+		return exitOK
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "csvproc: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+
+	switch {
+	case err == nil || errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errUsage):
+		// the flag package has already printed the error and the usage.
+		return exitUsage
+	default:
+		fmt.Fprintf(stderr, "csvproc: %v\n", err)
+		return exitFailed
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const input = "id,name\n1,alice\n2,bob\n3,carol\n2,bob\n"
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.csv")
+	second := filepath.Join(dir, "second.csv")
+	schema := filepath.Join(dir, "schema.json")
+	config := filepath.Join(dir, "pipeline.json")
+	writeFile(t, first, "id,name\n1,alice\n")
+	writeFile(t, second, "id,name\n2,bob\n")
+	writeFile(t, filepath.Join(dir, "chunk_2.csv"), "id,name\n2,bob\n")
+	writeFile(t, filepath.Join(dir, "chunk_10.csv"), "id,name\n10,carol\n")
+	manifest := filepath.Join(dir, "manifest.txt")
+	writeFile(t, manifest, "# chunks\nsecond.csv\n\nfirst.csv\n")
+	writeFile(t, config, `{"output": {"file_format": "`+filepath.Join(dir, "config_%d.jsonl")+`", "format": "jsonl"}, "chunk_size": 10,
+		"transformers": [{"name": "replace_values", "params": {"replacements": {"bob": "robert"}}}]}`)
+	writeFile(t, schema, `{"columns": [{"name": "id", "type": "int", "required": true}, {"name": "name"}]}`)
+
+	tests := []struct {
+		name         string
+		args         []string
+		stdin        string
+		wantCode     int
+		wantStdout   string
+		wantInStdout string
+		wantFiles    map[string]string
+	}{
+		{
+			name:     "split",
+			args:     []string{"split", "-chunk-size", "2", "-dedup", "0", "-add-row-num", "sno", "-o", filepath.Join(dir, "split_%d.csv")},
+			stdin:    input,
+			wantCode: exitOK,
+			wantFiles: map[string]string{
+				"split_1.csv": "sno,id,name\n1,1,alice\n2,2,bob\n",
+				"split_2.csv": "sno,id,name\n3,3,carol\n",
+			},
+		},
+		{
+			name:     "split with no. of chunks",
+			args:     []string{"split", "-chunk-size", "2", "-pre-scan", "-o", filepath.Join(dir, "part_%d_of_%d.csv"), first},
+			wantCode: exitOK,
+			wantFiles: map[string]string{
+				"part_1_of_1.csv": "id,name\n1,alice\n",
+			},
+		},
+		{
+			name:     "split round-robin",
+			args:     []string{"split", "-round-robin", "2", "-o", filepath.Join(dir, "shard_%d.csv")},
+			stdin:    input,
+			wantCode: exitOK,
+			wantFiles: map[string]string{
+				"shard_1.csv": "id,name\n1,alice\n3,carol\n",
+				"shard_2.csv": "id,name\n2,bob\n2,bob\n",
+			},
+		},
+		{
+			name:     "split by hash of key",
+			args:     []string{"split", "-hash-shards", "1", "-shard-column", "1", "-o", filepath.Join(dir, "hash_%d.csv")},
+			stdin:    input,
+			wantCode: exitOK,
+			wantFiles: map[string]string{
+				"hash_1.csv": input,
+			},
+		},
+		{
+			name:     "split keeping groups",
+			args:     []string{"split", "-chunk-size", "1", "-keep-groups", "0", "-o", filepath.Join(dir, "group_%d.csv")},
+			stdin:    "order,item\n1,a\n1,b\n2,c\n",
+			wantCode: exitOK,
+			wantFiles: map[string]string{
+				"group_1.csv": "order,item\n1,a\n1,b\n",
+				"group_2.csv": "order,item\n2,c\n",
+			},
+		},
+		{
+			name:     "split into chunks",
+			args:     []string{"split", "-chunks", "2", "-o", filepath.Join(dir, "half_%d_of_%d.csv"), first},
+			wantCode: exitOK,
+			wantFiles: map[string]string{
+				"half_1_of_1.csv": "id,name\n1,alice\n",
+			},
+		},
+		{
+			name:     "split with min. rows in the last chunk",
+			args:     []string{"split", "-chunk-size", "2", "-min-last-chunk", "2", "-o", filepath.Join(dir, "min_%d.csv")},
+			stdin:    "id\n1\n2\n3\n",
+			wantCode: exitOK,
+			wantFiles: map[string]string{
+				"min_1.csv": "id\n1\n2\n3\n",
+			},
+		},
+		{
+			name:     "split by day",
+			args:     []string{"split", "-time-column", "1", "-time-layout", "2006-01-02", "-o", filepath.Join(dir, "dt=%[2]s_%[1]d.csv")},
+			stdin:    "id,created\n1,2024-03-01\n2,2024-03-01\n3,2024-03-02\n",
+			wantCode: exitOK,
+			wantFiles: map[string]string{
+				"dt=2024-03-01_1.csv": "id,created\n1,2024-03-01\n2,2024-03-01\n",
+				"dt=2024-03-02_2.csv": "id,created\n3,2024-03-02\n",
+			},
+		},
+		{
+			name:       "transform to json lines",
+			args:       []string{"transform", "-format", "jsonl", "-add-column", "source=test", "-replace", "bob=robert", "-"},
+			stdin:      "id,name\n1,alice\n2,bob\n",
+			wantCode:   exitOK,
+			wantStdout: `{"id":"1","name":"alice","source":"test"}` + "\n" + `{"id":"2","name":"robert","source":"test"}` + "\n",
+		},
+		{
+			name:       "transform row no. offset and width",
+			args:       []string{"transform", "-add-row-num", "sno", "-row-num-offset", "98", "-row-num-width", "3"},
+			stdin:      "id,name\n1,alice\n2,bob\n",
+			wantCode:   exitOK,
+			wantStdout: "sno,id,name\n099,1,alice\n100,2,bob\n",
+		},
+		{
+			name:       "transform with header",
+			args:       []string{"transform", "-header", "id,name", "-format", "jsonl"},
+			stdin:      "1,alice\n",
+			wantCode:   exitOK,
+			wantStdout: `{"id":"1","name":"alice"}` + "\n",
+		},
+		{
+			name:     "transform unexpected header",
+			args:     []string{"transform", "-expect-header", "name,id", "-strict-header"},
+			stdin:    "id,name\n1,alice\n",
+			wantCode: exitFailed,
+		},
+		{
+			name:       "transform tab delimited",
+			args:       []string{"transform", "-delimiter", "tab", "-out-delimiter", ";"},
+			stdin:      "id\tname\n1\talice\n",
+			wantCode:   exitOK,
+			wantStdout: "id;name\n1;alice\n",
+		},
+		{
+			name:       "merge",
+			args:       []string{"merge", first, second},
+			wantCode:   exitOK,
+			wantStdout: "id,name\n1,alice\n2,bob\n",
+		},
+		{
+			name:       "merge glob in chunk order",
+			args:       []string{"merge", "-glob", filepath.Join(dir, "chunk_*.csv")},
+			wantCode:   exitOK,
+			wantStdout: "id,name\n2,bob\n10,carol\n",
+		},
+		{
+			name:       "merge manifest",
+			args:       []string{"merge", "-manifest", manifest},
+			wantCode:   exitOK,
+			wantStdout: "id,name\n2,bob\n1,alice\n",
+		},
+		{
+			name:         "validate",
+			args:         []string{"validate", "-schema", schema},
+			stdin:        "id,name\n1,alice\nx,bob\n",
+			wantCode:     exitFailed,
+			wantInStdout: "x",
+		},
+		{
+			name:         "profile",
+			args:         []string{"profile", "-top", "1"},
+			stdin:        input,
+			wantCode:     exitOK,
+			wantInStdout: "column 1: name (string)\n  values: 4, empty: 0, nulls: 0, distinct: ~3, max length: 5\n  min: \"alice\", max: \"carol\"\n  top values: bob (2)\n",
+		},
+		{
+			name:         "profile as json",
+			args:         []string{"profile", "-json"},
+			stdin:        input,
+			wantCode:     exitOK,
+			wantInStdout: `"type": "int"`,
+		},
+		{
+			name:     "schema",
+			args:     []string{"schema", "-sample", "2"},
+			stdin:    "id,name\n1,alice\n2,\nx,bob\n",
+			wantCode: exitOK,
+			wantStdout: `{
+  "columns": [
+    {
+      "name": "id",
+      "type": "int",
+      "required": true,
+      "max_length": 1
+    },
+    {
+      "name": "name",
+      "type": "string",
+      "required": false,
+      "max_length": 5
+    }
+  ]
+}
+`,
+		},
+		{
+			name:     "run config",
+			args:     []string{"run", "-config", config, second},
+			wantCode: exitOK,
+			wantFiles: map[string]string{
+				"config_1.jsonl": `{"id":"2","name":"robert"}` + "\n",
+			},
+		},
+		{
+			name:     "run config from stdin",
+			args:     []string{"run", "-config", config},
+			stdin:    "id,name\n1,bob\n",
+			wantCode: exitOK,
+			wantFiles: map[string]string{
+				"config_1.jsonl": `{"id":"1","name":"robert"}` + "\n",
+			},
+		},
+		{
+			name:         "transformers",
+			args:         []string{"transformers"},
+			wantCode:     exitOK,
+			wantInStdout: "add_row_num\n  column (string, required)\tname of the row no. column, added as the first column by default\n",
+		},
+		{
+			name:     "run without config",
+			args:     []string{"run"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "validate without schema",
+			args:     []string{"validate"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "merge one file",
+			args:     []string{"merge", first},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "unknown flag",
+			args:     []string{"split", "-unknown"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "unknown command",
+			args:     []string{"join"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "unsupported format",
+			args:     []string{"transform", "-format", "xml"},
+			wantCode: exitFailed,
+		},
+		{
+			name:     "missing file",
+			args:     []string{"transform", filepath.Join(dir, "missing.csv")},
+			wantCode: exitFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Fatalf("run() = %d, want %d, stderr: %s", code, tt.wantCode, stderr.String())
+			}
+
+			if tt.wantStdout != "" && stdout.String() != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.wantStdout)
+			}
+
+			if !strings.Contains(stdout.String(), tt.wantInStdout) {
+				t.Errorf("stdout = %q, want it to contain %q", stdout.String(), tt.wantInStdout)
+			}
+
+			for name, want := range tt.wantFiles {
+				got, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(got) != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+
+				// the chunks are appended to existing files.
+				os.Remove(filepath.Join(dir, name))
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,491 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// errUsage is returned for invalid flags, after the error is printed.
+var errUsage = errors.New("invalid usage")
+
+type command int
+
+const (
+	commandSplit command = iota
+	commandTransform
+	commandMerge
+)
+
+// file extension of each output format.
+var formatExtensions = map[string]string{
+	"csv":   ".csv",
+	"tsv":   ".tsv",
+	"json":  ".json",
+	"jsonl": ".jsonl",
+	"xlsx":  ".xlsx",
+}
+
+// listFlag is a flag that can be repeated, Eg: -replace a=b -replace c=d.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// inputFlags are the flags for reading the input, common to all the commands.
+type inputFlags struct {
+	delimiter string
+	encoding  string
+	noHeader  bool
+	header    string
+	verbose   bool
+
+	expectHeader string
+	strictHeader bool
+}
+
+func (f *inputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.delimiter, "delimiter", ",", `field delimiter of the input, "tab" for tab separated values, "auto" to detect it`)
+	fs.StringVar(&f.encoding, "encoding", "", "character encoding of the input, Eg: latin1, windows1252, utf16 (default utf8)")
+	fs.BoolVar(&f.noHeader, "no-header", false, "the input does not have a header row")
+	fs.StringVar(&f.header, "header", "", "comma separated column names of an input without a header row, Eg: id,name,amount")
+	fs.BoolVar(&f.verbose, "v", false, "log the progress to the standard error")
+	fs.StringVar(&f.expectHeader, "expect-header", "", "comma separated column names that the header must have, Eg: id,name,amount")
+	fs.BoolVar(&f.strictHeader, "strict-header", false, "the header must have only the -expect-header columns, in the same order")
+}
+
+func (f *inputFlags) options(files []string, stdin io.Reader, stderr io.Writer) ([]csvprocessor.Option, error) {
+	opts := []csvprocessor.Option{csvprocessor.SkipHeaders(f.noHeader), csvprocessor.WithLogger(func(string, ...any) {})}
+	if f.verbose {
+		opts = append(opts, csvprocessor.WithLogger(func(format string, args ...any) {
+			fmt.Fprintf(stderr, strings.TrimSuffix(format, "\n")+"\n", args...)
+		}))
+	}
+
+	if len(files) == 0 || (len(files) == 1 && files[0] == "-") {
+		opts = append(opts, csvprocessor.WithInputReader(io.NopCloser(stdin)))
+	} else {
+		opts = append(opts, csvprocessor.WithFileReaders(files...))
+	}
+
+	if f.delimiter == "auto" {
+		opts = append(opts, csvprocessor.WithAutoDialect())
+	} else {
+		delimiter, err := parseDelimiter(f.delimiter)
+		if err != nil {
+			return nil, fmt.Errorf("-delimiter: %w", err)
+		}
+
+		opts = append(opts, csvprocessor.WithInputDelimiter(delimiter))
+	}
+
+	if f.encoding != "" {
+		opts = append(opts, csvprocessor.WithInputEncoding(f.encoding))
+	}
+
+	if f.header != "" {
+		opts = append(opts, csvprocessor.WithHeader(strings.Split(f.header, ",")))
+	}
+
+	if f.expectHeader != "" {
+		opts = append(opts, csvprocessor.WithExpectedHeader(strings.Split(f.expectHeader, ","), f.strictHeader))
+	}
+
+	return opts, nil
+}
+
+// hasHeader reports whether the rows read with the options start with a header row.
+func (f *inputFlags) hasHeader() bool {
+	return !f.noHeader || f.header != ""
+}
+
+// processFlags are the flags of the split, transform and merge commands.
+type processFlags struct {
+	inputFlags
+
+	chunkSize    int
+	output       string
+	format       string
+	outDelimiter string
+	compress     string
+	archive      string
+	glob         string
+	manifest     string
+	preScan      bool
+	timeColumn   int
+	timeLayout   string
+	timeWindow   time.Duration
+	roundRobin   int
+	hashShards   int
+	shardColumn  int
+	keepGroups   string
+	chunks       int
+	minLastChunk int
+
+	addRowNum      string
+	addChunkRowNum string
+	rowNumOffset   int
+	rowNumWidth    int
+	addColumns     listFlag
+	replace        listFlag
+	dedup          string
+
+	closers []io.Closer // files opened for the output.
+}
+
+func (f *processFlags) register(fs *flag.FlagSet, cmd command) {
+	f.inputFlags.register(fs)
+
+	if cmd == commandSplit {
+		fs.IntVar(&f.chunkSize, "chunk-size", 10000, "no. of rows in each chunk")
+		fs.StringVar(&f.output, "o", "", `name of the chunks, with a verb for the chunk no. (default "output_%03d.<format>")`)
+		fs.StringVar(&f.compress, "compress", "", "write the chunks into a single archive: zip or tar.gz")
+		fs.StringVar(&f.archive, "archive", "", `path of the archive for -compress, "-" for the standard output (default "output.<compress>")`)
+		fs.BoolVar(&f.preScan, "pre-scan", false, `count the input rows first, so that -o can have a second verb for the no. of chunks, Eg: "part_%d_of_%d.csv"`)
+		fs.IntVar(&f.timeColumn, "time-column", -1, `split the rows by the time window of the timestamps in the given 0-based column, -o can have a second verb for the window, Eg: "dt=%[2]s/part_%[1]d.csv"`)
+		fs.StringVar(&f.timeLayout, "time-layout", time.RFC3339, "layout of the timestamps in -time-column, Eg: 2006-01-02 15:04:05")
+		fs.DurationVar(&f.timeWindow, "time-window", 24*time.Hour, "time window of the chunks of -time-column, Eg: 1h")
+		fs.IntVar(&f.roundRobin, "round-robin", 0, "distribute the rows across the given no. of chunks in turn, instead of -chunk-size rows in each chunk")
+		fs.IntVar(&f.hashShards, "hash-shards", 0, "distribute the rows across the given no. of chunks by the hash of -shard-column, so that the rows with the same key are in the same chunk")
+		fs.IntVar(&f.shardColumn, "shard-column", 0, "0-based key column of -hash-shards")
+		fs.IntVar(&f.chunks, "chunks", 0, "split the rows into the given no. of chunks of near-equal size, instead of -chunk-size rows in each chunk. The input must be a file")
+		fs.IntVar(&f.minLastChunk, "min-last-chunk", 0, "append the rows of the last chunk to the previous chunk if it has fewer rows than the given no.")
+		fs.StringVar(&f.keepGroups, "keep-groups", "", "do not split the consecutive rows with the same values in the given 0-based columns across chunks, Eg: 0,2")
+	} else {
+		fs.StringVar(&f.output, "o", "-", `output file, "-" for the standard output`)
+	}
+
+	if cmd == commandMerge {
+		fs.StringVar(&f.glob, "glob", "", `merge the files matching the pattern in the order of their chunk no., Eg: "output_*.csv"`)
+		fs.StringVar(&f.manifest, "manifest", "", "merge the files listed in the manifest file, one path per line")
+	}
+
+	fs.StringVar(&f.format, "format", "csv", "format of the output: csv, tsv, json, jsonl or xlsx")
+	fs.StringVar(&f.outDelimiter, "out-delimiter", ",", `field delimiter of the csv output, "tab" for tab separated values`)
+
+	fs.StringVar(&f.addRowNum, "add-row-num", "", "add a column with the given name and the row no. of each row")
+	fs.StringVar(&f.addChunkRowNum, "add-chunk-row-num", "", "add a column with the given name and the row no. of each row in its chunk")
+	fs.IntVar(&f.rowNumOffset, "row-num-offset", 0, "added to the row numbers, Eg: 1000 to continue the numbering after 1000 rows")
+	fs.IntVar(&f.rowNumWidth, "row-num-width", 0, "pad the row numbers with leading zeros to the given no. of digits")
+	fs.Var(&f.addColumns, "add-column", "add a column with a constant value, name=value (repeatable)")
+	fs.Var(&f.replace, "replace", "replace the values that are equal to old with new, old=new (repeatable)")
+	fs.StringVar(&f.dedup, "dedup", "", "drop the rows whose values in the given 0-based columns were seen before, Eg: 0,2")
+}
+
+func (f *processFlags) options(cmd command, files []string, stdin io.Reader, stdout, stderr io.Writer) ([]csvprocessor.Option, error) {
+	opts, err := f.inputFlags.options(files, stdin, stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	outputOpts, err := f.outputOptions(cmd, stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	transformer, err := f.transformer()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(append(opts, outputOpts...), csvprocessor.WithTransformer(transformer)), nil
+}
+
+func (f *processFlags) outputOptions(cmd command, stdout io.Writer) ([]csvprocessor.Option, error) {
+	extension, ok := formatExtensions[f.format]
+	if !ok {
+		return nil, fmt.Errorf("-format: unsupported format %q", f.format)
+	}
+
+	var opts []csvprocessor.Option
+	switch f.format {
+	case "tsv":
+		opts = append(opts, csvprocessor.WithOutputDelimiter('\t'))
+	case "json":
+		opts = append(opts, csvprocessor.WithJSONOutput())
+	case "jsonl":
+		opts = append(opts, csvprocessor.WithJSONLinesOutput())
+	case "xlsx":
+		opts = append(opts, csvprocessor.WithXLSXOutput())
+	default:
+		delimiter, err := parseDelimiter(f.outDelimiter)
+		if err != nil {
+			return nil, fmt.Errorf("-out-delimiter: %w", err)
+		}
+
+		opts = append(opts, csvprocessor.WithOutputDelimiter(delimiter))
+	}
+
+	if cmd != commandSplit {
+		opts = append(opts, csvprocessor.WithChunkSize(math.MaxInt32))
+		if f.output == "-" {
+			return append(opts, csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+				return csvprocessor.NoOpCloser(stdout), nil
+			})), nil
+		}
+
+		output := f.output
+		return append(opts, csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return os.Create(output)
+		})), nil
+	}
+
+	if f.chunkSize <= 0 {
+		return nil, fmt.Errorf("-chunk-size: must be > 0")
+	}
+
+	opts = append(opts, csvprocessor.WithChunkSize(f.chunkSize), csvprocessor.WithPreScan(f.preScan))
+	if f.timeColumn >= 0 {
+		opts = append(opts, csvprocessor.WithChunkByTimeColumn(f.timeColumn, f.timeLayout, f.timeWindow))
+	}
+
+	if f.roundRobin > 0 {
+		opts = append(opts, csvprocessor.WithRoundRobin(f.roundRobin))
+	}
+
+	if f.hashShards > 0 {
+		opts = append(opts, csvprocessor.WithShardByHash(f.shardColumn, f.hashShards))
+	}
+
+	if f.chunks > 0 {
+		opts = append(opts, csvprocessor.WithChunkCount(f.chunks))
+	}
+
+	if f.minLastChunk > 0 {
+		opts = append(opts, csvprocessor.WithMinLastChunkRows(f.minLastChunk))
+	}
+
+	if f.keepGroups != "" {
+		columns, err := parseColumns(f.keepGroups)
+		if err != nil {
+			return nil, fmt.Errorf("-keep-groups: %w", err)
+		}
+
+		opts = append(opts, csvprocessor.WithKeepKeyGroups(columns...))
+	}
+
+	name := f.output
+	switch {
+	case name == "" && f.compress != "":
+		name = "part_%05d" + extension
+	case name == "":
+		name = "output_%03d" + extension
+	}
+
+	switch f.compress {
+	case "":
+		return append(opts, csvprocessor.WithOutputFileFormat(name)), nil
+	case "zip":
+		archive := f.archive
+		if archive == "" {
+			archive = "output.zip"
+		}
+
+		return append(opts, csvprocessor.WithZipOutput(archive), csvprocessor.WithZipEntryFormat(name)), nil
+	case "tar.gz":
+		var output io.Writer = stdout
+		if f.archive != "-" {
+			archive := f.archive
+			if archive == "" {
+				archive = "output.tar.gz"
+			}
+
+			file, err := os.Create(archive)
+			if err != nil {
+				return nil, err
+			}
+
+			f.closers = append(f.closers, file)
+			output = file
+		}
+
+		return append(opts, csvprocessor.WithTarGzOutput(output), csvprocessor.WithZipEntryFormat(name)), nil
+	default:
+		return nil, fmt.Errorf("-compress: unsupported compression %q", f.compress)
+	}
+}
+
+// chunkFiles returns the files to be merged from -glob and -manifest.
+func (f *processFlags) chunkFiles() ([]string, error) {
+	var files []string
+	if f.glob != "" {
+		matches, err := csvprocessor.ChunkFiles(f.glob)
+		if err != nil {
+			return nil, fmt.Errorf("-glob: %w", err)
+		}
+
+		files = append(files, matches...)
+	}
+
+	if f.manifest != "" {
+		listed, err := csvprocessor.ReadManifest(f.manifest)
+		if err != nil {
+			return nil, fmt.Errorf("-manifest: %w", err)
+		}
+
+		files = append(files, listed...)
+	}
+
+	return files, nil
+}
+
+// close closes the files opened for the output.
+func (f *processFlags) close() error {
+	var firstErr error
+	for _, closer := range f.closers {
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	f.closers = nil
+	return firstErr
+}
+
+// transformer returns the chain of transformers for the flags, in the order they are listed in the usage.
+func (f *processFlags) transformer() (csvprocessor.CsvRowTransformer, error) {
+	var transformers []csvprocessor.CsvRowTransformer
+
+	if len(f.replace) > 0 {
+		replacements := map[string]string{}
+		for _, value := range f.replace {
+			old, replacement, ok := strings.Cut(value, "=")
+			if !ok {
+				return nil, fmt.Errorf("-replace: %q is not of the form old=new", value)
+			}
+
+			replacements[old] = replacement
+		}
+
+		transformers = append(transformers, csvprocessor.ReplaceValuesTransformer(replacements))
+	}
+
+	if f.dedup != "" {
+		columns, err := parseColumns(f.dedup)
+		if err != nil {
+			return nil, fmt.Errorf("-dedup: %w", err)
+		}
+
+		transformers = append(transformers, csvprocessor.DedupTransformer(columns...))
+	}
+
+	for _, value := range f.addColumns {
+		name, constant, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("-add-column: %q is not of the form name=value", value)
+		}
+
+		// the column is added at the end of the row.
+		transformers = append(transformers, func(ctx context.Context, row []string) []string {
+			return csvprocessor.AddConstantColumnTransformer(name, constant, len(row))(ctx, row)
+		})
+	}
+
+	rowNoOptions := csvprocessor.RowNoOptions{Offset: f.rowNumOffset, Width: f.rowNumWidth}
+	if f.addRowNum != "" {
+		transformers = append(transformers, csvprocessor.AddRowNoTransformerWithOptions(f.addRowNum, rowNoOptions))
+	}
+
+	if f.addChunkRowNum != "" {
+		transformers = append(transformers, csvprocessor.AddChunkRowNoTransformerWithOptions(f.addChunkRowNum, rowNoOptions))
+	}
+
+	return csvprocessor.ChainTransformers(transformers...), nil
+}
+
+func runProcess(cmd command, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	name := map[command]string{commandSplit: "split", commandTransform: "transform", commandMerge: "merge"}[cmd]
+	fs := flag.NewFlagSet("csvproc "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var flags processFlags
+	flags.register(fs, cmd)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+
+		return errUsage
+	}
+
+	files := fs.Args()
+	if cmd == commandMerge {
+		if len(files) < 2 && flags.glob == "" && flags.manifest == "" {
+			fmt.Fprintln(stderr, "csvproc merge: at least two files, -glob or -manifest are needed")
+			return errUsage
+		}
+
+		chunks, err := flags.chunkFiles()
+		if err != nil {
+			return err
+		}
+
+		files = append(files, chunks...)
+	}
+
+	opts, err := flags.options(cmd, files, stdin, stdout, stderr)
+	defer flags.close()
+	if err != nil {
+		return err
+	}
+
+	proc, err := csvprocessor.New(opts...)
+	if err != nil {
+		return err
+	}
+
+	if err := proc.Process(); err != nil {
+		return err
+	}
+
+	for _, rowErr := range proc.Stats().Errors {
+		fmt.Fprintf(stderr, "csvproc: skipped: %v\n", rowErr)
+	}
+
+	return flags.close()
+}
+
+// parseDelimiter returns the delimiter for the flag value, "tab" and `\t` are accepted for the tab character.
+func parseDelimiter(value string) (rune, error) {
+	if value == "tab" || value == `\t` {
+		return '\t', nil
+	}
+
+	delimiter, size := utf8.DecodeRuneInString(value)
+	if size == 0 || size != len(value) {
+		return 0, fmt.Errorf("%q is not a single character", value)
+	}
+
+	return delimiter, nil
+}
+
+// parseColumns parses a comma separated list of 0-based column indices.
+func parseColumns(value string) ([]int, error) {
+	var columns []int
+	for _, field := range strings.Split(value, ",") {
+		column, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || column < 0 {
+			return nil, fmt.Errorf("%q is not a list of column indices", value)
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns, nil
+}
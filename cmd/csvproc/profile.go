@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// runProfile profiles the input and prints the report.
+func runProfile(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("csvproc profile", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var flags inputFlags
+	flags.register(fs)
+	top := fs.Int("top", csvprocessor.DefaultTopValues, "no. of most frequent values of each column in the report")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+
+		return errUsage
+	}
+
+	opts, err := flags.options(fs.Args(), stdin, stderr)
+	if err != nil {
+		return err
+	}
+
+	proc, err := csvprocessor.New(append(opts,
+		csvprocessor.WithDataProfile(*top),
+		csvprocessor.WithDryRun(true),
+		csvprocessor.WithChunkSize(1),
+	)...)
+	if err != nil {
+		return err
+	}
+
+	if err := proc.Process(); err != nil {
+		return err
+	}
+
+	profile := proc.Stats().DataProfile
+	if *asJSON {
+		return profile.WriteJSON(stdout)
+	}
+
+	return profile.WriteText(stdout)
+}
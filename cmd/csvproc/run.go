@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// runConfig runs the pipeline described by a config file, see csvprocessor.Config.
+func runConfig(args []string, stdin io.Reader, stderr io.Writer) error {
+	fs := flag.NewFlagSet("csvproc run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	configPath := fs.String("config", "", "JSON config file of the pipeline")
+	verbose := fs.Bool("v", false, "log the progress to the standard error")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+
+		return errUsage
+	}
+
+	if *configPath == "" {
+		fmt.Fprintln(stderr, "csvproc run: -config is required")
+		return errUsage
+	}
+
+	config, err := csvprocessor.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	// the files given in the command line override the input files of the config.
+	switch files := fs.Args(); {
+	case len(files) == 1 && files[0] == "-":
+		config.Input.Files, config.Input.Glob = nil, ""
+	case len(files) > 0:
+		config.Input.Files, config.Input.Glob = files, ""
+	}
+
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", *configPath, err)
+	}
+
+	opts, err := config.Options()
+	if err != nil {
+		return err
+	}
+
+	if len(config.Input.Files) == 0 && config.Input.Glob == "" {
+		opts = append(opts, csvprocessor.WithInputReader(io.NopCloser(stdin)))
+	}
+
+	opts = append(opts, csvprocessor.WithLogger(func(string, ...any) {}))
+	if *verbose {
+		opts = append(opts, csvprocessor.WithLogger(func(format string, args ...any) {
+			fmt.Fprintf(stderr, strings.TrimSuffix(format, "\n")+"\n", args...)
+		}))
+	}
+
+	proc, err := csvprocessor.New(opts...)
+	if err != nil {
+		return err
+	}
+
+	if err := proc.Process(); err != nil {
+		return err
+	}
+
+	for _, rowErr := range proc.Stats().Errors {
+		fmt.Fprintf(stderr, "csvproc: skipped: %v\n", rowErr)
+	}
+
+	return nil
+}
+
+// listTransformers prints the transformers in the default registry with their params.
+func listTransformers(stdout io.Writer) {
+	registry := csvprocessor.DefaultTransformerRegistry
+	for _, name := range registry.Names() {
+		fmt.Fprintln(stdout, name)
+
+		params, _ := registry.Params(name)
+		for _, param := range params {
+			paramType := string(param.Type)
+			if param.Required {
+				paramType += ", required"
+			}
+
+			fmt.Fprintf(stdout, "  %s (%s)\t%s\n", param.Name, paramType, param.Description)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// runSchema infers the schema of the input and prints it in the format read by the validate command.
+func runSchema(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("csvproc schema", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var flags inputFlags
+	flags.register(fs)
+	sample := fs.Int("sample", csvprocessor.DefaultInferSampleRows, "no. of rows sampled to infer the schema")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+
+		return errUsage
+	}
+
+	opts, err := flags.options(fs.Args(), stdin, stderr)
+	if err != nil {
+		return err
+	}
+
+	it, err := csvprocessor.NewRowIterator(append(opts, csvprocessor.WithLimitRows(*sample))...)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	schema, err := csvprocessor.InferSchema(&iteratorReader{it: it}, flags.hasHeader(), *sample)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(schema)
+}
+
+// iteratorReader reads the header and the rows of a RowIterator, so that the input is read with the input flags.
+type iteratorReader struct {
+	it      *csvprocessor.RowIterator
+	started bool
+	next    []string // first row, returned after the header.
+}
+
+func (r *iteratorReader) Read() ([]string, error) {
+	if r.next != nil {
+		row := r.next
+		r.next = nil
+		return row, nil
+	}
+
+	ok := r.it.Next()
+	if !ok {
+		// Err() is only safe to call once Next() returns false, the iterator is still reading otherwise.
+		if err := r.it.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if !r.started {
+		r.started = true
+		if header := r.it.Header(); header != nil {
+			if ok {
+				r.next = r.it.Row()
+			}
+
+			return header, nil
+		}
+	}
+
+	if !ok {
+		return nil, io.EOF
+	}
+
+	return r.it.Row(), nil
+}
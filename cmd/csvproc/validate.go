@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// errInvalid is returned when the input does not match the schema, after the violations are printed.
+var errInvalid = errors.New("the input does not match the schema")
+
+// column types by their name in the schema file.
+var columnTypes = map[string]csvprocessor.ColumnType{
+	"":       csvprocessor.TypeString,
+	"string": csvprocessor.TypeString,
+	"int":    csvprocessor.TypeInt,
+	"float":  csvprocessor.TypeFloat,
+	"bool":   csvprocessor.TypeBool,
+	"time":   csvprocessor.TypeTime,
+}
+
+// schemaFile is the JSON representation of csvprocessor.Schema, Eg:
+//
+//	{"columns": [{"name": "id", "type": "int", "required": true}, {"name": "email", "pattern": "^.+@.+$"}]}
+type schemaFile struct {
+	Columns []struct {
+		Name      string   `json:"name"`
+		Type      string   `json:"type"`
+		Layout    string   `json:"layout"`
+		Required  bool     `json:"required"`
+		Pattern   string   `json:"pattern"`
+		Enum      []string `json:"enum"`
+		MaxLength int      `json:"max_length"`
+	} `json:"columns"`
+}
+
+func readSchema(path string) (csvprocessor.Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return csvprocessor.Schema{}, err
+	}
+
+	var file schemaFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return csvprocessor.Schema{}, fmt.Errorf("invalid schema %s: %w", path, err)
+	}
+
+	var schema csvprocessor.Schema
+	for _, column := range file.Columns {
+		columnType, ok := columnTypes[column.Type]
+		if !ok {
+			return csvprocessor.Schema{}, fmt.Errorf("invalid schema %s: column %q has unknown type %q", path, column.Name, column.Type)
+		}
+
+		schema.Columns = append(schema.Columns, csvprocessor.ColumnSchema{
+			Name:      column.Name,
+			Type:      columnType,
+			Layout:    column.Layout,
+			Required:  column.Required,
+			Pattern:   column.Pattern,
+			Enum:      column.Enum,
+			MaxLength: column.MaxLength,
+		})
+	}
+
+	return schema, nil
+}
+
+// runValidate validates the input against the schema and prints all the violations.
+func runValidate(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("csvproc validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var flags inputFlags
+	flags.register(fs)
+	schemaPath := fs.String("schema", "", `JSON schema of the input, Eg: {"columns": [{"name": "id", "type": "int", "required": true}]}`)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+
+		return errUsage
+	}
+
+	if *schemaPath == "" {
+		fmt.Fprintln(stderr, "csvproc validate: -schema is required")
+		return errUsage
+	}
+
+	schema, err := readSchema(*schemaPath)
+	if err != nil {
+		return err
+	}
+
+	opts, err := flags.options(fs.Args(), stdin, stderr)
+	if err != nil {
+		return err
+	}
+
+	proc, err := csvprocessor.New(append(opts,
+		csvprocessor.WithSchemaValidation(schema),
+		csvprocessor.WithDryRun(true),
+		csvprocessor.WithChunkSize(1),
+	)...)
+	if err != nil {
+		return err
+	}
+
+	if err := proc.Process(); err != nil {
+		return err
+	}
+
+	stats := proc.Stats()
+	for _, violation := range stats.Errors {
+		fmt.Fprintln(stdout, violation)
+	}
+
+	if len(stats.Errors) > 0 {
+		fmt.Fprintf(stderr, "csvproc: %d rows read, %d errors\n", stats.RowsRead, len(stats.Errors))
+		return errInvalid
+	}
+
+	fmt.Fprintf(stderr, "csvproc: %d rows read, no errors\n", stats.RowsRead)
+	return nil
+}
@@ -0,0 +1,241 @@
+package csvprocessor
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ColumnStats is the summary of the values of a column in the output, see WithColumnStats().
+type ColumnStats struct {
+	// Index is the 0-based index of the column, and Name is its name in the header (empty if there is no header).
+	Index int    `json:"index"`
+	Name  string `json:"name"`
+	// Count is the no. of values, including the empty and null values.
+	Count int `json:"count"`
+	// Empty is the no. of empty or whitespace-only values.
+	Empty int `json:"empty"`
+	// Nulls is the no. of rows that do not have the column, and the values that are NULL (in any case) or \N.
+	Nulls int `json:"nulls"`
+	// Numeric is the no. of numeric values, MinNumber, MaxNumber and Mean are computed from them.
+	Numeric   int     `json:"numeric"`
+	MinNumber float64 `json:"min_number"`
+	MaxNumber float64 `json:"max_number"`
+	Mean      float64 `json:"mean"`
+	// MinValue and MaxValue are the smallest and largest non-empty values compared as strings.
+	MinValue string `json:"min_value"`
+	MaxValue string `json:"max_value"`
+	// Distinct is the estimated no. of distinct values, the estimate is usually within 2% of the actual count.
+	Distinct int `json:"distinct"`
+	// MaxLength is the length of the longest value in characters.
+	MaxLength int `json:"max_length"`
+}
+
+// WithColumnStats computes the ColumnStats of each column of the rows written to the output, in the same pass.
+// The stats are available in Stats.Columns, the header rows are not counted.
+// A fixed amount of memory (about 4 KB) is used per column, to estimate the no. of distinct values.
+func WithColumnStats() Option {
+	return func(c *Processor) error {
+		c.columnStats = true
+		return nil
+	}
+}
+
+// columnCollector collects the stats of the columns of a run.
+// The methods can be called on a nil *columnCollector, when WithColumnStats() is not used.
+type columnCollector struct {
+	rows    int
+	names   []string
+	columns []*columnStats
+
+	columnStats bool // whether WithColumnStats() is used.
+	profile     bool // whether WithDataProfile() is used.
+	topValues   int  // no. of top values of each column in the DataProfile.
+}
+
+// columnStats is the running stats of a column.
+type columnStats struct {
+	ColumnStats
+	sum      float64
+	distinct hyperLogLog
+	profile  *valueProfile // nil if WithDataProfile() is not used.
+}
+
+// newColumnCollector returns the collector of the run, or nil if neither WithColumnStats() nor WithDataProfile() is used.
+func (c *Processor) newColumnCollector() *columnCollector {
+	if !c.columnStats && !c.dataProfile {
+		return nil
+	}
+
+	return &columnCollector{columnStats: c.columnStats, profile: c.dataProfile, topValues: c.profileTopValues}
+}
+
+// setHeader sets the names of the columns from the header written to the output.
+func (cc *columnCollector) setHeader(header []string) {
+	if cc != nil && cc.names == nil {
+		cc.names = append([]string(nil), header...)
+	}
+}
+
+// add adds the values of the row written to the output.
+func (cc *columnCollector) add(row []string) {
+	if cc == nil {
+		return
+	}
+
+	for len(cc.columns) < len(row) {
+		// the rows before the first row with the column did not have it.
+		column := &columnStats{ColumnStats: ColumnStats{Index: len(cc.columns), Nulls: cc.rows}}
+		if cc.profile {
+			column.profile = newValueProfile(cc.topValues)
+		}
+
+		cc.columns = append(cc.columns, column)
+	}
+
+	for i, column := range cc.columns {
+		if i >= len(row) {
+			column.Nulls++
+			continue
+		}
+
+		column.add(row[i])
+	}
+
+	cc.rows++
+}
+
+func (s *columnStats) add(value string) {
+	s.Count++
+	if length := utf8.RuneCountInString(value); length > s.MaxLength {
+		s.MaxLength = length
+	}
+
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		s.Empty++
+		return
+	}
+
+	isNull := strings.EqualFold(trimmed, "null") || trimmed == `\N`
+	if isNull {
+		s.Nulls++
+	}
+
+	if s.MinValue == "" || value < s.MinValue {
+		s.MinValue = value
+	}
+
+	if value > s.MaxValue {
+		s.MaxValue = value
+	}
+
+	s.distinct.add(value)
+	number, err := strconv.ParseFloat(trimmed, 64)
+	isNumber := err == nil && !math.IsInf(number, 0) && !math.IsNaN(number)
+	if !isNull {
+		s.profile.add(value, trimmed, number, isNumber)
+	}
+
+	if isNumber {
+		if s.Numeric == 0 || number < s.MinNumber {
+			s.MinNumber = number
+		}
+
+		if s.Numeric == 0 || number > s.MaxNumber {
+			s.MaxNumber = number
+		}
+
+		s.Numeric++
+		s.sum += number
+	}
+}
+
+// result returns the stats of the columns, or nil if WithColumnStats() is not used.
+func (cc *columnCollector) result() []ColumnStats {
+	if cc == nil || !cc.columnStats {
+		return nil
+	}
+
+	result := make([]ColumnStats, 0, len(cc.columns))
+	for i, column := range cc.columns {
+		result = append(result, column.result(valueAt(cc.names, i)))
+	}
+
+	return result
+}
+
+// result returns the stats of the column with the given name.
+func (s *columnStats) result(name string) ColumnStats {
+	stats := s.ColumnStats
+	stats.Name = name
+	stats.Distinct = s.distinct.estimate()
+	if stats.Numeric > 0 {
+		stats.Mean = s.sum / float64(stats.Numeric)
+	}
+
+	return stats
+}
+
+// hyperLogLogPrecision is the no. of bits of the hash used to pick the register, there are 2^precision registers.
+const hyperLogLogPrecision = 12
+
+// hyperLogLog estimates the no. of distinct values using a fixed amount of memory, see
+// "HyperLogLog: the analysis of a near-optimal cardinality estimation algorithm" by Flajolet et al.
+// The zero value is ready to use, the registers are allocated by the first add().
+type hyperLogLog struct {
+	registers []uint8
+}
+
+func (h *hyperLogLog) add(value string) {
+	if h.registers == nil {
+		h.registers = make([]uint8, 1<<hyperLogLogPrecision)
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(value)) //nolint:errcheck
+	x := mix64(hash.Sum64())
+
+	index := x >> (64 - hyperLogLogPrecision)
+	// the position of the first set bit after the index bits, a sentinel bit bounds it to 64-precision+1.
+	rank := uint8(bits.LeadingZeros64(x<<hyperLogLogPrecision|1<<(hyperLogLogPrecision-1)) + 1)
+	if rank > h.registers[index] {
+		h.registers[index] = rank
+	}
+}
+
+func (h *hyperLogLog) estimate() int {
+	if h.registers == nil {
+		return 0
+	}
+
+	m := float64(len(h.registers))
+	sum, zeros := 0.0, 0
+	for _, register := range h.registers {
+		sum += 1 / float64(uint64(1)<<register)
+		if register == 0 {
+			zeros++
+		}
+	}
+
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// linear counting is more accurate for the small cardinalities.
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return int(math.Round(estimate))
+}
+
+// mix64 is the finalizer of MurmurHash3, it spreads the bits of the FNV hash for the registers of hyperLogLog.
+func mix64(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
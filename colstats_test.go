@@ -0,0 +1,123 @@
+package csvprocessor_test
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// columnStats processes the input with the given options and returns the stats of the columns.
+func columnStats(t *testing.T, input string, opts ...csvprocessor.Option) []csvprocessor.ColumnStats {
+	t.Helper()
+
+	proc, err := csvprocessor.New(append([]csvprocessor.Option{
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader(input))),
+		csvprocessor.WithWriterGenerator(func(i int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(io.Discard), nil
+		}),
+		csvprocessor.WithChunkSize(2),
+		csvprocessor.WithLogger(t.Logf),
+	}, opts...)...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	return proc.Stats().Columns
+}
+
+func TestWithColumnStats(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []csvprocessor.Option
+		want  []csvprocessor.ColumnStats
+	}{
+		{
+			name:  "Test numeric and text columns",
+			input: "id,name,amount\n1,alice,10.5\n2,bob,\n3,alice,NULL\n4,chloé,-2.5\n",
+			opts:  []csvprocessor.Option{csvprocessor.WithColumnStats()},
+			want: []csvprocessor.ColumnStats{
+				{
+					Index: 0, Name: "id", Count: 4, Numeric: 4, MinNumber: 1, MaxNumber: 4, Mean: 2.5,
+					MinValue: "1", MaxValue: "4", Distinct: 4, MaxLength: 1,
+				},
+				{
+					Index: 1, Name: "name", Count: 4, MinValue: "alice", MaxValue: "chloé", Distinct: 3, MaxLength: 5,
+				},
+				{
+					Index: 2, Name: "amount", Count: 4, Empty: 1, Nulls: 1, Numeric: 2, MinNumber: -2.5, MaxNumber: 10.5, Mean: 4,
+					MinValue: "-2.5", MaxValue: "NULL", Distinct: 3, MaxLength: 4,
+				},
+			},
+		},
+		{
+			name:  "Test rows with missing columns",
+			input: "1\n2,x\n3\n",
+			opts: []csvprocessor.Option{
+				csvprocessor.WithColumnStats(), csvprocessor.SkipHeaders(true),
+				csvprocessor.WithNormalizeFieldCount(csvprocessor.FieldCountAny),
+			},
+			want: []csvprocessor.ColumnStats{
+				{Index: 0, Count: 3, Numeric: 3, MinNumber: 1, MaxNumber: 3, Mean: 2, MinValue: "1", MaxValue: "3", Distinct: 3, MaxLength: 1},
+				{Index: 1, Count: 1, Nulls: 2, MinValue: "x", MaxValue: "x", Distinct: 1, MaxLength: 1},
+			},
+		},
+		{
+			name:  "Test stats of the transformed rows",
+			input: "id,name\n1,alice\n2,bob\n",
+			opts: []csvprocessor.Option{
+				csvprocessor.WithColumnStats(), csvprocessor.WithTransformer(csvprocessor.AddRowNoTransformer("row")),
+			},
+			want: []csvprocessor.ColumnStats{
+				{Index: 0, Name: "row", Count: 2, Numeric: 2, MinNumber: 1, MaxNumber: 2, Mean: 1.5, MinValue: "1", MaxValue: "2", Distinct: 2, MaxLength: 1},
+				{Index: 1, Name: "id", Count: 2, Numeric: 2, MinNumber: 1, MaxNumber: 2, Mean: 1.5, MinValue: "1", MaxValue: "2", Distinct: 2, MaxLength: 1},
+				{Index: 2, Name: "name", Count: 2, MinValue: "alice", MaxValue: "bob", Distinct: 2, MaxLength: 5},
+			},
+		},
+		{
+			name:  "Test without WithColumnStats",
+			input: "id\n1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := columnStats(t, tt.input, tt.opts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Stats().Columns = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithColumnStats_Distinct(t *testing.T) {
+	const distinct = 50_000
+
+	var input strings.Builder
+	input.WriteString("id\n")
+	for i := 0; i < 2*distinct; i++ {
+		input.WriteString(strconv.Itoa(i % distinct))
+		input.WriteByte('\n')
+	}
+
+	stats := columnStats(t, input.String(), csvprocessor.WithColumnStats())
+	if len(stats) != 1 {
+		t.Fatalf("Stats().Columns = %+v, want 1 column", stats)
+	}
+
+	if stats[0].Count != 2*distinct {
+		t.Errorf("Stats().Columns[0].Count = %d, want %d", stats[0].Count, 2*distinct)
+	}
+
+	if errorRate := math.Abs(float64(stats[0].Distinct-distinct)) / distinct; errorRate > 0.05 {
+		t.Errorf("Stats().Columns[0].Distinct = %d, want %d ± 5%%", stats[0].Distinct, distinct)
+	}
+}
@@ -0,0 +1,285 @@
+package csvprocessor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidConfig is returned when the pipeline config is not valid, Eg: it has unknown fields or unsupported values.
+var ErrInvalidConfig = errors.New("csvprocessor: invalid config")
+
+// Config is a declarative description of a pipeline, so that pipelines can be versioned as files and run without recompiling.
+// It is usually loaded from a JSON file using LoadConfig() or NewFromConfig(), Eg:
+//
+//	{
+//		"input": {"files": ["orders.csv"], "delimiter": ";"},
+//		"output": {"file_format": "orders_%03d.jsonl", "format": "jsonl"},
+//		"chunk_size": 10000,
+//		"transformers": [
+//			{"name": "replace_values", "params": {"replacements": {"NULL": ""}}},
+//			{"name": "add_row_num", "params": {"column": "S.No"}}
+//		]
+//	}
+//
+// The paths are relative to the working directory. The same config can be written in YAML and loaded using the yamlconfig module.
+type Config struct {
+	Input  InputConfig  `json:"input"`
+	Output OutputConfig `json:"output"`
+	// ChunkSize is the no. of rows in each chunk, see WithChunkSize().
+	ChunkSize int `json:"chunk_size"`
+	// Transformers are applied to each row in the given order, see ChainTransformers().
+	Transformers []TransformerConfig `json:"transformers"`
+
+	// Registry is used to create the transformers, the default is DefaultTransformerRegistry.
+	Registry *TransformerRegistry `json:"-"`
+}
+
+// InputConfig describes the input of the pipeline. If neither Files nor Glob is set, the input is read from the standard input.
+type InputConfig struct {
+	// Files are read as a single input in the given order, see WithFileReaders().
+	Files []string `json:"files"`
+	// Glob reads the files matching the pattern, see WithInputGlob().
+	Glob string `json:"glob"`
+	// Delimiter is the field delimiter, a single character or "tab". "auto" detects the dialect, see WithAutoDialect().
+	Delimiter string `json:"delimiter"`
+	// Encoding is the character encoding of the input, see WithInputEncoding().
+	Encoding string `json:"encoding"`
+	// SkipHeaders is true if the input does not have a header row, see SkipHeaders().
+	SkipHeaders bool `json:"skip_headers"`
+}
+
+// OutputConfig describes the output of the pipeline.
+type OutputConfig struct {
+	// FileFormat is the format of the names of the output files, see WithOutputFileFormat().
+	// With Zip, it is the format of the names of the entries in the archive.
+	FileFormat string `json:"file_format"`
+	// Format is the format of the chunks: csv (the default), tsv, json, jsonl or xlsx.
+	Format string `json:"format"`
+	// Delimiter is the field delimiter of the csv format, a single character or "tab".
+	Delimiter string `json:"delimiter"`
+	// Encoding is the character encoding of the output, see WithOutputEncoding().
+	Encoding string `json:"encoding"`
+	// CRLF ends the rows with \r\n instead of \n, see WithCRLF().
+	CRLF bool `json:"crlf"`
+	// Zip is the path of a zip archive to which the chunks are written, see WithZipOutput().
+	Zip string `json:"zip"`
+}
+
+// TransformerConfig refers to a registered transformer by its name, with the parameters of the transformer. See TransformerRegistry.
+type TransformerConfig struct {
+	Name   string          `json:"name"`
+	Params json.RawMessage `json:"params"`
+	// Apply limits the transformer to the header rows ("header", see HeaderOnly()) or the data rows ("rows", see RowsOnly()),
+	// it is applied to all the rows by default.
+	Apply string `json:"apply"`
+}
+
+// LoadConfig reads the pipeline config from the JSON file at path.
+func LoadConfig(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	config, err := ParseConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// ParseConfig reads the pipeline config as JSON from r. Unknown fields are reported as errors to catch typos.
+func ParseConfig(r io.Reader) (*Config, error) {
+	var config Config
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	return &config, nil
+}
+
+// NewFromConfig creates a Processor from the pipeline config in the JSON file at path, see Config.
+func NewFromConfig(path string) (*Processor, error) {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	opts, err := config.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	return New(opts...)
+}
+
+// Options returns the options for the pipeline described by the config, more options can be appended before passing them to New().
+// The input files are opened only when the options are applied.
+func (c *Config) Options() ([]Option, error) {
+	input, err := c.Input.options()
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := c.Output.options()
+	if err != nil {
+		return nil, err
+	}
+
+	registry := c.Registry
+	if registry == nil {
+		registry = DefaultTransformerRegistry
+	}
+
+	transformers := make([]CsvRowTransformer, 0, len(c.Transformers))
+	for i, transformerConfig := range c.Transformers {
+		transformer, err := registry.New(transformerConfig.Name, transformerConfig.Params)
+		if errors.Is(err, ErrUnknownTransformer) {
+			return nil, fmt.Errorf("transformers[%d]: %w", i, err)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: transformers[%d]: %v", ErrInvalidConfig, i, err)
+		}
+
+		switch transformerConfig.Apply {
+		case "", "all":
+		case "header":
+			transformer = HeaderOnly(transformer)
+		case "rows":
+			transformer = RowsOnly(transformer)
+		default:
+			return nil, fmt.Errorf("%w: transformers[%d]: apply must be all, header or rows: %q", ErrInvalidConfig, i, transformerConfig.Apply)
+		}
+
+		transformers = append(transformers, transformer)
+	}
+
+	opts := append(input, output...)
+	return append(opts, WithChunkSize(c.ChunkSize), WithTransformer(ChainTransformers(transformers...))), nil
+}
+
+// Validate checks that the config describes a complete pipeline, the required keys that are missing are reported by name,
+// Eg: "missing required keys: output.file_format, chunk_size". NewFromConfig() validates the config,
+// it is not needed if the options missing in the config are appended to Options().
+func (c *Config) Validate() error {
+	var missing []string
+	if c.Output.FileFormat == "" && c.Output.Zip == "" {
+		missing = append(missing, "output.file_format")
+	}
+
+	if c.ChunkSize == 0 {
+		missing = append(missing, "chunk_size")
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing required keys: %s", ErrInvalidConfig, strings.Join(missing, ", "))
+	}
+
+	if c.ChunkSize < 0 {
+		return fmt.Errorf("%w: chunk_size must be positive: %d", ErrInvalidConfig, c.ChunkSize)
+	}
+
+	return nil
+}
+
+func (c InputConfig) options() ([]Option, error) {
+	var opts []Option
+	switch {
+	case len(c.Files) > 0 && c.Glob != "":
+		return nil, fmt.Errorf("%w: input: only one of files and glob can be set", ErrInvalidConfig)
+	case len(c.Files) > 0:
+		opts = append(opts, WithFileReaders(c.Files...))
+	case c.Glob != "":
+		opts = append(opts, WithInputGlob(c.Glob))
+	default:
+		opts = append(opts, WithStdin())
+	}
+
+	switch c.Delimiter {
+	case "":
+	case "auto":
+		opts = append(opts, WithAutoDialect())
+	default:
+		delimiter, err := configDelimiter(c.Delimiter)
+		if err != nil {
+			return nil, fmt.Errorf("%w: input: %v", ErrInvalidConfig, err)
+		}
+
+		opts = append(opts, WithInputDelimiter(delimiter))
+	}
+
+	if c.Encoding != "" {
+		opts = append(opts, WithInputEncoding(c.Encoding))
+	}
+
+	return append(opts, SkipHeaders(c.SkipHeaders)), nil
+}
+
+func (c OutputConfig) options() ([]Option, error) {
+	var opts []Option
+	switch c.Format {
+	case "", "csv":
+	case "tsv":
+		opts = append(opts, WithOutputDelimiter('\t'))
+	case "json":
+		opts = append(opts, WithJSONOutput())
+	case "jsonl":
+		opts = append(opts, WithJSONLinesOutput())
+	case "xlsx":
+		opts = append(opts, WithXLSXOutput())
+	default:
+		return nil, fmt.Errorf("%w: output: unknown format %q, must be one of csv, tsv, json, jsonl or xlsx", ErrInvalidConfig, c.Format)
+	}
+
+	if c.Delimiter != "" {
+		delimiter, err := configDelimiter(c.Delimiter)
+		if err != nil {
+			return nil, fmt.Errorf("%w: output: %v", ErrInvalidConfig, err)
+		}
+
+		opts = append(opts, WithOutputDelimiter(delimiter))
+	}
+
+	if c.Encoding != "" {
+		opts = append(opts, WithOutputEncoding(c.Encoding))
+	}
+
+	if c.Zip != "" {
+		opts = append(opts, WithZipOutput(c.Zip))
+		if c.FileFormat != "" {
+			opts = append(opts, WithZipEntryFormat(c.FileFormat))
+		}
+	} else {
+		opts = append(opts, WithOutputFileFormat(c.FileFormat))
+	}
+
+	return append(opts, WithCRLF(c.CRLF)), nil
+}
+
+// configDelimiter returns the delimiter for the value in the config, "tab" is accepted for the tab character.
+func configDelimiter(value string) (rune, error) {
+	if value == "tab" {
+		return '\t', nil
+	}
+
+	delimiter, size := utf8.DecodeRuneInString(value)
+	if size != len(value) {
+		return 0, fmt.Errorf("delimiter %q is not a single character", value)
+	}
+
+	return delimiter, nil
+}
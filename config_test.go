@@ -0,0 +1,193 @@
+package csvprocessor_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.csv")
+	writeTestFile(t, input, "id;name;qty;price\n1;alice;2;1.5\n2;NULL;3;2\n1;alice;2;1.5\n3;carol;1;4\n")
+
+	config := `{
+		"input": {"files": [` + quote(input) + `], "delimiter": ";"},
+		"output": {"file_format": ` + quote(filepath.Join(dir, "output_%d.csv")) + `},
+		"chunk_size": 2,
+		"transformers": [
+			{"name": "dedup", "params": {"columns": [0]}},
+			{"name": "replace_values", "params": {"replacements": {"NULL": ""}}},
+			{"name": "arithmetic", "params": {"column": "total", "op": "product", "precision": 2, "columns": [2, 3]}},
+			{"name": "add_constant_column", "params": {"column": "src", "value": "erp"}}
+		]
+	}`
+	configPath := filepath.Join(dir, "pipeline.json")
+	writeTestFile(t, configPath, config)
+
+	proc, err := csvprocessor.NewFromConfig(configPath)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	want := map[string]string{
+		"output_1.csv": "src,id,name,qty,price,total\nerp,1,alice,2,1.5,3.00\nerp,2,,3,2,6.00\n",
+		"output_2.csv": "src,id,name,qty,price,total\nerp,3,carol,1,4,4.00\n",
+	}
+	for name, want := range want {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestConfigApply(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.csv")
+	writeTestFile(t, input, "id,NULL\n1,NULL\n")
+
+	config := `{
+		"input": {"files": [` + quote(input) + `]},
+		"output": {"file_format": ` + quote(filepath.Join(dir, "output_%d.csv")) + `},
+		"chunk_size": 10,
+		"transformers": [
+			{"name": "replace_values", "params": {"replacements": {"NULL": "status"}}, "apply": "header"},
+			{"name": "replace_values", "params": {"replacements": {"NULL": "", "id": "x"}}, "apply": "rows"}
+		]
+	}`
+	configPath := filepath.Join(dir, "pipeline.json")
+	writeTestFile(t, configPath, config)
+
+	proc, err := csvprocessor.NewFromConfig(configPath)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "output_1.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "id,status\n1,\n"; string(got) != want {
+		t.Errorf("output_1.csv = %q, want %q", got, want)
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		wantErr  error
+		contains string
+	}{
+		{
+			name:    "unknown field",
+			config:  `{"output": {"file_format": "out_%d.csv"}, "chunk_size": 10, "chunksize": 10}`,
+			wantErr: csvprocessor.ErrInvalidConfig,
+		},
+		{
+			name:    "unknown transformer",
+			config:  `{"output": {"file_format": "out_%d.csv"}, "chunk_size": 10, "transformers": [{"name": "upper_case"}]}`,
+			wantErr: csvprocessor.ErrUnknownTransformer,
+		},
+		{
+			name:    "unknown transformer param",
+			config:  `{"output": {"file_format": "out_%d.csv"}, "chunk_size": 10, "transformers": [{"name": "dedup", "params": {"column": 1}}]}`,
+			wantErr: csvprocessor.ErrInvalidConfig,
+		},
+		{
+			name:    "unknown arithmetic op",
+			config:  `{"output": {"file_format": "out_%d.csv"}, "chunk_size": 10, "transformers": [{"name": "arithmetic", "params": {"op": "mod"}}]}`,
+			wantErr: csvprocessor.ErrInvalidConfig,
+		},
+		{
+			name:    "invalid apply",
+			config:  `{"output": {"file_format": "out_%d.csv"}, "chunk_size": 10, "transformers": [{"name": "dedup", "apply": "footer"}]}`,
+			wantErr: csvprocessor.ErrInvalidConfig,
+		},
+		{
+			name:    "unknown format",
+			config:  `{"output": {"file_format": "out_%d.xml", "format": "xml"}, "chunk_size": 10}`,
+			wantErr: csvprocessor.ErrInvalidConfig,
+		},
+		{
+			name:    "invalid delimiter",
+			config:  `{"input": {"delimiter": "::"}, "output": {"file_format": "out_%d.csv"}, "chunk_size": 10}`,
+			wantErr: csvprocessor.ErrInvalidConfig,
+		},
+		{
+			name:    "files and glob",
+			config:  `{"input": {"files": ["a.csv"], "glob": "*.csv"}, "output": {"file_format": "out_%d.csv"}, "chunk_size": 10}`,
+			wantErr: csvprocessor.ErrInvalidConfig,
+		},
+		{
+			name:     "missing chunk size",
+			config:   `{"output": {"file_format": "out_%d.csv"}}`,
+			wantErr:  csvprocessor.ErrInvalidConfig,
+			contains: "missing required keys: chunk_size",
+		},
+		{
+			name:     "missing keys",
+			config:   `{"input": {"files": ["a.csv"]}}`,
+			wantErr:  csvprocessor.ErrInvalidConfig,
+			contains: "missing required keys: output.file_format, chunk_size",
+		},
+		{
+			name:     "negative chunk size",
+			config:   `{"output": {"zip": "out.zip"}, "chunk_size": -1}`,
+			wantErr:  csvprocessor.ErrInvalidConfig,
+			contains: "chunk_size",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := csvprocessor.ParseConfig(strings.NewReader(tt.config))
+			if err == nil {
+				var opts []csvprocessor.Option
+				if err = config.Validate(); err == nil {
+					if opts, err = config.Options(); err == nil {
+						_, err = csvprocessor.New(opts...)
+					}
+				}
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil && !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.contains)
+			}
+		})
+	}
+}
+
+func writeTestFile(tb testing.TB, path, data string) {
+	tb.Helper()
+
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `\`, `\\`) + `"`
+}
@@ -0,0 +1,79 @@
+package csvprocessor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+var (
+	// ErrInvalidConflictPolicy is returned for an unknown ConflictPolicy.
+	ErrInvalidConflictPolicy = errors.New("csvprocessor: invalid output conflict policy")
+
+	// ErrOutputFileExists is returned by ConflictFail when an output file already exists.
+	ErrOutputFileExists = errors.New("csvprocessor: output file already exists")
+)
+
+// ConflictPolicy represents how an output file that already exists is handled, see WithOutputConflictPolicy().
+type ConflictPolicy int
+
+const (
+	// ConflictAppend appends the rows to the existing file, it is the default.
+	ConflictAppend ConflictPolicy = iota
+	// ConflictOverwrite replaces the content of the existing file.
+	ConflictOverwrite
+	// ConflictFail stops the processing with ErrOutputFileExists.
+	ConflictFail
+)
+
+// WithOutputConflictPolicy sets how the output files of WithOutputFileFormat() that already exist are handled,
+// Eg: the chunks left by a previous run. The archive of WithZipOutput() is replaced, unless the policy is ConflictFail.
+func WithOutputConflictPolicy(policy ConflictPolicy) Option {
+	return func(c *Processor) error {
+		if policy < ConflictAppend || policy > ConflictFail {
+			return ErrInvalidConflictPolicy
+		}
+
+		c.conflictPolicy = policy
+		return nil
+	}
+}
+
+// createOutputFile opens the output file with the permissions set by WithOutputPermissions(), creating its parent directories if missing.
+// An existing file is handled as set by WithOutputConflictPolicy(), it is appended to only if canAppend is true, else it is replaced.
+func (c *Processor) createOutputFile(name string, canAppend bool) (io.WriteCloser, error) {
+	if err := os.MkdirAll(filepath.Dir(name), c.dirMode); err != nil {
+		return nil, fmt.Errorf("csvprocessor: error while creating output directory: %w", err)
+	}
+
+	appendFile := canAppend && c.conflictPolicy == ConflictAppend
+	if c.atomicWrites {
+		if c.conflictPolicy == ConflictFail {
+			if _, err := os.Lstat(name); err == nil {
+				return nil, fmt.Errorf("%w: %q", ErrOutputFileExists, name)
+			}
+		}
+
+		return createAtomicFile(name, c.fileMode, appendFile)
+	}
+
+	flag := os.O_CREATE | os.O_WRONLY //nolint:nosnakecase
+	switch {
+	case c.conflictPolicy == ConflictFail:
+		flag |= os.O_EXCL //nolint:nosnakecase
+	case appendFile:
+		flag |= os.O_APPEND //nolint:nosnakecase
+	default:
+		flag |= os.O_TRUNC //nolint:nosnakecase
+	}
+
+	file, err := os.OpenFile(name, flag, c.fileMode)
+	if errors.Is(err, fs.ErrExist) {
+		return nil, fmt.Errorf("%w: %q", ErrOutputFileExists, name)
+	}
+
+	return file, err
+}
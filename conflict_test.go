@@ -0,0 +1,78 @@
+package csvprocessor_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithOutputConflictPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  csvprocessor.ConflictPolicy
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "Test append",
+			policy: csvprocessor.ConflictAppend,
+			want:   "old\nid\n1\n",
+		},
+		{
+			name:   "Test overwrite",
+			policy: csvprocessor.ConflictOverwrite,
+			want:   "id\n1\n",
+		},
+		{
+			name:    "Test fail",
+			policy:  csvprocessor.ConflictFail,
+			want:    "old\n",
+			wantErr: csvprocessor.ErrOutputFileExists,
+		},
+	}
+	for _, tt := range tests {
+		for _, atomic := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s atomic=%v", tt.name, atomic), func(t *testing.T) {
+				dir := t.TempDir()
+				output := filepath.Join(dir, "out_1.csv")
+				if err := os.WriteFile(output, []byte("old\n"), 0o600); err != nil {
+					t.Fatalf("WriteFile() error = %v", err)
+				}
+
+				proc, err := csvprocessor.New(
+					csvprocessor.WithInputReader(strings.NewReader("id\n1\n")),
+					csvprocessor.WithChunkSize(10),
+					csvprocessor.WithOutputFileFormat(filepath.Join(dir, "out_%d.csv")),
+					csvprocessor.WithOutputConflictPolicy(tt.policy),
+					csvprocessor.WithAtomicWrites(atomic),
+					csvprocessor.WithLogger(t.Logf),
+				)
+				if err != nil {
+					t.Fatalf("New() error = %v", err)
+				}
+
+				if err := proc.Process(); !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+				}
+
+				got, err := os.ReadFile(output)
+				if err != nil {
+					t.Fatalf("ReadFile() error = %v", err)
+				}
+
+				if string(got) != tt.want {
+					t.Errorf("output = %q, want %q", got, tt.want)
+				}
+			})
+		}
+	}
+
+	if _, err := csvprocessor.New(csvprocessor.WithOutputConflictPolicy(csvprocessor.ConflictFail + 1)); !errors.Is(err, csvprocessor.ErrInvalidConflictPolicy) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidConflictPolicy)
+	}
+}
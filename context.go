@@ -8,17 +8,24 @@ type any = interface{} //nolint:predeclared
 type csvCtx struct {
 	context.Context //nolint:containedctx
 	m               map[ctxKey]any
+	profiler        *profiler // see WithProfile().
 }
 
-func newCtx() *csvCtx {
-	ctx := csvCtx{context.TODO(), make(map[ctxKey]any)}
+// ctxKeys are the keys of the values set by the processor in its context.
+var ctxKeys = []ctxKey{
+	CtxChunkNum, CtxRowNum, CtxChunkRowNum, CtxTotalRows, CtxIsHeader, CtxHeaderRows, CtxHeaderRowNum, CtxChunkSize, CtxHeader, CtxColumnIndex,
+}
+
+// newCtx returns the context passed to the transformers, the values and the cancellation of the parent are also available.
+func newCtx(parent context.Context) *csvCtx {
+	ctx := csvCtx{Context: parent, m: make(map[ctxKey]any)}
 	return &ctx
 }
 
 func (c *csvCtx) Value(key any) any {
 	k, ok := key.(ctxKey)
 	if !ok {
-		return nil
+		return c.Context.Value(key)
 	}
 
 	return c.m[k]
@@ -27,3 +34,15 @@ func (c *csvCtx) Value(key any) any {
 func (c *csvCtx) setValue(key ctxKey, val any) {
 	c.m[key] = val
 }
+
+// isHeader reports whether the row being transformed is a header,
+// the value is read directly from the processor's context to avoid the allocation of boxing the key in ctx.Value().
+func isHeader(ctx context.Context) bool {
+	if c, ok := ctx.(*csvCtx); ok {
+		header, _ := c.m[CtxIsHeader].(bool)
+		return header
+	}
+
+	header, _ := ctx.Value(CtxIsHeader).(bool)
+	return header
+}
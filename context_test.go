@@ -1,12 +1,13 @@
 package csvprocessor
 
 import (
+	"context"
 	"testing"
 )
 
 func Test_newCtx(t *testing.T) {
 	t.Run("valid context test", func(t *testing.T) {
-		got := newCtx()
+		got := newCtx(context.TODO())
 		if got == nil {
 			t.Errorf("newCtx() expected non-nil value, got = %v", got)
 		}
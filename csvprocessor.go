@@ -5,13 +5,15 @@ package csvprocessor
 
 import (
 	"bufio"
+	"context"
 	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
-	"os"
+	"math/rand"
 	"strings"
+	"time"
 )
 
 // CsvProcessor represents the interface for transforming and splitting CSVs.
@@ -27,6 +29,16 @@ type CsvWriter interface {
 	Write(record []string) error
 }
 
+// CsvWriterFactory creates the CsvWriter that writes the rows of an output chunk to w.
+// If the CsvWriter implements io.Closer, it is closed after the last row of the chunk, Eg: to write a footer.
+type CsvWriterFactory func(w io.Writer) CsvWriter
+
+// HeaderWriter is implemented by the CsvWriters that handle the header differently from the rows, Eg: JSONWriter.
+// For such writers, WriteHeader is called with the header instead of Write.
+type HeaderWriter interface {
+	WriteHeader(header []string) error
+}
+
 // CsvReader represents the reader from which CSV content can be read.
 type CsvReader interface {
 	Read() ([]string, error)
@@ -34,6 +46,15 @@ type CsvReader interface {
 
 // Processor is the default implementation for CsvProcessor.
 type Processor struct {
+	// chunkInterval is the max. time a chunk is kept open, see WithChunkInterval().
+	chunkInterval time.Duration
+
+	// minLastChunkRows is the min. no. of rows of the last chunk, see WithMinLastChunkRows().
+	minLastChunkRows int
+
+	// groupColumns are the key columns of the groups of rows that are not split across chunks, see WithKeepKeyGroups().
+	groupColumns []int
+
 	// chunkSize represents the no of rows per each file when splitting the CSV into multiple files.
 	// To prevent splitting, set this value to be greater than the total no. of rows.
 	chunkSize int
@@ -49,14 +70,201 @@ type Processor struct {
 	skipHeaders bool
 
 	// log represents the logger used by the processor to print info and diagnostics.
-	log Logger
+	log LeveledLogger
 
 	WriteBufferSize int
 
+	// fileMode and dirMode are the permissions of the output files and of the directories created for them.
+	fileMode fs.FileMode
+	dirMode  fs.FileMode
+
+	// conflictPolicy represents how the existing output files are handled, see WithOutputConflictPolicy().
+	conflictPolicy ConflictPolicy
+
+	// atomicWrites controls whether the output files are written to temp files and renamed when complete, see WithAtomicWrites().
+	atomicWrites bool
+
+	// readBufferSize represents the size of the buffer used to read each input stream, see WithReadBufferSize().
+	readBufferSize int
+
+	// inputDelimiter represents the field delimiter used to parse the input streams.
+	inputDelimiter rune
+
+	// outputDelimiter represents the field delimiter used in the output chunks.
+	outputDelimiter rune
+
+	// quoteMode represents how the fields are quoted in the output chunks.
+	quoteMode QuoteMode
+
+	// inputQuote and inputEscape are the quote and escape characters of the input streams, see WithInputQuote().
+	inputQuote  rune
+	inputEscape rune
+
+	// outputQuote and outputEscape are the quote and escape characters of the output chunks, see WithOutputQuote().
+	outputQuote  rune
+	outputEscape rune
+
+	// fastParser controls whether the inputs are parsed with FastReader, see WithFastParser().
+	fastParser bool
+
+	// writerFactory creates the CsvWriter for each chunk, if set. See WithWriterFactory().
+	writerFactory CsvWriterFactory
+	// sqlDialect is the syntax of the SQL statements, see WithSQLDialect().
+	sqlDialect SQLDialect
+
+	// useCRLF controls whether the output rows end with \r\n instead of \n.
+	useCRLF bool
+
+	// outputBOM controls whether a BOM is written at the start of every chunk.
+	outputBOM bool
+
+	// autoDialect controls whether the delimiter and the presence of header are detected from the input.
+	autoDialect bool
+
+	// fieldCountMode controls how the rows with a different no. of fields than the header are handled.
+	fieldCountMode FieldCountMode
+
+	// headerRows is the no. of rows in the header block, see WithHeaderRows().
+	headerRows int
+
+	// inputHeader is the header of the inputs without a header row, see WithHeader().
+	inputHeader []string
+
+	// dialect is the dialect detected from the first input in the current run, nil if it is not detected, see WithAutoDialect().
+	dialect *Dialect
+
+	// preScan controls whether the rows of the inputs are counted before processing them, see WithPreScan().
+	preScan bool
+	// totalChunks is the estimated no. of chunks of the current run, 0 if it is not known.
+	totalChunks int
+	// chunkCount is the no. of chunks that the rows are split into, see WithChunkCount().
+	chunkCount int
+
+	// timeChunks splits the rows into chunks by the time window of a column, see WithChunkByTimeColumn().
+	timeChunks *timeChunks
+	// chunkWindow is the start of the time window of the chunk being opened, formatted for the chunk name.
+	chunkWindow string
+
+	// shards is the no. of shards that the rows are distributed across, see WithRoundRobin().
+	shards int
+	// shardColumn is the key column of the shards, -1 if the rows are distributed in turn. See WithShardByHash().
+	shardColumn int
+
+	// firstPass collects the rows of the input before they are processed, see WithFirstPass().
+	firstPass CsvRowTransformer
+	// runSeed is the seed of the random no. generators of the current run.
+	runSeed int64
+
+	// expectedHeader is the header that the input must have, see WithExpectedHeader().
+	expectedHeader []string
+	strictHeader   bool
+
+	// skipLines is the no. of lines discarded at the start of each input, see WithSkipLeadingLines().
+	skipLines int
+
+	// footerRows is the no. of footer rows at the end of each input, see WithFooterRows().
+	footerRows   int
+	footerPolicy FooterPolicy
+	footer       [][]string // footer rows of the current run.
+
+	// join is the join with another input, see WithJoin().
+	join *Join
+
+	// transposeCells is the max no. of cells of the input to transpose, 0 if the input is not transposed. See WithTranspose().
+	transposeCells int
+
+	// columnStats controls whether the stats of the columns are collected, see WithColumnStats().
+	columnStats bool
+
+	// dataProfile controls whether the profile of the output is collected, see WithDataProfile().
+	dataProfile      bool
+	profileTopValues int
+
+	// headerDetection controls whether the presence of header is detected from the first rows of the input.
+	headerDetection bool
+
+	// inputEncoding and outputEncoding convert the input and output from/to UTF-8, nil means UTF-8.
+	inputEncoding  encoding
+	outputEncoding encoding
+
+	// skipRows, limitRows and tailRows represent the range of input rows that are processed.
+	skipRows  int
+	limitRows int
+	tailRows  int
+
+	// sampleRate and sampleSize represent the random sampling applied on the input rows, if set.
+	sampleRate float64
+	sampleSize int
+	seed       int64
+	seeded     bool
+
+	// groupBy and aggregations represent the aggregation applied on the input before processing, if set.
+	groupBy      []int
+	aggregations []Aggregation
+
+	// sortColumns represents the columns by which the rows are sorted before processing, if set.
+	sortColumns []int
+	sortOrder   SortOrder
+	sortRunSize int
+
+	// maxMemory bounds the buffers and the rows held in memory, 0 means unbounded, see WithMaxMemory().
+	maxMemory int64
+
+	// zeroCopy controls whether the copies of the rows are reused, see WithZeroCopy().
+	zeroCopy bool
+
+	// profile controls whether the time spent in each phase is recorded, see WithProfile().
+	profile bool
+
+	// inputBuffers are the read buffers of the inputs, returned to the pool when the inputs are closed.
+	inputBuffers []*bufio.Reader
+
+	// validator validates each input row against the schema, if set.
+	validator *validator
+
+	// rejectWriter is the writer to which the rows that fail validation or transformation are written, if set.
+	rejectWriter CsvWriter
+
+	// dryRun controls whether the output is discarded, see WithDryRun().
+	dryRun bool
+
+	// onChunkStart and onChunkEnd are called when a chunk is opened and closed, see WithChunkHooks().
+	onChunkStart func(ChunkInfo) error
+	onChunkEnd   func(ChunkInfo) error
+
+	// rowErrorHandler decides how the errors in a row are handled, see WithRowErrorHandler().
+	rowErrorHandler RowErrorHandler
+
+	// metrics are updated as the rows are processed, if set. See WithMetrics().
+	metrics *Metrics
+
+	// progressInterval is the interval at which the progress is logged, see WithProgressInterval().
+	progressInterval time.Duration
+
+	// rateLimit is the max. no. of rows processed per second, see WithRateLimit().
+	rateLimit float64
+
+	// tracer traces the processing, if set. See WithTracer().
+	tracer         Tracer
+	traceBatchSize int // no. of rows in each row batch span, see WithRowBatchSpans().
+
+	// checksums are the algorithms of the checksums computed for each chunk, see WithChunkChecksums().
+	checksums []string
+
+	// beforeRunHook and afterRunHook are called before and after each run, see WithBeforeRun() and WithAfterRun().
+	beforeRunHook func(context.Context) error
+	afterRunHook  func(context.Context, Stats) error
+
 	// Unexported fields
+	stats                Stats                // stats of the last Process() run
 	header               []string             // contains the header row
+	extraHeaders         [][]string           // contains the header rows after the first one, see WithHeaderRows()
 	reader               CsvReader            // reader from which input content is read.
+	inputs               []io.Reader          // input streams that are parsed as CSV, if reader is not set.
+	fixedWidthColumns    []FixedWidthColumn   // columns of the fixed-width input, see WithFixedWidthInput().
 	outputChunkGenerator OutputChunkGenerator // function to generate output chunk files
+	newArchive           newArchiveFunc       // creates the archive to which the chunks are written, see WithZipOutput()
+	archiveEntryFormat   string               // format of the entry names in the archive
 }
 
 type ctxKey string
@@ -87,12 +295,43 @@ var (
 	// For headers, this value will be -1.
 	CtxRowNum ctxKey = "_csvproc_rownum"
 
+	// CtxChunkRowNum represents the context.Context() key which contains the row no. of the current row within its chunk,
+	// starting from 1 in every chunk. Unlike CtxRowNum % CtxChunkSize, it counts only the rows written to the chunk,
+	// so it is correct when rows are filtered out or the chunks are rotated by size or time.
+	// If the transformer filters out the row, the next row gets the same no. For headers, this value will be -1.
+	CtxChunkRowNum ctxKey = "_csvproc_chunkrownum"
+
+	// CtxTotalRows represents the context.Context() key which contains the total no. of data rows of the input (int),
+	// counted before the processing. It is set only if WithPreScan() is used.
+	CtxTotalRows ctxKey = "_csvproc_totalrows"
+
 	// CtxIsHeader represents the context.Context() key which contains whether the current row is a header or not.
 	CtxIsHeader ctxKey = "_csvproc_isheader"
 
+	// CtxHeaderRows represents the context.Context() key which contains all the header rows ([][]string) for header rows.
+	// The header has multiple rows only when WithHeaderRows() is used.
+	CtxHeaderRows ctxKey = "_csvproc_headerrows"
+
+	// CtxHeaderRowNum represents the context.Context() key which contains the index of the current header row within CtxHeaderRows.
+	CtxHeaderRowNum ctxKey = "_csvproc_headerrownum"
+
 	// CtxChunkSize represents the context.Context() key which contains the Chunk size for this processor.
 	CtxChunkSize ctxKey = "_csvproc_chunksize"
 
+	// CtxHeader represents the context.Context() key which contains the header of the input ([]string) for all the rows,
+	// it is not set if the input has no header. The header is the one read from the input (or set using WithHeader()),
+	// before any transformer is applied to it, and must not be modified.
+	CtxHeader ctxKey = "_csvproc_header"
+
+	// CtxColumnIndex represents the context.Context() key which contains the 0-based index of each column of CtxHeader
+	// by its name (map[string]int) for all the rows, so that the transformers can find a column by its name.
+	// If the header has duplicate names, the first column is used. The map must not be modified.
+	// Eg:
+	//
+	//	columns, _ := ctx.Value(csvprocessor.CtxColumnIndex).(map[string]int)
+	//	index, ok := columns["email"]
+	CtxColumnIndex ctxKey = "_csvproc_columnindex"
+
 	// noOpTransformer is the default transformer, it does not modify the rows.
 	noOpTransformer CsvRowTransformer = NoOpTransformer()
 )
@@ -101,89 +340,1042 @@ const (
 	// file permission for the output files.
 	permission fs.FileMode = 0o644
 
+	// permission for the parent directories of the output files, created if missing.
+	dirPermission fs.FileMode = 0o755
+
 	// DefaultWriteBufferSize represents the default write buffer size of CsvWriter implementation used by the Processor.
 	DefaultWriteBufferSize = 10 * 1024 * 1024
 
 	// DefaultReadBufferSize represents the default read buffer size of CsvReader implementation used by the Processor.
 	DefaultReadBufferSize = 10 * 1024 * 1024
+
+	// UTF-8 byte order mark.
+	utf8BOM = "\ufeff"
 )
 
 // Process performs the transformation and splitting and writes the output to the given location.
 func (c *Processor) Process() error {
-	return c.process()
+	return c.ProcessContext(context.Background())
+}
+
+// ProcessContext is like Process(), the processing stops with ctx.Err() if ctx is cancelled.
+// ctx is passed to the hooks (see WithBeforeRun()), and its values are available to the transformers.
+func (c *Processor) ProcessContext(ctx context.Context) error {
+	return c.process(ctx, runOutput{})
+}
+
+// runOutput overrides the output of the processor for a run.
+type runOutput struct {
+	sink        CsvWriter            // writer for all the chunks, if set. See RowIterator.
+	generator   OutputChunkGenerator // generator for the chunks, if set. See Reader().
+	singleChunk bool                 // all the rows are written to a single chunk.
+}
+
+// process runs the processing, writing the rows to the output of the processor unless it is overridden by out.
+func (c *Processor) process(ctx context.Context, out runOutput) (err error) {
+	ctx, span := c.startSpan(ctx, SpanProcess)
+	defer func() {
+		c.endProcessSpan(span, err)
+	}()
+
+	if err := c.beforeRun(ctx); err != nil {
+		_ = c.closeInputs()
+		return err
+	}
+
+	if err := c.processInput(ctx, out); err != nil {
+		return err
+	}
+
+	return c.afterRun(ctx)
 }
 
-func (c *Processor) process() error {
-	var fileWriter CsvWriter
-	var outputFile io.WriteCloser
+// processInput reads, transforms and writes the rows of the input.
+func (c *Processor) processInput(ctx context.Context, out runOutput) (err error) {
+	// the same seed is used for all the passes over the input, so that the same rows are sampled.
+	c.runSeed = c.seed
+	if !c.seeded {
+		c.runSeed = time.Now().UnixNano()
+	}
+
+	// the dialect is detected again from the inputs of each run.
+	c.dialect = nil
+	c.setHTTPContext(ctx)
+
+	scan, err := c.scanInputs()
+	if err != nil {
+		_ = c.closeInputs()
+		return err
+	}
 
-	currentRow := 0
-	currentSplit := 0
-	addHeaders := !c.skipHeaders
-	needNewChunk := true
-	ctx := newCtx()
+	if err := c.runFirstPass(ctx, scan); err != nil {
+		_ = c.closeInputs()
+		return err
+	}
+
+	c.totalChunks = 0
+	if scan != nil && c.timeChunks == nil {
+		c.totalChunks = scan.chunks(c.chunkSize)
+		if c.chunkCount > 0 {
+			c.totalChunks = scan.countChunks(c.chunkCount)
+		}
+	}
 
-	ctx.setValue(CtxChunkSize, c.chunkSize)
+	progress := c.newProgress(scan)
+	defer progress.stop()
 
+	limiter := c.newRateLimiter()
+
+	reader, closeReader, err := c.inputReader(progress.counter())
+	if err != nil {
+		_ = closeReader()
+		return err
+	}
+
+	defer func() {
+		if closeErr := closeReader(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	r := c.newRun(ctx, out)
+	r.scan = scan
+	if scan != nil {
+		r.ctx.setValue(CtxTotalRows, scan.rows)
+	}
+
+	if c.newArchive != nil && !c.dryRun && out.sink == nil && out.generator == nil {
+		archive, createErr := c.newArchive(c.archiveEntryFormat)
+		if createErr != nil {
+			return createErr
+		}
+
+		r.generator = archive.chunk
+		defer func() {
+			if err != nil {
+				_ = archive.abort()
+				return
+			}
+
+			if closeErr := archive.close(); closeErr != nil {
+				err = fmt.Errorf("csvprocessor: error while closing archive: %w", closeErr)
+			}
+		}()
+	}
+
+	defer func() {
+		r.endBatchSpan(true)
+		r.stats.Profile = r.profiler.result()
+		r.stats.Footer = c.footer
+		r.stats.Columns = r.columns.result()
+		r.stats.DataProfile = r.columns.dataProfile()
+		c.stats = r.stats
+		if err == nil {
+			c.logProfile(r.stats.Profile)
+		}
+		if err != nil {
+			r.abortChunks()
+		}
+
+		if c.rejectWriter == nil {
+			return
+		}
+
+		if flushErr := flushToFile(c.rejectWriter); err == nil && flushErr != nil {
+			err = fmt.Errorf("csvprocessor: error while flushing rejects writer: %w", flushErr)
+		}
+	}()
+
+	readHeader := c.hasHeader()
 	for {
-		row, err := c.reader.Read()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		progress.check(r.currentRow)
+
+		readStart := r.profiler.start()
+		row, err := reader.Read()
+		r.profiler.end(phaseRead, readStart)
 		if errors.Is(err, io.EOF) {
 			break
 		}
 
-		if needNewChunk {
-			// close previous chunk file
-			c.log("%d rows processed \n", currentRow)
-			err := flushAndCloseFile(fileWriter, outputFile)
-			if err != nil {
+		if errors.Is(err, ErrNoMessage) {
+			// the stream is idle, the chunk is rotated without waiting for the next row.
+			if r.chunkExpired() && r.currentChunk().Rows > 0 {
+				if err := r.closeChunk(); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return fmt.Errorf("csvprocessor: error while reading input: %w", err)
+		}
+
+		if err != nil {
+			// malformed rows are recorded and skipped, rows with unexpected no. of fields are processed.
+			rowErr := &RowError{Row: r.currentRow + 1, Err: err}
+			switch r.decide(rowErr.Row, row, rowErr) {
+			case DecisionAbort:
+				return rowErr
+			case DecisionSkip:
+				r.addErrors(rowErr)
+				if row != nil {
+					r.currentRow++
+					r.stats.RowsRead++
+					r.c.metrics.rowRead()
+				}
+
+				continue
+			}
+
+			r.addErrors(rowErr)
+			if row == nil {
+				continue
+			}
+		}
+
+		if readHeader {
+			// the first row is the header, it is written at the top of every chunk.
+			c.header = append([]string(nil), row...)
+			if len(c.header) > 0 {
+				// a BOM in the header would break the header name matching.
+				c.header[0] = strings.TrimPrefix(c.header[0], utf8BOM)
+			}
+			readHeader = false
+
+			r.fieldCount = len(c.header)
+			r.setHeader(c.header)
+			if err := r.validateHeader(c.header); err != nil {
 				return err
 			}
 
-			// update split id
-			currentSplit++
-			ctx.setValue(CtxChunkNum, currentSplit)
-			addHeaders = !c.skipHeaders
+			switch {
+			case r.sharded():
+				if err := r.openShards(); err != nil {
+					return err
+				}
+			case c.timeChunks == nil:
+				// with time chunking, the first row decides the window of the first chunk.
+				if err := r.openChunk(r.currentSplit + 1); err != nil {
+					return err
+				}
+			}
 
-			// create next chunk file
-			outputFile, err = c.outputChunkGenerator(currentSplit)
-			if err != nil {
+			continue
+		}
+
+		if err := limiter.wait(ctx); err != nil {
+			return err
+		}
+
+		if c.minLastChunkRows > 0 {
+			err = r.holdRow(row)
+		} else {
+			err = r.processRow(row)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := r.releaseRows(true); err != nil {
+		return err
+	}
+
+	c.log.Log(LogInfo, fmt.Sprintf("%d total rows updated", r.currentRow))
+	if r.shards != nil {
+		// the footer is written at the end of the last shard.
+		if err := r.useShard(len(r.shards) - 1); err != nil {
+			return err
+		}
+	}
+
+	if err := r.writeFooter(); err != nil {
+		return err
+	}
+
+	if err := r.closeChunks(); err != nil {
+		return err
+	}
+
+	if c.dryRun {
+		c.logDryRun(r.stats)
+	}
+
+	return nil
+}
+
+// run holds the state of a single Process() run.
+type run struct {
+	c         *Processor
+	ctx       *csvCtx
+	generator OutputChunkGenerator
+	out       runOutput
+	stats     Stats
+
+	currentRow   int       // overall row no. of the last row read.
+	currentSplit int       // ID of the current chunk.
+	chunkOpened  time.Time // time at which the current chunk was opened.
+	chunkIndex   int       // index of the current chunk in stats.Chunks.
+	window       time.Time // start of the time window of the current chunk, see WithChunkByTimeColumn().
+	groupKey     string    // key of the last row written, see WithKeepKeyGroups().
+
+	rejectHeaderWritten bool
+
+	// counts of the pre-scan, nil if WithPreScan() is not used.
+	scan *inputScan
+
+	// rows held after a full chunk and whether they are the last rows, see WithMinLastChunkRows().
+	heldRows  [][]string
+	lastChunk bool
+
+	// no. of fields in a row, used by WithNormalizeFieldCount().
+	fieldCount int
+
+	// current chunk, nil if no chunk is open.
+	outputFile  io.WriteCloser
+	fileWriter  CsvWriter
+	chunkBytes  int64         // no. of bytes written to the outputFile.
+	chunkHashes *chunkHashes  // checksums of the bytes written to the outputFile, if enabled.
+	writeBuffer *bufio.Writer // buffer of the fileWriter, returned to the pool when the chunk is closed.
+
+	// copies of the rows reused for every row, see WithZeroCopy().
+	originalRow []string
+	retryRow    []string
+	headerRow   []string
+
+	// open chunks of the shards and the index of the current one, see WithRoundRobin().
+	shards []chunkState
+	shard  int
+
+	// profiler records the time spent in each phase, see WithProfile().
+	profiler *profiler
+
+	// columns collects the stats of the columns written, see WithColumnStats().
+	columns *columnCollector
+
+	// spans of the run, see WithTracer().
+	spanCtx       context.Context //nolint:containedctx
+	chunkSpan     Span
+	batchSpan     Span
+	batchFirstRow int
+}
+
+func (c *Processor) newRun(ctx context.Context, out runOutput) *run {
+	c.header = nil
+	c.extraHeaders = nil
+	c.footer = nil
+	r := &run{
+		c:         c,
+		ctx:       newCtx(ctx),
+		generator: c.outputChunkGenerator,
+		out:       out,
+		spanCtx:   ctx,
+		profiler:  c.newProfiler(),
+		columns:   c.newColumnCollector(),
+	}
+	r.ctx.profiler = r.profiler
+
+	switch {
+	case c.dryRun || out.sink != nil:
+		r.generator = func(int) (io.WriteCloser, error) {
+			return NoOpCloser(io.Discard), nil
+		}
+	case out.generator != nil:
+		r.generator = out.generator
+	}
+
+	r.ctx.setValue(CtxChunkSize, c.chunkSize)
+	return r
+}
+
+// processRow transforms the row and writes it to the current chunk, opening a new chunk if needed.
+func (r *run) processRow(row []string) error {
+	c := r.c
+	r.currentRow++
+	r.stats.RowsRead++
+	r.c.metrics.rowRead()
+	r.traceRow()
+	defer r.endBatchSpan(false)
+
+	if c.fieldCountMode != FieldCountAny {
+		if r.fieldCount == 0 {
+			// without a header, the first row decides the no. of fields.
+			r.fieldCount = len(row)
+		}
+
+		var err error
+		if row, err = normalizeFieldCount(c.fieldCountMode, row, r.fieldCount); err != nil {
+			if rejected, err := r.rowFailed(row, []error{&RowError{Row: r.currentRow, Err: err}}); rejected || err != nil {
 				return err
 			}
+		}
+	}
 
-			fileWriter = c.getCsvWriter(outputFile)
+	if c.validator != nil {
+		if errs := c.validator.validate(r.currentRow, row); len(errs) > 0 {
+			if rejected, err := r.rowFailed(row, errs); rejected || err != nil {
+				return err
+			}
 		}
+	}
 
-		if addHeaders {
-			// transform and write header
-			err = c.writeHeaders(row, ctx, fileWriter)
-			if err != nil {
+	var originalRow []string
+	if c.rejectWriter != nil || c.rowErrorHandler != nil {
+		// transformers can modify the row in-place, so a copy is kept to be written to the rejects writer or retried.
+		originalRow = r.copyRow(&r.originalRow, row)
+	}
+
+	window := r.window
+	if c.timeChunks != nil {
+		var err error
+		if window, err = c.timeChunks.windowOf(row); err != nil {
+			if rejected, err := r.rowFailed(row, []error{&RowError{Row: r.currentRow, Err: err}}); rejected || err != nil {
 				return err
 			}
 
-			addHeaders = false
+			// the rows kept by the error handler are written to the current chunk.
+			window = r.window
+		}
+	}
+
+	sharded := r.sharded()
+	if sharded {
+		if err := r.useShard(r.shardOf(row)); err != nil {
+			return err
+		}
+	}
 
-			if currentRow == 0 {
-				needNewChunk = false
-				continue
+	var groupKey string
+	if c.groupColumns != nil {
+		groupKey = rowKey(row, c.groupColumns)
+	}
+
+	// a full chunk is not closed until the group of the last row written ends.
+	chunkFull := r.chunkFull() && (c.groupColumns == nil || groupKey != r.groupKey)
+	needNewChunk := !sharded && (r.fileWriter == nil || chunkFull || r.chunkExpired() || (!r.out.singleChunk && !window.Equal(r.window)))
+	chunkID, chunkRowNum := r.currentSplit, 1
+	if needNewChunk {
+		chunkID++
+	} else {
+		chunkRowNum = r.currentChunk().Rows + 1
+	}
+
+	// transform the row
+	r.ctx.setValue(CtxChunkNum, chunkID)
+	r.ctx.setValue(CtxIsHeader, false)
+	r.ctx.setValue(CtxRowNum, r.currentRow)
+	r.ctx.setValue(CtxChunkRowNum, chunkRowNum)
+	transformedRow, err := r.transform(r.currentRow, row)
+	for err != nil {
+		decision := r.decide(r.currentRow, originalRow, err)
+		if decision != DecisionRetry {
+			_, err = r.failRow(originalRow, []error{err}, decision)
+			return err
+		}
+
+		transformedRow, err = r.transform(r.currentRow, r.copyRow(&r.retryRow, originalRow))
+	}
+
+	if transformedRow == nil {
+		// transformer has filtered out this row.
+		return nil
+	}
+
+	if needNewChunk {
+		if err := r.closeChunk(); err != nil {
+			return err
+		}
+
+		r.window = window
+		if c.timeChunks != nil {
+			c.chunkWindow = c.timeChunks.format(window)
+		}
+
+		if err := r.openChunk(chunkID); err != nil {
+			return err
+		}
+
+		r.currentChunk().Window = window
+	}
+
+	if skipped, err := r.write(transformedRow); skipped || err != nil {
+		return err
+	}
+
+	chunk := r.currentChunk()
+	if chunk.Rows == 0 {
+		chunk.FirstRow = r.currentRow
+	}
+
+	chunk.LastRow = r.currentRow
+	chunk.Rows++
+	r.groupKey = groupKey
+	r.stats.RowsWritten++
+	r.c.metrics.rowWritten()
+	r.columns.add(transformedRow)
+	return nil
+}
+
+// openChunk creates the output file for the given chunk and writes the header rows to it.
+func (r *run) openChunk(chunkID int) error {
+	r.c.log.Log(LogDebug, fmt.Sprintf("%d rows processed \n", r.currentRow))
+
+	r.currentSplit = chunkID
+	r.chunkOpened = time.Now()
+	r.ctx.setValue(CtxChunkNum, chunkID)
+	r.stats.Chunks = append(r.stats.Chunks, ChunkInfo{ID: chunkID})
+	r.chunkIndex = len(r.stats.Chunks) - 1
+	r.startChunkSpan()
+
+	writeStart := r.profiler.start()
+	outputFile, err := r.generator(chunkID)
+	r.profiler.end(phaseWrite, writeStart)
+	if err != nil {
+		return err
+	}
+
+	r.outputFile = outputFile
+	r.chunkBytes = 0
+	if named, ok := outputFile.(interface{ Name() string }); ok {
+		r.currentChunk().Name = named.Name()
+	}
+
+	if err := r.chunkHook(r.c.onChunkStart); err != nil {
+		return err
+	}
+
+	var output io.Writer = &countingWriter{w: outputFile, n: &r.chunkBytes}
+	r.chunkHashes = nil
+	if len(r.c.checksums) > 0 {
+		r.chunkHashes = newChunkHashes(r.c.checksums)
+		output = r.chunkHashes.tee(output)
+	}
+
+	if r.c.outputEncoding != nil {
+		output = r.c.outputEncoding.newEncoder(output)
+	}
+
+	if r.c.outputBOM && (r.c.outputEncoding == nil || hasBOM(r.c.outputEncoding)) {
+		// the BOM is written through the encoder, so it is the BOM of the output encoding.
+		if _, err := io.WriteString(output, utf8BOM); err != nil {
+			return err
+		}
+	}
+
+	r.fileWriter = r.out.sink
+	if r.fileWriter == nil {
+		// the chunks of all the shards are open at once, see WithRoundRobin().
+		r.writeBuffer = getWriteBuffer(output, r.c.chunkBufferSize(len(r.shards)))
+		r.fileWriter = r.c.getCsvWriter(r.writeBuffer)
+	}
+
+	if r.c.header != nil {
+		return r.writeHeaders()
+	}
+
+	return nil
+}
+
+func (r *run) writeHeaders() error {
+	r.ctx.setValue(CtxIsHeader, true)
+	r.ctx.setValue(CtxRowNum, -1)
+	r.ctx.setValue(CtxChunkRowNum, -1)
+
+	headers := append([][]string{r.c.header}, r.c.extraHeaders...)
+	headerWriter, isHeaderWriter := r.fileWriter.(HeaderWriter)
+	if isHeaderWriter {
+		// writers like JSONWriter need only the column names.
+		headers = headers[:1]
+	}
+
+	r.ctx.setValue(CtxHeaderRows, headers)
+	for i, headerRow := range headers {
+		r.ctx.setValue(CtxHeaderRowNum, i)
+
+		// transformers can modify the row in-place, so a copy of the header is passed to them.
+		header, err := r.transform(-1, r.copyRow(&r.headerRow, headerRow))
+		if err != nil {
+			return err
+		}
+
+		if i == 0 && header != nil {
+			r.columns.setHeader(header)
+		}
+
+		switch {
+		case header == nil:
+		case isHeaderWriter:
+			err = headerWriter.WriteHeader(header)
+		default:
+			err = r.fileWriter.Write(header)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// closeChunk flushes and closes the current chunk, if any.
+func (r *run) closeChunk() error {
+	if r.fileWriter == nil && r.outputFile == nil {
+		return nil
+	}
+
+	writeStart := r.profiler.start()
+	err := flushAndCloseFile(r.fileWriter, r.outputFile)
+	r.profiler.end(phaseWrite, writeStart)
+	r.releaseChunk()
+	if err != nil {
+		r.endChunkSpan(err)
+		return err
+	}
+
+	r.currentChunk().Bytes = r.chunkBytes
+	if r.chunkHashes != nil {
+		r.currentChunk().Checksums = r.chunkHashes.sums()
+	}
+
+	r.c.metrics.chunkWritten(*r.currentChunk(), time.Since(r.chunkOpened))
+
+	err = r.chunkHook(r.c.onChunkEnd)
+	r.endChunkSpan(err)
+	return err
+}
+
+// abortChunk releases the current chunk, if any, after an error. The buffered rows are not flushed.
+func (r *run) abortChunk() {
+	if r.chunkSpan != nil {
+		r.chunkSpan.SetAttribute("csvprocessor.chunk.aborted", true)
+		r.endChunkSpan(nil)
+	}
+
+	if aborter, ok := r.fileWriter.(interface{ Abort() }); ok {
+		aborter.Abort()
+	}
+
+	if aborter, ok := r.outputFile.(interface{ Abort() }); ok {
+		// Eg: to cancel an upload, so that the partial chunk is not stored.
+		aborter.Abort()
+		r.releaseChunk()
+		return
+	}
+
+	if r.outputFile != nil {
+		_ = r.outputFile.Close()
+	}
+
+	r.releaseChunk()
+}
+
+// releaseChunk releases the writers of the current chunk after it is closed or aborted.
+func (r *run) releaseChunk() {
+	putWriteBuffer(r.writeBuffer)
+	r.fileWriter, r.outputFile, r.writeBuffer = nil, nil, nil
+}
+
+// setHeader sets the header and the index of its columns in the context of the transformers.
+func (r *run) setHeader(header []string) {
+	setHeaderCtx(r.ctx, header)
+}
+
+func setHeaderCtx(ctx *csvCtx, header []string) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if _, ok := index[name]; !ok {
+			index[name] = i
+		}
+	}
+
+	ctx.setValue(CtxHeader, header)
+	ctx.setValue(CtxColumnIndex, index)
+}
+
+// validateHeader validates the header against the expected header and the schema, if set.
+// In a dry run, the violations are recorded in the stats instead of being returned.
+func (r *run) validateHeader(header []string) error {
+	var errs []error
+	if r.c.expectedHeader != nil {
+		if err := checkHeader(header, r.c.expectedHeader, r.c.strictHeader); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if r.c.validator != nil {
+		errs = append(errs, r.c.validator.validateHeader(header)...)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	if r.c.dryRun {
+		r.addErrors(errs...)
+		return nil
+	}
+
+	return errs[0]
+}
+
+// transform applies the transformer on the row, a panic in the transformer is returned as an error.
+func (r *run) transform(rowNum int, row []string) (transformedRow []string, err error) {
+	defer r.profiler.end(phaseTransform, r.profiler.start())
+	if observe := r.c.metrics.timeTransform(); observe != nil {
+		defer observe()
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			r.profiler.panicked()
+			panicErr, ok := recovered.(error)
+			if !ok {
+				panicErr = fmt.Errorf("%w: %v", ErrTransformerPanic, recovered)
 			}
+
+			err = &RowError{Row: rowNum, Err: panicErr}
 		}
+	}()
 
-		currentRow++
-		// transform the row
-		ctx.setValue(CtxIsHeader, false)
-		ctx.setValue(CtxRowNum, currentRow)
-		if err := fileWriter.Write(c.rowTransformer(ctx, row)); err != nil {
+	return r.c.rowTransformer(r.ctx, row), nil
+}
+
+// rowFailed handles the errors in a row, it returns true if the row was skipped. See failRow().
+func (r *run) rowFailed(row []string, errs []error) (bool, error) {
+	return r.failRow(row, errs, r.decide(r.currentRow, row, errs[0]))
+}
+
+// failRow handles the errors in a row as decided by the row error handler, it returns true if the row was skipped.
+// In a dry run, the errors are recorded in the stats. By default, the row is written to the rejects writer, or
+// the first error is returned if there is no rejects writer.
+func (r *run) failRow(row []string, errs []error, decision Decision) (bool, error) {
+	if r.c.dryRun {
+		r.addErrors(errs...)
+		return false, nil
+	}
+
+	switch decision {
+	case DecisionAbort:
+		return false, errs[0]
+	case DecisionRetry:
+		// the errors in reading or validating the row cannot be retried, the row is processed as it is.
+		return false, nil
+	case DecisionSkip:
+		if r.c.rejectWriter == nil {
+			r.addErrors(errs...)
+			return true, nil
+		}
+	}
+
+	if r.c.rejectWriter == nil {
+		return false, errs[0]
+	}
+
+	if !r.rejectHeaderWritten && r.c.header != nil {
+		r.rejectHeaderWritten = true
+		if err := r.c.rejectWriter.Write(append(append([]string(nil), r.c.header...), RejectReasonColumn)); err != nil {
+			return false, fmt.Errorf("csvprocessor: error while writing to rejects writer: %w", err)
+		}
+	}
+
+	reasons := make([]string, len(errs))
+	for i, err := range errs {
+		reasons[i] = err.Error()
+	}
+
+	if err := r.c.rejectWriter.Write(append(row, strings.Join(reasons, "; "))); err != nil {
+		return false, fmt.Errorf("csvprocessor: error while writing to rejects writer: %w", err)
+	}
+
+	r.stats.RowsRejected++
+	r.c.metrics.rowRejected()
+	return true, nil
+}
+
+// chunkExpired reports whether the current chunk has been open longer than the interval set by WithChunkInterval().
+func (r *run) chunkExpired() bool {
+	return !r.out.singleChunk && !r.sharded() && r.c.chunkInterval > 0 && r.fileWriter != nil && time.Since(r.chunkOpened) >= r.c.chunkInterval
+}
+
+// chunkLimit returns the max. no. of rows of the current chunk.
+func (r *run) chunkLimit() int {
+	if r.c.chunkCount > 0 && r.scan != nil {
+		return r.scan.chunkRows(r.c.chunkCount, r.currentSplit)
+	}
+
+	return r.c.chunkSize
+}
+
+func (r *run) currentChunk() *ChunkInfo {
+	return &r.stats.Chunks[r.chunkIndex]
+}
+
+// inputReader returns the reader from which the rows are processed, and a function to release its resources and close the inputs.
+// If count is not nil, the no. of bytes read from the inputs is added to it.
+func (c *Processor) inputReader(count *int64) (CsvReader, func() error, error) {
+	reader, release, err := c.inputPipeline(count)
+	closeReader := func() error {
+		releaseErr := release()
+		if err := c.closeInputs(); err != nil {
 			return err
 		}
 
-		needNewChunk = (currentRow % c.chunkSize) == 0
+		return releaseErr
 	}
 
-	c.log("%d total rows updated", currentRow)
-	return flushAndCloseFile(fileWriter, outputFile)
+	return reader, closeReader, err
+}
+
+// inputPipeline returns the reader of the rows of the inputs, and a function to release its resources without closing the inputs.
+func (c *Processor) inputPipeline(count *int64) (CsvReader, func() error, error) {
+	reader := c.reader
+	release := func() error { return nil }
+	if reader == nil {
+		readers := make([]CsvReader, len(c.inputs))
+		for i, input := range c.inputs {
+			if count != nil {
+				input = &countingReader{r: input, n: count}
+			}
+
+			var err error
+			readers[i], err = c.parseInput(i, input)
+			if err != nil {
+				return nil, release, err
+			}
+
+			if c.headerRows > 1 && c.inputHasHeader() {
+				// only the header rows of the first input are written, the rest are dropped.
+				onHeader := func([][]string) {}
+				if i == 0 {
+					onHeader = c.setHeaderBlock
+				}
+
+				readers[i] = newHeaderBlockReader(readers[i], c.headerRows, onHeader)
+			}
+
+			if c.footerRows > 0 {
+				readers[i] = newFooterReader(readers[i], c.footerRows, c.inputHasHeader(), c.addFooter)
+			}
+		}
+
+		reader = NewMultiReader(c.inputHasHeader(), readers...)
+	} else {
+		switch custom := reader.(type) {
+		case *MessageReader:
+			custom.comma = c.delimiter()
+		case *JSONLinesReader:
+			custom.setBufferSize(c.inputBufferSize())
+		}
+
+		if c.headerDetection && c.inputHeader == nil {
+			reader = c.detectHeader(reader)
+		}
+
+		if c.headerRows > 1 && c.inputHasHeader() {
+			reader = newHeaderBlockReader(reader, c.headerRows, c.setHeaderBlock)
+		}
+
+		if c.footerRows > 0 {
+			reader = newFooterReader(reader, c.footerRows, c.inputHasHeader(), c.addFooter)
+		}
+	}
+
+	if c.inputHeader != nil {
+		reader = &headerReader{reader: reader, header: c.inputHeader}
+	}
+
+	if c.skipRows > 0 || c.limitRows >= 0 {
+		reader = newRangeReader(reader, c.skipRows, c.limitRows, c.hasHeader())
+	}
+
+	// budget bounds the rows held in memory by the readers below, see WithMaxMemory().
+	budget := c.newMemoryBudget()
+	if c.join != nil {
+		reader = newJoinReader(reader, *c.join, c.hasHeader(), budget)
+	}
+
+	if c.transposeCells > 0 {
+		reader = newTransposeReader(reader, c.transposeCells, budget)
+	}
+
+	if c.tailRows >= 0 {
+		tail := newTailReader(reader, c.tailRows, c.hasHeader())
+		tail.budget = budget
+		reader = tail
+	}
+
+	if c.sampleRate > 0 {
+		reader = newSampleReader(reader, c.sampleRate, rand.New(rand.NewSource(c.runSeed)), c.hasHeader()) //nolint:gosec
+	}
+
+	if c.sampleSize > 0 {
+		reservoir := newReservoirReader(reader, c.sampleSize, rand.New(rand.NewSource(c.runSeed)), c.hasHeader()) //nolint:gosec
+		reservoir.budget = budget
+		reader = reservoir
+	}
+
+	if c.groupBy != nil {
+		aggregator := NewAggregator(reader, c.groupBy, c.hasHeader(), c.aggregations...)
+		aggregator.budget = budget
+		reader = aggregator
+	}
+
+	if c.sortColumns != nil {
+		sorter := NewSorter(reader, c.sortColumns, c.sortOrder, c.sortRunSize, c.hasHeader())
+		sorter.budget = budget
+		reader = sorter
+		release = sorter.Close
+	}
+
+	return reader, release, nil
+}
+
+// parseInput returns the reader of the rows of the i-th input, the dialect and the header are detected from the first input.
+func (c *Processor) parseInput(i int, input io.Reader) (CsvReader, error) {
+	bufferedInput, err := c.bufferInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if i == 0 && c.autoDialect {
+		// the dialect of the first input is used for all the inputs.
+		if err := c.applyDialect(bufferedInput); err != nil {
+			return nil, fmt.Errorf("csvprocessor: error while detecting the dialect: %w", err)
+		}
+	}
+
+	reader := c.newCsvReader(bufferedInput)
+	if i == 0 && c.headerDetection && c.inputHeader == nil {
+		reader = c.detectHeader(reader)
+	}
+
+	return reader, nil
+}
+
+// bufferInput decodes and buffers the input stream, and discards the leading lines.
+func (c *Processor) bufferInput(input io.Reader) (*bufio.Reader, error) {
+	if c.inputEncoding != nil {
+		input = c.inputEncoding.newDecoder(input)
+	}
+
+	bufferedInput := getReadBuffer(input, c.inputBufferSize())
+	c.inputBuffers = append(c.inputBuffers, bufferedInput)
+	skipBOM(bufferedInput)
+	if err := skipLines(bufferedInput, c.skipLines); err != nil {
+		return nil, fmt.Errorf("csvprocessor: error while skipping the leading lines: %w", err)
+	}
+
+	return bufferedInput, nil
+}
+
+// setHeaderBlock sets the header rows after the first header row.
+func (c *Processor) setHeaderBlock(extra [][]string) {
+	c.extraHeaders = extra
+}
+
+// newCsvReader creates the CsvReader used to parse the buffered input streams.
+func (c *Processor) newCsvReader(bufferedInput *bufio.Reader) CsvReader {
+	if len(c.fixedWidthColumns) > 0 {
+		return NewFixedWidthReader(bufferedInput, c.fixedWidthColumns...)
+	}
+
+	if c.useFastParser() {
+		fastReader := NewFastReader(bufferedInput, byte(c.delimiter()))
+		fastReader.ReuseRecord = true
+		if c.fieldCountMode != FieldCountAny {
+			fastReader.FieldsPerRecord = -1
+		}
+
+		return fastReader
+	}
+
+	if c.customQuote() {
+		quotedReader := NewQuotedReader(bufferedInput, c.delimiter(), c.quote(), c.inputEscape)
+		quotedReader.ReuseRecord = true
+		if c.fieldCountMode != FieldCountAny {
+			quotedReader.FieldsPerRecord = -1
+		}
+
+		return quotedReader
+	}
+
+	csvReader := csv.NewReader(bufferedInput)
+	csvReader.Comma = c.delimiter()
+	csvReader.LazyQuotes = true
+	csvReader.ReuseRecord = true
+	if c.fieldCountMode != FieldCountAny {
+		// the no. of fields is checked by the processor.
+		csvReader.FieldsPerRecord = -1
+	}
+
+	return csvReader
+}
+
+// skipLines discards the first n lines of the input, the lines are not parsed as CSV.
+func skipLines(input *bufio.Reader, n int) error {
+	for i := 0; i < n; i++ {
+		for {
+			_, err := input.ReadSlice('\n')
+			if err == nil {
+				break
+			}
+
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			// the rest of a line longer than the buffer is read in the next iteration.
+			if !errors.Is(err, bufio.ErrBufferFull) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// skipBOM discards the UTF-8 byte order mark at the start of the input, if present.
+func skipBOM(input *bufio.Reader) {
+	if prefix, err := input.Peek(len(utf8BOM)); err == nil && string(prefix) == utf8BOM {
+		_, _ = input.Discard(len(utf8BOM))
+	}
+}
+
+// closeInputs closes the input streams opened by the processor.
+func (c *Processor) closeInputs() error {
+	var firstErr error
+	for _, input := range c.inputs {
+		if closer, ok := input.(io.Closer); ok {
+			if err := closer.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	for _, buf := range c.inputBuffers {
+		putReadBuffer(buf)
+	}
+
+	c.inputBuffers = nil
+	return firstErr
 }
 
 func flushAndCloseFile(fileWriter CsvWriter, outputFile io.WriteCloser) error {
+	if closer, ok := fileWriter.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("csprocessor: error while closing the output writer: %w", err)
+		}
+	}
+
 	if fileWriter != nil {
 		if err := flushToFile(fileWriter); err != nil {
 			return fmt.Errorf("csprocessor: error while flushing to output file: %w", err)
@@ -198,29 +1390,57 @@ func flushAndCloseFile(fileWriter CsvWriter, outputFile io.WriteCloser) error {
 	return nil
 }
 
-func (c *Processor) writeHeaders(row []string, ctx *csvCtx, fileWriter CsvWriter) error {
-	if c.header == nil {
-		c.header = row
+func (c *Processor) getCsvWriter(buffer *bufio.Writer) CsvWriter {
+	if c.writerFactory != nil {
+		return c.writerFactory(buffer)
 	}
 
-	ctx.setValue(CtxIsHeader, true)
-	ctx.setValue(CtxRowNum, -1)
-	return fileWriter.Write(c.rowTransformer(ctx, c.header))
-}
+	if c.quoteMode != QuoteMinimal || c.outputQuote != '"' || c.outputEscape != 0 {
+		return newDelimitedWriter(buffer, c.outputDelimiter, c.outputQuote, c.outputEscape, c.quoteMode, c.useCRLF)
+	}
 
-func (c *Processor) getCsvWriter(outputFile io.WriteCloser) CsvWriter {
-	return csv.NewWriter(bufio.NewWriterSize(outputFile, c.WriteBufferSize))
+	csvWriter := csv.NewWriter(buffer)
+	csvWriter.Comma = c.outputDelimiter
+	csvWriter.UseCRLF = c.useCRLF
+
+	return csvWriter
 }
 
-func splitFileGenerator(outputFileFormat string) func(int) (io.WriteCloser, error) {
+func (c *Processor) splitFileGenerator(outputFileFormat string) func(int) (io.WriteCloser, error) {
 	return func(split int) (io.WriteCloser, error) {
-		filename := fmt.Sprintf(outputFileFormat, split)
-		filename = strings.Split(filename, "%!")[0]
+		var arg any
+		switch {
+		case c.timeChunks != nil:
+			arg = c.chunkWindow
+		case c.totalChunks > 0:
+			arg = c.totalChunks
+		}
+
+		filename := chunkNameOf(outputFileFormat, split, arg)
 
-		return os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, permission) //nolint:nosnakecase
+		return c.createOutputFile(filename, true)
 	}
 }
 
+// ChunkName returns the name of the chunk with the given ID, the format can contain one verb for the chunk ID.
+// Eg: ChunkName("output_%03d.csv", 7) returns "output_007.csv".
+func ChunkName(format string, chunkID int) string {
+	name := fmt.Sprintf(format, chunkID)
+	return strings.Split(name, "%!")[0]
+}
+
+// chunkNameOf returns the name of the chunk like ChunkName(), the format can contain a second verb for arg if it is not nil,
+// Eg: the total no. of chunks (see WithPreScan()) or the time window of the chunk (see WithChunkByTimeColumn()).
+// Eg: chunkNameOf("orders_%d_of_%d.csv", 2, 5) returns "orders_2_of_5.csv".
+func chunkNameOf(format string, chunkID int, arg any) string {
+	if arg == nil {
+		return ChunkName(format, chunkID)
+	}
+
+	name := fmt.Sprintf(format, chunkID, arg)
+	return strings.Split(name, "%!")[0]
+}
+
 func flushToFile(w CsvWriter) error {
 	w.Flush()
 	return w.Error()
@@ -4,8 +4,13 @@
 package csvprocessor_test
 
 import (
+	"context"
 	"encoding/csv"
+	"errors"
 	"io"
+	"math"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -143,6 +148,24 @@ func TestProcessor_Process(t *testing.T) {
 				elementLength: (6 * 2) + (2 + 1) + (1 + 1),
 			},
 		},
+		{
+			name: "Test With Headers - verySmallCSV - with filtering transformer",
+			args: args{
+				reader:         strings.NewReader(verySmallCSV + verySmallCSV),
+				expectedChunks: 2,
+				opt: []csvprocessor.Option{
+					csvprocessor.WithLogger(t.Logf),
+					csvprocessor.WithChunkSize(2),
+					csvprocessor.WithTransformer(csvprocessor.DedupTransformer()),
+				},
+			},
+			expect: expect{
+				wantErr:      false,
+				bufferLength: 2,
+				// header + 2 rows in each chunk, the last chunk has a duplicate header row as data
+				elementLength: 6 * 3,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -275,4 +298,195 @@ func newProcessor(tb testing.TB, reader io.Reader, bytesArr []strings.Builder, o
 	return c
 }
 
+// processString processes the input CSV with the given options and returns the content of all the output chunks.
+func processString(tb testing.TB, input string, opt ...csvprocessor.Option) (string, error) {
+	tb.Helper()
+
+	var output strings.Builder
+	bufferOpt := []csvprocessor.Option{
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader(input))),
+		csvprocessor.WithWriterGenerator(func(i int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(&output), nil
+		}),
+		csvprocessor.WithChunkSize(math.MaxInt32),
+		csvprocessor.WithLogger(tb.Logf),
+	}
+
+	proc, err := csvprocessor.New(append(bufferOpt, opt...)...)
+	if err != nil {
+		return "", err
+	}
+
+	err = proc.Process()
+	return output.String(), err
+}
+
+// processFile writes the input CSV to a temp file, processes it with the given options and returns the content of all the output chunks.
+func processFile(tb testing.TB, input string, opt ...csvprocessor.Option) (string, error) {
+	tb.Helper()
+
+	inputFile := filepath.Join(tb.TempDir(), "input.csv")
+	if err := os.WriteFile(inputFile, []byte(input), 0o600); err != nil {
+		tb.Fatalf("unable to create input file: %v", err)
+	}
+
+	var output strings.Builder
+	fileOpt := []csvprocessor.Option{
+		csvprocessor.WithFileReader(inputFile),
+		csvprocessor.WithWriterGenerator(func(i int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(&output), nil
+		}),
+		csvprocessor.WithChunkSize(math.MaxInt32),
+		csvprocessor.WithLogger(tb.Logf),
+	}
+
+	proc, err := csvprocessor.New(append(fileOpt, opt...)...)
+	if err != nil {
+		return "", err
+	}
+
+	err = proc.Process()
+	return output.String(), err
+}
+
 type any = interface{} //nolint:predeclared
+
+func TestProcessor_WithRejectWriter(t *testing.T) {
+	panicOnX := func(ctx context.Context, row []string) []string {
+		if row[0] == "x" {
+			panic("unexpected value x")
+		}
+
+		if row[0] == "y" {
+			panic(errors.New("unexpected value y"))
+		}
+
+		row[0] = strings.ToUpper(row[0])
+		return row
+	}
+
+	schema := csvprocessor.Schema{Columns: []csvprocessor.ColumnSchema{{Name: "id", Required: true}}}
+	tests := []struct {
+		name        string
+		input       string
+		opt         []csvprocessor.Option
+		wantOutput  string
+		wantRejects string
+		wantErr     bool
+	}{
+		{
+			name:        "Test rejects with validation and transformation failures",
+			input:       "id,val\na,1\n,2\nx,3\ny,4\nb,5\n",
+			opt:         []csvprocessor.Option{csvprocessor.WithSchemaValidation(schema)},
+			wantOutput:  "ID,val\nA,1\nB,5\n",
+			wantRejects: "id,val,reject_reason\n,2,\"csvprocessor: row 2, column 0 (id): value is required\"\nx,3,csvprocessor: row 3: csvprocessor: transformer panicked: unexpected value x\ny,4,csvprocessor: row 4: unexpected value y\n",
+		},
+		{
+			name:        "Test rejects without header",
+			input:       "a,1\nx,3\n",
+			opt:         []csvprocessor.Option{csvprocessor.SkipHeaders(true)},
+			wantOutput:  "A,1\n",
+			wantRejects: "x,3,csvprocessor: row 2: csvprocessor: transformer panicked: unexpected value x\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rejects strings.Builder
+			rejectWriter := csv.NewWriter(&rejects)
+			opt := append([]csvprocessor.Option{
+				csvprocessor.WithTransformer(panicOnX),
+				csvprocessor.WithRejectWriter(rejectWriter),
+			}, tt.opt...)
+
+			output, err := processString(t, tt.input, opt...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if output != tt.wantOutput {
+				t.Errorf("Process() output = %q, want %q", output, tt.wantOutput)
+			}
+
+			if rejects.String() != tt.wantRejects {
+				t.Errorf("Process() rejects = %q, want %q", rejects.String(), tt.wantRejects)
+			}
+		})
+	}
+}
+
+func TestProcessor_TransformerPanic(t *testing.T) {
+	panicking := func(ctx context.Context, row []string) []string {
+		panic("test")
+	}
+
+	_, err := processString(t, verySmallCSV, csvprocessor.WithTransformer(panicking))
+	if !errors.Is(err, csvprocessor.ErrTransformerPanic) {
+		t.Errorf("Process() error = %v, want %v", err, csvprocessor.ErrTransformerPanic)
+	}
+}
+
+func TestWithKeepKeyGroups(t *testing.T) {
+	input := "order,item\n1,a\n1,b\n2,c\n2,d\n2,e\n3,f\n4,g\n4,h\n"
+	tests := []struct {
+		name    string
+		opt     []csvprocessor.Option
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "Test groups exceed the chunk size",
+			opt:  []csvprocessor.Option{csvprocessor.WithChunkSize(2), csvprocessor.WithKeepKeyGroups(0)},
+			want: []string{"order,item\n1,a\n1,b\n", "order,item\n2,c\n2,d\n2,e\n", "order,item\n3,f\n4,g\n4,h\n"},
+		},
+		{
+			name: "Test groups smaller than the chunk size",
+			opt:  []csvprocessor.Option{csvprocessor.WithChunkSize(3), csvprocessor.WithKeepKeyGroups(0)},
+			want: []string{"order,item\n1,a\n1,b\n2,c\n2,d\n2,e\n", "order,item\n3,f\n4,g\n4,h\n"},
+		},
+		{
+			name: "Test multiple key columns",
+			opt:  []csvprocessor.Option{csvprocessor.WithChunkSize(1), csvprocessor.WithKeepKeyGroups(0, 1)},
+			want: []string{"order,item\n1,a\n", "order,item\n1,b\n", "order,item\n2,c\n", "order,item\n2,d\n", "order,item\n2,e\n"},
+		},
+		{
+			name:    "Test no key columns",
+			opt:     []csvprocessor.Option{csvprocessor.WithKeepKeyGroups()},
+			wantErr: csvprocessor.ErrInvalidGroupColumns,
+		},
+		{
+			name:    "Test negative key column",
+			opt:     []csvprocessor.Option{csvprocessor.WithKeepKeyGroups(-1)},
+			wantErr: csvprocessor.ErrInvalidGroupColumns,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := make([]strings.Builder, 8)
+			opts := append([]csvprocessor.Option{
+				csvprocessor.WithReader(csv.NewReader(strings.NewReader(input))),
+				csvprocessor.WithWriterGenerator(func(i int) (io.WriteCloser, error) {
+					return csvprocessor.NoOpCloser(&chunks[i-1]), nil
+				}),
+			}, tt.opt...)
+
+			proc, err := csvprocessor.New(opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("New() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if err := proc.Process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			for i := range tt.want {
+				if got := chunks[i].String(); got != tt.want[i] {
+					t.Errorf("chunk %d = %q, want %q", i+1, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
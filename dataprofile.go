@@ -0,0 +1,403 @@
+package csvprocessor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTopValues is returned when the no. of top values of the data profile is negative.
+var ErrInvalidTopValues = errors.New("csvprocessor: no. of top values must be >= 0")
+
+// DefaultTopValues is the no. of most frequent values of each column reported by WithDataProfile().
+const DefaultTopValues = 5
+
+// DataProfile is the profiling report of the output, see WithDataProfile().
+type DataProfile struct {
+	// Rows is the no. of rows profiled, the header rows are not counted.
+	Rows    int             `json:"rows"`
+	Columns []ColumnProfile `json:"columns"`
+}
+
+// ColumnProfile is the profile of a column, in addition to its ColumnStats.
+type ColumnProfile struct {
+	ColumnStats
+	// Type is the type inferred from the non-empty, non-null values, and Layout is the time layout for TypeTime.
+	// A type is inferred if at least 95% of the values are of the type, the other values are counted in Mismatches.
+	Type   ColumnType `json:"type"`
+	Layout string     `json:"layout,omitempty"`
+	// Mismatches is the no. of values that are not of the inferred Type.
+	Mismatches int `json:"mismatches"`
+	// StdDev, P25, Median and P75 describe the distribution of the numeric values.
+	// The percentiles are computed from a random sample of 1024 values, so they are exact only for smaller columns.
+	StdDev float64 `json:"std_dev"`
+	P25    float64 `json:"p25"`
+	Median float64 `json:"median"`
+	P75    float64 `json:"p75"`
+	// TopValues are the most frequent non-empty, non-null values in the descending order of their count.
+	// The counts are exact if the column has at most 1000 distinct values, else they are lower bounds.
+	TopValues []ValueCount `json:"top_values"`
+	// Anomalies describe the possible data quality issues in the column, Eg: "60% of the values are empty or null".
+	Anomalies []string `json:"anomalies"`
+}
+
+// ValueCount is a value and the no. of times it occurs in a column.
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// WithDataProfile profiles the rows written to the output, the report is available in Stats.DataProfile.
+// The profile has the ColumnStats of each column (see WithColumnStats()), the inferred type of the values,
+// the distribution of the numeric values, the topValues most frequent values (see DefaultTopValues) and the possible anomalies.
+// Use it with WithDryRun(true) to only profile the input, Eg:
+//
+//	proc, _ := csvprocessor.New(csvprocessor.WithFileReader("orders.csv"), csvprocessor.WithDryRun(true), csvprocessor.WithDataProfile(5))
+//	_ = proc.Process()
+//	_ = proc.Stats().DataProfile.WriteText(os.Stdout)
+//
+// The profile is computed in the same pass, using a fixed amount of memory per column.
+func WithDataProfile(topValues int) Option {
+	return func(c *Processor) error {
+		if topValues < 0 {
+			return ErrInvalidTopValues
+		}
+
+		c.dataProfile = true
+		c.profileTopValues = topValues
+		return nil
+	}
+}
+
+// WriteText writes the profile as a human-readable report, Eg:
+//
+//	rows: 3
+//
+//	column 0: amount (float)
+//	  values: 3, empty: 0, nulls: 0, distinct: ~3, max length: 3
+//	  min: 1.5, max: 10, mean: 5.5, std dev: 4.272, p25: 5, median: 5, p75: 10
+//	  top values: 1.5 (1), 10 (1), 5 (1)
+func (p *DataProfile) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "rows: %d\n", p.Rows)
+	for i := range p.Columns {
+		column := &p.Columns[i]
+		fmt.Fprintf(&b, "\ncolumn %d: %s (%s", column.Index, column.Name, column.Type)
+		if column.Layout != "" {
+			fmt.Fprintf(&b, " %s", column.Layout)
+		}
+
+		fmt.Fprintf(&b, ")\n  values: %d, empty: %d, nulls: %d, distinct: ~%d, max length: %d\n",
+			column.Count, column.Empty, column.Nulls, column.Distinct, column.MaxLength)
+		if column.Numeric > 0 {
+			fmt.Fprintf(&b, "  min: %s, max: %s, mean: %s, std dev: %s, p25: %s, median: %s, p75: %s\n",
+				formatNumber(column.MinNumber), formatNumber(column.MaxNumber), formatNumber(column.Mean),
+				formatNumber(column.StdDev), formatNumber(column.P25), formatNumber(column.Median), formatNumber(column.P75))
+		} else if column.MinValue != "" {
+			fmt.Fprintf(&b, "  min: %q, max: %q\n", column.MinValue, column.MaxValue)
+		}
+
+		if len(column.TopValues) > 0 {
+			values := make([]string, 0, len(column.TopValues))
+			for _, value := range column.TopValues {
+				values = append(values, fmt.Sprintf("%s (%d)", value.Value, value.Count))
+			}
+
+			fmt.Fprintf(&b, "  top values: %s\n", strings.Join(values, ", "))
+		}
+
+		if len(column.Anomalies) > 0 {
+			fmt.Fprintf(&b, "  anomalies:\n")
+			for _, anomaly := range column.Anomalies {
+				fmt.Fprintf(&b, "    - %s\n", anomaly)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON writes the profile as indented JSON.
+func (p *DataProfile) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(p)
+}
+
+// formatNumber formats the number with at most 4 decimal places, Eg: 4.2720.
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(math.Round(number*1e4)/1e4, 'f', -1, 64)
+}
+
+// profile thresholds.
+const (
+	typeThreshold     = 0.95 // min. fraction of the values of a type to infer the type.
+	emptyThreshold    = 0.5  // min. fraction of empty or null values reported as an anomaly.
+	outlierDeviations = 3    // no. of standard deviations from the mean beyond which a number is an outlier.
+	quantileSamples   = 1024 // no. of numeric values sampled for the percentiles.
+	trackedValues     = 1000 // min. no. of distinct values counted for the top values.
+)
+
+// time layouts tried while inferring TypeTime, in the order of preference.
+var profileTimeLayouts = []string{time.RFC3339, "2006-01-02", "2006-01-02 15:04:05", "2006-01-02T15:04:05"}
+
+// valueProfile is the running profile of the non-empty, non-null values of a column.
+type valueProfile struct {
+	values     int
+	ints       int
+	floats     int
+	bools      int
+	times      []int // no. of values matching each of profileTimeLayouts.
+	padded     int   // no. of values with leading or trailing spaces.
+	mismatches map[ColumnType]string
+
+	// running mean and variance of the numeric values, see Welford's online algorithm.
+	numbers int
+	mean    float64
+	m2      float64
+	samples []float64
+	random  *rand.Rand
+
+	// counts of the frequent values, see the Misra-Gries heavy hitters algorithm.
+	topValues int
+	capacity  int
+	counts    map[string]int
+}
+
+func newValueProfile(topValues int) *valueProfile {
+	capacity := 10 * topValues
+	if capacity < trackedValues {
+		capacity = trackedValues
+	}
+
+	return &valueProfile{
+		times:      make([]int, len(profileTimeLayouts)),
+		mismatches: make(map[ColumnType]string),
+		random:     rand.New(rand.NewSource(1)), //nolint:gosec
+		topValues:  topValues,
+		capacity:   capacity,
+		counts:     make(map[string]int),
+	}
+}
+
+// add adds a non-empty, non-null value, trimmed is the value without the surrounding spaces.
+func (p *valueProfile) add(value, trimmed string, number float64, isNumber bool) {
+	if p == nil {
+		return
+	}
+
+	p.values++
+	if trimmed != value {
+		p.padded++
+	}
+
+	if _, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+		p.ints++
+	} else {
+		p.mismatch(TypeInt, value)
+	}
+
+	if isNumber {
+		p.floats++
+		p.addNumber(number)
+	} else {
+		p.mismatch(TypeFloat, value)
+	}
+
+	if _, err := strconv.ParseBool(trimmed); err == nil {
+		p.bools++
+	} else {
+		p.mismatch(TypeBool, value)
+	}
+
+	matched := false
+	if len(trimmed) >= len("2006-01-02") && trimmed[4] == '-' {
+		// the layouts start with the date, so the other values are not parsed.
+		for i, layout := range profileTimeLayouts {
+			if _, err := time.Parse(layout, trimmed); err == nil {
+				p.times[i]++
+				matched = true
+			}
+		}
+	}
+
+	if !matched {
+		p.mismatch(TypeTime, value)
+	}
+
+	p.count(value)
+}
+
+// mismatch records the first value that is not of the type.
+func (p *valueProfile) mismatch(columnType ColumnType, value string) {
+	if _, ok := p.mismatches[columnType]; !ok {
+		p.mismatches[columnType] = value
+	}
+}
+
+func (p *valueProfile) addNumber(number float64) {
+	p.numbers++
+	delta := number - p.mean
+	p.mean += delta / float64(p.numbers)
+	p.m2 += delta * (number - p.mean)
+
+	if len(p.samples) < quantileSamples {
+		p.samples = append(p.samples, number)
+	} else if replace := p.random.Intn(p.numbers); replace < quantileSamples {
+		p.samples[replace] = number
+	}
+}
+
+// count counts the value, when all the counters are in use, every counter is decremented to make room for the value.
+func (p *valueProfile) count(value string) {
+	if p.topValues == 0 {
+		return
+	}
+
+	if _, ok := p.counts[value]; ok || len(p.counts) < p.capacity {
+		p.counts[value]++
+		return
+	}
+
+	for tracked, count := range p.counts {
+		if count == 1 {
+			delete(p.counts, tracked)
+		} else {
+			p.counts[tracked] = count - 1
+		}
+	}
+}
+
+// inferType returns the most specific type of at least the threshold fraction of the values, its time layout,
+// and the no. of values that are not of the type.
+func (p *valueProfile) inferType(threshold float64) (ColumnType, string, int) {
+	if p.values == 0 {
+		return TypeString, "", 0
+	}
+
+	layout, times := "", 0
+	for i, count := range p.times {
+		if count > times {
+			layout, times = profileTimeLayouts[i], count
+		}
+	}
+
+	required := int(math.Ceil(threshold * float64(p.values)))
+	for _, candidate := range []struct {
+		columnType ColumnType
+		count      int
+	}{{TypeInt, p.ints}, {TypeFloat, p.floats}, {TypeBool, p.bools}, {TypeTime, times}} {
+		if candidate.count >= required {
+			if candidate.columnType != TypeTime {
+				layout = ""
+			}
+
+			return candidate.columnType, layout, p.values - candidate.count
+		}
+	}
+
+	return TypeString, "", 0
+}
+
+// top returns the most frequent values.
+func (p *valueProfile) top() []ValueCount {
+	values := make([]ValueCount, 0, len(p.counts))
+	for value, count := range p.counts {
+		values = append(values, ValueCount{Value: value, Count: count})
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+
+		return values[i].Value < values[j].Value
+	})
+
+	if len(values) > p.topValues {
+		values = values[:p.topValues]
+	}
+
+	return values
+}
+
+// quantile returns the value at the fraction q of the sorted samples.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	return sorted[int(math.Round(q*float64(len(sorted)-1)))]
+}
+
+// dataProfile returns the profile of the columns, or nil if WithDataProfile() is not used.
+func (cc *columnCollector) dataProfile() *DataProfile {
+	if cc == nil || !cc.profile {
+		return nil
+	}
+
+	profile := &DataProfile{Rows: cc.rows, Columns: make([]ColumnProfile, 0, len(cc.columns))}
+	for i, column := range cc.columns {
+		p := column.profile
+		columnProfile := ColumnProfile{ColumnStats: column.result(valueAt(cc.names, i))}
+		columnProfile.Type, columnProfile.Layout, columnProfile.Mismatches = p.inferType(typeThreshold)
+		if p.numbers > 1 {
+			columnProfile.StdDev = math.Sqrt(p.m2 / float64(p.numbers-1))
+		}
+
+		sorted := append([]float64(nil), p.samples...)
+		sort.Float64s(sorted)
+		columnProfile.P25, columnProfile.Median, columnProfile.P75 = quantile(sorted, 0.25), quantile(sorted, 0.5), quantile(sorted, 0.75)
+		columnProfile.TopValues = p.top()
+		columnProfile.Anomalies = p.anomalies(&columnProfile, cc.rows)
+		profile.Columns = append(profile.Columns, columnProfile)
+	}
+
+	return profile
+}
+
+// anomalies returns the possible data quality issues in the column.
+func (p *valueProfile) anomalies(column *ColumnProfile, rows int) []string {
+	var anomalies []string
+	if rows == 0 {
+		return anomalies
+	}
+
+	missing := column.Empty + column.Nulls
+	switch {
+	case missing == rows:
+		anomalies = append(anomalies, "all the values are empty or null")
+	case float64(missing) >= emptyThreshold*float64(rows):
+		anomalies = append(anomalies, fmt.Sprintf("%d%% of the values are empty or null", missing*100/rows))
+	}
+
+	if column.Mismatches > 0 {
+		anomalies = append(anomalies, fmt.Sprintf("%d values are not %s, Eg: %q", column.Mismatches, column.Type, p.mismatches[column.Type]))
+	}
+
+	if p.values > 1 && column.MinValue == column.MaxValue {
+		anomalies = append(anomalies, fmt.Sprintf("all the values are %q", column.MinValue))
+	}
+
+	if p.padded > 0 {
+		anomalies = append(anomalies, fmt.Sprintf("%d values have leading or trailing spaces", p.padded))
+	}
+
+	if (column.Type == TypeInt || column.Type == TypeFloat) && column.StdDev > 0 {
+		limit := outlierDeviations * column.StdDev
+		if column.MinNumber < column.Mean-limit || column.MaxNumber > column.Mean+limit {
+			anomalies = append(anomalies, fmt.Sprintf("min %s or max %s is more than %d standard deviations from the mean %s",
+				formatNumber(column.MinNumber), formatNumber(column.MaxNumber), outlierDeviations, formatNumber(column.Mean)))
+		}
+	}
+
+	return anomalies
+}
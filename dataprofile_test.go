@@ -0,0 +1,219 @@
+package csvprocessor_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// dataProfile processes the input with the given options and returns the data profile.
+func dataProfile(t *testing.T, input string, opts ...csvprocessor.Option) *csvprocessor.DataProfile {
+	t.Helper()
+
+	proc, err := csvprocessor.New(append([]csvprocessor.Option{
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader(input))),
+		csvprocessor.WithDryRun(true),
+		csvprocessor.WithChunkSize(math.MaxInt32),
+		csvprocessor.WithLogger(t.Logf),
+	}, opts...)...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	return proc.Stats().DataProfile
+}
+
+func TestWithDataProfile(t *testing.T) {
+	input := "id,amount,active,created,city,note\n" +
+		"1,1.5,true,2024-01-02,LA,\n" +
+		"2,5,false,2024-01-03, NY,\n" +
+		"3,10,true,2024-01-04,LA,\n" +
+		"4,x,true,2024-01-05,LA,n/a\n"
+
+	profile := dataProfile(t, input, csvprocessor.WithDataProfile(2))
+	if profile.Rows != 4 || len(profile.Columns) != 6 {
+		t.Fatalf("DataProfile = %+v, want 4 rows and 6 columns", profile)
+	}
+
+	tests := []struct {
+		column     int
+		wantType   csvprocessor.ColumnType
+		wantLayout string
+		wantTop    []csvprocessor.ValueCount
+		wantAnoms  []string
+	}{
+		{
+			column:   0,
+			wantType: csvprocessor.TypeInt,
+			wantTop:  []csvprocessor.ValueCount{{Value: "1", Count: 1}, {Value: "2", Count: 1}},
+		},
+		{
+			column:   1,
+			wantType: csvprocessor.TypeString,
+			wantTop:  []csvprocessor.ValueCount{{Value: "1.5", Count: 1}, {Value: "10", Count: 1}},
+		},
+		{
+			column:   2,
+			wantType: csvprocessor.TypeBool,
+			wantTop:  []csvprocessor.ValueCount{{Value: "true", Count: 3}, {Value: "false", Count: 1}},
+		},
+		{
+			column:     3,
+			wantType:   csvprocessor.TypeTime,
+			wantLayout: "2006-01-02",
+			wantTop:    []csvprocessor.ValueCount{{Value: "2024-01-02", Count: 1}, {Value: "2024-01-03", Count: 1}},
+		},
+		{
+			column:    4,
+			wantType:  csvprocessor.TypeString,
+			wantTop:   []csvprocessor.ValueCount{{Value: "LA", Count: 3}, {Value: " NY", Count: 1}},
+			wantAnoms: []string{"1 values have leading or trailing spaces"},
+		},
+		{
+			column:    5,
+			wantType:  csvprocessor.TypeString,
+			wantTop:   []csvprocessor.ValueCount{{Value: "n/a", Count: 1}},
+			wantAnoms: []string{"75% of the values are empty or null"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(profile.Columns[tt.column].Name, func(t *testing.T) {
+			column := profile.Columns[tt.column]
+			if column.Type != tt.wantType || column.Layout != tt.wantLayout {
+				t.Errorf("Type = %v %q, want %v %q", column.Type, column.Layout, tt.wantType, tt.wantLayout)
+			}
+
+			if !reflect.DeepEqual(column.TopValues, tt.wantTop) {
+				t.Errorf("TopValues = %v, want %v", column.TopValues, tt.wantTop)
+			}
+
+			if !reflect.DeepEqual(column.Anomalies, tt.wantAnoms) {
+				t.Errorf("Anomalies = %q, want %q", column.Anomalies, tt.wantAnoms)
+			}
+		})
+	}
+
+	amount := profile.Columns[1]
+	if amount.Numeric != 3 || amount.Mean != 5.5 || amount.Median != 5 || amount.MinNumber != 1.5 || amount.MaxNumber != 10 {
+		t.Errorf("amount = %+v, want 3 numeric values with mean 5.5 and median 5 between 1.5 and 10", amount)
+	}
+}
+
+func TestWithDataProfile_Anomalies(t *testing.T) {
+	var input strings.Builder
+	input.WriteString("id,status,amount,blank\n")
+	for i := 1; i <= 100; i++ {
+		amount := strconv.Itoa(i % 10)
+		status := "ok"
+		if i == 50 {
+			amount = "100000"
+		}
+
+		id := strconv.Itoa(i)
+		if i == 7 {
+			id = "seven"
+		}
+
+		input.WriteString(id + "," + status + "," + amount + ",\n")
+	}
+
+	profile := dataProfile(t, input.String(), csvprocessor.WithDataProfile(csvprocessor.DefaultTopValues))
+	want := map[string][]string{
+		"id":     {`1 values are not int, Eg: "seven"`},
+		"status": {`all the values are "ok"`},
+		"amount": {"min 0 or max 100000 is more than 3 standard deviations from the mean 1004.5"},
+		"blank":  {"all the values are empty or null"},
+	}
+	for _, column := range profile.Columns {
+		if !reflect.DeepEqual(column.Anomalies, want[column.Name]) {
+			t.Errorf("%s.Anomalies = %q, want %q", column.Name, column.Anomalies, want[column.Name])
+		}
+	}
+
+	if id := profile.Columns[0]; id.Type != csvprocessor.TypeInt || id.Mismatches != 1 {
+		t.Errorf("id.Type = %v with %d mismatches, want int with 1 mismatch", id.Type, id.Mismatches)
+	}
+}
+
+func TestWithDataProfile_TopValues(t *testing.T) {
+	// the frequent values are found even if there are more distinct values than the counters.
+	var input strings.Builder
+	input.WriteString("value\n")
+	for i := 0; i < 5000; i++ {
+		input.WriteString("a\nb\nc-" + strconv.Itoa(i) + "\n")
+	}
+
+	profile := dataProfile(t, input.String(), csvprocessor.WithDataProfile(2))
+	top := profile.Columns[0].TopValues
+	if len(top) != 2 || top[0].Value != "a" || top[1].Value != "b" || top[0].Count > 5000 || top[0].Count < 4000 {
+		t.Errorf("TopValues = %v, want a and b with about 5000 each", top)
+	}
+}
+
+func TestWithDataProfile_Report(t *testing.T) {
+	profile := dataProfile(t, "amount,name\n1.5,alice\n5,bob\n10,\n", csvprocessor.WithDataProfile(3))
+
+	var text strings.Builder
+	if err := profile.WriteText(&text); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+
+	want := `rows: 3
+
+column 0: amount (float)
+  values: 3, empty: 0, nulls: 0, distinct: ~3, max length: 3
+  min: 1.5, max: 10, mean: 5.5, std dev: 4.272, p25: 5, median: 5, p75: 10
+  top values: 1.5 (1), 10 (1), 5 (1)
+
+column 1: name (string)
+  values: 3, empty: 1, nulls: 0, distinct: ~2, max length: 5
+  min: "alice", max: "bob"
+  top values: alice (1), bob (1)
+`
+	if text.String() != want {
+		t.Errorf("WriteText() = %s, want %s", text.String(), want)
+	}
+
+	var output strings.Builder
+	if err := profile.WriteJSON(&output); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	var decoded csvprocessor.DataProfile
+	if err := json.Unmarshal([]byte(output.String()), &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(&decoded, profile) {
+		t.Errorf("WriteJSON() = %s, want %+v", output.String(), profile)
+	}
+
+	if !strings.Contains(output.String(), `"type": "float"`) {
+		t.Errorf("WriteJSON() = %s, want the type as a string", output.String())
+	}
+}
+
+func TestWithDataProfile_Invalid(t *testing.T) {
+	_, err := csvprocessor.New(
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader(""))),
+		csvprocessor.WithWriterGenerator(func(i int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(io.Discard), nil
+		}),
+		csvprocessor.WithDataProfile(-1),
+	)
+	if !errors.Is(err, csvprocessor.ErrInvalidTopValues) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidTopValues)
+	}
+}
@@ -0,0 +1,287 @@
+package csvprocessor
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DBWriter is a CsvWriter that inserts the rows into a database table, with the header as the column names.
+// The rows of a chunk are inserted in a single transaction using prepared multi-row INSERT statements of batchSize rows,
+// the transaction is committed when the writer is closed and rolled back if any insert fails.
+// The values are inserted as strings, the database converts them to the column types.
+type DBWriter struct {
+	db        *sql.DB
+	table     string
+	batchSize int
+
+	// Dialect is the syntax used to quote the names and the placeholders of the statements, the default is SQLStandard
+	// with ? placeholders. Eg: SQLPostgres for $1, SQLServer for @p1 and SQLOracle for :1. It must be set before the first row is written.
+	Dialect SQLDialect
+
+	// Unexported fields
+	header []string // column names, nil if there is no header.
+	tx     *sql.Tx
+	stmt   *sql.Stmt // statement for a full batch.
+	args   []any     // values of the pending rows.
+	rows   int       // no. of pending rows.
+	width  int       // no. of values in a row.
+	err    error
+}
+
+// NewDBWriter creates a DBWriter that inserts into the given table, with at most batchSize rows per INSERT statement.
+// If batchSize is <= 0, DefaultSQLBatchSize is used. Set the Dialect for the placeholders of the database.
+func NewDBWriter(db *sql.DB, table string, batchSize int) *DBWriter {
+	if batchSize <= 0 {
+		batchSize = DefaultSQLBatchSize
+	}
+
+	return &DBWriter{db: db, table: table, batchSize: batchSize}
+}
+
+// WriteHeader sets the column names of the INSERT statements.
+// If WriteHeader is not called, the column names are not included in the statements.
+func (d *DBWriter) WriteHeader(header []string) error {
+	d.header = append([]string{}, header...)
+	d.width = len(header)
+	return nil
+}
+
+// Write adds the row to the current batch, the batch is inserted once it is full.
+// All the rows must have the same no. of fields as the header (or the first row, if there is no header).
+func (d *DBWriter) Write(record []string) error {
+	if d.err != nil {
+		return d.err
+	}
+
+	if d.width == 0 {
+		d.width = len(record)
+	}
+
+	if len(record) != d.width {
+		d.fail(fmt.Errorf("%w: expected %d, got %d", ErrFieldCount, d.width, len(record)))
+		return d.err
+	}
+
+	for _, value := range record {
+		d.args = append(d.args, value)
+	}
+
+	d.rows++
+	if d.rows >= d.batchSize {
+		d.Flush()
+	}
+
+	return d.err
+}
+
+// Flush inserts the pending rows.
+func (d *DBWriter) Flush() {
+	if d.err != nil || d.rows == 0 {
+		return
+	}
+
+	if d.tx == nil {
+		if d.tx, d.err = d.db.Begin(); d.err != nil {
+			return
+		}
+	}
+
+	stmt := d.stmt
+	if d.rows < d.batchSize || stmt == nil {
+		var err error
+		if stmt, err = d.tx.Prepare(d.insertQuery(d.rows)); err != nil {
+			d.fail(err)
+			return
+		}
+
+		if d.rows == d.batchSize {
+			// the statement for a full batch is reused.
+			d.stmt = stmt
+		} else {
+			defer stmt.Close()
+		}
+	}
+
+	if _, err := stmt.Exec(d.args...); err != nil {
+		d.fail(err)
+		return
+	}
+
+	d.args, d.rows = d.args[:0], 0
+}
+
+// insertQuery returns the INSERT statement for the given no. of rows.
+func (d *DBWriter) insertQuery(rows int) string {
+	var query strings.Builder
+	query.WriteString("INSERT INTO ")
+	query.WriteString(d.Dialect.quoteTableName(d.table))
+	query.WriteString(d.Dialect.quoteColumns(d.header))
+	query.WriteString(" VALUES ")
+	for row := 0; row < rows; row++ {
+		if row > 0 {
+			query.WriteString(", ")
+		}
+
+		query.WriteByte('(')
+		for column := 0; column < d.width; column++ {
+			if column > 0 {
+				query.WriteString(", ")
+			}
+
+			query.WriteString(d.Dialect.placeholder(row*d.width + column + 1))
+		}
+
+		query.WriteByte(')')
+	}
+
+	return query.String()
+}
+
+// fail records the error, closes the statement and rolls back the transaction.
+func (d *DBWriter) fail(err error) {
+	d.err = err
+	d.closeStmt()
+	if d.tx != nil {
+		_ = d.tx.Rollback()
+		d.tx = nil
+	}
+}
+
+// closeStmt closes the prepared statement for a full batch, if any.
+func (d *DBWriter) closeStmt() {
+	if d.stmt != nil {
+		_ = d.stmt.Close()
+		d.stmt = nil
+	}
+}
+
+// Abort discards the pending rows and rolls back the transaction, the processor calls it when the processing fails.
+func (d *DBWriter) Abort() {
+	d.closeStmt()
+	if d.tx != nil {
+		_ = d.tx.Rollback()
+		d.tx = nil
+	}
+
+	d.args, d.rows = d.args[:0], 0
+}
+
+// Error reports any error that has occurred during a previous Write or Flush.
+func (d *DBWriter) Error() error {
+	return d.err
+}
+
+// Close inserts the pending rows, closes the prepared statement and commits the transaction.
+func (d *DBWriter) Close() error {
+	d.Flush()
+	d.closeStmt()
+
+	if d.err == nil && d.tx != nil {
+		d.err = d.tx.Commit()
+		d.tx = nil
+	}
+
+	return d.err
+}
+
+// WithDBSink inserts the rows into the given database table instead of writing them to files,
+// each chunk is inserted in a separate transaction using batches of batchSize rows. See DBWriter.
+// The statements use ? placeholders, set the dialect of the database using WithSQLDialect(), Eg: SQLPostgres for $1.
+func WithDBSink(db *sql.DB, table string, batchSize int) Option {
+	return func(c *Processor) error {
+		if db == nil {
+			return ErrOutputWriterNil
+		}
+
+		c.outputChunkGenerator = func(int) (io.WriteCloser, error) {
+			return NoOpCloser(io.Discard), nil
+		}
+
+		c.writerFactory = func(io.Writer) CsvWriter {
+			writer := NewDBWriter(db, table, batchSize)
+			writer.Dialect = c.sqlDialect
+			return writer
+		}
+
+		return nil
+	}
+}
+
+// QueryReader is a CsvReader that reads the results of a database query.
+// The first row returned is the header with the column names, the values are formatted like ParquetReader.
+// The rows are closed after the last row is read or if reading fails.
+type QueryReader struct {
+	rows *sql.Rows
+
+	// Unexported fields
+	header     []string
+	headerRead bool
+	values     []any
+	scanArgs   []any
+}
+
+// NewQueryReader runs the query with the given arguments and returns a QueryReader for its results.
+func NewQueryReader(db *sql.DB, query string, args ...any) (*QueryReader, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := NewRowsReader(rows)
+	if err != nil {
+		_ = rows.Close()
+		return nil, err
+	}
+
+	return reader, nil
+}
+
+// NewRowsReader creates a QueryReader for the results of a query that has already been run.
+func NewRowsReader(rows *sql.Rows) (*QueryReader, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	q := &QueryReader{rows: rows, header: columns, values: make([]any, len(columns)), scanArgs: make([]any, len(columns))}
+	for i := range q.values {
+		q.scanArgs[i] = &q.values[i]
+	}
+
+	return q, nil
+}
+
+// Read returns the header first and then the rows of the query results.
+func (q *QueryReader) Read() ([]string, error) {
+	if !q.headerRead {
+		q.headerRead = true
+		return append([]string(nil), q.header...), nil
+	}
+
+	if !q.rows.Next() {
+		if err := q.rows.Err(); err != nil {
+			return nil, err
+		}
+
+		return nil, io.EOF
+	}
+
+	if err := q.rows.Scan(q.scanArgs...); err != nil {
+		_ = q.rows.Close()
+		return nil, err
+	}
+
+	row := make([]string, len(q.values))
+	for i, value := range q.values {
+		row[i] = formatValue(value)
+	}
+
+	return row, nil
+}
+
+// Close closes the rows, it is needed only if the reader is not read till the end.
+func (q *QueryReader) Close() error {
+	return q.rows.Close()
+}
@@ -0,0 +1,243 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+var errFakeExec = errors.New("fake exec error")
+
+// fakeDB is an in-memory database/sql driver that records the statements executed.
+type fakeDB struct {
+	mu  sync.Mutex
+	log []string
+
+	// no. of statements prepared and closed.
+	prepared, closed int
+
+	// results of the queries.
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (f *fakeDB) record(format string, args ...any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.log = append(f.log, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeDB) Open(string) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.prepared++
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.db.record("BEGIN")
+	return &fakeTx{db: c.db}, nil
+}
+
+type fakeTx struct{ db *fakeDB }
+
+func (t *fakeTx) Commit() error {
+	t.db.record("COMMIT")
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.db.record("ROLLBACK")
+	return nil
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	s.db.closed++
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	values := make([]string, len(args))
+	for i, arg := range args {
+		values[i] = fmt.Sprint(arg)
+		if arg == "fail" {
+			return nil, errFakeExec
+		}
+	}
+
+	s.db.record("%s %v", s.query, values)
+	return driver.RowsAffected(len(args)), nil
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	if strings.Contains(s.query, "fail") {
+		return nil, errFakeExec
+	}
+
+	return &fakeRows{columns: s.db.columns, rows: s.db.rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// openFakeDB opens a new fake database.
+func openFakeDB(tb testing.TB) (*sql.DB, *fakeDB) {
+	tb.Helper()
+
+	fake := &fakeDB{}
+	db := sql.OpenDB(fakeConnector{fake})
+	tb.Cleanup(func() { db.Close() })
+
+	return db, fake
+}
+
+type fakeConnector struct{ db *fakeDB }
+
+func (f fakeConnector) Connect(context.Context) (driver.Conn, error) { return f.db.Open("") }
+func (f fakeConnector) Driver() driver.Driver                        { return f.db }
+
+func TestWithDBSink(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opt     []csvprocessor.Option
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "Test batches",
+			input: "id,name\n1,a\n2,b\n3,c\n",
+			want: []string{
+				"BEGIN",
+				`INSERT INTO "users" ("id", "name") VALUES (?, ?), (?, ?) [1 a 2 b]`,
+				`INSERT INTO "users" ("id", "name") VALUES (?, ?) [3 c]`,
+				"COMMIT",
+			},
+		},
+		{
+			name:  "Test transaction per chunk",
+			input: "id,name\n1,a\n2,b\n3,c\n",
+			opt:   []csvprocessor.Option{csvprocessor.WithChunkSize(1)},
+			want: []string{
+				"BEGIN", `INSERT INTO "users" ("id", "name") VALUES (?, ?) [1 a]`, "COMMIT",
+				"BEGIN", `INSERT INTO "users" ("id", "name") VALUES (?, ?) [2 b]`, "COMMIT",
+				"BEGIN", `INSERT INTO "users" ("id", "name") VALUES (?, ?) [3 c]`, "COMMIT",
+			},
+		},
+		{
+			name:  "Test PostgreSQL placeholders",
+			input: "id,name\n1,a\n",
+			opt:   []csvprocessor.Option{csvprocessor.WithSQLDialect(csvprocessor.SQLPostgres)},
+			want:  []string{"BEGIN", `INSERT INTO "users" ("id", "name") VALUES ($1, $2) [1 a]`, "COMMIT"},
+		},
+		{
+			name:  "Test MySQL identifiers",
+			input: "id\n1\n2\n",
+			opt:   []csvprocessor.Option{csvprocessor.WithSQLDialect(csvprocessor.SQLMySQL)},
+			want:  []string{"BEGIN", "INSERT INTO `users` (`id`) VALUES (?), (?) [1 2]", "COMMIT"},
+		},
+		{
+			name:    "Test rollback on error",
+			input:   "id,name\n1,a\n2,b\n3,fail\n",
+			want:    []string{"BEGIN", `INSERT INTO "users" ("id", "name") VALUES (?, ?), (?, ?) [1 a 2 b]`, "ROLLBACK"},
+			wantErr: errFakeExec,
+		},
+		{
+			name:    "Test rollback on field count mismatch",
+			input:   "id,name\n1,a\n2,b\n3\n",
+			want:    []string{"BEGIN", `INSERT INTO "users" ("id", "name") VALUES (?, ?), (?, ?) [1 a 2 b]`, "ROLLBACK"},
+			wantErr: csvprocessor.ErrFieldCount,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := openFakeDB(t)
+			proc, err := csvprocessor.New(append([]csvprocessor.Option{
+				csvprocessor.WithReader(csv.NewReader(strings.NewReader(tt.input))),
+				csvprocessor.WithChunkSize(math.MaxInt32),
+				csvprocessor.WithDBSink(db, "users", 2),
+				csvprocessor.WithLogger(t.Logf),
+			}, tt.opt...)...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if err := proc.Process(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(fake.log, tt.want) {
+				t.Errorf("statements = %q, want %q", fake.log, tt.want)
+			}
+
+			if fake.prepared != fake.closed {
+				t.Errorf("%d statements prepared, %d closed", fake.prepared, fake.closed)
+			}
+		})
+	}
+}
+
+func TestNewQueryReader(t *testing.T) {
+	db, fake := openFakeDB(t)
+	fake.columns = []string{"id", "name", "score", "active", "created_at", "note"}
+	fake.rows = [][]driver.Value{
+		{int64(1), "alice", 9.5, true, time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC), []byte("x,y")},
+		{int64(2), "bob", nil, false, nil, nil},
+	}
+
+	reader, err := csvprocessor.NewQueryReader(db, "SELECT * FROM users WHERE id > ?", 0)
+	if err != nil {
+		t.Fatalf("NewQueryReader() error = %v", err)
+	}
+
+	want := [][]string{
+		{"id", "name", "score", "active", "created_at", "note"},
+		{"1", "alice", "9.5", "true", "2023-01-02T03:04:05Z", "x,y"},
+		{"2", "bob", "", "false", "", ""},
+	}
+	if got := readAll(t, reader); !reflect.DeepEqual(got, want) {
+		t.Errorf("QueryReader rows = %q, want %q", got, want)
+	}
+
+	if _, err := csvprocessor.NewQueryReader(db, "SELECT fail"); !errors.Is(err, errFakeExec) {
+		t.Errorf("NewQueryReader() error = %v, want %v", err, errFakeExec)
+	}
+}
@@ -0,0 +1,323 @@
+package csvprocessor
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DialectSampleSize is the no. of bytes from the start of the input used to detect the dialect.
+const DialectSampleSize = 64 * 1024
+
+// maximum no. of rows used to detect the header.
+const headerSampleRows = 20
+
+// candidate delimiters in the order of preference.
+var dialectDelimiters = []rune{',', ';', '\t', '|', ':'}
+
+// Dialect represents the format of a CSV input.
+type Dialect struct {
+	// Delimiter is the field delimiter.
+	Delimiter rune
+	// Quote is the character used to quote the fields.
+	Quote rune
+	// HasHeader reports whether the first row is a header.
+	HasHeader bool
+}
+
+// DetectDialect guesses the dialect of the CSV input by sampling the first DialectSampleSize bytes.
+// The delimiter is one of ',', ';', '\t', '|' and ':', whichever splits the rows into the most consistent no. of fields.
+// The first row is considered a header unless its values look like the values of the rest of the rows,
+// Eg: a non-numeric first row on top of numeric columns is a header.
+// If the dialect cannot be guessed, the default dialect (',', '"' and a header) is returned.
+func DetectDialect(r io.Reader) (Dialect, error) {
+	sample, err := io.ReadAll(io.LimitReader(r, DialectSampleSize+1))
+	if err != nil {
+		return Dialect{}, err
+	}
+
+	complete := len(sample) <= DialectSampleSize
+	if !complete {
+		sample = sample[:DialectSampleSize]
+	}
+
+	return detectDialect(sample, complete), nil
+}
+
+// applyDialect detects the dialect of the buffered input and uses it for the inputs of the current run.
+func (c *Processor) applyDialect(input *bufio.Reader) error {
+	sample, err := input.Peek(DialectSampleSize)
+	complete := false
+	switch {
+	case err == nil:
+	case errors.Is(err, io.EOF):
+		// the sample is the whole input.
+		complete = true
+	case errors.Is(err, bufio.ErrBufferFull):
+		// the read buffer is smaller than DialectSampleSize, the buffered bytes are the sample.
+	default:
+		return err
+	}
+
+	dialect := detectDialect(sample, complete)
+	c.dialect = &dialect
+	return nil
+}
+
+// delimiter returns the field delimiter of the inputs, the detected one if WithAutoDialect() is used.
+func (c *Processor) delimiter() rune {
+	if c.dialect != nil {
+		return c.dialect.Delimiter
+	}
+
+	return c.inputDelimiter
+}
+
+// quote returns the quote character of the inputs, the detected one if WithAutoDialect() is used.
+func (c *Processor) quote() rune {
+	if c.dialect != nil {
+		return c.dialect.Quote
+	}
+
+	return c.inputQuote
+}
+
+func detectDialect(sample []byte, complete bool) Dialect {
+	dialect := Dialect{Delimiter: ',', Quote: '"', HasHeader: true}
+	if !complete {
+		// the last line could be truncated.
+		if i := bytes.LastIndexByte(sample, '\n'); i >= 0 {
+			sample = sample[:i+1]
+		}
+	}
+
+	if len(bytes.TrimSpace(sample)) == 0 {
+		return dialect
+	}
+
+	dialect.Quote = detectQuote(sample)
+
+	bestScore := 0.0
+	var bestRows [][]string
+	for _, delimiter := range dialectDelimiters {
+		rows := splitSample(sample, delimiter, dialect.Quote)
+		if score := consistency(rows); score > bestScore {
+			bestScore = score
+			bestRows = rows
+			dialect.Delimiter = delimiter
+		}
+	}
+
+	if bestRows == nil {
+		bestRows = splitSample(sample, dialect.Delimiter, dialect.Quote)
+	}
+
+	dialect.HasHeader = detectHeader(bestRows)
+	return dialect
+}
+
+// detectQuote returns the quote character that is used most at the start of the fields.
+func detectQuote(sample []byte) rune {
+	counts := map[byte]int{}
+	for i, ch := range sample {
+		if ch != '"' && ch != '\'' {
+			continue
+		}
+
+		if i == 0 || sample[i-1] == '\n' || isDelimiter(rune(sample[i-1])) {
+			counts[ch]++
+		}
+	}
+
+	if counts['\''] > counts['"'] {
+		return '\''
+	}
+
+	return '"'
+}
+
+func isDelimiter(r rune) bool {
+	for _, delimiter := range dialectDelimiters {
+		if r == delimiter {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitSample splits the sample into rows and fields using the given delimiter and quote.
+func splitSample(sample []byte, delimiter, quote rune) [][]string {
+	var rows [][]string
+	var row []string
+	var field []rune
+	inQuotes := false
+	text := string(sample)
+	for i, width := 0, 0; i < len(text); i += width {
+		var ch rune
+		ch, width = utf8.DecodeRuneInString(text[i:])
+		switch {
+		case inQuotes && ch == quote:
+			if next, _ := utf8.DecodeRuneInString(text[i+width:]); next == quote {
+				field = append(field, quote)
+				width++
+				continue
+			}
+
+			inQuotes = false
+		case inQuotes:
+			field = append(field, ch)
+		case ch == quote && len(field) == 0:
+			inQuotes = true
+		case ch == delimiter:
+			row = append(row, string(field))
+			field = field[:0]
+		case ch == '\n':
+			row = append(row, strings.TrimSuffix(string(field), "\r"))
+			rows = append(rows, row)
+			row, field = nil, field[:0]
+		default:
+			field = append(field, ch)
+		}
+	}
+
+	if row != nil || len(field) > 0 {
+		rows = append(rows, append(row, string(field)))
+	}
+
+	return rows
+}
+
+// consistency returns the fraction of rows that have the most common no. of fields,
+// rows with a single field do not count as they are not split by the delimiter.
+func consistency(rows [][]string) float64 {
+	counts := map[int]int{}
+	best := 0
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+
+		counts[len(row)]++
+		if counts[len(row)] > best {
+			best = counts[len(row)]
+		}
+	}
+
+	if len(rows) == 0 {
+		return 0
+	}
+
+	return float64(best) / float64(len(rows))
+}
+
+// detectHeader guesses whether the first row is a header by comparing it with the following rows.
+// A column votes against a header when the first value is repeated in the following rows,
+// and for a header when all the following values are numeric but the first value is not,
+// or when all the following values have the same length but the first value has a different length.
+func detectHeader(rows [][]string) bool {
+	if len(rows) < 2 {
+		return true
+	}
+
+	header := rows[0]
+	data := rows[1:]
+	if len(data) > headerSampleRows {
+		data = data[:headerSampleRows]
+	}
+
+	votes := 0
+	for i, name := range header {
+		numeric, sameLength, repeated := true, true, false
+		length := -1
+		for _, row := range data {
+			if i >= len(row) {
+				continue
+			}
+
+			if row[i] == name {
+				repeated = true
+			}
+
+			if !isNumeric(row[i]) {
+				numeric = false
+			}
+
+			if length == -1 {
+				length = len(row[i])
+			} else if length != len(row[i]) {
+				sameLength = false
+			}
+		}
+
+		switch {
+		case length == -1:
+		case repeated:
+			votes--
+		case numeric && !isNumeric(name):
+			votes++
+		case numeric:
+			votes--
+		case sameLength && len(name) != length:
+			votes++
+		case sameLength:
+			votes--
+		}
+	}
+
+	return votes >= 0
+}
+
+func isNumeric(value string) bool {
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
+// detectHeader reads the first rows of the reader to detect whether the input has a header,
+// and returns a reader that replays the rows read.
+func (c *Processor) detectHeader(reader CsvReader) CsvReader {
+	replay := &replayReader{reader: reader}
+	for len(replay.rows) <= headerSampleRows {
+		row, err := reader.Read()
+		if err != nil {
+			replay.err = err
+			break
+		}
+
+		// the rows are copied as the reader can reuse them.
+		replay.rows = append(replay.rows, append([]string(nil), row...))
+	}
+
+	if len(replay.rows) > 0 {
+		c.skipHeaders = !detectHeader(replay.rows)
+	}
+
+	return replay
+}
+
+// replayReader returns the buffered rows and error before reading the rest of the rows from the reader.
+type replayReader struct {
+	reader CsvReader
+	rows   [][]string
+	err    error
+}
+
+func (r *replayReader) Read() ([]string, error) {
+	if len(r.rows) > 0 {
+		row := r.rows[0]
+		r.rows = r.rows[1:]
+		return row, nil
+	}
+
+	if r.err != nil {
+		err := r.err
+		r.err = nil
+		return nil, err
+	}
+
+	return r.reader.Read()
+}
@@ -0,0 +1,162 @@
+package csvprocessor_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestDetectDialect(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  csvprocessor.Dialect
+	}{
+		{
+			name:  "Test comma with header",
+			input: "id,name,amount\n1,alice,10.5\n2,bob,20\n",
+			want:  csvprocessor.Dialect{Delimiter: ',', Quote: '"', HasHeader: true},
+		},
+		{
+			name:  "Test semicolon with quoted delimiters",
+			input: "id;name;note\n1;\"a;b\";x\n2;c;\"y, z\"\n",
+			want:  csvprocessor.Dialect{Delimiter: ';', Quote: '"', HasHeader: true},
+		},
+		{
+			name:  "Test tab without header",
+			input: "1\t10\t3.5\n2\t20\t4.5\n3\t30\t5.5\n",
+			want:  csvprocessor.Dialect{Delimiter: '\t', Quote: '"', HasHeader: false},
+		},
+		{
+			name:  "Test pipe with single quotes",
+			input: "code|label\n'AB'|'x|y'\n'CD'|'z'\n",
+			want:  csvprocessor.Dialect{Delimiter: '|', Quote: '\'', HasHeader: true},
+		},
+		{
+			name:  "Test fixed length codes",
+			input: "country,code\nIN,IND\nUS,USA\nFR,FRA\n",
+			want:  csvprocessor.Dialect{Delimiter: ',', Quote: '"', HasHeader: true},
+		},
+		{
+			name:  "Test single column",
+			input: "name\nalice\nbob\n",
+			want:  csvprocessor.Dialect{Delimiter: ',', Quote: '"', HasHeader: true},
+		},
+		{
+			name:  "Test empty input",
+			input: "",
+			want:  csvprocessor.Dialect{Delimiter: ',', Quote: '"', HasHeader: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := csvprocessor.DetectDialect(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("DetectDialect() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("DetectDialect() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithAutoDialect(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []csvprocessor.Option
+		want  string
+	}{
+		{
+			name:  "Test semicolon with header",
+			input: "id;name\n1;alice\n2;bob\n",
+			want:  "#,id,name\n1,1,alice\n2,2,bob\n",
+		},
+		{
+			name:  "Test tab without header",
+			input: "1\t10\n2\t20\n",
+			want:  "1,1,10\n2,2,20\n",
+		},
+		{
+			name:  "Test pipe with single quotes",
+			input: "id|name\n1|'a|b'\n2|'c'\n",
+			want:  "#,id,name\n1,1,a|b\n2,2,c\n",
+		},
+		{
+			name:  "Test sample larger than the read buffer",
+			input: "id;name\n" + strings.Repeat("1;alice\n", 1000),
+			opts:  []csvprocessor.Option{csvprocessor.WithReadBufferSize(4096), csvprocessor.WithLimitRows(1)},
+			want:  "#,id,name\n1,1,alice\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append(tt.opts, csvprocessor.WithAutoDialect(), csvprocessor.WithTransformer(csvprocessor.AddRowNoTransformer("#")))
+			output, err := processFile(t, tt.input, opts...)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithHeaderDetection(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opt   []csvprocessor.Option
+		want  string
+	}{
+		{
+			name:  "Test header",
+			input: "id,amount\n1,10\n2,20\n",
+			want:  "#,id,amount\n1,1,10\n2,2,20\n",
+		},
+		{
+			name:  "Test no header",
+			input: "1,10\n2,20\n3,30\n",
+			want:  "1,1,10\n2,2,20\n3,3,30\n",
+		},
+		{
+			name:  "Test repeated first row",
+			input: "open,alice\nopen,bob\nclosed,carol\n",
+			want:  "1,open,alice\n2,open,bob\n3,closed,carol\n",
+		},
+		{
+			name:  "Test overrides SkipHeaders",
+			input: "id,amount\n1,10\n2,20\n",
+			opt:   []csvprocessor.Option{csvprocessor.SkipHeaders(true)},
+			want:  "#,id,amount\n1,1,10\n2,2,20\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := append(tt.opt, csvprocessor.WithHeaderDetection(), csvprocessor.WithTransformer(csvprocessor.AddRowNoTransformer("#")))
+
+			output, err := processString(t, tt.input, opt...)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+
+			output, err = processFile(t, tt.input, opt...)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() file output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
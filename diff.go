@@ -0,0 +1,302 @@
+package csvprocessor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsortedInput is returned by Diff and WithJoin() when an input marked as sorted is not sorted by the key columns.
+var ErrUnsortedInput = errors.New("csvprocessor: input is not sorted by the key columns")
+
+// DiffKind represents the kind of difference between the rows of two inputs, see Diff.
+type DiffKind int
+
+const (
+	// DiffAdded is a row with a key that is only in the new input.
+	DiffAdded DiffKind = iota
+	// DiffRemoved is a row with a key that is only in the old input.
+	DiffRemoved
+	// DiffChanged is a row with a key that is in both the inputs, with different values.
+	DiffChanged
+)
+
+// String returns the name of the kind, Eg: "added".
+func (k DiffKind) String() string {
+	switch k {
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	case DiffChanged:
+		return "changed"
+	}
+
+	return "DiffKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// DiffRow is a difference between the rows of two inputs.
+type DiffRow struct {
+	Kind DiffKind
+	// Key contains the values of the key columns.
+	Key []string
+	// Old is the row in the old input, nil for DiffAdded.
+	Old []string
+	// New is the row in the new input, nil for DiffRemoved.
+	New []string
+	// Changes contains the columns with different values, for DiffChanged.
+	Changes []ColumnChange
+}
+
+// ColumnChange is a column whose value is different in the old and the new row.
+type ColumnChange struct {
+	// Column is the 0-based index of the column.
+	Column int
+	// Name is the name of the column in the header of the new input, or empty if the inputs do not have headers.
+	Name string
+	Old  string
+	New  string
+}
+
+// Diff compares two CSV inputs by the key columns and returns the added, removed and changed rows. Eg:
+//
+//	diff := csvprocessor.NewDiff(oldReader, newReader, []int{0}, true)
+//	defer diff.Close()
+//	for {
+//		row, err := diff.Next()
+//		if errors.Is(err, io.EOF) {
+//			break
+//		}
+//		...
+//	}
+//
+// The inputs are sorted by the key columns using a Sorter (the rows beyond RunSize are spilled to temp files),
+// and the sorted inputs are merged, so only a row of each input is held in memory while comparing.
+// The keys are compared as strings. If a key is repeated, the rows with the key are paired in the order of the input.
+//
+// Diff is also a CsvReader, so the differences can be written using the processor, see Read().
+type Diff struct {
+	// RunSize is the max no. of rows of each input held in memory while sorting, see NewSorter().
+	RunSize int
+	// Sorted skips sorting when both the inputs are already sorted by the key columns in ascending order,
+	// ErrUnsortedInput is returned if they are not.
+	Sorted bool
+
+	// Unexported fields
+	readers   [2]CsvReader // old and new inputs.
+	keys      []int
+	hasHeader bool
+
+	started    bool
+	sorters    []*Sorter
+	header     []string
+	rows       [2][]string // next row of each input, nil at the end of the input.
+	headerRead bool        // whether Read() has returned the header.
+}
+
+// old and new indices of the inputs.
+const (
+	diffOld = iota
+	diffNew
+)
+
+// NewDiff creates a Diff that compares the rows of oldReader and newReader by the given key columns.
+// If hasHeader is true, the first row of each input is treated as header and the header of the new input is used for the column names.
+func NewDiff(oldReader, newReader CsvReader, keys []int, hasHeader bool) *Diff {
+	return &Diff{readers: [2]CsvReader{oldReader, newReader}, keys: keys, hasHeader: hasHeader}
+}
+
+// Header returns the header of the new input, it is available after the first call to Next() or Read().
+func (d *Diff) Header() []string {
+	return d.header
+}
+
+// Next returns the next difference in the order of the keys, it returns io.EOF when both the inputs are compared.
+// The unchanged rows are skipped.
+func (d *Diff) Next() (DiffRow, error) {
+	if !d.started {
+		if err := d.start(); err != nil {
+			return DiffRow{}, err
+		}
+	}
+
+	for {
+		oldRow, newRow := d.rows[diffOld], d.rows[diffNew]
+		var diff DiffRow
+		switch {
+		case oldRow == nil && newRow == nil:
+			return DiffRow{}, io.EOF
+		case oldRow == nil || (newRow != nil && d.compare(newRow, oldRow) < 0):
+			diff = DiffRow{Kind: DiffAdded, Key: keyValues(newRow, d.keys), New: newRow}
+			if err := d.advance(diffNew); err != nil {
+				return DiffRow{}, err
+			}
+		case newRow == nil || d.compare(oldRow, newRow) < 0:
+			diff = DiffRow{Kind: DiffRemoved, Key: keyValues(oldRow, d.keys), Old: oldRow}
+			if err := d.advance(diffOld); err != nil {
+				return DiffRow{}, err
+			}
+		default:
+			diff = DiffRow{Kind: DiffChanged, Key: keyValues(newRow, d.keys), Old: oldRow, New: newRow, Changes: d.changes(oldRow, newRow)}
+			if err := d.advance(diffOld); err != nil {
+				return DiffRow{}, err
+			}
+
+			if err := d.advance(diffNew); err != nil {
+				return DiffRow{}, err
+			}
+
+			if len(diff.Changes) == 0 {
+				continue
+			}
+		}
+
+		return diff, nil
+	}
+}
+
+// Read returns the differences as CSV rows, so that Diff can be used with WithReader().
+// Each row has the kind of the difference, the values of the new row (the old row for DiffRemoved),
+// and the changed columns, Eg: "changed,1,alice,NY,city: LA -> NY".
+// If the inputs have headers, the first row is the header with "diff" and "changes" columns around the header of the new input.
+func (d *Diff) Read() ([]string, error) {
+	if !d.started {
+		if err := d.start(); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.hasHeader && !d.headerRead {
+		d.headerRead = true
+		return append(append([]string{"diff"}, d.header...), "changes"), nil
+	}
+
+	diff, err := d.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	values := diff.New
+	if diff.Kind == DiffRemoved {
+		values = diff.Old
+	}
+
+	changes := make([]string, 0, len(diff.Changes))
+	for _, change := range diff.Changes {
+		name := change.Name
+		if name == "" {
+			name = strconv.Itoa(change.Column)
+		}
+
+		changes = append(changes, fmt.Sprintf("%s: %s -> %s", name, change.Old, change.New))
+	}
+
+	return append(append([]string{diff.Kind.String()}, values...), strings.Join(changes, "; ")), nil
+}
+
+// Close removes the temp files created while sorting the inputs.
+func (d *Diff) Close() error {
+	var firstErr error
+	for _, sorter := range d.sorters {
+		if err := sorter.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	d.sorters = nil
+	return firstErr
+}
+
+// start sorts the inputs if needed, and reads the headers and the first row of each input.
+func (d *Diff) start() error {
+	d.started = true
+	if !d.Sorted {
+		for i, reader := range d.readers {
+			sorter := NewSorter(reader, d.keys, Ascending, d.RunSize, d.hasHeader)
+			d.sorters = append(d.sorters, sorter)
+			d.readers[i] = sorter
+		}
+	}
+
+	for i, reader := range d.readers {
+		if d.hasHeader {
+			header, err := readCopy(reader)
+			if err != nil {
+				return fmt.Errorf("csvprocessor: error while reading header: %w", err)
+			}
+
+			if i == diffNew {
+				d.header = header
+			}
+		}
+
+		if err := d.advance(i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// advance reads the next row of the input, the row is nil at the end of the input.
+func (d *Diff) advance(input int) error {
+	row, err := readCopy(d.readers[input])
+	if err != nil {
+		return fmt.Errorf("csvprocessor: error while reading input: %w", err)
+	}
+
+	if d.Sorted && row != nil && d.rows[input] != nil && d.compare(row, d.rows[input]) < 0 {
+		return fmt.Errorf("%w: key %v after %v", ErrUnsortedInput, keyValues(row, d.keys), keyValues(d.rows[input], d.keys))
+	}
+
+	d.rows[input] = row
+	return nil
+}
+
+// readCopy reads a copy of the next row, as the readers can reuse the row. It returns nil at EOF.
+func readCopy(reader CsvReader) ([]string, error) {
+	row, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]string(nil), row...), nil
+}
+
+// compare compares the keys of the rows in the order of the Sorter.
+func (d *Diff) compare(a, b []string) int {
+	for _, column := range d.keys {
+		if cmp := strings.Compare(valueAt(a, column), valueAt(b, column)); cmp != 0 {
+			return cmp
+		}
+	}
+
+	return 0
+}
+
+// changes returns the columns with different values in the rows.
+func (d *Diff) changes(oldRow, newRow []string) []ColumnChange {
+	columns := len(newRow)
+	if len(oldRow) > columns {
+		columns = len(oldRow)
+	}
+
+	var changes []ColumnChange
+	for i := 0; i < columns; i++ {
+		oldValue, newValue := valueAt(oldRow, i), valueAt(newRow, i)
+		if oldValue != newValue {
+			changes = append(changes, ColumnChange{Column: i, Name: valueAt(d.header, i), Old: oldValue, New: newValue})
+		}
+	}
+
+	return changes
+}
+
+// compile time check to ensure Diff implements CsvReader.
+var _ CsvReader = (*Diff)(nil)
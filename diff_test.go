@@ -0,0 +1,106 @@
+package csvprocessor_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestDiff(t *testing.T) {
+	oldInput := "id,name,city\n3,carol,SF\n1,alice,LA\n2,bob,NY\n"
+	newInput := "id,name,city\n4,dave,LA\n1,alice,NY\n3,carol,SF\n"
+
+	diff := csvprocessor.NewDiff(csv.NewReader(strings.NewReader(oldInput)), csv.NewReader(strings.NewReader(newInput)), []int{0}, true)
+	diff.RunSize = 1 // spill every row, to merge the sorted runs.
+	defer diff.Close()
+
+	var got []csvprocessor.DiffRow
+	for {
+		row, err := diff.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+
+		got = append(got, row)
+	}
+
+	want := []csvprocessor.DiffRow{
+		{
+			Kind: csvprocessor.DiffChanged, Key: []string{"1"}, Old: []string{"1", "alice", "LA"}, New: []string{"1", "alice", "NY"},
+			Changes: []csvprocessor.ColumnChange{{Column: 2, Name: "city", Old: "LA", New: "NY"}},
+		},
+		{Kind: csvprocessor.DiffRemoved, Key: []string{"2"}, Old: []string{"2", "bob", "NY"}},
+		{Kind: csvprocessor.DiffAdded, Key: []string{"4"}, New: []string{"4", "dave", "LA"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Next() = %+v, want %+v", got, want)
+	}
+
+	if header := diff.Header(); !reflect.DeepEqual(header, []string{"id", "name", "city"}) {
+		t.Errorf("Header() = %v", header)
+	}
+}
+
+func TestDiff_Read(t *testing.T) {
+	tests := []struct {
+		name      string
+		old, new  string
+		hasHeader bool
+		sorted    bool
+		want      string
+		wantErr   error
+	}{
+		{
+			name:      "Test diff with header",
+			old:       "id,name\n1,alice\n2,bob\n",
+			new:       "id,name\n2,robert\n3,carol\n",
+			hasHeader: true,
+			want:      "diff,id,name,changes\nremoved,1,alice,\nchanged,2,robert,name: bob -> robert\nadded,3,carol,\n",
+		},
+		{
+			name: "Test diff without header",
+			old:  "1,a\n1,b\n",
+			new:  "1,a\n",
+			want: "removed,1,b,\n",
+		},
+		{
+			name:   "Test sorted inputs",
+			old:    "1,a\n2,b\n",
+			new:    "1,x\n2,b\n",
+			sorted: true,
+			want:   "changed,1,x,1: a -> x\n",
+		},
+		{
+			name:    "Test unsorted input",
+			old:     "2,a\n1,b\n",
+			new:     "1,a\n",
+			sorted:  true,
+			wantErr: csvprocessor.ErrUnsortedInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := csvprocessor.NewDiff(csv.NewReader(strings.NewReader(tt.old)), csv.NewReader(strings.NewReader(tt.new)), []int{0}, tt.hasHeader)
+			diff.Sorted = tt.sorted
+			defer diff.Close()
+
+			output, err := processString(t, "", csvprocessor.WithReader(diff), csvprocessor.SkipHeaders(!tt.hasHeader))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package csvprocessor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrInvalidEncodedInput is returned when the input has bytes that are not valid in the encoding set by WithInputEncoding(),
+// Eg: an unpaired surrogate in UTF-16 or a byte that is not used by Windows-1252.
+var ErrInvalidEncodedInput = errors.New("csvprocessor: input is not valid in the input encoding")
+
+// encoding converts the input from and the output to a character encoding other than UTF-8.
+type encoding interface {
+	// newDecoder returns a reader that converts the encoded input to UTF-8.
+	newDecoder(r io.Reader) io.Reader
+	// newEncoder returns a writer that converts the UTF-8 output to the encoding.
+	newEncoder(w io.Writer) io.Writer
+}
+
+// lookupEncoding returns the encoding with the given name,
+// nil is returned for UTF-8 as no conversion is needed.
+func lookupEncoding(name string) (encoding, bool) {
+	name = strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(name))
+	switch name {
+	case "utf8":
+		return nil, true
+	case "latin1", "iso88591":
+		return newSingleByteEncoding(nil), true
+	case "windows1252", "cp1252":
+		return newSingleByteEncoding(&windows1252), true
+	case "utf16":
+		return utf16Encoding{littleEndian: true, detectBOM: true}, true
+	case "utf16le":
+		return utf16Encoding{littleEndian: true}, true
+	case "utf16be":
+		return utf16Encoding{littleEndian: false}, true
+	}
+
+	return nil, false
+}
+
+// hasBOM returns true if the encoding has a byte order mark, the single byte encodings do not have one.
+func hasBOM(e encoding) bool {
+	_, singleByte := e.(*singleByteEncoding)
+	return !singleByte
+}
+
+// windows1252 contains the characters of the range 0x80 - 0x9F of Windows-1252,
+// the rest of the characters are the same as ISO-8859-1. The unused bytes are invalid (-1).
+var windows1252 = [32]rune{
+	0x20AC, -1, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, -1, 0x017D, -1,
+	-1, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, -1, 0x017E, 0x0178,
+}
+
+// singleByteEncoding represents encodings like ISO-8859-1 where every byte is a character.
+type singleByteEncoding struct {
+	decode [256]rune // -1 for the bytes that are not used by the encoding.
+	encode map[rune]byte
+}
+
+func newSingleByteEncoding(extended *[32]rune) *singleByteEncoding {
+	e := &singleByteEncoding{encode: make(map[rune]byte, 128)}
+	for i := range e.decode {
+		e.decode[i] = rune(i)
+	}
+
+	if extended != nil {
+		copy(e.decode[0x80:], extended[:])
+	}
+
+	for i := 0x80; i < len(e.decode); i++ {
+		if e.decode[i] >= 0 {
+			e.encode[e.decode[i]] = byte(i)
+		}
+	}
+
+	return e
+}
+
+func (e *singleByteEncoding) newDecoder(r io.Reader) io.Reader {
+	return &decodingReader{r: r, decode: func(src []byte, dst []byte) ([]byte, []byte, bool) {
+		for i, b := range src {
+			if e.decode[b] < 0 {
+				return dst, src[i:], false
+			}
+
+			dst = utf8.AppendRune(dst, e.decode[b])
+		}
+
+		return dst, nil, true
+	}}
+}
+
+func (e *singleByteEncoding) newEncoder(w io.Writer) io.Writer {
+	return &encodingWriter{w: w, encode: func(dst []byte, r rune) []byte {
+		if r < utf8.RuneSelf {
+			return append(dst, byte(r))
+		}
+
+		if b, ok := e.encode[r]; ok {
+			return append(dst, b)
+		}
+
+		// the character cannot be represented in this encoding.
+		return append(dst, '?')
+	}}
+}
+
+// utf16Encoding represents UTF-16 in little or big endian byte order.
+type utf16Encoding struct {
+	littleEndian bool
+	// detectBOM reads the byte order from the BOM at the start of the input, if any.
+	detectBOM bool
+}
+
+func (e utf16Encoding) newDecoder(r io.Reader) io.Reader {
+	started := false
+	return &decodingReader{r: r, decode: func(src []byte, dst []byte) ([]byte, []byte, bool) {
+		if !started && e.detectBOM {
+			if len(src) < 2 {
+				return dst, src, true
+			}
+
+			switch {
+			case src[0] == 0xFE && src[1] == 0xFF:
+				e.littleEndian = false
+			case src[0] == 0xFF && src[1] == 0xFE:
+				e.littleEndian = true
+			}
+		}
+
+		started = true
+		for len(src) >= 2 {
+			r := e.unit(src)
+			switch {
+			case r >= 0xD800 && r < 0xDC00:
+				if len(src) < 4 {
+					// the low surrogate is kept until it is read.
+					return dst, src, true
+				}
+
+				decoded := utf16.DecodeRune(r, e.unit(src[2:]))
+				if decoded == utf8.RuneError {
+					// a high surrogate that is not followed by a low surrogate.
+					return dst, src, false
+				}
+
+				dst = utf8.AppendRune(dst, decoded)
+				src = src[4:]
+			case utf16.IsSurrogate(r):
+				return dst, src, false
+			default:
+				dst = utf8.AppendRune(dst, r)
+				src = src[2:]
+			}
+		}
+
+		// an odd byte is kept until the rest of the character is read.
+		return dst, src, true
+	}}
+}
+
+// unit returns the 16-bit code unit at the start of src.
+func (e utf16Encoding) unit(src []byte) rune {
+	if e.littleEndian {
+		return rune(src[1])<<8 | rune(src[0])
+	}
+
+	return rune(src[0])<<8 | rune(src[1])
+}
+
+func (e utf16Encoding) newEncoder(w io.Writer) io.Writer {
+	return &encodingWriter{w: w, encode: func(dst []byte, r rune) []byte {
+		r1, r2 := utf16.EncodeRune(r)
+		if r1 == utf8.RuneError {
+			return e.appendUint16(dst, uint16(r))
+		}
+
+		return e.appendUint16(e.appendUint16(dst, uint16(r1)), uint16(r2))
+	}}
+}
+
+func (e utf16Encoding) appendUint16(dst []byte, v uint16) []byte {
+	if e.littleEndian {
+		return append(dst, byte(v), byte(v>>8))
+	}
+
+	return append(dst, byte(v>>8), byte(v))
+}
+
+// decodingReader converts the underlying reader to UTF-8.
+type decodingReader struct {
+	r io.Reader
+	// decode appends the decoded src to dst, the bytes that could not be decoded yet are returned as remaining.
+	// If the input is not valid, valid is false and remaining starts at the invalid bytes.
+	decode func(src []byte, dst []byte) (decoded []byte, remaining []byte, valid bool)
+
+	src     []byte
+	decoded []byte
+	offset  int64 // no. of bytes of the input decoded.
+	err     error
+}
+
+func (d *decodingReader) Read(p []byte) (int, error) {
+	for len(d.decoded) == 0 {
+		if d.err != nil {
+			if len(d.src) > 0 && errors.Is(d.err, io.EOF) {
+				// incomplete character at the end of the input.
+				d.src = nil
+				d.err = d.invalid()
+			}
+
+			return 0, d.err
+		}
+
+		buf := make([]byte, len(d.src), len(d.src)+4096)
+		copy(buf, d.src)
+		n, err := d.r.Read(buf[len(d.src):cap(buf)])
+		d.err = err
+
+		var valid bool
+		size := len(d.src) + n
+		d.decoded, d.src, valid = d.decode(buf[:size], d.decoded[:0])
+		d.offset += int64(size - len(d.src))
+		if !valid {
+			d.src = nil
+			d.err = d.invalid()
+		}
+	}
+
+	n := copy(p, d.decoded)
+	d.decoded = d.decoded[n:]
+
+	return n, nil
+}
+
+// invalid returns the error for the invalid bytes at the current offset.
+func (d *decodingReader) invalid() error {
+	return fmt.Errorf("%w: at byte %d", ErrInvalidEncodedInput, d.offset)
+}
+
+// encodingWriter converts the UTF-8 output to another encoding.
+type encodingWriter struct {
+	w      io.Writer
+	encode func(dst []byte, r rune) []byte
+
+	// incomplete UTF-8 character from the previous write.
+	partial []byte
+	buf     []byte
+}
+
+func (e *encodingWriter) Write(p []byte) (int, error) {
+	src := p
+	if len(e.partial) > 0 {
+		src = append(e.partial, p...)
+		e.partial = nil
+	}
+
+	e.buf = e.buf[:0]
+	for len(src) > 0 {
+		if !utf8.FullRune(src) {
+			e.partial = append([]byte(nil), src...)
+			break
+		}
+
+		r, size := utf8.DecodeRune(src)
+		e.buf = e.encode(e.buf, r)
+		src = src[size:]
+	}
+
+	if _, err := e.w.Write(e.buf); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
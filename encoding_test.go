@@ -0,0 +1,162 @@
+package csvprocessor_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithInputEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		input    string
+		want     string
+	}{
+		{
+			name:     "Test latin1",
+			encoding: "ISO-8859-1",
+			input:    "name,city\nJos\xe9,M\xfcnchen\n",
+			want:     "name,city\nJosé,München\n",
+		},
+		{
+			name:     "Test windows-1252",
+			encoding: "windows-1252",
+			input:    "item,price\ncoffee,\x805\n\x93quoted\x94,1\n",
+			want:     "item,price\ncoffee,€5\n“quoted”,1\n",
+		},
+		{
+			name:     "Test utf-16le with BOM",
+			encoding: "utf-16le",
+			input:    "\xff\xfea\x00,\x00b\x00\n\x00\xe9\x00,\x00=\xd8\x00\xde\n\x00",
+			want:     "a,b\né,😀\n",
+		},
+		{
+			name:     "Test utf-16be",
+			encoding: "UTF-16BE",
+			input:    "\x00a\x00,\x00b\x00\n\x00\xe9\x00,\x00x\x00\n",
+			want:     "a,b\né,x\n",
+		},
+		{
+			name:     "Test utf-16 with big endian BOM",
+			encoding: "utf-16",
+			input:    "\xfe\xff\x00a\x00,\x00b\x00\n\x00\xe9\x00,\x00x\x00\n",
+			want:     "a,b\né,x\n",
+		},
+		{
+			name:     "Test utf-16 without BOM",
+			encoding: "utf-16",
+			input:    "a\x00,\x00b\x00\n\x00",
+			want:     "a,b\n",
+		},
+		{
+			name:     "Test utf-8",
+			encoding: "utf8",
+			input:    "a,b\né,x\n",
+			want:     "a,b\né,x\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processFile(t, tt.input, csvprocessor.WithInputEncoding(tt.encoding))
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithOutputEncoding(t *testing.T) {
+	tests := []struct {
+		name  string
+		opt   []csvprocessor.Option
+		input string
+		want  string
+	}{
+		{
+			name:  "Test latin1",
+			opt:   []csvprocessor.Option{csvprocessor.WithOutputEncoding("latin1")},
+			input: "name,city\nJosé,München €\n",
+			want:  "name,city\nJos\xe9,M\xfcnchen ?\n",
+		},
+		{
+			name:  "Test windows-1252",
+			opt:   []csvprocessor.Option{csvprocessor.WithOutputEncoding("cp1252")},
+			input: "item,price\ncoffee,€5\n",
+			want:  "item,price\ncoffee,\x805\n",
+		},
+		{
+			name:  "Test utf-16le with BOM",
+			opt:   []csvprocessor.Option{csvprocessor.WithOutputEncoding("utf-16le"), csvprocessor.WithOutputBOM(true)},
+			input: "a\n😀\n",
+			want:  "\xff\xfea\x00\n\x00=\xd8\x00\xde\n\x00",
+		},
+		{
+			name:  "Test latin1 without BOM",
+			opt:   []csvprocessor.Option{csvprocessor.WithOutputEncoding("latin1"), csvprocessor.WithOutputBOM(true)},
+			input: "a\né\n",
+			want:  "a\n\xe9\n",
+		},
+		{
+			name:  "Test utf-16be",
+			opt:   []csvprocessor.Option{csvprocessor.WithOutputEncoding("utf-16be")},
+			input: "a\né\n",
+			want:  "\x00a\x00\n\x00\xe9\x00\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processString(t, tt.input, tt.opt...)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithInputEncoding_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		input    string
+		wantErr  string
+	}{
+		{name: "Test unused windows-1252 byte", encoding: "windows-1252", input: "a,b\nc,\x81\n", wantErr: "at byte 6"},
+		{name: "Test unpaired low surrogate", encoding: "utf-16le", input: "a\x00\n\x00\x00\xdc\n\x00", wantErr: "at byte 4"},
+		{name: "Test unpaired high surrogate", encoding: "utf-16le", input: "a\x00\n\x00=\xd8b\x00\n\x00", wantErr: "at byte 4"},
+		{name: "Test high surrogate at the end", encoding: "utf-16le", input: "a\x00\n\x00=\xd8", wantErr: "at byte 4"},
+		{name: "Test odd no. of bytes", encoding: "utf-16be", input: "\x00a\x00\n\x00", wantErr: "at byte 4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := processFile(t, tt.input, csvprocessor.WithInputEncoding(tt.encoding))
+			if !errors.Is(err, csvprocessor.ErrInvalidEncodedInput) {
+				t.Fatalf("Process() error = %v, want %v", err, csvprocessor.ErrInvalidEncodedInput)
+			}
+
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Process() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncoding_Unsupported(t *testing.T) {
+	if _, err := processString(t, "a\n", csvprocessor.WithInputEncoding("ebcdic")); !errors.Is(err, csvprocessor.ErrUnsupportedEncoding) {
+		t.Errorf("WithInputEncoding() error = %v, want %v", err, csvprocessor.ErrUnsupportedEncoding)
+	}
+
+	if _, err := processString(t, "a\n", csvprocessor.WithOutputEncoding("ebcdic")); !errors.Is(err, csvprocessor.ErrUnsupportedEncoding) {
+		t.Errorf("WithOutputEncoding() error = %v, want %v", err, csvprocessor.ErrUnsupportedEncoding)
+	}
+}
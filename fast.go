@@ -0,0 +1,251 @@
+package csvprocessor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// WithFastParser parses the inputs with FastReader instead of csv.Reader, which is several times faster for well-formed inputs.
+// It is used only when the input delimiter is a single byte character and the default quote is used (see WithInputQuote()),
+// else the inputs are parsed with csv.Reader.
+func WithFastParser() Option {
+	return func(c *Processor) error {
+		c.fastParser = true
+		return nil
+	}
+}
+
+// useFastParser reports whether the inputs are parsed with FastReader.
+func (c *Processor) useFastParser() bool {
+	return c.fastParser && c.delimiter() < utf8.RuneSelf && !c.customQuote()
+}
+
+// FastReader is a CsvReader for the well-formed CSV inputs, with a single byte delimiter and '"' as the quote. Eg:
+//
+//	reader := csvprocessor.NewFastReader(input, ',')
+//
+// The lines without quotes are split by scanning for the delimiter with bytes.IndexByte, and the fields of a record
+// share a single string. The quoted fields are parsed like csv.Reader with LazyQuotes.
+// \r\n, and \r at the end of the input, are read as \n and the empty lines are skipped.
+type FastReader struct {
+	// Comma is the field delimiter.
+	Comma byte
+	// FieldsPerRecord is the no. of fields expected in each record, same as csv.Reader.FieldsPerRecord.
+	FieldsPerRecord int
+	// ReuseRecord controls whether the slice returned by Read is reused by the next call, same as csv.Reader.ReuseRecord.
+	ReuseRecord bool
+
+	// Unexported fields
+	r       *bufio.Reader
+	line    int
+	lineBuf []byte // the line, if it does not fit in the buffer of r.
+	buf     []byte // the unquoted fields of a quoted record.
+	ends    []int
+	record  []string
+}
+
+// NewFastReader creates a FastReader with the given delimiter.
+func NewFastReader(r io.Reader, comma byte) *FastReader {
+	reader, ok := r.(*bufio.Reader)
+	if !ok {
+		reader = bufio.NewReaderSize(r, DefaultReadBufferSize)
+	}
+
+	return &FastReader{Comma: comma, r: reader}
+}
+
+// Read reads the next record, it returns io.EOF at the end of the input.
+// If the record has an unexpected no. of fields, it is returned along with a *csv.ParseError wrapping csv.ErrFieldCount.
+func (f *FastReader) Read() ([]string, error) {
+	startLine := f.line + 1
+	line, err := f.readLine()
+	for err == nil && len(line) == 1 {
+		// empty lines are skipped.
+		startLine = f.line + 1
+		line, err = f.readLine()
+	}
+
+	if len(line) == 0 {
+		return nil, err
+	}
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	record, quoted := f.split(line)
+	if quoted {
+		if record, err = f.parseQuoted(line); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case f.FieldsPerRecord == 0:
+		f.FieldsPerRecord = len(record)
+	case f.FieldsPerRecord > 0 && len(record) != f.FieldsPerRecord:
+		return record, &csv.ParseError{StartLine: startLine, Line: f.line, Column: 1, Err: csv.ErrFieldCount}
+	}
+
+	return record, nil
+}
+
+// readLine reads the next line, \r\n at the end is returned as \n and a \r at the end of the input is dropped.
+// The line is valid till the next call, it does not end with \n only at the end of the input.
+func (f *FastReader) readLine() ([]byte, error) {
+	line, err := f.r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		f.lineBuf = append(f.lineBuf[:0], line...)
+		for errors.Is(err, bufio.ErrBufferFull) {
+			line, err = f.r.ReadSlice('\n')
+			f.lineBuf = append(f.lineBuf, line...)
+		}
+
+		line = f.lineBuf
+	}
+
+	if len(line) > 0 {
+		f.line++
+	}
+
+	if n := len(line); n >= 2 && line[n-2] == '\r' && line[n-1] == '\n' {
+		line[n-2] = '\n'
+		line = line[:n-1]
+	} else if n > 0 && line[n-1] == '\r' && errors.Is(err, io.EOF) {
+		// like csv.Reader, a \r at the end of the input ends the last line.
+		line = line[:n-1]
+	}
+
+	return line, err
+}
+
+// split splits the line without quotes at the delimiter, it returns quoted if the line has a quote.
+// The line is scanned once for both the delimiters and the quotes, as the lines are usually too short for bytes.IndexByte to pay off.
+func (f *FastReader) split(line []byte) (record []string, quoted bool) {
+	line = bytes.TrimSuffix(line, []byte{'\n'})
+	f.ends = f.ends[:0]
+	for i, b := range line {
+		switch b {
+		case f.Comma:
+			f.ends = append(f.ends, i)
+		case '"':
+			return nil, true
+		}
+	}
+
+	return f.fields(string(line), len(line), 1), false
+}
+
+// fields slices the record from s using ends, the end offset of all the fields except the last one,
+// the fields are separated by sep bytes.
+func (f *FastReader) fields(s string, last, sep int) []string {
+	record := f.newRecord(len(f.ends) + 1)
+	start := 0
+	for _, end := range f.ends {
+		record = append(record, s[start:end])
+		start = end + sep
+	}
+
+	return append(record, s[start:last])
+}
+
+// parseQuoted parses the line with quoted fields, the quoted fields can span multiple lines.
+func (f *FastReader) parseQuoted(line []byte) ([]string, error) {
+	f.buf = f.buf[:0]
+	f.ends = f.ends[:0]
+	for {
+		end := len(line)
+		if end > 0 && line[end-1] == '\n' {
+			end--
+		}
+
+		if len(line) == 0 || line[0] != '"' {
+			// unquoted field.
+			i := bytes.IndexByte(line[:end], f.Comma)
+			if i < 0 {
+				f.buf = append(f.buf, line[:end]...)
+				f.ends = append(f.ends, len(f.buf))
+				break
+			}
+
+			f.buf = append(f.buf, line[:i]...)
+			f.ends = append(f.ends, len(f.buf))
+			line = line[i+1:]
+			continue
+		}
+
+		// quoted field.
+		var done bool
+		var err error
+		if line, done, err = f.readQuoted(line[1:]); err != nil {
+			return nil, err
+		}
+
+		f.ends = append(f.ends, len(f.buf))
+		if done {
+			break
+		}
+	}
+
+	// the fields are contiguous in buf.
+	last := f.ends[len(f.ends)-1]
+	f.ends = f.ends[:len(f.ends)-1]
+	return f.fields(string(f.buf), last, 0), nil
+}
+
+// readQuoted appends the quoted field to buf, line starts after the opening quote.
+// It returns the rest of the line after the delimiter, or done if the record ends with the field.
+func (f *FastReader) readQuoted(line []byte) (rest []byte, done bool, err error) {
+	for {
+		i := bytes.IndexByte(line, '"')
+		if i < 0 {
+			// the field continues in the next line, an unterminated field ends at EOF.
+			f.buf = append(f.buf, line...)
+			line, err = f.readLine()
+			if err != nil && !errors.Is(err, io.EOF) {
+				return nil, true, err
+			}
+
+			if len(line) == 0 {
+				return nil, true, nil
+			}
+
+			continue
+		}
+
+		f.buf = append(f.buf, line[:i]...)
+		line = line[i+1:]
+		switch {
+		case len(line) == 0 || line[0] == '\n':
+			return nil, true, nil
+		case line[0] == f.Comma:
+			return line[1:], false, nil
+		default:
+			// a doubled quote, or a quote in the middle of the field that is read as it is.
+			f.buf = append(f.buf, '"')
+			if line[0] == '"' {
+				line = line[1:]
+			}
+		}
+	}
+}
+
+// newRecord returns the slice for the fields of the record.
+func (f *FastReader) newRecord(fields int) []string {
+	if !f.ReuseRecord {
+		return make([]string, 0, fields)
+	}
+
+	if cap(f.record) < fields {
+		f.record = make([]string, 0, fields)
+	}
+
+	return f.record[:0]
+}
+
+// compile time check to ensure FastReader implements CsvReader.
+var _ CsvReader = (*FastReader)(nil)
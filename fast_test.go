@@ -0,0 +1,138 @@
+package csvprocessor_test
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestFastReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "unquoted", input: "id,name,amount\n1,alice,10\n2,bob,\n"},
+		{name: "no trailing new line", input: "a,b\nc,d"},
+		{name: "crlf and empty lines", input: "a,b\r\n\r\n\nc,d\r\n"},
+		{name: "quoted fields", input: "1,\"a,b\",\"say \"\"hi\"\"\"\n2,\"\",x\n"},
+		{name: "multi-line quoted field", input: "1,\"line1\r\nline2\nline3\",end\n2,x,y\n"},
+		{name: "lazy quotes", input: "a\"b,\"c\"d\",e\n"},
+		{name: "trailing delimiter after quoted field", input: "\"a\",\n"},
+		{name: "unterminated quote", input: "1,\"abc\n"},
+		{name: "bare CR at the end", input: "a,b\nc\rd,e\r"},
+		{name: "bare CR at the end of a quoted field", input: "a,\"b\"\r"},
+		{name: "bare CR as the last line", input: "a,b\n\r"},
+		{name: "long line", input: strings.Repeat("x", 10000) + ",\"" + strings.Repeat("y", 10000) + "\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			csvReader := csv.NewReader(strings.NewReader(tt.input))
+			csvReader.LazyQuotes = true
+			want := readAll(t, csvReader)
+
+			got := readAll(t, csvprocessor.NewFastReader(bufio.NewReaderSize(strings.NewReader(tt.input), 16), ','))
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("rows = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestFastReader_FieldCount(t *testing.T) {
+	reader := csvprocessor.NewFastReader(strings.NewReader("a,b\nc\n"), ',')
+	if _, err := reader.Read(); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	row, err := reader.Read()
+	if !errors.Is(err, csv.ErrFieldCount) || !reflect.DeepEqual(row, []string{"c"}) {
+		t.Errorf("Read() = %q, %v, want [c], %v", row, err, csv.ErrFieldCount)
+	}
+}
+
+func TestWithFastParser(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []csvprocessor.Option
+		want  string
+	}{
+		{
+			name:  "Test fast parser",
+			input: "id,name\n1,\"a,b\"\n2,c\n",
+			opts:  []csvprocessor.Option{csvprocessor.WithFastParser()},
+			want:  "id,name\n1,\"a,b\"\n2,c\n",
+		},
+		{
+			name:  "Test fast parser with semicolon",
+			input: "id;name\n1;a,b\n",
+			opts:  []csvprocessor.Option{csvprocessor.WithFastParser(), csvprocessor.WithInputDelimiter(';')},
+			want:  "id,name\n1,\"a,b\"\n",
+		},
+		{
+			name:  "Test multi-byte delimiter falls back to csv.Reader",
+			input: "id§name\n1§a\n",
+			opts:  []csvprocessor.Option{csvprocessor.WithFastParser(), csvprocessor.WithInputDelimiter('§')},
+			want:  "id,name\n1,a\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processFile(t, tt.input, tt.opts...)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkFastReader(b *testing.B) {
+	input := strings.Repeat("1,alice,alice@example.com,2023-04-05,10.50,NY\n2,bob,bob@example.com,2023-04-06,7.00,\"London, UK\"\n", 50_000)
+	readers := []struct {
+		name      string
+		newReader func(r io.Reader) csvprocessor.CsvReader
+	}{
+		{
+			name: "csv.Reader",
+			newReader: func(r io.Reader) csvprocessor.CsvReader {
+				reader := csv.NewReader(r)
+				reader.ReuseRecord = true
+				return reader
+			},
+		},
+		{
+			name: "FastReader",
+			newReader: func(r io.Reader) csvprocessor.CsvReader {
+				reader := csvprocessor.NewFastReader(r, ',')
+				reader.ReuseRecord = true
+				return reader
+			},
+		},
+	}
+
+	for _, rd := range readers {
+		b.Run(rd.name, func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				reader := rd.newReader(bufio.NewReaderSize(strings.NewReader(input), 64<<10))
+				for {
+					if _, err := reader.Read(); err != nil {
+						break
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package csvprocessor
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrFieldCount is returned (wrapped in a *RowError) for the rows rejected by FieldCountReject.
+var ErrFieldCount = errors.New("csvprocessor: wrong number of fields")
+
+// FieldCountMode represents how the rows with a different no. of fields than the header are handled.
+type FieldCountMode int
+
+const (
+	// FieldCountAny writes the rows as they are.
+	FieldCountAny FieldCountMode = iota
+	// FieldCountPad pads the short rows with empty fields, the long rows are written as they are.
+	FieldCountPad
+	// FieldCountTruncate drops the extra fields of the long rows, the short rows are written as they are.
+	FieldCountTruncate
+	// FieldCountPadTruncate pads the short rows and truncates the long rows.
+	FieldCountPadTruncate
+	// FieldCountReject rejects the rows, they are written to the rejects writer if set, else the processing fails.
+	FieldCountReject
+)
+
+// normalizeFieldCount pads or truncates the row to the given width based on the mode.
+// For FieldCountReject, an error is returned if the row does not have the given width.
+func normalizeFieldCount(mode FieldCountMode, row []string, width int) ([]string, error) {
+	switch {
+	case len(row) == width:
+		return row, nil
+	case mode == FieldCountReject:
+		return row, fmt.Errorf("%w: expected %d, got %d", ErrFieldCount, width, len(row))
+	case len(row) < width && (mode == FieldCountPad || mode == FieldCountPadTruncate):
+		return append(row, make([]string, width-len(row))...), nil
+	case len(row) > width && (mode == FieldCountTruncate || mode == FieldCountPadTruncate):
+		return row[:width], nil
+	}
+
+	return row, nil
+}
@@ -0,0 +1,98 @@
+package csvprocessor_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithNormalizeFieldCount(t *testing.T) {
+	const raggedCSV = "a,b,c\n1,2\n3,4,5\n6,7,8,9\n"
+
+	tests := []struct {
+		name        string
+		input       string
+		opt         []csvprocessor.Option
+		want        string
+		wantRejects string
+		wantErr     error
+	}{
+		{
+			name:  "Test any",
+			input: raggedCSV,
+			opt:   []csvprocessor.Option{csvprocessor.WithNormalizeFieldCount(csvprocessor.FieldCountAny)},
+			want:  "a,b,c\n1,2\n3,4,5\n6,7,8,9\n",
+		},
+		{
+			name:  "Test pad",
+			input: raggedCSV,
+			opt:   []csvprocessor.Option{csvprocessor.WithNormalizeFieldCount(csvprocessor.FieldCountPad)},
+			want:  "a,b,c\n1,2,\n3,4,5\n6,7,8,9\n",
+		},
+		{
+			name:  "Test truncate",
+			input: raggedCSV,
+			opt:   []csvprocessor.Option{csvprocessor.WithNormalizeFieldCount(csvprocessor.FieldCountTruncate)},
+			want:  "a,b,c\n1,2\n3,4,5\n6,7,8\n",
+		},
+		{
+			name:  "Test pad and truncate",
+			input: raggedCSV,
+			opt:   []csvprocessor.Option{csvprocessor.WithNormalizeFieldCount(csvprocessor.FieldCountPadTruncate)},
+			want:  "a,b,c\n1,2,\n3,4,5\n6,7,8\n",
+		},
+		{
+			name:  "Test pad without header",
+			input: "1,2,3\n4\n",
+			opt:   []csvprocessor.Option{csvprocessor.SkipHeaders(true), csvprocessor.WithNormalizeFieldCount(csvprocessor.FieldCountPad)},
+			want:  "1,2,3\n4,,\n",
+		},
+		{
+			name:        "Test reject",
+			input:       raggedCSV,
+			opt:         []csvprocessor.Option{csvprocessor.WithNormalizeFieldCount(csvprocessor.FieldCountReject)},
+			want:        "a,b,c\n3,4,5\n",
+			wantRejects: "a,b,c,reject_reason\n1,2,\"csvprocessor: row 1: csvprocessor: wrong number of fields: expected 3, got 2\"\n6,7,8,9,\"csvprocessor: row 3: csvprocessor: wrong number of fields: expected 3, got 4\"\n",
+		},
+		{
+			name:    "Test invalid mode",
+			input:   raggedCSV,
+			opt:     []csvprocessor.Option{csvprocessor.WithNormalizeFieldCount(csvprocessor.FieldCountMode(10))},
+			wantErr: csvprocessor.ErrInvalidFieldCountMode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rejects strings.Builder
+			rejectWriter := csv.NewWriter(&rejects)
+
+			output, err := processFile(t, tt.input, append(tt.opt, csvprocessor.WithRejectWriter(rejectWriter))...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+
+			if rejects.String() != tt.wantRejects {
+				t.Errorf("Process() rejects = %q, want %q", rejects.String(), tt.wantRejects)
+			}
+		})
+	}
+}
+
+func TestWithNormalizeFieldCount_RejectWithoutRejectWriter(t *testing.T) {
+	_, err := processFile(t, "a,b\n1\n", csvprocessor.WithNormalizeFieldCount(csvprocessor.FieldCountReject))
+	if !errors.Is(err, csvprocessor.ErrFieldCount) {
+		t.Errorf("Process() error = %v, want %v", err, csvprocessor.ErrFieldCount)
+	}
+
+	var rowErr *csvprocessor.RowError
+	if !errors.As(err, &rowErr) || rowErr.Row != 1 {
+		t.Errorf("Process() error = %v, want a *RowError for row 1", err)
+	}
+}
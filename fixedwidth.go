@@ -0,0 +1,307 @@
+package csvprocessor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidFixedWidthColumns is returned when no columns are given, or a column has a negative start or a width <= 0.
+var ErrInvalidFixedWidthColumns = errors.New("csvprocessor: fixed-width columns must have start >= 0 and width > 0")
+
+// FixedWidthColumn is a column of a fixed-width record.
+type FixedWidthColumn struct {
+	// Name is the column name used in the header.
+	Name string
+	// Start is the 0-based offset of the column in the line, in characters.
+	Start int
+	// Width is the no. of characters in the column.
+	Width int
+}
+
+// FixedWidthReader is a CsvReader that parses fixed-width (mainframe-style) records, one record per line.
+// The fields are sliced from each line using the column offsets and widths, and the surrounding spaces are trimmed.
+// If any column has a name, the first row returned is the header with the column names. Empty lines are skipped.
+type FixedWidthReader struct {
+	r       *bufio.Reader
+	columns []FixedWidthColumn
+
+	// Unexported fields
+	headerRead bool
+	record     []string
+	runes      []rune
+}
+
+// NewFixedWidthReader creates a FixedWidthReader with the given columns, see ValidateFixedWidthColumns().
+func NewFixedWidthReader(r io.Reader, columns ...FixedWidthColumn) *FixedWidthReader {
+	reader, ok := r.(*bufio.Reader)
+	if !ok {
+		reader = bufio.NewReaderSize(r, DefaultReadBufferSize)
+	}
+
+	return &FixedWidthReader{r: reader, columns: columns, record: make([]string, len(columns))}
+}
+
+// ValidateFixedWidthColumns returns ErrInvalidFixedWidthColumns if the columns are not valid, the columns can overlap.
+func ValidateFixedWidthColumns(columns []FixedWidthColumn) error {
+	if len(columns) == 0 {
+		return ErrInvalidFixedWidthColumns
+	}
+
+	for _, column := range columns {
+		if column.Start < 0 || column.Width <= 0 {
+			return ErrInvalidFixedWidthColumns
+		}
+	}
+
+	return nil
+}
+
+// Read returns the header first, if the columns have names, and then a row for each line.
+// The returned row is reused by the next call to Read.
+func (f *FixedWidthReader) Read() ([]string, error) {
+	if !f.headerRead {
+		f.headerRead = true
+		for _, column := range f.columns {
+			if column.Name != "" {
+				header := make([]string, len(f.columns))
+				for i, column := range f.columns {
+					header[i] = column.Name
+				}
+
+				return header, nil
+			}
+		}
+	}
+
+	for {
+		line, err := f.r.ReadString('\n')
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if line == "" {
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		f.parse(line)
+		return f.record, nil
+	}
+}
+
+func (f *FixedWidthReader) parse(line string) {
+	if !isASCII(line) {
+		// the offsets are in characters, so multi-byte lines are sliced as runes.
+		f.runes = append(f.runes[:0], []rune(line)...)
+		for i, column := range f.columns {
+			start, end := bounds(column, len(f.runes))
+			f.record[i] = strings.TrimSpace(string(f.runes[start:end]))
+		}
+
+		return
+	}
+
+	for i, column := range f.columns {
+		start, end := bounds(column, len(line))
+		f.record[i] = strings.TrimSpace(line[start:end])
+	}
+}
+
+// bounds returns the slice bounds of the column in a line of the given length.
+func bounds(column FixedWidthColumn, length int) (int, int) {
+	start, end := column.Start, column.Start+column.Width
+	if start > length {
+		start = length
+	}
+
+	if end > length {
+		end = length
+	}
+
+	return start, end
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ErrFieldTooWide is returned by FixedWidthWriter when a value is wider than its field and the field has OverflowError.
+var ErrFieldTooWide = errors.New("csvprocessor: value is wider than the fixed-width field")
+
+// Alignment is the alignment of a value in a fixed-width field.
+type Alignment int
+
+const (
+	// AlignLeft pads the value on the right.
+	AlignLeft Alignment = iota
+	// AlignRight pads the value on the left, Eg: for numbers.
+	AlignRight
+)
+
+// Overflow is the rule for values that are wider than their fixed-width field.
+type Overflow int
+
+const (
+	// OverflowTruncate drops the characters at the end of the value.
+	OverflowTruncate Overflow = iota
+	// OverflowTruncateStart drops the characters at the start of the value, Eg: to keep the last digits of a number.
+	OverflowTruncateStart
+	// OverflowError fails the write with ErrFieldTooWide.
+	OverflowError
+)
+
+// FixedWidthField is a field of the records written by FixedWidthWriter.
+type FixedWidthField struct {
+	// Width is the no. of characters in the field.
+	Width int
+	// Align is the alignment of the value in the field, the default is AlignLeft.
+	Align Alignment
+	// Pad is the character used to fill the field, the default is a space. Eg: '0' with AlignRight for zero padded numbers.
+	Pad rune
+	// Overflow is the rule for values wider than the field, the default is OverflowTruncate.
+	Overflow Overflow
+}
+
+// FixedWidthWriter is a CsvWriter that writes each row as a fixed-width record, the fields are written one after another without separators.
+// The missing fields are written as empty values and the extra fields are dropped.
+type FixedWidthWriter struct {
+	// Header controls whether the header row is written as a record, the default is false as fixed-width files rarely have one.
+	Header bool
+	// UseCRLF controls whether the records end with \r\n instead of \n.
+	UseCRLF bool
+
+	w      *bufio.Writer
+	fields []FixedWidthField
+
+	// Unexported fields
+	buf []byte
+	err error
+}
+
+// NewFixedWidthWriter creates a FixedWidthWriter with the given fields, see ValidateFixedWidthFields().
+func NewFixedWidthWriter(w io.Writer, fields ...FixedWidthField) *FixedWidthWriter {
+	return &FixedWidthWriter{w: bufio.NewWriter(w), fields: fields}
+}
+
+// ValidateFixedWidthFields returns ErrInvalidFixedWidthColumns if no fields are given or a field has a width <= 0.
+func ValidateFixedWidthFields(fields []FixedWidthField) error {
+	if len(fields) == 0 {
+		return ErrInvalidFixedWidthColumns
+	}
+
+	for _, field := range fields {
+		if field.Width <= 0 {
+			return ErrInvalidFixedWidthColumns
+		}
+	}
+
+	return nil
+}
+
+// WriteHeader writes the header as a record, if Header is true.
+func (f *FixedWidthWriter) WriteHeader(header []string) error {
+	if !f.Header {
+		return f.err
+	}
+
+	return f.Write(header)
+}
+
+// Write writes the row as a fixed-width record.
+func (f *FixedWidthWriter) Write(record []string) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	buf := f.buf[:0]
+	for i, field := range f.fields {
+		value := ""
+		if i < len(record) {
+			value = record[i]
+		}
+
+		var err error
+		if buf, err = appendFixedWidth(buf, value, field); err != nil {
+			return fmt.Errorf("%w: field %d %q", err, i+1, value)
+		}
+	}
+
+	if f.UseCRLF {
+		buf = append(buf, '\r')
+	}
+
+	f.buf = append(buf, '\n')
+	_, f.err = f.w.Write(f.buf)
+	return f.err
+}
+
+// appendFixedWidth appends the value padded or truncated to the width of the field.
+func appendFixedWidth(buf []byte, value string, field FixedWidthField) ([]byte, error) {
+	pad := field.Pad
+	if pad == 0 {
+		pad = ' '
+	}
+
+	length := utf8.RuneCountInString(value)
+	if length > field.Width {
+		switch field.Overflow {
+		case OverflowError:
+			return buf, ErrFieldTooWide
+		case OverflowTruncateStart:
+			value = value[runeOffset(value, length-field.Width):]
+		default:
+			value = value[:runeOffset(value, field.Width)]
+		}
+
+		length = field.Width
+	}
+
+	if field.Align == AlignLeft {
+		buf = append(buf, value...)
+	}
+
+	for ; length < field.Width; length++ {
+		buf = utf8.AppendRune(buf, pad)
+	}
+
+	if field.Align == AlignRight {
+		buf = append(buf, value...)
+	}
+
+	return buf, nil
+}
+
+// runeOffset returns the byte offset of the n-th character of the value.
+func runeOffset(value string, n int) int {
+	for i := range value {
+		if n == 0 {
+			return i
+		}
+
+		n--
+	}
+
+	return len(value)
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (f *FixedWidthWriter) Flush() {
+	if f.err == nil {
+		f.err = f.w.Flush()
+	}
+}
+
+// Error reports any error that has occurred during a previous Write or Flush.
+func (f *FixedWidthWriter) Error() error {
+	return f.err
+}
@@ -0,0 +1,165 @@
+package csvprocessor_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+var fixedWidthColumns = []csvprocessor.FixedWidthColumn{
+	{Name: "id", Start: 0, Width: 4},
+	{Name: "name", Start: 4, Width: 10},
+	{Name: "amount", Start: 14, Width: 8},
+}
+
+func TestFixedWidthReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		columns []csvprocessor.FixedWidthColumn
+		want    [][]string
+	}{
+		{
+			name:    "with header",
+			input:   "0001alice     00012.50\r\n0002bob       00007.00\r\n",
+			columns: fixedWidthColumns,
+			want:    [][]string{{"id", "name", "amount"}, {"0001", "alice", "00012.50"}, {"0002", "bob", "00007.00"}},
+		},
+		{
+			name:    "without header",
+			input:   "0001alice     00012.50\n\n0002bob\n",
+			columns: []csvprocessor.FixedWidthColumn{{Start: 0, Width: 4}, {Start: 4, Width: 10}, {Start: 14, Width: 8}},
+			want:    [][]string{{"0001", "alice", "00012.50"}, {"0002", "bob", ""}},
+		},
+		{
+			name:    "multi-byte characters",
+			input:   "0003zoë       00001.25",
+			columns: fixedWidthColumns,
+			want:    [][]string{{"id", "name", "amount"}, {"0003", "zoë", "00001.25"}},
+		},
+		{
+			name:    "overlapping and unordered columns",
+			input:   "20230405",
+			columns: []csvprocessor.FixedWidthColumn{{Name: "month", Start: 4, Width: 2}, {Name: "date", Start: 0, Width: 8}},
+			want:    [][]string{{"month", "date"}, {"04", "20230405"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readAll(t, csvprocessor.NewFixedWidthReader(strings.NewReader(tt.input), tt.columns...))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithFixedWidthInput(t *testing.T) {
+	got, err := processFile(t, "0001alice     00012.50\n0002bob       00007.00\n", csvprocessor.WithFixedWidthInput(fixedWidthColumns...))
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	want := "id,name,amount\n0001,alice,00012.50\n0002,bob,00007.00\n"
+	if got != want {
+		t.Errorf("Process() output = %q, want %q", got, want)
+	}
+
+	for _, columns := range [][]csvprocessor.FixedWidthColumn{nil, {{Start: -1, Width: 2}}, {{Start: 0, Width: 0}}} {
+		if _, err := csvprocessor.New(csvprocessor.WithStdin(), csvprocessor.WithFixedWidthInput(columns...)); !errors.Is(err, csvprocessor.ErrInvalidFixedWidthColumns) {
+			t.Errorf("New(%v) error = %v, want %v", columns, err, csvprocessor.ErrInvalidFixedWidthColumns)
+		}
+	}
+}
+
+func TestFixedWidthWriter(t *testing.T) {
+	fields := []csvprocessor.FixedWidthField{
+		{Width: 4, Align: csvprocessor.AlignRight, Pad: '0'},
+		{Width: 6},
+		{Width: 5, Align: csvprocessor.AlignRight, Overflow: csvprocessor.OverflowTruncateStart},
+	}
+
+	tests := []struct {
+		name    string
+		fields  []csvprocessor.FixedWidthField
+		header  bool
+		useCRLF bool
+		rows    [][]string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "padding and truncation",
+			rows: [][]string{{"1", "alice", "12.5"}, {"22", "zoë", "123456.75"}, {"3", "bartholomew"}, {"4", "x", "1", "extra"}},
+			want: "0001alice  12.5\n" + "0022zoë   56.75\n" + "0003bartho     \n" + "0004x         1\n",
+		},
+		{
+			name:    "header and CRLF",
+			header:  true,
+			useCRLF: true,
+			rows:    [][]string{{"1", "alice", "2"}},
+			want:    "0 idname  total\r\n0001alice     2\r\n",
+		},
+		{
+			name:    "overflow error",
+			fields:  []csvprocessor.FixedWidthField{{Width: 4, Overflow: csvprocessor.OverflowError}},
+			rows:    [][]string{{"123"}, {"12345"}},
+			wantErr: csvprocessor.ErrFieldTooWide,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output strings.Builder
+			if tt.fields == nil {
+				tt.fields = fields
+			}
+
+			writer := csvprocessor.NewFixedWidthWriter(&output, tt.fields...)
+			writer.Header = tt.header
+			writer.UseCRLF = tt.useCRLF
+
+			err := writer.WriteHeader([]string{" id", "name", "total"})
+			for _, row := range tt.rows {
+				if err == nil {
+					err = writer.Write(row)
+				}
+			}
+
+			writer.Flush()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Write() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && output.String() != tt.want {
+				t.Errorf("output = %q, want %q", output.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWithFixedWidthOutput(t *testing.T) {
+	got, err := processString(t, "id,name\n1,alice\n2,bob\n",
+		csvprocessor.WithFixedWidthOutput(
+			csvprocessor.FixedWidthField{Width: 3, Align: csvprocessor.AlignRight, Pad: '0'},
+			csvprocessor.FixedWidthField{Width: 8},
+		),
+		csvprocessor.WithCRLF(true),
+	)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	want := "001alice   \r\n002bob     \r\n"
+	if got != want {
+		t.Errorf("Process() output = %q, want %q", got, want)
+	}
+
+	if _, err := csvprocessor.New(csvprocessor.WithStdin(), csvprocessor.WithFixedWidthOutput(csvprocessor.FixedWidthField{})); !errors.Is(err, csvprocessor.ErrInvalidFixedWidthColumns) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidFixedWidthColumns)
+	}
+}
@@ -0,0 +1,132 @@
+package csvprocessor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidFooterPolicy is returned for an unknown FooterPolicy.
+var ErrInvalidFooterPolicy = errors.New("csvprocessor: invalid footer policy")
+
+// FooterPolicy represents how the footer rows at the end of the input are handled, see WithFooterRows().
+type FooterPolicy int
+
+const (
+	// FooterDrop drops the footer rows.
+	FooterDrop FooterPolicy = iota
+	// FooterKeep writes the footer rows as they are at the end of the last chunk, after the data rows.
+	FooterKeep
+)
+
+// WithFooterRows treats the last n rows of each input as footer rows, Eg: the "TOTAL,12345" summary rows of finance exports.
+// The footer rows are not counted or processed as data rows, they are either dropped or written at the end of the last chunk
+// based on the policy, and are available in Stats.Footer. The transformers are not applied to the footer rows.
+// It applies to the inputs read by the processor and to the CsvReader set using WithReader().
+func WithFooterRows(n int, policy FooterPolicy) Option {
+	return func(c *Processor) error {
+		if n < 0 {
+			return ErrInvalidRowCount
+		}
+
+		if policy < FooterDrop || policy > FooterKeep {
+			return ErrInvalidFooterPolicy
+		}
+
+		c.footerRows = n
+		c.footerPolicy = policy
+		return nil
+	}
+}
+
+// footerReader is a CsvReader that holds back the last n rows of the underlying reader and passes them to onFooter at the end.
+// The returned row is valid till the next call to Read.
+type footerReader struct {
+	reader    CsvReader
+	size      int
+	hasHeader bool
+	onFooter  func(footer [][]string)
+
+	headerRead bool
+	ring       []footerEntry // rows read ahead, the last size rows at the end are the footer.
+	next       int           // index of the oldest row in ring.
+	count      int           // no. of rows in ring.
+	done       bool
+}
+
+// footerEntry is a row read ahead along with the error returned with it, Eg: a *csv.ParseError for a wrong no. of fields.
+type footerEntry struct {
+	row []string
+	err error
+}
+
+func newFooterReader(reader CsvReader, size int, hasHeader bool, onFooter func([][]string)) *footerReader {
+	return &footerReader{reader: reader, size: size, hasHeader: hasHeader, onFooter: onFooter, ring: make([]footerEntry, size+1)}
+}
+
+func (f *footerReader) Read() ([]string, error) {
+	if f.hasHeader && !f.headerRead {
+		f.headerRead = true
+		return f.reader.Read()
+	}
+
+	for !f.done && f.count < len(f.ring) {
+		row, err := f.reader.Read()
+		if errors.Is(err, io.EOF) {
+			f.done = true
+			f.footer()
+			break
+		}
+
+		if row == nil {
+			// the errors without a row are returned as they are, Eg: ErrNoMessage for an idle stream.
+			return nil, err
+		}
+
+		// readers can reuse the row slice, so the row is copied to the slot reused for every len(ring) rows.
+		slot := &f.ring[(f.next+f.count)%len(f.ring)]
+		slot.row = append(slot.row[:0], row...)
+		slot.err = err
+		f.count++
+	}
+
+	if f.count <= f.size {
+		return nil, io.EOF
+	}
+
+	entry := f.ring[f.next]
+	f.next = (f.next + 1) % len(f.ring)
+	f.count--
+	return entry.row, entry.err
+}
+
+// footer passes the rows read ahead at the end of the input to onFooter.
+func (f *footerReader) footer() {
+	footer := make([][]string, 0, f.count)
+	for i := 0; i < f.count; i++ {
+		footer = append(footer, append([]string(nil), f.ring[(f.next+i)%len(f.ring)].row...))
+	}
+
+	f.count = 0
+	f.onFooter(footer)
+}
+
+// addFooter records the footer rows of an input.
+func (c *Processor) addFooter(footer [][]string) {
+	c.footer = append(c.footer, footer...)
+}
+
+// writeFooter writes the footer rows at the end of the last chunk, if FooterKeep is used.
+func (r *run) writeFooter() error {
+	if r.c.footerPolicy != FooterKeep || r.fileWriter == nil {
+		return nil
+	}
+
+	for _, row := range r.c.footer {
+		if err := r.fileWriter.Write(row); err != nil {
+			return fmt.Errorf("csvprocessor: error while writing footer row: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package csvprocessor_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithFooterRows(t *testing.T) {
+	input := "id,amount\n1,10\n2,20\n3,30\nTOTAL,60\nROWS,3\n"
+
+	tests := []struct {
+		name    string
+		opts    []csvprocessor.Option
+		want    string
+		wantErr error
+	}{
+		{
+			name: "Test drop footer rows",
+			opts: []csvprocessor.Option{csvprocessor.WithFooterRows(2, csvprocessor.FooterDrop)},
+			want: "id,amount\n1,10\n2,20\n3,30\n",
+		},
+		{
+			name: "Test keep footer rows",
+			opts: []csvprocessor.Option{csvprocessor.WithFooterRows(2, csvprocessor.FooterKeep)},
+			want: "id,amount\n1,10\n2,20\n3,30\nTOTAL,60\nROWS,3\n",
+		},
+		{
+			name: "Test transformers are not applied to footer rows",
+			opts: []csvprocessor.Option{
+				csvprocessor.WithFooterRows(1, csvprocessor.FooterKeep),
+				csvprocessor.WithTransformer(csvprocessor.AddRowNoTransformer("row")),
+			},
+			want: "row,id,amount\n1,1,10\n2,2,20\n3,3,30\n4,TOTAL,60\nROWS,3\n",
+		},
+		{
+			name: "Test footer rows without header",
+			opts: []csvprocessor.Option{csvprocessor.WithFooterRows(3, csvprocessor.FooterDrop), csvprocessor.SkipHeaders(true)},
+			want: "id,amount\n1,10\n2,20\n",
+		},
+		{
+			name: "Test footer longer than input",
+			opts: []csvprocessor.Option{csvprocessor.WithFooterRows(10, csvprocessor.FooterDrop)},
+			want: "id,amount\n",
+		},
+		{
+			name:    "Test invalid row count",
+			opts:    []csvprocessor.Option{csvprocessor.WithFooterRows(-1, csvprocessor.FooterDrop)},
+			wantErr: csvprocessor.ErrInvalidRowCount,
+		},
+		{
+			name:    "Test invalid policy",
+			opts:    []csvprocessor.Option{csvprocessor.WithFooterRows(1, csvprocessor.FooterPolicy(5))},
+			wantErr: csvprocessor.ErrInvalidFooterPolicy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processString(t, input, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithFooterRows_Stats(t *testing.T) {
+	var output strings.Builder
+	proc, err := csvprocessor.New(
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader("id,amount\n1,10\n2,20\nTOTAL,30\n"))),
+		csvprocessor.WithWriterGenerator(func(i int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(&output), nil
+		}),
+		csvprocessor.WithChunkSize(math.MaxInt32),
+		csvprocessor.WithFooterRows(1, csvprocessor.FooterDrop),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	stats := proc.Stats()
+	if stats.RowsRead != 2 || stats.RowsWritten != 2 {
+		t.Errorf("Stats() rows read = %d, written = %d, want 2, 2", stats.RowsRead, stats.RowsWritten)
+	}
+
+	if want := [][]string{{"TOTAL", "30"}}; !reflect.DeepEqual(stats.Footer, want) {
+		t.Errorf("Stats().Footer = %v, want %v", stats.Footer, want)
+	}
+}
@@ -0,0 +1,152 @@
+// Package gcs reads the input from and streams the output chunks to Google Cloud Storage.
+//
+// Client is the subset of the storage API used by the package, a thin wrapper of cloud.google.com/go/storage implements it,
+// Eg: with ObjectHandle.NewReader and ObjectHandle.NewWriter.
+package gcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// DefaultContentType is the content type of the chunks, unless changed using WithContentType().
+const DefaultContentType = "text/csv"
+
+// ErrInvalidURL is returned for URLs that are not of the form gs://bucket/object.
+var ErrInvalidURL = errors.New("gcs: URL must be of the form gs://bucket/object")
+
+// Client represents the GCS operations used by this package.
+type Client interface {
+	// NewReader returns a reader of the content of the object.
+	NewReader(ctx context.Context, bucket, object string) (io.ReadCloser, error)
+	// NewWriter returns a writer that uploads the object with the given content type, the upload completes on Close.
+	// The upload must be cancelled when ctx is cancelled, like storage.Writer.
+	NewWriter(ctx context.Context, bucket, object, contentType string) (io.WriteCloser, error)
+}
+
+// ParseURL returns the bucket and the object name of a gs://bucket/object URL.
+func ParseURL(url string) (string, string, error) {
+	path := strings.TrimPrefix(url, "gs://")
+	bucket, object, found := strings.Cut(path, "/")
+	if path == url || !found || bucket == "" || object == "" {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidURL, url)
+	}
+
+	return bucket, object, nil
+}
+
+// GCSFileReader sets the GCS object at the gs://bucket/object URL as the input of the processor,
+// the object is streamed without downloading it to disk.
+// The object is opened at the first read, so that it is not left open if the processor is not created or run.
+func GCSFileReader(client Client, url string) csvprocessor.Option { //nolint:revive
+	return func(c *csvprocessor.Processor) error {
+		bucket, name, err := ParseURL(url)
+		if err != nil {
+			return err
+		}
+
+		return csvprocessor.WithInputReader(&object{client: client, bucket: bucket, name: name})(c)
+	}
+}
+
+// object is the content of a GCS object, it is opened at the first read.
+type object struct {
+	client       Client
+	bucket, name string
+
+	// Unexported fields
+	reader io.ReadCloser
+	err    error
+}
+
+func (o *object) Read(p []byte) (int, error) {
+	if o.reader == nil && o.err == nil {
+		o.reader, o.err = o.client.NewReader(context.Background(), o.bucket, o.name)
+	}
+
+	if o.err != nil {
+		return 0, o.err
+	}
+
+	return o.reader.Read(p)
+}
+
+// Close closes the object if it was opened.
+func (o *object) Close() error {
+	if o.reader == nil {
+		return nil
+	}
+
+	return o.reader.Close()
+}
+
+// ChunkOption configures the objects written by GCSChunkGenerator.
+type ChunkOption func(*chunkConfig)
+
+type chunkConfig struct {
+	contentType string
+	objectName  func(chunkID int) string
+}
+
+// WithContentType sets the content type of the chunks, the default is DefaultContentType.
+func WithContentType(contentType string) ChunkOption {
+	return func(c *chunkConfig) {
+		c.contentType = contentType
+	}
+}
+
+// WithObjectNamer sets the function that returns the object name of each chunk, it overrides the name format.
+func WithObjectNamer(objectName func(chunkID int) string) ChunkOption {
+	return func(c *chunkConfig) {
+		c.objectName = objectName
+	}
+}
+
+// GCSChunkGenerator returns an OutputChunkGenerator that streams each chunk to a GCS object as it is produced.
+// The object name of each chunk is generated from nameFormat using csvprocessor.ChunkName(), Eg: "exports/orders_%03d.csv".
+// The upload completes when the chunk is closed, and is cancelled if the processing fails.
+func GCSChunkGenerator(client Client, bucket, nameFormat string, opts ...ChunkOption) csvprocessor.OutputChunkGenerator { //nolint:revive
+	config := chunkConfig{
+		contentType: DefaultContentType,
+		objectName: func(chunkID int) string {
+			return csvprocessor.ChunkName(nameFormat, chunkID)
+		},
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return func(chunkID int) (io.WriteCloser, error) {
+		ctx, cancel := context.WithCancel(context.Background())
+		writer, err := client.NewWriter(ctx, bucket, config.objectName(chunkID), config.contentType)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		return &objectWriter{WriteCloser: writer, cancel: cancel}, nil
+	}
+}
+
+// objectWriter cancels the upload when aborted.
+type objectWriter struct {
+	io.WriteCloser
+	cancel context.CancelFunc
+}
+
+func (o *objectWriter) Close() error {
+	defer o.cancel()
+	return o.WriteCloser.Close()
+}
+
+// Abort cancels the upload, the processor calls it when the processing fails.
+func (o *objectWriter) Abort() {
+	o.cancel()
+	_ = o.WriteCloser.Close()
+}
@@ -0,0 +1,192 @@
+package gcs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+	"github.com/sivaramasubramanian/csvprocessor/gcs"
+)
+
+// fakeGCS is an in-memory Client, the objects are stored with their content type.
+type fakeGCS struct {
+	mu      sync.Mutex
+	objects map[string]string
+	reads   int
+}
+
+func (f *fakeGCS) NewReader(_ context.Context, bucket, object string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.reads++
+	content, ok := f.objects[bucket+"/"+object]
+	if !ok {
+		return nil, fmt.Errorf("object not found: %s/%s", bucket, object)
+	}
+
+	return io.NopCloser(bytes.NewBufferString(content)), nil
+}
+
+func (f *fakeGCS) NewWriter(ctx context.Context, bucket, object, contentType string) (io.WriteCloser, error) {
+	return &fakeWriter{ctx: ctx, gcs: f, name: bucket + "/" + object + " (" + contentType + ")"}, nil
+}
+
+type fakeWriter struct {
+	bytes.Buffer
+	ctx  context.Context
+	gcs  *fakeGCS
+	name string
+}
+
+func (w *fakeWriter) Close() error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+
+	w.gcs.mu.Lock()
+	defer w.gcs.mu.Unlock()
+	w.gcs.objects[w.name] = w.String()
+	return nil
+}
+
+func TestGCS(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []gcs.ChunkOption
+		want map[string]string
+	}{
+		{
+			name: "Test default options",
+			want: map[string]string{
+				"out/part_1.csv (text/csv)": "id\n1\n2\n",
+				"out/part_2.csv (text/csv)": "id\n3\n",
+			},
+		},
+		{
+			name: "Test content type and object namer",
+			opts: []gcs.ChunkOption{
+				gcs.WithContentType("application/octet-stream"),
+				gcs.WithObjectNamer(func(chunkID int) string { return fmt.Sprintf("dt=2023-01-01/%d", chunkID) }),
+			},
+			want: map[string]string{
+				"out/dt=2023-01-01/1 (application/octet-stream)": "id\n1\n2\n",
+				"out/dt=2023-01-01/2 (application/octet-stream)": "id\n3\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeGCS{objects: map[string]string{"in/orders.csv": "id\n1\n2\n3\n"}}
+			proc, err := csvprocessor.New(
+				gcs.GCSFileReader(client, "gs://in/orders.csv"),
+				csvprocessor.WithWriterGenerator(gcs.GCSChunkGenerator(client, "out", "part_%d.csv", tt.opts...)),
+				csvprocessor.WithChunkSize(2),
+			)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if err := proc.Process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			delete(client.objects, "in/orders.csv")
+			if !reflect.DeepEqual(client.objects, tt.want) {
+				t.Errorf("objects = %q, want %q", client.objects, tt.want)
+			}
+		})
+	}
+}
+
+func TestGCS_Abort(t *testing.T) {
+	client := &fakeGCS{objects: map[string]string{"in/orders.csv": "id\n1\n2\n3\n"}}
+	errBadRow := errors.New("bad row")
+
+	proc, err := csvprocessor.New(
+		gcs.GCSFileReader(client, "gs://in/orders.csv"),
+		csvprocessor.WithWriterGenerator(gcs.GCSChunkGenerator(client, "out", "part_%d.csv")),
+		csvprocessor.WithChunkSize(10),
+		csvprocessor.WithTransformer(func(ctx context.Context, row []string) []string {
+			if row[0] == "3" {
+				panic(errBadRow)
+			}
+
+			return row
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); !errors.Is(err, errBadRow) {
+		t.Fatalf("Process() error = %v, want %v", err, errBadRow)
+	}
+
+	if len(client.objects) != 1 {
+		t.Errorf("objects = %q, want no chunks", client.objects)
+	}
+}
+
+func TestGCSFileReader_Error(t *testing.T) {
+	client := &fakeGCS{objects: map[string]string{}}
+	proc, err := csvprocessor.New(
+		gcs.GCSFileReader(client, "gs://in/missing.csv"),
+		csvprocessor.WithWriterGenerator(gcs.GCSChunkGenerator(client, "out", "part_%d.csv")),
+		csvprocessor.WithChunkSize(2),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err == nil {
+		t.Errorf("Process() error = nil, want an error for missing object")
+	}
+
+	// the object is not opened if the processor is not created.
+	client = &fakeGCS{objects: map[string]string{"in/orders.csv": "id\n1\n"}}
+	if _, err := csvprocessor.New(gcs.GCSFileReader(client, "gs://in/orders.csv")); err == nil {
+		t.Errorf("New() error = nil, want an error for the missing generator")
+	}
+
+	if client.reads != 0 {
+		t.Errorf("NewReader() called %d times, want 0", client.reads)
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		url        string
+		wantBucket string
+		wantObject string
+		wantErr    bool
+	}{
+		{url: "gs://bucket/dir/object.csv", wantBucket: "bucket", wantObject: "dir/object.csv"},
+		{url: "bucket/object.csv", wantErr: true},
+		{url: "gs://bucket", wantErr: true},
+		{url: "gs:///object.csv", wantErr: true},
+		{url: "gs://bucket/", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			bucket, object, err := gcs.ParseURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && !errors.Is(err, gcs.ErrInvalidURL) {
+				t.Errorf("ParseURL() error = %v, want %v", err, gcs.ErrInvalidURL)
+			}
+
+			if bucket != tt.wantBucket || object != tt.wantObject {
+				t.Errorf("ParseURL() = %q, %q, want %q, %q", bucket, object, tt.wantBucket, tt.wantObject)
+			}
+		})
+	}
+}
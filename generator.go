@@ -0,0 +1,283 @@
+package csvprocessor
+
+import (
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidGenerator is returned when the generator has no columns, a column without a ValueGenerator, or a negative no. of rows.
+var ErrInvalidGenerator = errors.New("csvprocessor: generator needs columns with a ValueGenerator and no. of rows >= 0")
+
+// ValueGenerator returns a value of a column, rowNum is the 1-based no. of the data row.
+// The values must be generated using random, so that the rows are reproducible for the same Generator.Seed.
+type ValueGenerator func(random *rand.Rand, rowNum int) string
+
+// GeneratedColumn is a column of the rows generated by a Generator.
+type GeneratedColumn struct {
+	Name     string
+	Generate ValueGenerator
+}
+
+// Generator generates rows of synthetic data, Eg: for test fixtures. It is a CsvReader, so the rows can be written
+// using the processor, Eg: to write 10 million rows in chunks of 1 million rows:
+//
+//	generator := csvprocessor.NewGenerator(schema, 10_000_000, 42)
+//	proc, err := csvprocessor.New(
+//		csvprocessor.WithReader(generator),
+//		csvprocessor.WithOutputFileFormat("fixture_%03d.csv"),
+//		csvprocessor.WithChunkSize(1_000_000),
+//	)
+//
+// The first row is the header with the names of the columns, unless NoHeader is true.
+type Generator struct {
+	// Columns are the columns of the rows, see GeneratorForColumn() for the generators of the schema columns.
+	Columns []GeneratedColumn
+	// Rows is the no. of data rows.
+	Rows int
+	// Seed of the random values, the same seed generates the same rows.
+	Seed int64
+	// NoHeader skips the header row.
+	NoHeader bool
+
+	// Unexported fields
+	random     *rand.Rand
+	headerRead bool
+	rowNum     int
+	record     []string
+}
+
+// NewGenerator creates a Generator of rows for the columns of the schema, see GeneratorForColumn().
+func NewGenerator(schema Schema, rows int, seed int64) *Generator {
+	generator := &Generator{Rows: rows, Seed: seed}
+	for _, column := range schema.Columns {
+		generator.Columns = append(generator.Columns, GeneratedColumn{Name: column.Name, Generate: GeneratorForColumn(column)})
+	}
+
+	return generator
+}
+
+// Read returns the next generated row, the returned slice is reused by the next call.
+func (g *Generator) Read() ([]string, error) {
+	if g.random == nil {
+		if len(g.Columns) == 0 || g.Rows < 0 {
+			return nil, ErrInvalidGenerator
+		}
+
+		for _, column := range g.Columns {
+			if column.Generate == nil {
+				return nil, ErrInvalidGenerator
+			}
+		}
+
+		g.random = rand.New(rand.NewSource(g.Seed)) //nolint:gosec
+	}
+
+	g.record = g.record[:0]
+	if !g.NoHeader && !g.headerRead {
+		g.headerRead = true
+		for _, column := range g.Columns {
+			g.record = append(g.record, column.Name)
+		}
+
+		return g.record, nil
+	}
+
+	if g.rowNum >= g.Rows {
+		return nil, io.EOF
+	}
+
+	g.rowNum++
+	for _, column := range g.Columns {
+		g.record = append(g.record, column.Generate(g.random, g.rowNum))
+	}
+
+	return g.record, nil
+}
+
+// optionalRate is the fraction of empty values generated for the columns that are not required.
+const optionalRate = 0.05
+
+// GeneratorForColumn returns a generator of valid values for the column:
+//   - the values of Enum, if it is not empty.
+//   - TypeInt: the row no. if the name is "id" or ends with "_id", else integers from 0 to 100,000.
+//   - TypeFloat: numbers from 0 to 10,000 with 2 decimal places.
+//   - TypeBool: true or false.
+//   - TypeTime: timestamps from 2020 to 2024 in the Layout (time.RFC3339 by default).
+//   - TypeString: fake names, emails, phone numbers or cities if the name has "name", "email", "phone" or "city", else words.
+//
+// The strings of TypeString are at most MaxLength characters long, and 5% of the values are empty if the column is not required.
+// Pattern is not used, so the values may not match it.
+func GeneratorForColumn(column ColumnSchema) ValueGenerator {
+	name := strings.ToLower(column.Name)
+	var generator ValueGenerator
+	switch {
+	case len(column.Enum) > 0:
+		generator = EnumGenerator(column.Enum...)
+	case column.Type == TypeInt && (name == "id" || strings.HasSuffix(name, "_id")):
+		generator = SequenceGenerator(1)
+	case column.Type == TypeInt:
+		generator = IntGenerator(0, 100_000)
+	case column.Type == TypeFloat:
+		generator = FloatGenerator(0, 10_000, 2)
+	case column.Type == TypeBool:
+		generator = EnumGenerator("true", "false")
+	case column.Type == TypeTime:
+		layout := column.Layout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+
+		generator = TimeGenerator(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), layout)
+	case strings.Contains(name, "email"):
+		generator = EmailGenerator()
+	case strings.Contains(name, "phone"):
+		generator = PhoneGenerator()
+	case strings.Contains(name, "city"):
+		generator = EnumGenerator(cities...)
+	case strings.Contains(name, "name"):
+		generator = NameGenerator()
+	default:
+		generator = WordsGenerator(column.MaxLength)
+	}
+
+	if column.Type == TypeString && column.MaxLength > 0 {
+		generator = truncateGenerator(generator, column.MaxLength)
+	}
+
+	if !column.Required {
+		generator = OptionalGenerator(generator, optionalRate)
+	}
+
+	return generator
+}
+
+// SequenceGenerator returns the row no. offset by start - 1, Eg: start, start+1, start+2...
+func SequenceGenerator(start int) ValueGenerator {
+	return func(_ *rand.Rand, rowNum int) string {
+		return strconv.Itoa(start + rowNum - 1)
+	}
+}
+
+// IntGenerator returns random integers from min to max (which must be >= min), both inclusive.
+func IntGenerator(min, max int) ValueGenerator {
+	return func(random *rand.Rand, _ int) string {
+		return strconv.Itoa(min + random.Intn(max-min+1))
+	}
+}
+
+// FloatGenerator returns random numbers from min to max with the given no. of decimal places.
+func FloatGenerator(min, max float64, precision int) ValueGenerator {
+	scale := math.Pow(10, float64(precision))
+	return func(random *rand.Rand, _ int) string {
+		value := math.Round((min+random.Float64()*(max-min))*scale) / scale
+		return strconv.FormatFloat(value, 'f', precision, 64)
+	}
+}
+
+// EnumGenerator returns one of the values at random.
+func EnumGenerator(values ...string) ValueGenerator {
+	return func(random *rand.Rand, _ int) string {
+		return values[random.Intn(len(values))]
+	}
+}
+
+// TimeGenerator returns random timestamps from from to to (which must be after from), formatted using the layout.
+func TimeGenerator(from, to time.Time, layout string) ValueGenerator {
+	seconds := to.Unix() - from.Unix()
+	return func(random *rand.Rand, _ int) string {
+		return from.Add(time.Duration(random.Int63n(seconds)) * time.Second).Format(layout)
+	}
+}
+
+// NameGenerator returns fake full names, Eg: "Maya K. Fischer".
+func NameGenerator() ValueGenerator {
+	return func(random *rand.Rand, _ int) string {
+		return firstNames[random.Intn(len(firstNames))] + " " + string(rune('A'+random.Intn(26))) + ". " + lastNames[random.Intn(len(lastNames))]
+	}
+}
+
+// EmailGenerator returns fake email addresses at example.com, Eg: "maya.fischer42@example.com".
+func EmailGenerator() ValueGenerator {
+	return func(random *rand.Rand, _ int) string {
+		return strings.ToLower(firstNames[random.Intn(len(firstNames))]+"."+lastNames[random.Intn(len(lastNames))]) +
+			strconv.Itoa(random.Intn(1000)) + "@example.com"
+	}
+}
+
+// PhoneGenerator returns fake phone numbers in the range reserved for fiction, Eg: "+1-555-0142".
+func PhoneGenerator() ValueGenerator {
+	return func(random *rand.Rand, _ int) string {
+		return "+1-555-01" + strconv.Itoa(10+random.Intn(90))
+	}
+}
+
+// WordsGenerator returns random words of lorem ipsum text, at most maxLength characters long (20 if maxLength is 0).
+func WordsGenerator(maxLength int) ValueGenerator {
+	if maxLength <= 0 {
+		maxLength = 20
+	}
+
+	return func(random *rand.Rand, _ int) string {
+		var b strings.Builder
+		for {
+			word := loremWords[random.Intn(len(loremWords))]
+			if b.Len() == 0 && len(word) > maxLength {
+				return word[:maxLength]
+			}
+
+			if b.Len() > 0 && b.Len()+1+len(word) > maxLength {
+				break
+			}
+
+			if b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+
+			b.WriteString(word)
+			if random.Intn(3) == 0 {
+				break
+			}
+		}
+
+		return b.String()
+	}
+}
+
+// OptionalGenerator returns empty values at the given rate (Eg: 0.05), and the values of the generator otherwise.
+func OptionalGenerator(generator ValueGenerator, emptyRate float64) ValueGenerator {
+	return func(random *rand.Rand, rowNum int) string {
+		if random.Float64() < emptyRate {
+			return ""
+		}
+
+		return generator(random, rowNum)
+	}
+}
+
+// truncateGenerator limits the values of the generator to maxLength characters.
+func truncateGenerator(generator ValueGenerator, maxLength int) ValueGenerator {
+	return func(random *rand.Rand, rowNum int) string {
+		value := generator(random, rowNum)
+		if len(value) <= maxLength {
+			return value
+		}
+
+		return string([]rune(value)[:maxLength])
+	}
+}
+
+var cities = []string{
+	"Amsterdam", "Austin", "Bangalore", "Berlin", "Cape Town", "Chennai", "Chicago", "Dublin", "Lagos", "Lisbon",
+	"London", "Madrid", "Melbourne", "Mexico City", "Mumbai", "Nairobi", "New York", "Osaka", "Paris", "Seoul",
+	"Singapore", "Sao Paulo", "Tokyo", "Toronto", "Vancouver",
+}
+
+var loremWords = []string{
+	"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do", "eiusmod", "tempor",
+	"incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim", "ad", "minim", "veniam", "quis",
+}
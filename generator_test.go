@@ -0,0 +1,106 @@
+package csvprocessor_test
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+var generatorSchema = csvprocessor.Schema{Columns: []csvprocessor.ColumnSchema{
+	{Name: "id", Type: csvprocessor.TypeInt, Required: true},
+	{Name: "name", Type: csvprocessor.TypeString, Required: true, MaxLength: 30},
+	{Name: "email", Type: csvprocessor.TypeString},
+	{Name: "amount", Type: csvprocessor.TypeFloat, Required: true},
+	{Name: "active", Type: csvprocessor.TypeBool, Required: true},
+	{Name: "created", Type: csvprocessor.TypeTime, Layout: "2006-01-02", Required: true},
+	{Name: "status", Enum: []string{"new", "paid"}, Required: true},
+	{Name: "note", Type: csvprocessor.TypeString, Required: true, MaxLength: 8},
+}}
+
+func TestGenerator(t *testing.T) {
+	output, err := processString(t, "",
+		csvprocessor.WithReader(csvprocessor.NewGenerator(generatorSchema, 500, 42)),
+		csvprocessor.WithSchemaValidation(generatorSchema),
+	)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	if len(lines) != 501 || lines[0] != "id,name,email,amount,active,created,status,note" {
+		t.Fatalf("Process() output has %d lines, header %q", len(lines), lines[0])
+	}
+
+	if !strings.HasPrefix(lines[1], "1,") || !strings.HasPrefix(lines[500], "500,") {
+		t.Errorf("Process() rows %q ... %q, want the ids 1 to 500", lines[1], lines[500])
+	}
+
+	again, err := processString(t, "", csvprocessor.WithReader(csvprocessor.NewGenerator(generatorSchema, 500, 42)))
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if again != output {
+		t.Errorf("Process() output is different for the same seed")
+	}
+
+	other, err := processString(t, "", csvprocessor.WithReader(csvprocessor.NewGenerator(generatorSchema, 500, 7)))
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if other == output {
+		t.Errorf("Process() output is the same for a different seed")
+	}
+}
+
+func TestGenerator_Chunks(t *testing.T) {
+	generator := &csvprocessor.Generator{
+		Columns: []csvprocessor.GeneratedColumn{
+			{Name: "id", Generate: csvprocessor.SequenceGenerator(100)},
+			{Name: "kind", Generate: csvprocessor.EnumGenerator("a")},
+			{Name: "score", Generate: func(random *rand.Rand, rowNum int) string { return strings.Repeat("*", rowNum) }},
+		},
+		Rows: 5,
+	}
+
+	chunks := make([]strings.Builder, 3)
+	proc := newProcessor(t, strings.NewReader(""), chunks, csvprocessor.WithReader(generator), csvprocessor.WithChunkSize(2))
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	want := []string{"id,kind,score\n100,a,*\n101,a,**\n", "id,kind,score\n102,a,***\n103,a,****\n", "id,kind,score\n104,a,*****\n"}
+	for i := range want {
+		if got := chunks[i].String(); got != want[i] {
+			t.Errorf("chunk %d = %q, want %q", i+1, got, want[i])
+		}
+	}
+}
+
+func TestGenerator_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator *csvprocessor.Generator
+	}{
+		{name: "Test no columns", generator: &csvprocessor.Generator{Rows: 1}},
+		{name: "Test column without generator", generator: &csvprocessor.Generator{Columns: []csvprocessor.GeneratedColumn{{Name: "id"}}, Rows: 1}},
+		{name: "Test negative rows", generator: csvprocessor.NewGenerator(generatorSchema, -1, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.generator.Read(); !errors.Is(err, csvprocessor.ErrInvalidGenerator) {
+				t.Errorf("Read() error = %v, want %v", err, csvprocessor.ErrInvalidGenerator)
+			}
+		})
+	}
+
+	generator := &csvprocessor.Generator{Columns: []csvprocessor.GeneratedColumn{{Name: "id", Generate: csvprocessor.SequenceGenerator(1)}}, NoHeader: true}
+	if _, err := generator.Read(); !errors.Is(err, io.EOF) {
+		t.Errorf("Read() error = %v, want %v", err, io.EOF)
+	}
+}
@@ -0,0 +1,134 @@
+package csvprocessor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidHeader is returned when the header set using WithHeader() or WithExpectedHeader() is empty.
+	ErrInvalidHeader = errors.New("csvprocessor: header must have at least one column")
+	// ErrUnexpectedHeader is returned when the header of the input does not match the header set using WithExpectedHeader().
+	ErrUnexpectedHeader = errors.New("csvprocessor: header does not match the expected header")
+)
+
+// WithHeader sets the header of inputs that do not have a header row, the first row of every input is processed as data.
+// The header is written at the top of every chunk and passed to the transformers like a header read from the input,
+// so that the columns can be referred to by name, Eg: by the schema validation, the JSON output or the column stats.
+// SkipHeaders(), WithHeaderRows() and the header detection of WithHeaderDetection() and WithAutoDialect() are ignored.
+// Eg: csvprocessor.WithHeader([]string{"id", "name", "amount"})
+func WithHeader(header []string) Option {
+	return func(c *Processor) error {
+		if len(header) == 0 {
+			return ErrInvalidHeader
+		}
+
+		c.inputHeader = append([]string(nil), header...)
+		return nil
+	}
+}
+
+// WithExpectedHeader fails the processing before anything is written if the header of the input does not match the given columns,
+// so that a change in the columns of the input is not silently written to the output.
+// If strict is true, the header must have the same columns in the same order. Else the header must have all the given columns,
+// in any order and compared ignoring case and surrounding spaces, and the other columns of the header are allowed.
+// Eg: csvprocessor.WithExpectedHeader([]string{"id", "name", "amount"}, true)
+// The error is ErrUnexpectedHeader with the missing, unexpected or reordered columns. The header is checked before any
+// transformer runs, for a dry run the error is recorded in the stats instead, see WithDryRun().
+func WithExpectedHeader(columns []string, strict bool) Option {
+	return func(c *Processor) error {
+		if len(columns) == 0 {
+			return ErrInvalidHeader
+		}
+
+		c.expectedHeader = append([]string(nil), columns...)
+		c.strictHeader = strict
+		return nil
+	}
+}
+
+// checkHeader returns an error describing the differences between the header and the expected header, nil if they match.
+func checkHeader(header, expected []string, strict bool) error {
+	normalize := func(name string) string {
+		if strict {
+			return name
+		}
+
+		return strings.ToLower(strings.TrimSpace(name))
+	}
+
+	present := make(map[string]bool, len(header))
+	for _, name := range header {
+		present[normalize(name)] = true
+	}
+
+	wanted := make(map[string]bool, len(expected))
+	var missing []string
+	for _, name := range expected {
+		wanted[normalize(name)] = true
+		if !present[normalize(name)] {
+			missing = append(missing, name)
+		}
+	}
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("missing columns %q", missing))
+	}
+
+	if strict {
+		var unexpected []string
+		for _, name := range header {
+			if !wanted[name] {
+				unexpected = append(unexpected, name)
+			}
+		}
+
+		if len(unexpected) > 0 {
+			problems = append(problems, fmt.Sprintf("unexpected columns %q", unexpected))
+		}
+
+		if len(problems) == 0 && !equalRows(header, expected) {
+			problems = append(problems, fmt.Sprintf("columns are in a different order %q, expected %q", header, expected))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnexpectedHeader, strings.Join(problems, ", "))
+}
+
+// inputHasHeader reports whether the inputs start with a header row.
+func (c *Processor) inputHasHeader() bool {
+	return !c.skipsHeaders() && c.inputHeader == nil
+}
+
+// hasHeader reports whether the rows read from inputReader() start with a header row.
+func (c *Processor) hasHeader() bool {
+	return !c.skipsHeaders() || c.inputHeader != nil
+}
+
+// skipsHeaders reports whether the first row of the inputs is data, see SkipHeaders() and WithAutoDialect().
+func (c *Processor) skipsHeaders() bool {
+	return c.skipHeaders || (c.dialect != nil && !c.dialect.HasHeader)
+}
+
+// headerReader returns the header before the rows of the reader.
+type headerReader struct {
+	reader     CsvReader
+	header     []string
+	headerRead bool
+}
+
+func (h *headerReader) Read() ([]string, error) {
+	if h.headerRead {
+		return h.reader.Read()
+	}
+
+	h.headerRead = true
+	// the header is copied, as the rows returned by the reader can be modified.
+	return append([]string(nil), h.header...), nil
+}
@@ -0,0 +1,219 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithHeader(t *testing.T) {
+	header := csvprocessor.WithHeader([]string{"id", "name"})
+	tests := []struct {
+		name string
+		opts []csvprocessor.Option
+		want string
+	}{
+		{name: "Test header", opts: []csvprocessor.Option{header}, want: "id,name\n1,alice\n2,bob\n"},
+		{name: "Test skip headers is ignored", opts: []csvprocessor.Option{header, csvprocessor.SkipHeaders(true)}, want: "id,name\n1,alice\n2,bob\n"},
+		{name: "Test header detection is ignored", opts: []csvprocessor.Option{header, csvprocessor.WithHeaderDetection()}, want: "id,name\n1,alice\n2,bob\n"},
+		{
+			name: "Test JSON lines output",
+			opts: []csvprocessor.Option{header, csvprocessor.WithJSONLinesOutput()},
+			want: `{"id":"1","name":"alice"}` + "\n" + `{"id":"2","name":"bob"}` + "\n",
+		},
+		{
+			name: "Test transformers",
+			opts: []csvprocessor.Option{header, csvprocessor.WithTransformer(csvprocessor.AddRowNoTransformer("n"))},
+			want: "n,id,name\n1,1,alice\n2,2,bob\n",
+		},
+		{
+			name: "Test sorted rows",
+			opts: []csvprocessor.Option{header, csvprocessor.WithSortBy([]int{1}, csvprocessor.Descending)},
+			want: "id,name\n2,bob\n1,alice\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processFile(t, "1,alice\n2,bob\n", tt.opts...)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithHeader_Inputs(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.csv"), filepath.Join(dir, "second.csv")
+	writeTestFile(t, first, "1,alice\n")
+	writeTestFile(t, second, "2,bob\n")
+
+	var output strings.Builder
+	proc, err := csvprocessor.New(
+		csvprocessor.WithFileReaders(first, second),
+		csvprocessor.WithWriterGenerator(func(i int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(&output), nil
+		}),
+		csvprocessor.WithChunkSize(1),
+		csvprocessor.WithHeader([]string{"id", "name"}),
+		csvprocessor.WithSchemaValidation(csvprocessor.Schema{Columns: []csvprocessor.ColumnSchema{
+			{Name: "id", Type: csvprocessor.TypeInt, Required: true}, {Name: "name", Required: true},
+		}}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	// the first row of every input is data, and the header is written to every chunk.
+	if want := "id,name\n1,alice\nid,name\n2,bob\n"; output.String() != want {
+		t.Errorf("Process() output = %q, want %q", output.String(), want)
+	}
+
+	if _, err := processString(t, "1\n", csvprocessor.WithHeader(nil)); !errors.Is(err, csvprocessor.ErrInvalidHeader) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidHeader)
+	}
+}
+
+func TestWithExpectedHeader(t *testing.T) {
+	expected := []string{"id", "name", "amount"}
+	tests := []struct {
+		name    string
+		input   string
+		strict  bool
+		wantErr string
+	}{
+		{name: "Test strict match", input: "id,name,amount\n1,a,2\n", strict: true},
+		{name: "Test strict reordered", input: "id,amount,name\n1,2,a\n", strict: true, wantErr: `different order ["id" "amount" "name"]`},
+		{name: "Test strict extra column", input: "id,name,amount,note\n1,a,2,x\n", strict: true, wantErr: `unexpected columns ["note"]`},
+		{name: "Test strict case", input: "ID,name,amount\n1,a,2\n", strict: true, wantErr: `missing columns ["id"], unexpected columns ["ID"]`},
+		{name: "Test strict missing column", input: "id,name\n1,a\n", strict: true, wantErr: `missing columns ["amount"]`},
+		{name: "Test lenient match", input: "Amount,ID,name,note\n2,1,a,x\n"},
+		{name: "Test lenient missing column", input: "id,amt,name\n1,2,a\n", wantErr: `missing columns ["amount"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processString(t, tt.input, csvprocessor.WithExpectedHeader(expected, tt.strict))
+			if tt.wantErr == "" {
+				if err != nil || output != tt.input {
+					t.Errorf("Process() = %q, %v, want %q", output, err, tt.input)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, csvprocessor.ErrUnexpectedHeader) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Process() error = %v, want %v with %s", err, csvprocessor.ErrUnexpectedHeader, tt.wantErr)
+			}
+
+			// the header is checked before anything is written.
+			if output != "" {
+				t.Errorf("Process() output = %q, want empty", output)
+			}
+		})
+	}
+
+	if _, err := processString(t, "id\n", csvprocessor.WithExpectedHeader(nil, true)); !errors.Is(err, csvprocessor.ErrInvalidHeader) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidHeader)
+	}
+}
+
+func TestWithExpectedHeader_DryRun(t *testing.T) {
+	proc, err := csvprocessor.New(
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader("id,amount\n1,2\n"))),
+		csvprocessor.WithDryRun(true),
+		csvprocessor.WithChunkSize(10),
+		csvprocessor.WithLogger(t.Logf),
+		csvprocessor.WithExpectedHeader([]string{"id", "name"}, false),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if errs := proc.Stats().Errors; len(errs) != 1 || !errors.Is(errs[0], csvprocessor.ErrUnexpectedHeader) {
+		t.Errorf("Stats().Errors = %v, want %v", errs, csvprocessor.ErrUnexpectedHeader)
+	}
+}
+
+func TestCtxColumnIndex(t *testing.T) {
+	// upperEmail upper cases the email column, wherever it is in the input.
+	upperEmail := func(ctx context.Context, row []string) []string {
+		header, _ := ctx.Value(csvprocessor.CtxHeader).([]string)
+		columns, _ := ctx.Value(csvprocessor.CtxColumnIndex).(map[string]int)
+		index, ok := columns["email"]
+		if isHeader, _ := ctx.Value(csvprocessor.CtxIsHeader).(bool); isHeader || !ok {
+			return append(row, strings.Join(header, "|"))
+		}
+
+		row[index] = strings.ToUpper(row[index])
+		return append(row, strings.Join(header, "|"))
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		opts  []csvprocessor.Option
+		want  string
+	}{
+		{name: "Test header", input: "email,id,email\na@x.org,1,b@x.org\n", want: "email,id,email,email|id|email\nA@X.ORG,1,b@x.org,email|id|email\n"},
+		{name: "Test other position", input: "id,email\n1,a@x.org\n", want: "id,email,id|email\n1,A@X.ORG,id|email\n"},
+		{
+			name:  "Test supplied header",
+			input: "1,a@x.org\n",
+			opts:  []csvprocessor.Option{csvprocessor.WithHeader([]string{"id", "email"})},
+			want:  "id,email,id|email\n1,A@X.ORG,id|email\n",
+		},
+		{name: "Test no header", input: "1,a@x.org\n", opts: []csvprocessor.Option{csvprocessor.SkipHeaders(true)}, want: "1,a@x.org,\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processString(t, tt.input, append(tt.opts, csvprocessor.WithTransformer(upperEmail))...)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestCtxHeader_BeforeTransformers(t *testing.T) {
+	var headers [][]string
+	record := func(ctx context.Context, row []string) []string {
+		header, _ := ctx.Value(csvprocessor.CtxHeader).([]string)
+		headers = append(headers, header)
+		return row
+	}
+
+	_, err := processString(t, "id,name\n1,a\n", csvprocessor.WithTransformer(csvprocessor.ChainTransformers(
+		csvprocessor.AddRowNoTransformer("n"), record,
+	)))
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	// the header of the input is passed, not the header transformed by AddRowNoTransformer().
+	if want := [][]string{{"id", "name"}, {"id", "name"}}; !reflect.DeepEqual(headers, want) {
+		t.Errorf("CtxHeader = %q, want %q", headers, want)
+	}
+}
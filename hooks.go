@@ -0,0 +1,180 @@
+package csvprocessor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// WithChunkHooks sets the functions called when a chunk is opened and after it is flushed and closed,
+// Eg: to load each chunk into a database as soon as it is complete.
+// onStart is called with the ID and the name of the chunk, and onEnd is also called with the no. of rows and bytes written, see ChunkInfo.
+// Either of them can be nil. An error returned by a hook stops the processing. The hooks are not called in a dry run.
+func WithChunkHooks(onStart, onEnd func(ChunkInfo) error) Option {
+	return func(c *Processor) error {
+		c.onChunkStart = onStart
+		c.onChunkEnd = onEnd
+		return nil
+	}
+}
+
+// chunkHook calls the hook with the current chunk, if it is set.
+func (r *run) chunkHook(hook func(ChunkInfo) error) error {
+	if hook == nil || r.c.dryRun || r.out.sink != nil {
+		return nil
+	}
+
+	chunk := r.currentChunk()
+	if err := hook(*chunk); err != nil {
+		return fmt.Errorf("csvprocessor: chunk hook failed for chunk %d: %w", chunk.ID, err)
+	}
+
+	return nil
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	*cw.n += int64(n)
+	return n, err
+}
+
+// Decision represents how an error in a row is handled, see WithRowErrorHandler().
+type Decision int
+
+const (
+	// DecisionDefault handles the error as if there is no row error handler: the row is written to the rejects writer
+	// if it is set (see WithRejectWriter()), else the processing is stopped. Malformed input rows are recorded in Stats().Errors and skipped.
+	DecisionDefault Decision = iota
+	// DecisionSkip skips the row, the error is recorded in Stats().Errors. The row is written to the rejects writer if it is set.
+	DecisionSkip
+	// DecisionAbort stops the processing, the error is returned by Process().
+	DecisionAbort
+	// DecisionRetry retries the step that failed, i.e. the transformation or the write of the row. The handler is called again if it fails again.
+	// The errors in reading or validating the row cannot be retried, the row is processed as it is. The write is retried up to 3 times,
+	// and only if no part of the row was written, else the processing is stopped as for DecisionAbort.
+	DecisionRetry
+)
+
+// maxWriteRetries is the max. no. of times the write of a row is retried, see DecisionRetry.
+const maxWriteRetries = 3
+
+// RowErrorHandler decides how an error in a row is handled. rowNum is the overall row number (see CtxRowNum) of the row,
+// and row is the row as read from the input or, for the errors in writing it, the transformed row. row is nil if it could not be parsed.
+type RowErrorHandler func(rowNum int, row []string, err error) Decision
+
+// WithRowErrorHandler sets the handler called for the errors in reading, validating, transforming and writing each row,
+// so that the processing can skip, abort or retry the row. Eg: to skip the malformed rows of a partially dirty file
+// while stopping for any other error:
+//
+//	csvprocessor.WithRowErrorHandler(func(rowNum int, row []string, err error) csvprocessor.Decision {
+//		var parseErr *csv.ParseError
+//		if errors.As(err, &parseErr) {
+//			return csvprocessor.DecisionSkip
+//		}
+//
+//		return csvprocessor.DecisionAbort
+//	})
+//
+// The handler is not called in a dry run, see WithDryRun().
+func WithRowErrorHandler(handler RowErrorHandler) Option {
+	return func(c *Processor) error {
+		c.rowErrorHandler = handler
+		return nil
+	}
+}
+
+// decide calls the row error handler, if it is set.
+func (r *run) decide(rowNum int, row []string, err error) Decision {
+	if r.c.rowErrorHandler == nil || r.c.dryRun {
+		return DecisionDefault
+	}
+
+	return r.c.rowErrorHandler(rowNum, row, err)
+}
+
+// write writes the transformed row to the current chunk, it returns true if the row was skipped after an error.
+func (r *run) write(row []string) (bool, error) {
+	defer r.profiler.end(phaseWrite, r.profiler.start())
+	for retries := 0; ; retries++ {
+		written := r.writtenBytes()
+		err := r.fileWriter.Write(row)
+		if err == nil || errors.Is(err, errIteratorClosed) {
+			return false, err
+		}
+
+		decision := r.decide(r.currentRow, row, err)
+		if decision == DecisionRetry && (retries >= maxWriteRetries || r.writtenBytes() != written) {
+			// the error is not transient, Eg: the sticky error of a buffered writer, or a part of the row is already written.
+			decision = DecisionAbort
+		}
+
+		switch decision {
+		case DecisionRetry:
+			continue
+		case DecisionSkip:
+			r.addErrors(&RowError{Row: r.currentRow, Err: err})
+			return true, nil
+		default:
+			return false, err
+		}
+	}
+}
+
+// writtenBytes returns the no. of bytes written to the current chunk, including the buffered bytes.
+func (r *run) writtenBytes() int64 {
+	written := r.chunkBytes
+	if r.writeBuffer != nil {
+		written += int64(r.writeBuffer.Buffered())
+	}
+
+	return written
+}
+
+// WithBeforeRun sets the function called before the input is read, Eg: to create the output directory or to acquire a lock.
+// ctx is the context passed to ProcessContext(). An error returned by it stops the processing before any row is read.
+func WithBeforeRun(hook func(ctx context.Context) error) Option {
+	return func(c *Processor) error {
+		c.beforeRunHook = hook
+		return nil
+	}
+}
+
+// WithAfterRun sets the function called after all the chunks are written, with the stats of the run, Eg: to publish a completion event.
+// ctx is the context passed to ProcessContext(). It is not called if the processing fails, and the error returned by it is returned by Process().
+func WithAfterRun(hook func(ctx context.Context, stats Stats) error) Option {
+	return func(c *Processor) error {
+		c.afterRunHook = hook
+		return nil
+	}
+}
+
+func (c *Processor) beforeRun(ctx context.Context) error {
+	if c.beforeRunHook == nil {
+		return nil
+	}
+
+	if err := c.beforeRunHook(ctx); err != nil {
+		return fmt.Errorf("csvprocessor: before run hook failed: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Processor) afterRun(ctx context.Context) error {
+	if c.afterRunHook == nil {
+		return nil
+	}
+
+	if err := c.afterRunHook(ctx, c.stats); err != nil {
+		return fmt.Errorf("csvprocessor: after run hook failed: %w", err)
+	}
+
+	return nil
+}
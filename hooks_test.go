@@ -0,0 +1,413 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithChunkHooks(t *testing.T) {
+	outputFormat := filepath.Join(t.TempDir(), "out_%d.csv")
+
+	var started, ended []csvprocessor.ChunkInfo
+	proc, err := csvprocessor.New(
+		csvprocessor.WithInputReader(strings.NewReader("id\n1\n2\n3\n")),
+		csvprocessor.WithChunkSize(2),
+		csvprocessor.WithOutputFileFormat(outputFormat),
+		csvprocessor.WithChunkHooks(func(chunk csvprocessor.ChunkInfo) error {
+			started = append(started, chunk)
+			return nil
+		}, func(chunk csvprocessor.ChunkInfo) error {
+			// the chunk is complete when the hook is called.
+			data, err := os.ReadFile(chunk.Name)
+			if err != nil {
+				return err
+			}
+
+			if int64(len(data)) != chunk.Bytes {
+				t.Errorf("chunk %d: Bytes = %d, file size = %d", chunk.ID, chunk.Bytes, len(data))
+			}
+
+			ended = append(ended, chunk)
+			return nil
+		}),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	name := func(id int) string {
+		return csvprocessor.ChunkName(outputFormat, id)
+	}
+
+	wantStarted := []csvprocessor.ChunkInfo{{ID: 1, Name: name(1)}, {ID: 2, Name: name(2)}}
+	if !reflect.DeepEqual(started, wantStarted) {
+		t.Errorf("onStart chunks = %+v, want %+v", started, wantStarted)
+	}
+
+	wantEnded := []csvprocessor.ChunkInfo{
+		{ID: 1, FirstRow: 1, LastRow: 2, Rows: 2, Bytes: 7, Name: name(1)},
+		{ID: 2, FirstRow: 3, LastRow: 3, Rows: 1, Bytes: 5, Name: name(2)},
+	}
+	if !reflect.DeepEqual(ended, wantEnded) {
+		t.Errorf("onEnd chunks = %+v, want %+v", ended, wantEnded)
+	}
+
+	if stats := proc.Stats(); !reflect.DeepEqual(stats.Chunks, wantEnded) {
+		t.Errorf("Stats().Chunks = %+v, want %+v", stats.Chunks, wantEnded)
+	}
+}
+
+func TestWithChunkHooksError(t *testing.T) {
+	errLoad := errors.New("load failed")
+	tests := []struct {
+		name    string
+		onStart func(csvprocessor.ChunkInfo) error
+		onEnd   func(csvprocessor.ChunkInfo) error
+	}{
+		{
+			name:    "onStart",
+			onStart: func(csvprocessor.ChunkInfo) error { return errLoad },
+		},
+		{
+			name:  "onEnd",
+			onEnd: func(csvprocessor.ChunkInfo) error { return errLoad },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc, err := csvprocessor.New(
+				csvprocessor.WithInputReader(strings.NewReader("id\n1\n2\n3\n")),
+				csvprocessor.WithChunkSize(2),
+				csvprocessor.WithZipOutput(filepath.Join(t.TempDir(), "out.zip")),
+				csvprocessor.WithChunkHooks(tt.onStart, tt.onEnd),
+				csvprocessor.WithLogger(t.Logf),
+			)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			err = proc.Process()
+			if !errors.Is(err, errLoad) {
+				t.Fatalf("Process() error = %v, want %v", err, errLoad)
+			}
+
+			if !strings.Contains(err.Error(), "chunk 1") {
+				t.Errorf("Process() error = %q, want it to contain the chunk ID", err)
+			}
+
+			if stats := proc.Stats(); stats.Chunks[0].Name != "part_00001.csv" {
+				t.Errorf("Stats().Chunks[0].Name = %q, want %q", stats.Chunks[0].Name, "part_00001.csv")
+			}
+		})
+	}
+}
+
+// flakyWriter fails the first `failures` writes of the row with the id "2".
+type flakyWriter struct {
+	*csv.Writer
+	failures int
+}
+
+func (w *flakyWriter) Write(record []string) error {
+	if record[0] == "2" && w.failures > 0 {
+		w.failures--
+		return errWrite
+	}
+
+	return w.Writer.Write(record)
+}
+
+var errWrite = errors.New("write failed")
+
+func TestWithRowErrorHandler(t *testing.T) {
+	errTransform := errors.New("transform failed")
+
+	tests := []struct {
+		name          string
+		input         string
+		transformErrs int // no. of times the transformer fails for the row with the id "2".
+		writeErrs     int // no. of times the write fails for the row with the id "2".
+		decision      csvprocessor.Decision
+		want          string
+		wantErr       error
+		wantStatsErrs int
+		wantCalls     int
+	}{
+		{
+			name:      "abort on row with unexpected no. of fields",
+			input:     "id,name\n1,a\n2\n3,c\n",
+			decision:  csvprocessor.DecisionAbort,
+			wantErr:   csv.ErrFieldCount,
+			wantCalls: 1,
+		},
+		{
+			name:          "skip row with unexpected no. of fields",
+			input:         "id,name\n1,a\n2\n3,c\n",
+			decision:      csvprocessor.DecisionSkip,
+			want:          "id,name\n1,a\n3,c\n",
+			wantStatsErrs: 1,
+			wantCalls:     1,
+		},
+		{
+			name:          "default for row with unexpected no. of fields",
+			input:         "id,name\n1,a\n2\n3,c\n",
+			decision:      csvprocessor.DecisionDefault,
+			want:          "id,name\n1,a\n2\n3,c\n",
+			wantStatsErrs: 1,
+			wantCalls:     1,
+		},
+		{
+			name:          "retry transform",
+			input:         "id,name\n1,a\n2,b\n3,c\n",
+			transformErrs: 2,
+			decision:      csvprocessor.DecisionRetry,
+			want:          "id,name\n1,a\n2,b\n3,c\n",
+			wantCalls:     2,
+		},
+		{
+			name:          "skip transform",
+			input:         "id,name\n1,a\n2,b\n3,c\n",
+			transformErrs: 1,
+			decision:      csvprocessor.DecisionSkip,
+			want:          "id,name\n1,a\n3,c\n",
+			wantStatsErrs: 1,
+			wantCalls:     1,
+		},
+		{
+			name:          "default transform",
+			input:         "id,name\n1,a\n2,b\n3,c\n",
+			transformErrs: 1,
+			decision:      csvprocessor.DecisionDefault,
+			wantErr:       errTransform,
+			wantCalls:     1,
+		},
+		{
+			name:      "retry write",
+			input:     "id,name\n1,a\n2,b\n3,c\n",
+			writeErrs: 3,
+			decision:  csvprocessor.DecisionRetry,
+			want:      "id,name\n1,a\n2,b\n3,c\n",
+			wantCalls: 3,
+		},
+		{
+			name:      "retry write until the max. retries",
+			input:     "id,name\n1,a\n2,b\n3,c\n",
+			writeErrs: 10,
+			decision:  csvprocessor.DecisionRetry,
+			wantErr:   errWrite,
+			wantCalls: 4,
+		},
+		{
+			name:          "skip write",
+			input:         "id,name\n1,a\n2,b\n3,c\n",
+			writeErrs:     1,
+			decision:      csvprocessor.DecisionSkip,
+			want:          "id,name\n1,a\n3,c\n",
+			wantStatsErrs: 1,
+			wantCalls:     1,
+		},
+		{
+			name:      "abort write",
+			input:     "id,name\n1,a\n2,b\n3,c\n",
+			writeErrs: 1,
+			decision:  csvprocessor.DecisionAbort,
+			wantErr:   errWrite,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformErrs := tt.transformErrs
+			calls := 0
+			var output strings.Builder
+			proc, err := csvprocessor.New(
+				csvprocessor.WithInputReader(strings.NewReader(tt.input)),
+				csvprocessor.WithChunkSize(10),
+				csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+					return csvprocessor.NoOpCloser(&output), nil
+				}),
+				csvprocessor.WithWriterFactory(func(w io.Writer) csvprocessor.CsvWriter {
+					return &flakyWriter{Writer: csv.NewWriter(w), failures: tt.writeErrs}
+				}),
+				csvprocessor.WithTransformer(func(_ context.Context, row []string) []string {
+					if row[0] == "2" && transformErrs > 0 {
+						transformErrs--
+						// the processor returns the panics in the transformers as errors.
+						panic(errTransform)
+					}
+
+					return row
+				}),
+				csvprocessor.WithRowErrorHandler(func(rowNum int, row []string, err error) csvprocessor.Decision {
+					calls++
+					if rowNum != 2 {
+						t.Errorf("handler called with rowNum = %d, want 2", rowNum)
+					}
+
+					return tt.decision
+				}),
+				csvprocessor.WithLogger(t.Logf),
+			)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			err = proc.Process()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && output.String() != tt.want {
+				t.Errorf("output = %q, want %q", output.String(), tt.want)
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("handler calls = %d, want %d", calls, tt.wantCalls)
+			}
+
+			if got := len(proc.Stats().Errors); got != tt.wantStatsErrs {
+				t.Errorf("len(Stats().Errors) = %d, want %d", got, tt.wantStatsErrs)
+			}
+		})
+	}
+}
+
+type ctxKey string
+
+func TestWithRunHooks(t *testing.T) {
+	errHook := errors.New("hook failed")
+	ctx := context.WithValue(context.Background(), ctxKey("job"), "daily")
+
+	tests := []struct {
+		name       string
+		beforeErr  error
+		afterErr   error
+		wantErr    error
+		wantCalls  []string
+		wantOutput bool
+	}{
+		{
+			name:       "success",
+			wantCalls:  []string{"before", "transform", "transform", "after"},
+			wantOutput: true,
+		},
+		{
+			name:      "before run fails",
+			beforeErr: errHook,
+			wantErr:   errHook,
+			wantCalls: []string{"before"},
+		},
+		{
+			name:       "after run fails",
+			afterErr:   errHook,
+			wantErr:    errHook,
+			wantCalls:  []string{"before", "transform", "transform", "after"},
+			wantOutput: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := filepath.Join(t.TempDir(), "out")
+			var calls []string
+			proc, err := csvprocessor.New(
+				csvprocessor.WithInputReader(strings.NewReader("id\n1\n")),
+				csvprocessor.WithChunkSize(10),
+				csvprocessor.WithOutputFileFormat(filepath.Join(outputDir, "out_%d.csv")),
+				csvprocessor.WithBeforeRun(func(ctx context.Context) error {
+					calls = append(calls, "before")
+					if ctx.Value(ctxKey("job")) != "daily" {
+						t.Error("WithBeforeRun() hook is not called with the context of ProcessContext()")
+					}
+
+					if tt.beforeErr != nil {
+						return tt.beforeErr
+					}
+
+					// the output directory is created before the chunks are written.
+					return os.MkdirAll(outputDir, 0o755)
+				}),
+				csvprocessor.WithTransformer(func(ctx context.Context, row []string) []string {
+					calls = append(calls, "transform")
+					if ctx.Value(ctxKey("job")) != "daily" {
+						t.Error("transformer context does not have the values of the context of ProcessContext()")
+					}
+
+					return row
+				}),
+				csvprocessor.WithAfterRun(func(_ context.Context, stats csvprocessor.Stats) error {
+					calls = append(calls, "after")
+					if stats.RowsWritten != 1 || len(stats.Chunks) != 1 {
+						t.Errorf("WithAfterRun() hook stats = %+v, want 1 row in 1 chunk", stats)
+					}
+
+					return tt.afterErr
+				}),
+				csvprocessor.WithLogger(t.Logf),
+			)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if err := proc.ProcessContext(ctx); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ProcessContext() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %q, want %q", calls, tt.wantCalls)
+			}
+
+			if _, err := os.Stat(filepath.Join(outputDir, "out_1.csv")); (err == nil) != tt.wantOutput {
+				t.Errorf("output file Stat() error = %v, want output %v", err, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestProcessor_ProcessContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	proc, err := csvprocessor.New(
+		csvprocessor.WithInputReader(strings.NewReader("id\n1\n2\n3\n")),
+		csvprocessor.WithChunkSize(10),
+		csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(io.Discard), nil
+		}),
+		csvprocessor.WithTransformer(func(_ context.Context, row []string) []string {
+			if row[0] == "2" {
+				cancel()
+			}
+
+			return row
+		}),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.ProcessContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ProcessContext() error = %v, want %v", err, context.Canceled)
+	}
+
+	if stats := proc.Stats(); stats.RowsRead != 2 {
+		t.Errorf("Stats().RowsRead = %d, want 2", stats.RowsRead)
+	}
+}
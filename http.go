@@ -0,0 +1,211 @@
+package csvprocessor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultHTTPRetries is the default no. of times a HTTP download is resumed after a failure.
+	DefaultHTTPRetries = 3
+	// DefaultHTTPBackoff is the default wait before the first attempt to resume a HTTP download.
+	DefaultHTTPBackoff = 100 * time.Millisecond
+)
+
+var (
+	// ErrHTTPStatus is returned when the server responds with a non-success status code.
+	ErrHTTPStatus = errors.New("csvprocessor: unexpected HTTP status")
+	// ErrHTTPResume is returned when an interrupted download cannot be resumed,
+	// Eg: the server does not support range requests or the content has changed.
+	ErrHTTPResume = errors.New("csvprocessor: HTTP download cannot be resumed")
+)
+
+// HTTPOptions configures the download of the input by WithHTTPReader().
+type HTTPOptions struct {
+	// Client is the HTTP client used for the requests, http.DefaultClient is used if nil.
+	Client *http.Client
+	// Header contains the headers added to the requests, Eg: Authorization.
+	Header http.Header
+	// Retries is the no. of times the download is resumed after a failure, DefaultHTTPRetries is used if 0.
+	// Set it to a negative value to disable the retries.
+	Retries int
+	// Backoff is the wait before the first attempt to resume the download, it is doubled after each attempt.
+	// DefaultHTTPBackoff is used if 0.
+	Backoff time.Duration
+}
+
+// WithHTTPReader streams the CSV at the given URL as the input of the processor, without downloading it to disk.
+// If the download fails midway, it is resumed from the last byte read using a range request, up to opts.Retries times.
+// The attempts to resume are spaced out by opts.Backoff, doubled each time, a failed attempt also counts as a retry.
+// The request is sent when the processing starts, cancelling the context of ProcessContext() aborts the download and the retries.
+func WithHTTPReader(url string, opts HTTPOptions) Option {
+	return func(c *Processor) error {
+		client := opts.Client
+		if client == nil {
+			client = http.DefaultClient
+		}
+
+		retries := opts.Retries
+		if retries == 0 {
+			retries = DefaultHTTPRetries
+		}
+
+		backoff := opts.Backoff
+		if backoff <= 0 {
+			backoff = DefaultHTTPBackoff
+		}
+
+		return WithInputReader(&httpReader{client: client, url: url, header: opts.Header, retries: retries, backoff: backoff})(c)
+	}
+}
+
+// httpReader reads the response body of a GET request, resuming the download on failures.
+type httpReader struct {
+	client  *http.Client
+	url     string
+	header  http.Header
+	retries int
+	backoff time.Duration // wait before the next attempt to resume.
+
+	// context of the processing, see setHTTPContext().
+	ctx context.Context //nolint:containedctx
+
+	body      io.ReadCloser
+	offset    int64  // no. of bytes read.
+	validator string // strong ETag or Last-Modified of the content, to resume only if the content has not changed.
+}
+
+func (h *httpReader) Read(p []byte) (int, error) {
+	for {
+		if h.body == nil {
+			if h.offset > 0 {
+				if err := h.wait(); err != nil {
+					return 0, err
+				}
+
+				h.backoff *= 2
+			}
+
+			retry, err := h.open()
+			if err != nil && (!retry || h.offset == 0 || h.retries <= 0) {
+				return 0, err
+			}
+
+			if err != nil {
+				// the attempt to resume failed, it is tried again after the backoff.
+				h.retries--
+				continue
+			}
+		}
+
+		n, err := h.body.Read(p)
+		h.offset += int64(n)
+		if err == nil || errors.Is(err, io.EOF) || h.retries <= 0 {
+			return n, err
+		}
+
+		// the download failed, it is resumed in the next read.
+		h.retries--
+		_ = h.body.Close()
+		h.body = nil
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// wait waits for the backoff, it returns the error of the context if the processing is cancelled first.
+func (h *httpReader) wait() error {
+	timer := time.NewTimer(h.backoff)
+	defer timer.Stop()
+
+	select {
+	case <-h.context().Done():
+		return h.context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// context returns the context of the processing, or context.Background() if the reader is used outside of a run.
+func (h *httpReader) context() context.Context {
+	if h.ctx == nil {
+		return context.Background()
+	}
+
+	return h.ctx
+}
+
+// open sends the request for the content from the current offset,
+// retry is true if the request failed due to a network error or a server error that can go away on its own.
+func (h *httpReader) open() (retry bool, err error) {
+	req, err := http.NewRequestWithContext(h.context(), http.MethodGet, h.url, http.NoBody)
+	if err != nil {
+		return false, err
+	}
+
+	for key, values := range h.header {
+		req.Header[key] = values
+	}
+
+	if h.offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(h.offset, 10)+"-")
+		if h.validator != "" {
+			req.Header.Set("If-Range", h.validator)
+		}
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return h.context().Err() == nil, err
+	}
+
+	switch {
+	case h.offset == 0 && resp.StatusCode >= 200 && resp.StatusCode < 300:
+		h.validator = rangeValidator(resp.Header)
+	case h.offset > 0 && resp.StatusCode == http.StatusPartialContent:
+	case h.offset > 0 && resp.StatusCode == http.StatusOK:
+		resp.Body.Close()
+		return false, fmt.Errorf("%w: %s", ErrHTTPResume, h.url)
+	default:
+		resp.Body.Close()
+		retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return retry, fmt.Errorf("%w: %s: %s", ErrHTTPStatus, h.url, resp.Status)
+	}
+
+	h.body = resp.Body
+	return false, nil
+}
+
+// rangeValidator returns the value for the If-Range header of the range requests,
+// weak ETags cannot be used in If-Range, so Last-Modified is used instead.
+func rangeValidator(header http.Header) string {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+
+	return header.Get("Last-Modified")
+}
+
+// setHTTPContext sets the context of the processing to the HTTP inputs, see WithHTTPReader().
+func (c *Processor) setHTTPContext(ctx context.Context) {
+	for _, input := range c.inputs {
+		if h, ok := input.(*httpReader); ok {
+			h.ctx = ctx
+		}
+	}
+}
+
+func (h *httpReader) Close() error {
+	if h.body == nil {
+		return nil
+	}
+
+	return h.body.Close()
+}
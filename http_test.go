@@ -0,0 +1,230 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+const httpCSV = "id,name\n1,alice\n2,bob\n3,carol\n4,dave\n"
+
+// flakyServer serves httpCSV, the first `failures` responses are cut off halfway through the content.
+func flakyServer(tb testing.TB, failures int, ranges bool) (*httptest.Server, *[]string) {
+	tb.Helper()
+
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		requested = append(requested, r.Header.Get("Range"))
+		content := httpCSV
+		w.Header().Set("ETag", `"v1"`)
+		if start := strings.TrimPrefix(r.Header.Get("Range"), "bytes="); ranges && start != "" {
+			offset, _ := strconv.Atoi(strings.TrimSuffix(start, "-"))
+			w.Header().Set("Content-Range", "bytes "+strconv.Itoa(offset)+"-"+strconv.Itoa(len(content)-1)+"/"+strconv.Itoa(len(content)))
+			content = content[offset:]
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		}
+
+		if len(requested) > failures {
+			_, _ = io.WriteString(w, content)
+			return
+		}
+
+		_, _ = io.WriteString(w, content[:len(content)/2])
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}))
+	tb.Cleanup(server.Close)
+
+	return server, &requested
+}
+
+func processHTTP(tb testing.TB, url string, opts csvprocessor.HTTPOptions) (string, error) {
+	tb.Helper()
+
+	return processHTTPContext(context.Background(), tb, url, opts)
+}
+
+func processHTTPContext(ctx context.Context, tb testing.TB, url string, opts csvprocessor.HTTPOptions) (string, error) {
+	tb.Helper()
+
+	if opts.Backoff == 0 {
+		opts.Backoff = time.Millisecond
+	}
+
+	var output strings.Builder
+	proc, err := csvprocessor.New(
+		csvprocessor.WithHTTPReader(url, opts),
+		csvprocessor.WithWriterGenerator(func(i int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(&output), nil
+		}),
+		csvprocessor.WithChunkSize(math.MaxInt32),
+		csvprocessor.WithLogger(tb.Logf),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	err = proc.ProcessContext(ctx)
+	return output.String(), err
+}
+
+func TestWithHTTPReader(t *testing.T) {
+	auth := http.Header{"Authorization": {"Bearer token"}}
+
+	tests := []struct {
+		name         string
+		failures     int
+		ranges       bool
+		opts         csvprocessor.HTTPOptions
+		wantErr      error
+		wantRequests int
+	}{
+		{"complete download", 0, true, csvprocessor.HTTPOptions{Header: auth}, nil, 1},
+		{"resumed download", 2, true, csvprocessor.HTTPOptions{Header: auth}, nil, 3},
+		{"too many failures", 2, true, csvprocessor.HTTPOptions{Header: auth, Retries: 1}, io.ErrUnexpectedEOF, 2},
+		{"retries disabled", 1, true, csvprocessor.HTTPOptions{Header: auth, Retries: -1}, io.ErrUnexpectedEOF, 1},
+		{"range not supported", 1, false, csvprocessor.HTTPOptions{Header: auth}, csvprocessor.ErrHTTPResume, 2},
+		{"unauthorized", 0, true, csvprocessor.HTTPOptions{}, csvprocessor.ErrHTTPStatus, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requested := flakyServer(t, tt.failures, tt.ranges)
+
+			got, err := processHTTP(t, server.URL, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if len(*requested) != tt.wantRequests {
+				t.Errorf("requests = %q, want %d requests", *requested, tt.wantRequests)
+			}
+
+			if tt.wantErr == nil && got != httpCSV {
+				t.Errorf("Process() output = %q, want %q", got, httpCSV)
+			}
+		})
+	}
+}
+
+func TestWithHTTPReader_Resume(t *testing.T) {
+	const lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+	tests := []struct {
+		name        string
+		etag        string
+		unavailable int // no. of 503 responses to the attempts to resume.
+		retries     int
+		wantErr     error
+		wantIfRange string
+	}{
+		{"strong etag", `"v1"`, 0, 0, nil, `"v1"`},
+		{"weak etag", `W/"v1"`, 0, 0, nil, lastModified},
+		{"server unavailable", `"v1"`, 2, 0, nil, `"v1"`},
+		{"server unavailable too long", `"v1"`, 3, 3, csvprocessor.ErrHTTPStatus, `"v1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			var ifRange []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Header().Set("ETag", tt.etag)
+				w.Header().Set("Last-Modified", lastModified)
+				if requests == 1 {
+					w.Header().Set("Content-Length", strconv.Itoa(len(httpCSV)))
+					_, _ = io.WriteString(w, httpCSV[:len(httpCSV)/2])
+					w.(http.Flusher).Flush()
+					panic(http.ErrAbortHandler)
+				}
+
+				ifRange = append(ifRange, r.Header.Get("If-Range"))
+				if requests-1 <= tt.unavailable {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+
+				offset, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(r.Header.Get("Range"), "bytes="), "-"))
+				w.Header().Set("Content-Range", "bytes "+strconv.Itoa(offset)+"-"+strconv.Itoa(len(httpCSV)-1)+"/"+strconv.Itoa(len(httpCSV)))
+				w.WriteHeader(http.StatusPartialContent)
+				_, _ = io.WriteString(w, httpCSV[offset:])
+			}))
+			defer server.Close()
+
+			got, err := processHTTP(t, server.URL, csvprocessor.HTTPOptions{Retries: tt.retries})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && got != httpCSV {
+				t.Errorf("Process() output = %q, want %q", got, httpCSV)
+			}
+
+			for _, value := range ifRange {
+				if value != tt.wantIfRange {
+					t.Errorf("If-Range = %q, want %q", value, tt.wantIfRange)
+				}
+			}
+		})
+	}
+}
+
+func TestWithHTTPReader_Cancel(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff time.Duration
+		stall   bool // the server stops sending the content instead of cutting it off.
+	}{
+		{name: "stalled download", backoff: time.Millisecond, stall: true},
+		{name: "pending retry", backoff: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"v1"`)
+				w.Header().Set("Content-Length", strconv.Itoa(len(httpCSV)))
+				_, _ = io.WriteString(w, httpCSV[:len(httpCSV)/2])
+				w.(http.Flusher).Flush()
+				if tt.stall {
+					<-r.Context().Done()
+					return
+				}
+
+				panic(http.ErrAbortHandler)
+			}))
+			defer server.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			_, err := processHTTPContext(ctx, t, server.URL, csvprocessor.HTTPOptions{Backoff: tt.backoff})
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Process() error = %v, want %v", err, context.DeadlineExceeded)
+			}
+
+			if elapsed := time.Since(start); elapsed > 10*time.Second {
+				t.Errorf("Process() returned after %v, want it to stop when the context is done", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+// Package httpserver exposes the processor as a HTTP service.
+//
+// A client POSTs a multipart/form-data request with the pipeline parameters as form fields followed by the CSV as the "file" part,
+// and receives a zip of the output chunks, or when the Handler has a Store, a JSON list of the locations of the chunks.
+// The input and the output are streamed, so the size of the CSV is not limited by the memory of the server.
+//
+// The supported form fields are:
+//   - chunk_size: no. of rows in each chunk, the CSV is not split by default.
+//   - input_delimiter, output_delimiter: field delimiter of the input and the chunks, Eg: ";".
+//   - skip_headers: "true" to not write the header in the chunks.
+//   - format: format of the chunks, one of csv (default), json, jsonl and xlsx.
+//   - name_format: name of the chunks, with one verb for the chunk ID, %d or %0Nd with N from 1 to 9, Eg: "orders_%02d.csv".
+//
+// The form fields must be sent before the "file" part, as the fields after it are not read.
+package httpserver
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// FilePart is the name of the form part with the CSV input.
+const FilePart = "file"
+
+// maximum size of a form field value.
+const maxFieldSize = 64 * 1024
+
+var (
+	// ErrMissingFile is returned when the request does not have the FilePart.
+	ErrMissingFile = errors.New("httpserver: request does not have a \"file\" part")
+	// ErrInvalidParam is returned when a form field has an invalid value.
+	ErrInvalidParam = errors.New("httpserver: invalid parameter")
+)
+
+// Store creates the writer for the chunk with the given name and returns it with the location of the chunk,
+// Eg: an object in cloud storage and its URL. The chunk is complete when the writer is closed.
+type Store func(ctx context.Context, name string) (io.WriteCloser, string, error)
+
+// Pipeline returns the additional processor options for the request, Eg: a transformer selected by a form field.
+// The params contain all the form fields sent before the file. If an error is returned, the request fails with 400 Bad Request.
+type Pipeline func(r *http.Request, params url.Values) ([]csvprocessor.Option, error)
+
+// Option configures the Handler.
+type Option func(*Handler)
+
+// WithStore writes the chunks to the store, the response is a JSON object with the locations of the chunks,
+// Eg: {"chunks":["s3://bucket/part_001.csv"]}.
+func WithStore(store Store) Option {
+	return func(h *Handler) {
+		h.store = store
+	}
+}
+
+// WithPipeline sets the function that returns the additional processor options for each request.
+func WithPipeline(pipeline Pipeline) Option {
+	return func(h *Handler) {
+		h.pipeline = pipeline
+	}
+}
+
+// WithMaxBytes limits the size of the request body, the default is no limit.
+func WithMaxBytes(n int64) Option {
+	return func(h *Handler) {
+		h.maxBytes = n
+	}
+}
+
+// Handler is a http.Handler that splits and transforms the CSV posted by the client, see the package documentation for the request format.
+type Handler struct {
+	store    Store
+	pipeline Pipeline
+	maxBytes int64
+}
+
+// NewHandler creates a Handler with the given options.
+func NewHandler(opts ...Option) *Handler {
+	h := &Handler{}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// ServeHTTP processes the CSV in the request.
+// Invalid requests fail with 400 Bad Request and processing errors with 422 Unprocessable Entity,
+// if the zip response has already started when processing fails, the connection is aborted so that the client gets an incomplete response.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.maxBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, h.maxBytes)
+	}
+
+	params, file, err := readForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	nameFormat, opts, err := h.options(r, params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	out := &response{w: w, r: r, store: h.store, nameFormat: nameFormat}
+	opts = append(opts, csvprocessor.WithInputReader(file), csvprocessor.WithWriterGenerator(out.chunk))
+
+	proc, err := csvprocessor.New(opts...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// the processing stops when the client disconnects.
+	if err := proc.ProcessContext(r.Context()); err != nil {
+		if out.zip != nil {
+			panic(http.ErrAbortHandler)
+		}
+
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	out.finish()
+}
+
+// readForm reads the form fields until the file part, and returns them with the file part.
+func readForm(r *http.Request) (url.Values, io.Reader, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	params := url.Values{}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, nil, ErrMissingFile
+		}
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if part.FormName() == FilePart {
+			return params, part, nil
+		}
+
+		value, err := io.ReadAll(io.LimitReader(part, maxFieldSize+1))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(value) > maxFieldSize {
+			return nil, nil, fmt.Errorf("%w: %s is too long", ErrInvalidParam, part.FormName())
+		}
+
+		params.Add(part.FormName(), string(value))
+	}
+}
+
+// options returns the chunk name format and the processor options for the form fields.
+func (h *Handler) options(r *http.Request, params url.Values) (string, []csvprocessor.Option, error) {
+	opts := []csvprocessor.Option{csvprocessor.WithChunkSize(math.MaxInt32)}
+
+	if value := params.Get("chunk_size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: chunk_size %q", ErrInvalidParam, value)
+		}
+
+		opts = append(opts, csvprocessor.WithChunkSize(size))
+	}
+
+	for name, option := range map[string]func(rune) csvprocessor.Option{
+		"input_delimiter":  csvprocessor.WithInputDelimiter,
+		"output_delimiter": csvprocessor.WithOutputDelimiter,
+	} {
+		if value := params.Get(name); value != "" {
+			delimiter, size := utf8.DecodeRuneInString(value)
+			if size != len(value) {
+				return "", nil, fmt.Errorf("%w: %s %q", ErrInvalidParam, name, value)
+			}
+
+			opts = append(opts, option(delimiter))
+		}
+	}
+
+	if value := params.Get("skip_headers"); value != "" {
+		skip, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: skip_headers %q", ErrInvalidParam, value)
+		}
+
+		opts = append(opts, csvprocessor.SkipHeaders(skip))
+	}
+
+	extension := ".csv"
+	switch format := params.Get("format"); format {
+	case "", "csv":
+	case "json":
+		extension = ".json"
+		opts = append(opts, csvprocessor.WithJSONOutput())
+	case "jsonl":
+		extension = ".jsonl"
+		opts = append(opts, csvprocessor.WithJSONLinesOutput())
+	case "xlsx":
+		extension = ".xlsx"
+		opts = append(opts, csvprocessor.WithXLSXOutput())
+	default:
+		return "", nil, fmt.Errorf("%w: format %q", ErrInvalidParam, format)
+	}
+
+	nameFormat := params.Get("name_format")
+	if nameFormat == "" {
+		nameFormat = "part_%03d" + extension
+	}
+
+	// the names are used as zip entries and object names, so they must not escape the target directory.
+	if !validNameFormat(nameFormat) || strings.HasPrefix(nameFormat, "/") || strings.Contains(nameFormat, "..") || strings.Contains(nameFormat, `\`) {
+		return "", nil, fmt.Errorf("%w: name_format %q", ErrInvalidParam, nameFormat)
+	}
+
+	if h.pipeline != nil {
+		extra, err := h.pipeline(r, params)
+		if err != nil {
+			return "", nil, err
+		}
+
+		opts = append(opts, extra...)
+	}
+
+	return nameFormat, opts, nil
+}
+
+// validNameFormat reports whether the format has exactly one verb, %d or %0Nd with N from 1 to 9, for the chunk ID.
+// %% is allowed for a literal %.
+func validNameFormat(format string) bool {
+	verbs := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+
+		rest := format[i+1:]
+		switch {
+		case strings.HasPrefix(rest, "%"):
+			i++
+		case strings.HasPrefix(rest, "d"):
+			verbs++
+			i++
+		case len(rest) >= 3 && rest[0] == '0' && rest[1] >= '1' && rest[1] <= '9' && rest[2] == 'd':
+			verbs++
+			i += 3
+		default:
+			return false
+		}
+	}
+
+	return verbs == 1
+}
+
+// response writes the chunks either to a zip in the response or to the store.
+type response struct {
+	w          http.ResponseWriter
+	r          *http.Request
+	store      Store
+	nameFormat string
+
+	zip       *zip.Writer // zip of the chunks, nil until the first chunk.
+	locations []string    // locations of the chunks in the store.
+}
+
+func (o *response) chunk(chunkID int) (io.WriteCloser, error) {
+	name := csvprocessor.ChunkName(o.nameFormat, chunkID)
+	if o.store != nil {
+		writer, location, err := o.store(o.r.Context(), name)
+		if err != nil {
+			return nil, err
+		}
+
+		o.locations = append(o.locations, location)
+		return writer, nil
+	}
+
+	if o.zip == nil {
+		o.startZip()
+	}
+
+	entry, err := o.zip.Create(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return csvprocessor.NoOpCloser(entry), nil
+}
+
+func (o *response) startZip() {
+	o.w.Header().Set("Content-Type", "application/zip")
+	o.w.Header().Set("Content-Disposition", `attachment; filename="chunks.zip"`)
+	o.zip = zip.NewWriter(o.w)
+}
+
+// finish completes the response after the processing succeeds.
+func (o *response) finish() {
+	if o.store != nil {
+		o.w.Header().Set("Content-Type", "application/json")
+		chunks := o.locations
+		if chunks == nil {
+			chunks = []string{}
+		}
+
+		_ = json.NewEncoder(o.w).Encode(struct {
+			Chunks []string `json:"chunks"`
+		}{chunks})
+
+		return
+	}
+
+	if o.zip == nil {
+		o.startZip()
+	}
+
+	if err := o.zip.Close(); err != nil {
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -0,0 +1,255 @@
+package httpserver_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+	"github.com/sivaramasubramanian/csvprocessor/httpserver"
+)
+
+const input = "id,name\n1,alice\n2,bob\n3,carol\n"
+
+// newRequest creates a multipart request with the given fields followed by the file part.
+func newRequest(tb testing.TB, fields map[string]string, file string) *http.Request {
+	tb.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	part, err := form.CreateFormFile(httpserver.FilePart, "input.csv")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	_, _ = io.WriteString(part, file)
+	if err := form.Close(); err != nil {
+		tb.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/split", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req
+}
+
+// unzip returns the content of each file in the zip.
+func unzip(tb testing.TB, data []byte) map[string]string {
+	tb.Helper()
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		tb.Fatalf("invalid zip: %v", err)
+	}
+
+	files := map[string]string{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[file.Name] = string(content)
+	}
+
+	return files
+}
+
+func TestHandler_Zip(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]string
+		want   map[string]string
+	}{
+		{
+			name:   "defaults",
+			fields: nil,
+			want:   map[string]string{"part_001.csv": input},
+		},
+		{
+			name:   "chunks",
+			fields: map[string]string{"chunk_size": "2", "output_delimiter": ";", "name_format": "users_%d.csv"},
+			want:   map[string]string{"users_1.csv": "id;name\n1;alice\n2;bob\n", "users_2.csv": "id;name\n3;carol\n"},
+		},
+		{
+			name:   "literal percent",
+			fields: map[string]string{"chunk_size": "3", "name_format": "100%%_%02d.csv"},
+			want:   map[string]string{"100%_01.csv": input},
+		},
+		{
+			name:   "jsonl",
+			fields: map[string]string{"format": "jsonl"},
+			want: map[string]string{
+				"part_001.jsonl": `{"id":"1","name":"alice"}` + "\n" + `{"id":"2","name":"bob"}` + "\n" + `{"id":"3","name":"carol"}` + "\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			httpserver.NewHandler().ServeHTTP(rec, newRequest(t, tt.fields, input))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body = %q", rec.Code, rec.Body.String())
+			}
+
+			if got := rec.Header().Get("Content-Type"); got != "application/zip" {
+				t.Errorf("Content-Type = %q, want application/zip", got)
+			}
+
+			if got := unzip(t, rec.Body.Bytes()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("chunks = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_Store(t *testing.T) {
+	stored := map[string]*strings.Builder{}
+	store := func(ctx context.Context, name string) (io.WriteCloser, string, error) {
+		stored[name] = &strings.Builder{}
+		return csvprocessor.NoOpCloser(stored[name]), "mem://" + name, nil
+	}
+
+	rec := httptest.NewRecorder()
+	handler := httpserver.NewHandler(httpserver.WithStore(store))
+	handler.ServeHTTP(rec, newRequest(t, map[string]string{"chunk_size": "2"}, input))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %q", rec.Code, rec.Body.String())
+	}
+
+	want := `{"chunks":["mem://part_001.csv","mem://part_002.csv"]}` + "\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+
+	if got := stored["part_002.csv"].String(); got != "id,name\n3,carol\n" {
+		t.Errorf("part_002.csv = %q", got)
+	}
+}
+
+func TestHandler_Cancelled(t *testing.T) {
+	var stored []string
+	store := func(ctx context.Context, name string) (io.WriteCloser, string, error) {
+		stored = append(stored, name)
+		return csvprocessor.NoOpCloser(io.Discard), "mem://" + name, nil
+	}
+
+	// the client disconnected before the processing started.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rec := httptest.NewRecorder()
+	httpserver.NewHandler(httpserver.WithStore(store)).ServeHTTP(rec, newRequest(t, map[string]string{"chunk_size": "1"}, input).WithContext(ctx))
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+
+	if len(stored) != 0 {
+		t.Errorf("stored chunks = %q, want none", stored)
+	}
+}
+
+func TestHandler_Pipeline(t *testing.T) {
+	errUnknown := errors.New("unknown transform")
+	pipeline := func(r *http.Request, params url.Values) ([]csvprocessor.Option, error) {
+		switch params.Get("transform") {
+		case "":
+			return nil, nil
+		case "upper":
+			return []csvprocessor.Option{csvprocessor.WithTransformer(func(ctx context.Context, row []string) []string {
+				return []string{row[0], strings.ToUpper(row[1])}
+			})}, nil
+		}
+
+		return nil, errUnknown
+	}
+
+	handler := httpserver.NewHandler(httpserver.WithPipeline(pipeline))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest(t, map[string]string{"transform": "upper"}, input))
+	want := map[string]string{"part_001.csv": "id,NAME\n1,ALICE\n2,BOB\n3,CAROL\n"}
+	if got := unzip(t, rec.Body.Bytes()); !reflect.DeepEqual(got, want) {
+		t.Errorf("chunks = %q, want %q", got, want)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest(t, map[string]string{"transform": "lower"}, input))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandler_BadRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]string
+	}{
+		{"invalid chunk size", map[string]string{"chunk_size": "ten"}},
+		{"invalid delimiter", map[string]string{"input_delimiter": ";;"}},
+		{"invalid format", map[string]string{"format": "yaml"}},
+		{"unsafe name", map[string]string{"name_format": "../part_%d.csv"}},
+		{"name without verb", map[string]string{"name_format": "part.csv"}},
+		{"name with two verbs", map[string]string{"name_format": "part_%d_%d.csv"}},
+		{"name with string verb", map[string]string{"name_format": "part_%s.csv"}},
+		{"name with wide verb", map[string]string{"name_format": "part_%0999999999d.csv"}},
+		{"name with indexed verb", map[string]string{"name_format": "part_%[1]d.csv"}},
+		{"name with trailing percent", map[string]string{"name_format": "part_%d%"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			httpserver.NewHandler().ServeHTTP(rec, newRequest(t, tt.fields, input))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		_ = form.WriteField("chunk_size", "2")
+		_ = form.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/split", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		rec := httptest.NewRecorder()
+		httpserver.NewHandler().ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "file") {
+			t.Errorf("status = %d, body = %q", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		httpserver.NewHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/split", nil))
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package csvprocessor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// DefaultInferSampleRows is the default no. of rows sampled by InferSchema().
+const DefaultInferSampleRows = 1000
+
+// InferSchema reads at most sampleRows data rows of the reader and returns a Schema that the rows match, Eg:
+//
+//	schema, err := csvprocessor.InferSchema(csv.NewReader(input), true, csvprocessor.DefaultInferSampleRows)
+//	data, err := json.Marshal(schema) // {"columns": [{"name": "id", "type": "int", "required": true, "max_length": 4}, ...]}
+//
+// If hasHeader is true, the first row is the header and it gives the names of the columns.
+// The type of a column is the most specific of int, float, bool and time (in the layouts RFC3339, 2006-01-02,
+// "2006-01-02 15:04:05" and 2006-01-02T15:04:05) that all the non-empty values of the sample have, else string.
+// A column is required if none of the sampled rows has an empty value for it, and MaxLength is the length
+// of its longest value in the sample.
+//
+// The schema can be used with WithSchemaValidation(), but it describes only the sampled rows,
+// so it should be reviewed before validating the rest of the input, Eg: to increase MaxLength.
+func InferSchema(reader CsvReader, hasHeader bool, sampleRows int) (Schema, error) {
+	if sampleRows <= 0 {
+		return Schema{}, ErrInvalidSampleSize
+	}
+
+	var inferrer schemaInferrer
+	for sampled := 0; sampled < sampleRows; {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil && row == nil {
+			return Schema{}, fmt.Errorf("csvprocessor: error while reading input: %w", err)
+		}
+
+		// the rows with a parse error (Eg: a different no. of fields) are sampled like the processor processes them.
+		if hasHeader && inferrer.header == nil {
+			inferrer.header = append([]string{}, row...)
+			continue
+		}
+
+		inferrer.add(row)
+		sampled++
+	}
+
+	return inferrer.schema(), nil
+}
+
+// inferredTypes are the types tried while inferring the type of a column, in the order of preference.
+var inferredTypes = func() []ColumnSchema {
+	types := []ColumnSchema{{Type: TypeInt}, {Type: TypeFloat}, {Type: TypeBool}}
+	for _, layout := range profileTimeLayouts {
+		types = append(types, ColumnSchema{Type: TypeTime, Layout: layout})
+	}
+
+	return types
+}()
+
+// schemaInferrer infers the schema of the rows added to it.
+type schemaInferrer struct {
+	header  []string
+	rows    int
+	columns []*inferredColumn
+}
+
+// inferredColumn is the running schema of a column.
+type inferredColumn struct {
+	values    int    // no. of non-empty values.
+	empty     int    // no. of rows with an empty or missing value.
+	matches   []bool // whether all the values so far match each of inferredTypes.
+	maxLength int
+}
+
+func (s *schemaInferrer) add(row []string) {
+	for len(s.columns) < len(row) {
+		// the rows before the first row with the column did not have it.
+		s.columns = append(s.columns, newInferredColumn(s.rows))
+	}
+
+	for i, column := range s.columns {
+		column.add(valueAt(row, i))
+	}
+
+	s.rows++
+}
+
+func newInferredColumn(empty int) *inferredColumn {
+	matches := make([]bool, len(inferredTypes))
+	for i := range matches {
+		matches[i] = true
+	}
+
+	return &inferredColumn{empty: empty, matches: matches}
+}
+
+func (c *inferredColumn) add(value string) {
+	if value == "" {
+		// empty values are only checked for Required by the validator, see ColumnSchema.
+		c.empty++
+		return
+	}
+
+	c.values++
+	if length := utf8.RuneCountInString(value); length > c.maxLength {
+		c.maxLength = length
+	}
+
+	for i, candidate := range inferredTypes {
+		if c.matches[i] && !matchesType(candidate, value) {
+			c.matches[i] = false
+		}
+	}
+}
+
+// matchesType returns whether the value is valid for the type of the column, the same way as the validator.
+func matchesType(column ColumnSchema, value string) bool {
+	if column.Type == TypeTime && (len(value) < len("2006-01-02") || value[4] != '-') {
+		// the layouts start with the date, so the other values are not parsed.
+		return false
+	}
+
+	return checkType(column.Type, column.Layout, value) == ""
+}
+
+func (s *schemaInferrer) schema() Schema {
+	columns := len(s.columns)
+	if len(s.header) > columns {
+		columns = len(s.header)
+	}
+
+	schema := Schema{Columns: make([]ColumnSchema, 0, columns)}
+	for i := 0; i < columns; i++ {
+		column := ColumnSchema{Name: valueAt(s.header, i), Type: TypeString}
+		if i < len(s.columns) {
+			inferred := s.columns[i]
+			column.Required = inferred.empty == 0
+			column.MaxLength = inferred.maxLength
+			for j, candidate := range inferredTypes {
+				if inferred.values > 0 && inferred.matches[j] {
+					column.Type, column.Layout = candidate.Type, candidate.Layout
+					break
+				}
+			}
+		}
+
+		schema.Columns = append(schema.Columns, column)
+	}
+
+	return schema
+}
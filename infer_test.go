@@ -0,0 +1,117 @@
+package csvprocessor_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestInferSchema(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		hasHeader  bool
+		sampleRows int
+		want       csvprocessor.Schema
+		wantErr    error
+	}{
+		{
+			name: "Test types",
+			input: "id,amount,active,created,updated,name,note\n" +
+				"1,1.5,true,2024-01-02,2024-01-02T10:00:00Z,alice,\n" +
+				"20,5,0,2024-01-03,2024-01-03T10:00:00+05:30,bob,x\n",
+			hasHeader:  true,
+			sampleRows: csvprocessor.DefaultInferSampleRows,
+			want: csvprocessor.Schema{Columns: []csvprocessor.ColumnSchema{
+				{Name: "id", Type: csvprocessor.TypeInt, Required: true, MaxLength: 2},
+				{Name: "amount", Type: csvprocessor.TypeFloat, Required: true, MaxLength: 3},
+				{Name: "active", Type: csvprocessor.TypeBool, Required: true, MaxLength: 4},
+				{Name: "created", Type: csvprocessor.TypeTime, Layout: "2006-01-02", Required: true, MaxLength: 10},
+				{Name: "updated", Type: csvprocessor.TypeTime, Layout: "2006-01-02T15:04:05Z07:00", Required: true, MaxLength: 25},
+				{Name: "name", Type: csvprocessor.TypeString, Required: true, MaxLength: 5},
+				{Name: "note", Type: csvprocessor.TypeString, MaxLength: 1},
+			}},
+		},
+		{
+			name:       "Test sample",
+			input:      "id\n1\n2\nthree\n",
+			hasHeader:  true,
+			sampleRows: 2,
+			want:       csvprocessor.Schema{Columns: []csvprocessor.ColumnSchema{{Name: "id", Type: csvprocessor.TypeInt, Required: true, MaxLength: 1}}},
+		},
+		{
+			name:       "Test without header",
+			input:      "1,a\n2\n",
+			sampleRows: 10,
+			want: csvprocessor.Schema{Columns: []csvprocessor.ColumnSchema{
+				{Type: csvprocessor.TypeInt, Required: true, MaxLength: 1},
+				{Type: csvprocessor.TypeString, MaxLength: 1},
+			}},
+		},
+		{
+			name:       "Test header without rows",
+			input:      "id,name\n",
+			hasHeader:  true,
+			sampleRows: 10,
+			want: csvprocessor.Schema{Columns: []csvprocessor.ColumnSchema{
+				{Name: "id", Type: csvprocessor.TypeString},
+				{Name: "name", Type: csvprocessor.TypeString},
+			}},
+		},
+		{
+			name:       "Test invalid sample size",
+			input:      "id\n1\n",
+			sampleRows: 0,
+			wantErr:    csvprocessor.ErrInvalidSampleSize,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := csv.NewReader(strings.NewReader(tt.input))
+			reader.FieldsPerRecord = -1
+			got, err := csvprocessor.InferSchema(reader, tt.hasHeader, tt.sampleRows)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("InferSchema() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InferSchema() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInferSchema_Validation(t *testing.T) {
+	input := "id,amount,created\n1,1.5,2024-01-02\n2,,2024-01-03\n"
+	schema, err := csvprocessor.InferSchema(csv.NewReader(strings.NewReader(input)), true, csvprocessor.DefaultInferSampleRows)
+	if err != nil {
+		t.Fatalf("InferSchema() error = %v", err)
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	want := `{"columns":[{"name":"id","type":"int","required":true,"max_length":1},` +
+		`{"name":"amount","type":"float","required":false,"max_length":3},` +
+		`{"name":"created","type":"time","layout":"2006-01-02","required":true,"max_length":10}]}`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+
+	var decoded csvprocessor.Schema
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	// the inferred schema accepts the sampled rows.
+	if _, err := processString(t, input, csvprocessor.WithSchemaValidation(decoded)); err != nil {
+		t.Errorf("Process() error = %v", err)
+	}
+}
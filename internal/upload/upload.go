@@ -0,0 +1,68 @@
+// Package upload streams the data written to an io.WriteCloser to the upload APIs that read from an io.Reader.
+package upload
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+var (
+	// ErrAborted is returned to the upload function when the writer is aborted.
+	ErrAborted = errors.New("upload: aborted")
+
+	// errFinished is returned by Write if the upload returns before reading all the data.
+	errFinished = errors.New("upload: upload finished before all the data was written")
+)
+
+// Writer is an io.WriteCloser that streams the written data to an upload function running in a separate goroutine.
+type Writer struct {
+	pw   *io.PipeWriter
+	done chan error
+
+	once sync.Once
+	err  error
+}
+
+// NewWriter starts the upload function with a reader of the data written to the returned Writer.
+// The upload must read till EOF, the error returned by it is returned by Close.
+func NewWriter(upload func(r io.Reader) error) *Writer {
+	pr, pw := io.Pipe()
+	w := &Writer{pw: pw, done: make(chan error, 1)}
+	go func() {
+		err := upload(pr)
+		if err != nil {
+			pr.CloseWithError(err)
+		} else {
+			pr.CloseWithError(errFinished)
+		}
+
+		w.done <- err
+	}()
+
+	return w
+}
+
+// Write writes the data to the upload.
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+// Close completes the upload and waits for it to finish.
+func (w *Writer) Close() error {
+	return w.finish(nil)
+}
+
+// Abort fails the upload with ErrAborted and waits for it to finish.
+func (w *Writer) Abort() {
+	_ = w.finish(ErrAborted)
+}
+
+func (w *Writer) finish(err error) error {
+	w.once.Do(func() {
+		_ = w.pw.CloseWithError(err)
+		w.err = <-w.done
+	})
+
+	return w.err
+}
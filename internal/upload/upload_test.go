@@ -0,0 +1,65 @@
+package upload_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor/internal/upload"
+)
+
+func TestWriter(t *testing.T) {
+	var uploaded []byte
+	w := upload.NewWriter(func(r io.Reader) (err error) {
+		uploaded, err = io.ReadAll(r)
+		return err
+	})
+
+	if _, err := io.WriteString(w, "a,b\n1,2\n"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if string(uploaded) != "a,b\n1,2\n" {
+		t.Errorf("uploaded = %q, want %q", uploaded, "a,b\n1,2\n")
+	}
+
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
+
+func TestWriter_UploadError(t *testing.T) {
+	errUpload := errors.New("access denied")
+	w := upload.NewWriter(func(r io.Reader) error {
+		return errUpload
+	})
+
+	if _, err := io.WriteString(w, "data"); !errors.Is(err, errUpload) {
+		t.Errorf("Write() error = %v, want %v", err, errUpload)
+	}
+
+	if err := w.Close(); !errors.Is(err, errUpload) {
+		t.Errorf("Close() error = %v, want %v", err, errUpload)
+	}
+}
+
+func TestWriter_Abort(t *testing.T) {
+	var readErr error
+	w := upload.NewWriter(func(r io.Reader) error {
+		_, readErr = io.ReadAll(r)
+		return readErr
+	})
+
+	if _, err := io.WriteString(w, "partial"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	w.Abort()
+	if !errors.Is(readErr, upload.ErrAborted) {
+		t.Errorf("upload read error = %v, want %v", readErr, upload.ErrAborted)
+	}
+}
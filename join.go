@@ -0,0 +1,276 @@
+package csvprocessor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidJoin is returned when the right input of the join is nil, the key columns are empty or negative,
+// the left and right inputs have a different no. of key columns, or the kind is unknown.
+var ErrInvalidJoin = errors.New("csvprocessor: join needs a right input, the same no. of key columns >= 0 on both sides and a valid kind")
+
+// JoinKind represents how the rows of the input without a matching row in the right input are handled, see WithJoin().
+type JoinKind int
+
+const (
+	// InnerJoin drops the rows without a matching row in the right input.
+	InnerJoin JoinKind = iota
+	// LeftJoin keeps the rows without a matching row in the right input, with empty values for the columns of the right input.
+	LeftJoin
+)
+
+// Join describes the join of the input with another CSV input, see WithJoin().
+type Join struct {
+	// Right is the input that is joined with the input of the processor.
+	Right CsvReader
+	// LeftKeys are the key columns of the input, and RightKeys are the key columns of Right in the same order.
+	LeftKeys, RightKeys []int
+	// Kind is the kind of the join, the default is InnerJoin.
+	Kind JoinKind
+	// Sorted does a merge join when both the inputs are sorted by the key columns in ascending order,
+	// instead of loading Right into memory. ErrUnsortedInput is returned if they are not sorted.
+	Sorted bool
+}
+
+// WithJoin joins the rows of the input with the rows of another CSV input that have the same values in the key columns. Eg:
+//
+//	csvprocessor.WithFileReader("orders.csv"),
+//	csvprocessor.WithJoin(csvprocessor.Join{Right: csv.NewReader(customers), LeftKeys: []int{1}, RightKeys: []int{0}, Kind: csvprocessor.LeftJoin}),
+//
+// Each joined row has the values of the input row followed by the values of the matching row in Right except its key columns,
+// a row is repeated for each matching row. The header is joined the same way if the inputs have headers (i.e SkipHeaders is false).
+// The joined rows are passed to the transformer and written to the chunks like the input rows.
+//
+// Right is loaded into memory, which is bounded by WithMaxMemory(), unless both the inputs are sorted (see Join.Sorted).
+// The keys are compared as strings, and Right is not closed by the processor.
+func WithJoin(join Join) Option {
+	return func(c *Processor) error {
+		if join.Right == nil || len(join.LeftKeys) == 0 || len(join.LeftKeys) != len(join.RightKeys) ||
+			join.Kind < InnerJoin || join.Kind > LeftJoin {
+			return ErrInvalidJoin
+		}
+
+		for i := range join.LeftKeys {
+			if join.LeftKeys[i] < 0 || join.RightKeys[i] < 0 {
+				return ErrInvalidJoin
+			}
+		}
+
+		join.LeftKeys = append([]int(nil), join.LeftKeys...)
+		join.RightKeys = append([]int(nil), join.RightKeys...)
+		c.join = &join
+		return nil
+	}
+}
+
+// joinReader is a CsvReader that joins the rows of the reader with the rows of the right input.
+type joinReader struct {
+	reader    CsvReader
+	join      Join
+	hasHeader bool
+	budget    *memoryBudget // bounds the rows of the right input loaded into memory, see WithMaxMemory().
+
+	started     bool
+	headerRead  bool
+	rightHeader []string // header of the right input without the key columns.
+	rightWidth  int      // no. of non-key columns in the right input.
+
+	table map[string][][]string // non-key values of the right rows by their key, when the inputs are not sorted.
+
+	// the inputs are merged when they are sorted.
+	right    []string   // next row of the right input, nil at the end of the input.
+	group    [][]string // the right rows with the key of groupRow.
+	groupRow []string
+	lastLeft []string
+
+	left    []string   // the current row of the input.
+	matches [][]string // the non-key values of the right rows matching left, yet to be returned.
+	record  []string
+}
+
+func newJoinReader(reader CsvReader, join Join, hasHeader bool, budget *memoryBudget) *joinReader {
+	return &joinReader{reader: reader, join: join, hasHeader: hasHeader, budget: budget}
+}
+
+func (j *joinReader) Read() ([]string, error) {
+	if !j.started {
+		j.started = true
+		if err := j.start(); err != nil {
+			return nil, err
+		}
+	}
+
+	for len(j.matches) == 0 {
+		row, err := j.reader.Read()
+		if err != nil {
+			// the malformed rows are returned as they are.
+			return row, err
+		}
+
+		if j.hasHeader && !j.headerRead {
+			j.headerRead = true
+			return append(append(j.record[:0], row...), j.rightHeader...), nil
+		}
+
+		j.left = append(j.left[:0], row...)
+		if j.matches, err = j.match(j.left); err != nil {
+			return nil, err
+		}
+
+		if len(j.matches) == 0 && j.join.Kind == LeftJoin {
+			j.matches = [][]string{make([]string, j.rightWidth)}
+		}
+	}
+
+	j.record = append(append(j.record[:0], j.left...), j.matches[0]...)
+	j.matches = j.matches[1:]
+	return j.record, nil
+}
+
+// start reads the header of the right input, and loads the right input into memory if the inputs are not sorted.
+func (j *joinReader) start() error {
+	if j.hasHeader {
+		header, err := j.readRight()
+		if err != nil {
+			return err
+		}
+
+		j.rightHeader = j.nonKey(header)
+		j.rightWidth = len(j.rightHeader)
+	}
+
+	if j.join.Sorted {
+		right, err := j.readRight()
+		if err != nil {
+			return err
+		}
+
+		if !j.hasHeader {
+			j.rightWidth = len(j.nonKey(right))
+		}
+
+		j.right = right
+		return nil
+	}
+
+	j.table = make(map[string][][]string)
+	for {
+		row, err := j.readRight()
+		if err != nil {
+			return err
+		}
+
+		if row == nil {
+			return nil
+		}
+
+		values := j.nonKey(row)
+		if !j.budget.reserve(rowSize(row)) {
+			return j.budget.exceeded("WithJoin()")
+		}
+
+		if !j.hasHeader && len(values) > j.rightWidth {
+			j.rightWidth = len(values)
+		}
+
+		key := rowKey(row, j.join.RightKeys)
+		j.table[key] = append(j.table[key], values)
+	}
+}
+
+// readRight reads a copy of the next row of the right input, it returns nil at the end of the input.
+func (j *joinReader) readRight() ([]string, error) {
+	row, err := readCopy(j.join.Right)
+	if err != nil {
+		return nil, fmt.Errorf("csvprocessor: error while reading the right input of the join: %w", err)
+	}
+
+	return row, nil
+}
+
+// match returns the non-key values of the right rows matching the left row.
+func (j *joinReader) match(left []string) ([][]string, error) {
+	if !j.join.Sorted {
+		return j.table[rowKey(left, j.join.LeftKeys)], nil
+	}
+
+	if j.lastLeft != nil && j.compare(left, j.join.LeftKeys, j.lastLeft, j.join.LeftKeys) < 0 {
+		return nil, fmt.Errorf("%w: join key %v after %v", ErrUnsortedInput, keyValues(left, j.join.LeftKeys), keyValues(j.lastLeft, j.join.LeftKeys))
+	}
+
+	j.lastLeft = append(j.lastLeft[:0], left...)
+	if j.groupRow != nil && j.compare(left, j.join.LeftKeys, j.groupRow, j.join.RightKeys) == 0 {
+		// the left rows with the same key match the same group.
+		return j.group, nil
+	}
+
+	j.group, j.groupRow = nil, nil
+	for j.right != nil {
+		cmp := j.compare(j.right, j.join.RightKeys, left, j.join.LeftKeys)
+		if cmp > 0 {
+			break
+		}
+
+		if cmp == 0 {
+			j.group = append(j.group, j.nonKey(j.right))
+			j.groupRow = j.right
+		}
+
+		next, err := j.readRight()
+		if err != nil {
+			return nil, err
+		}
+
+		if next != nil && j.compare(next, j.join.RightKeys, j.right, j.join.RightKeys) < 0 {
+			return nil, fmt.Errorf("%w: join key %v after %v in the right input",
+				ErrUnsortedInput, keyValues(next, j.join.RightKeys), keyValues(j.right, j.join.RightKeys))
+		}
+
+		j.right = next
+	}
+
+	return j.group, nil
+}
+
+// compare compares the keys of the rows in the order of the Sorter.
+func (j *joinReader) compare(a []string, aKeys []int, b []string, bKeys []int) int {
+	for i := range aKeys {
+		if cmp := strings.Compare(valueAt(a, aKeys[i]), valueAt(b, bKeys[i])); cmp != 0 {
+			return cmp
+		}
+	}
+
+	return 0
+}
+
+// nonKey returns the values of the right row except the key columns.
+func (j *joinReader) nonKey(row []string) []string {
+	values := make([]string, 0, len(row))
+	for i, value := range row {
+		if !containsInt(j.join.RightKeys, i) {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func keyValues(row []string, columns []int) []string {
+	key := make([]string, len(columns))
+	for i, column := range columns {
+		key[i] = valueAt(row, column)
+	}
+
+	return key
+}
@@ -0,0 +1,94 @@
+package csvprocessor_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithJoin(t *testing.T) {
+	orders := "order,customer,amount\n1,c2,10\n2,c1,20\n3,c3,30\n4,c2,40\n"
+	sortedOrders := "order,customer,amount\n2,c1,20\n1,c2,10\n4,c2,40\n3,c3,30\n"
+	customers := "id,name,city\nc1,alice,LA\nc2,bob,NY\nc2,bobby,SF\n"
+
+	tests := []struct {
+		name    string
+		input   string
+		right   string
+		join    csvprocessor.Join
+		opts    []csvprocessor.Option
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "Test inner join",
+			input: orders,
+			right: customers,
+			join:  csvprocessor.Join{LeftKeys: []int{1}, RightKeys: []int{0}},
+			want: "order,customer,amount,name,city\n1,c2,10,bob,NY\n1,c2,10,bobby,SF\n2,c1,20,alice,LA\n" +
+				"4,c2,40,bob,NY\n4,c2,40,bobby,SF\n",
+		},
+		{
+			name:  "Test left join",
+			input: orders,
+			right: customers,
+			join:  csvprocessor.Join{LeftKeys: []int{1}, RightKeys: []int{0}, Kind: csvprocessor.LeftJoin},
+			want: "order,customer,amount,name,city\n1,c2,10,bob,NY\n1,c2,10,bobby,SF\n2,c1,20,alice,LA\n3,c3,30,,\n" +
+				"4,c2,40,bob,NY\n4,c2,40,bobby,SF\n",
+		},
+		{
+			name:  "Test sorted left join",
+			input: sortedOrders,
+			right: customers,
+			join:  csvprocessor.Join{LeftKeys: []int{1}, RightKeys: []int{0}, Kind: csvprocessor.LeftJoin, Sorted: true},
+			want: "order,customer,amount,name,city\n2,c1,20,alice,LA\n1,c2,10,bob,NY\n1,c2,10,bobby,SF\n" +
+				"4,c2,40,bob,NY\n4,c2,40,bobby,SF\n3,c3,30,,\n",
+		},
+		{
+			name:  "Test join without header",
+			input: "1,c2\n2,c9\n",
+			right: "c2,bob\n",
+			join:  csvprocessor.Join{LeftKeys: []int{1}, RightKeys: []int{0}, Kind: csvprocessor.LeftJoin},
+			opts:  []csvprocessor.Option{csvprocessor.SkipHeaders(true)},
+			want:  "1,c2,bob\n2,c9,\n",
+		},
+		{
+			name:    "Test unsorted input",
+			input:   orders,
+			right:   customers,
+			join:    csvprocessor.Join{LeftKeys: []int{1}, RightKeys: []int{0}, Sorted: true},
+			wantErr: csvprocessor.ErrUnsortedInput,
+		},
+		{
+			name:    "Test right input exceeding the memory limit",
+			input:   orders,
+			right:   "id,name\n" + strings.Repeat("c1,alice\n", 100),
+			join:    csvprocessor.Join{LeftKeys: []int{1}, RightKeys: []int{0}},
+			opts:    []csvprocessor.Option{csvprocessor.WithMaxMemory(1024)},
+			wantErr: csvprocessor.ErrMaxMemoryExceeded,
+		},
+		{
+			name:    "Test invalid keys",
+			input:   orders,
+			right:   customers,
+			join:    csvprocessor.Join{LeftKeys: []int{1}, RightKeys: []int{0, 1}},
+			wantErr: csvprocessor.ErrInvalidJoin,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.join.Right = csv.NewReader(strings.NewReader(tt.right))
+			output, err := processString(t, tt.input, append(tt.opts, csvprocessor.WithJoin(tt.join))...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
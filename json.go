@@ -0,0 +1,357 @@
+package csvprocessor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ErrNotJSONObject is returned (wrapped in a *csv.ParseError) by JSONLinesReader when a line is not a JSON object.
+var ErrNotJSONObject = errors.New("csvprocessor: line is not a JSON object")
+
+// JSONWriter is a CsvWriter that writes the rows as JSON objects, using the header as the keys.
+// The rows are either written as a single JSON array (see NewJSONArrayWriter()) or as JSON Lines (see NewJSONLinesWriter()).
+// The rows are streamed to the underlying writer, Close() must be called to complete the JSON array.
+type JSONWriter struct {
+	w     *bufio.Writer
+	array bool
+
+	// Unexported fields
+	keys []string // JSON keys for each column.
+	rows int      // no. of rows written.
+	err  error
+}
+
+// NewJSONArrayWriter creates a JSONWriter that writes the rows as a single JSON array of objects.
+func NewJSONArrayWriter(w io.Writer) *JSONWriter {
+	return &JSONWriter{w: bufio.NewWriter(w), array: true}
+}
+
+// NewJSONLinesWriter creates a JSONWriter that writes each row as a JSON object in a separate line.
+func NewJSONLinesWriter(w io.Writer) *JSONWriter {
+	return &JSONWriter{w: bufio.NewWriter(w)}
+}
+
+// WriteHeader sets the keys of the JSON objects, see JSONKeys() for how the header is mapped to keys.
+// If WriteHeader is not called, the keys are column_1, column_2 and so on.
+func (j *JSONWriter) WriteHeader(header []string) error {
+	j.keys = JSONKeys(header)
+	return nil
+}
+
+// Write writes the row as a JSON object.
+func (j *JSONWriter) Write(record []string) error {
+	if j.err != nil {
+		return j.err
+	}
+
+	if len(record) > len(j.keys) {
+		// the columns without a header get generated keys.
+		j.keys = append(j.keys, JSONKeys(make([]string, len(record)))[len(j.keys):]...)
+	}
+
+	buf := make([]byte, 0, 64*len(record))
+	switch {
+	case !j.array:
+	case j.rows == 0:
+		buf = append(buf, "[\n"...)
+	default:
+		buf = append(buf, ",\n"...)
+	}
+
+	buf = append(buf, '{')
+	for i, value := range record {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+
+		buf = appendJSONString(buf, j.keys[i])
+		buf = append(buf, ':')
+		buf = appendJSONString(buf, value)
+	}
+
+	buf = append(buf, '}')
+	if !j.array {
+		buf = append(buf, '\n')
+	}
+
+	j.rows++
+	_, j.err = j.w.Write(buf)
+	return j.err
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (j *JSONWriter) Flush() {
+	if j.err == nil {
+		j.err = j.w.Flush()
+	}
+}
+
+// Error reports any error that has occurred during a previous Write or Flush.
+func (j *JSONWriter) Error() error {
+	return j.err
+}
+
+// Close completes the JSON array and flushes the writer, the underlying writer is not closed.
+func (j *JSONWriter) Close() error {
+	if j.err == nil && j.array {
+		if j.rows == 0 {
+			_, j.err = j.w.WriteString("[]\n")
+		} else {
+			_, j.err = j.w.WriteString("\n]\n")
+		}
+	}
+
+	j.Flush()
+	return j.err
+}
+
+// JSONKeys returns the JSON keys for the given header,
+// empty column names are replaced by column_<n> (starting from 1) and duplicate names get a _<n> suffix.
+// Eg: the header ["id", "", "id"] gives the keys ["id", "column_2", "id_2"].
+func JSONKeys(header []string) []string {
+	keys := make([]string, len(header))
+	seen := make(map[string]int, len(header))
+	for i, name := range header {
+		if name == "" {
+			name = "column_" + strconv.Itoa(i+1)
+		}
+
+		key := name
+		for seen[key] > 0 {
+			seen[name]++
+			key = name + "_" + strconv.Itoa(seen[name])
+		}
+
+		seen[key]++
+		keys[i] = key
+	}
+
+	return keys
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends the value as a JSON string, invalid UTF-8 is replaced by U+FFFD.
+func appendJSONString(buf []byte, value string) []byte {
+	buf = append(buf, '"')
+	for i := 0; i < len(value); {
+		b := value[i]
+		if b < utf8.RuneSelf {
+			switch {
+			case b == '"' || b == '\\':
+				buf = append(buf, '\\', b)
+			case b == '\n':
+				buf = append(buf, '\\', 'n')
+			case b == '\r':
+				buf = append(buf, '\\', 'r')
+			case b == '\t':
+				buf = append(buf, '\\', 't')
+			case b < 0x20:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			default:
+				buf = append(buf, b)
+			}
+
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(value[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			buf = append(buf, "\ufffd"...)
+		case r == '\u2028' || r == '\u2029':
+			// valid JSON, but not valid JavaScript.
+			buf = append(buf, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+		default:
+			buf = append(buf, value[i:i+size]...)
+		}
+
+		i += size
+	}
+
+	return append(buf, '"')
+}
+
+// JSONLinesReader is a CsvReader that reads JSON Lines (NDJSON), each line must be a JSON object.
+// The top-level keys of the objects are the columns, the first row returned is the header.
+// String values are returned as they are, null as an empty string and other values as compact JSON, Eg: 10, true or {"a":1}.
+// Lines that are not valid JSON objects are returned as *csv.ParseError, so that the processor records and skips them.
+type JSONLinesReader struct {
+	r   *bufio.Reader
+	src io.Reader // reader buffered by r, nil if a *bufio.Reader was given.
+
+	// Unexported fields
+	header     []string
+	index      map[string]int // index of each column in the header.
+	headerRead bool
+	line       int
+	pending    []string // first record, read to derive the header.
+}
+
+// NewJSONLinesReader creates a JSONLinesReader, the header is the given columns,
+// or if no columns are given, the keys of the first object in the order they appear.
+// The keys that are not in the header are ignored and the missing keys are returned as empty strings.
+// If r is not a *bufio.Reader, it is buffered using a buffer of DefaultReadBufferSize,
+// or of the size set by WithReadBufferSize() when the reader is passed to WithReader().
+func NewJSONLinesReader(r io.Reader, columns ...string) *JSONLinesReader {
+	j := &JSONLinesReader{}
+	if reader, ok := r.(*bufio.Reader); ok {
+		j.r = reader
+	} else {
+		j.r = bufio.NewReaderSize(r, DefaultReadBufferSize)
+		j.src = r
+	}
+
+	if len(columns) > 0 {
+		j.setHeader(columns)
+	}
+
+	return j
+}
+
+// setBufferSize replaces the buffer created by NewJSONLinesReader() with one of the given size, if nothing has been read yet.
+func (j *JSONLinesReader) setBufferSize(size int) {
+	if j.src == nil || j.r.Size() == size || j.line > 0 || j.r.Buffered() > 0 {
+		return
+	}
+
+	j.r = bufio.NewReaderSize(j.src, size)
+}
+
+func (j *JSONLinesReader) setHeader(columns []string) {
+	j.header = append([]string(nil), columns...)
+	j.index = make(map[string]int, len(columns))
+	for i, column := range columns {
+		if _, ok := j.index[column]; !ok {
+			j.index[column] = i
+		}
+	}
+}
+
+// Read returns the header first and then a row for each object.
+func (j *JSONLinesReader) Read() ([]string, error) {
+	if !j.headerRead {
+		if j.header == nil {
+			keys, values, err := j.readObject()
+			if err != nil {
+				return nil, err
+			}
+
+			j.setHeader(keys)
+			j.pending = values
+		}
+
+		j.headerRead = true
+		return append([]string(nil), j.header...), nil
+	}
+
+	if j.pending != nil {
+		row := j.pending
+		j.pending = nil
+		return row, nil
+	}
+
+	keys, values, err := j.readObject()
+	if err != nil {
+		return nil, err
+	}
+
+	row := make([]string, len(j.header))
+	for i, key := range keys {
+		if column, ok := j.index[key]; ok {
+			row[column] = values[i]
+		}
+	}
+
+	return row, nil
+}
+
+// readObject reads the next non-empty line and returns the keys and values of the object in it.
+func (j *JSONLinesReader) readObject() ([]string, []string, error) {
+	for {
+		line, err := j.r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) == 0 {
+			if err != nil {
+				return nil, nil, err
+			}
+
+			j.line++
+			continue
+		}
+
+		j.line++
+		keys, values, parseErr := parseJSONObject(line)
+		if parseErr != nil {
+			return nil, nil, &csv.ParseError{StartLine: j.line, Line: j.line, Err: parseErr}
+		}
+
+		return keys, values, nil
+	}
+}
+
+// parseJSONObject parses the object in the given line, keeping the order of the keys.
+func parseJSONObject(line []byte) ([]string, []string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, nil, ErrNotJSONObject
+	}
+
+	var keys, values []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		key, _ := token.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+
+		text, err := jsonValueText(value)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		keys = append(keys, key)
+		values = append(values, text)
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, nil, ErrNotJSONObject
+	}
+
+	return keys, values, nil
+}
+
+// jsonValueText returns the value of a column for the given JSON value.
+func jsonValueText(value json.RawMessage) (string, error) {
+	switch value[0] {
+	case '"':
+		var text string
+		err := json.Unmarshal(value, &text)
+		return text, err
+	case 'n':
+		return "", nil
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, value); err != nil {
+		return "", err
+	}
+
+	return compact.String(), nil
+}
@@ -0,0 +1,221 @@
+package csvprocessor_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithJSONOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opt   []csvprocessor.Option
+		want  string
+	}{
+		{
+			name:  "Test array",
+			input: "id,name\n1,alice\n2,\"bob \"\"b\"\"\"\n",
+			opt:   []csvprocessor.Option{csvprocessor.WithJSONOutput()},
+			want:  "[\n{\"id\":\"1\",\"name\":\"alice\"},\n{\"id\":\"2\",\"name\":\"bob \\\"b\\\"\"}\n]\n",
+		},
+		{
+			name:  "Test array with chunks",
+			input: "id\n1\n2\n3\n",
+			opt:   []csvprocessor.Option{csvprocessor.WithJSONOutput(), csvprocessor.WithChunkSize(2)},
+			want:  "[\n{\"id\":\"1\"},\n{\"id\":\"2\"}\n]\n[\n{\"id\":\"3\"}\n]\n",
+		},
+		{
+			name:  "Test array without rows",
+			input: "id,name\n",
+			opt:   []csvprocessor.Option{csvprocessor.WithJSONOutput()},
+			want:  "[]\n",
+		},
+		{
+			name:  "Test array without header",
+			input: "1,alice\n2,bob,x\n",
+			opt:   []csvprocessor.Option{csvprocessor.WithJSONOutput(), csvprocessor.SkipHeaders(true)},
+			want:  "[\n{\"column_1\":\"1\",\"column_2\":\"alice\"},\n{\"column_1\":\"2\",\"column_2\":\"bob\",\"column_3\":\"x\"}\n]\n",
+		},
+		{
+			name:  "Test lines",
+			input: "id,,id\n1,a\tb,x\n",
+			opt:   []csvprocessor.Option{csvprocessor.WithJSONLinesOutput()},
+			want:  "{\"id\":\"1\",\"column_2\":\"a\\tb\",\"id_2\":\"x\"}\n",
+		},
+		{
+			name:  "Test lines with transformer",
+			input: "id\n1\n",
+			opt: []csvprocessor.Option{
+				csvprocessor.WithJSONLinesOutput(),
+				csvprocessor.WithTransformer(csvprocessor.AddRowNoTransformer("row")),
+			},
+			want: "{\"row\":\"1\",\"id\":\"1\"}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processString(t, tt.input, tt.opt...)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONWriter_ValidJSON(t *testing.T) {
+	values := []string{"plain", "quote \" backslash \\", "new\nline\r", "control \x01", "html <&>", "invalid \xff utf8", "separator \u2028 \u2029", "unicode é 😀"}
+
+	var output strings.Builder
+	writer := csvprocessor.NewJSONArrayWriter(&output)
+	if err := writer.WriteHeader([]string{"value"}); err != nil {
+		t.Fatalf("WriteHeader() error = %v", err)
+	}
+
+	for _, value := range values {
+		if err := writer.Write([]string{value}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	var got []map[string]string
+	if err := json.Unmarshal([]byte(output.String()), &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v, output = %q", err, output.String())
+	}
+
+	want := make([]map[string]string, len(values))
+	for i, value := range values {
+		want[i] = map[string]string{"value": strings.ToValidUTF8(value, "\ufffd")}
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("JSONWriter output = %v, want %v", got, want)
+	}
+}
+
+func TestJSONKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []string
+		want   []string
+	}{
+		{name: "Test unique", header: []string{"a", "b"}, want: []string{"a", "b"}},
+		{name: "Test empty", header: []string{"a", "", ""}, want: []string{"a", "column_2", "column_3"}},
+		{name: "Test duplicates", header: []string{"a", "a", "a_2", "a"}, want: []string{"a", "a_2", "a_2_2", "a_3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvprocessor.JSONKeys(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("JSONKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONLinesReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		columns []string
+		want    [][]string
+	}{
+		{
+			name:  "Test header from first record",
+			input: "{\"id\":1,\"name\":\"alice\",\"active\":true}\n{\"name\":\"bob\",\"id\":2,\"extra\":\"x\"}\n",
+			want:  [][]string{{"id", "name", "active"}, {"1", "alice", "true"}, {"2", "bob", ""}},
+		},
+		{
+			name:    "Test supplied columns",
+			input:   "{\"id\":1,\"name\":\"alice\"}\n{\"id\":2,\"tags\":[\"a\", \"b\"],\"address\":{\"city\": \"x\"}}\n",
+			columns: []string{"id", "tags", "address"},
+			want:    [][]string{{"id", "tags", "address"}, {"1", "", ""}, {"2", "[\"a\",\"b\"]", "{\"city\":\"x\"}"}},
+		},
+		{
+			name:  "Test null, blank lines and no trailing new line",
+			input: "\n{\"id\":1,\"name\":null}\n\n  \n{\"id\":2.50,\"name\":\"a\\nb\"}",
+			want:  [][]string{{"id", "name"}, {"1", ""}, {"2.50", "a\nb"}},
+		},
+		{
+			name:  "Test empty input",
+			input: "",
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readAll(t, csvprocessor.NewJSONLinesReader(strings.NewReader(tt.input), tt.columns...))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("JSONLinesReader rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONLinesReader_Process(t *testing.T) {
+	input := "{\"id\":1,\"name\":\"alice\"}\nnot json\n[1,2]\n{\"id\":2,\"name\":\"bob\"}\n"
+
+	var output strings.Builder
+	proc, err := csvprocessor.New(
+		csvprocessor.WithReader(csvprocessor.NewJSONLinesReader(strings.NewReader(input))),
+		csvprocessor.WithWriterGenerator(func(i int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(&output), nil
+		}),
+		csvprocessor.WithChunkSize(10),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if want := "id,name\n1,alice\n2,bob\n"; output.String() != want {
+		t.Errorf("Process() output = %q, want %q", output.String(), want)
+	}
+
+	errs := proc.Stats().Errors
+	if len(errs) != 2 || !errors.Is(errs[0], csvprocessor.ErrNotJSONObject) || !errors.Is(errs[1], csvprocessor.ErrNotJSONObject) {
+		t.Errorf("Stats().Errors = %v, want 2 %v", errs, csvprocessor.ErrNotJSONObject)
+	}
+}
+
+func TestJSONLinesReader_ReadBufferSize(t *testing.T) {
+	input := strings.Repeat("{\"id\":1,\"name\":\"a long value that does not fit in the buffer\"}\n", 200)
+	recorder := &readSizeRecorder{r: strings.NewReader(input)}
+
+	proc, err := csvprocessor.New(
+		csvprocessor.WithReader(csvprocessor.NewJSONLinesReader(recorder)),
+		csvprocessor.WithWriterGenerator(func(i int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(io.Discard), nil
+		}),
+		csvprocessor.WithChunkSize(1000),
+		csvprocessor.WithReadBufferSize(8192),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if recorder.maxRead != 8192 {
+		t.Errorf("max read size = %d, want %d", recorder.maxRead, 8192)
+	}
+}
@@ -0,0 +1,252 @@
+// Package kafka publishes the transformed rows to a Kafka topic, Eg: to replay historical CSVs into a streaming platform.
+//
+// The messages are published through the Producer interface, so any Kafka client can be used,
+// Eg: kafka-go's Writer.WriteMessages, or confluent-kafka-go's Producer.Produce by waiting for the delivery reports
+// of all the messages on its Events channel.
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// DefaultBatchSize is the default no. of messages published together.
+const DefaultBatchSize = 100
+
+// ErrUnknownKeyColumn is returned when the key column is not in the header.
+var ErrUnknownKeyColumn = errors.New("kafka: key column is not in the header")
+
+// Format is the encoding of the message values.
+type Format int
+
+const (
+	// FormatJSON encodes each row as a JSON object with the header as the keys, see csvprocessor.JSONWriter.
+	FormatJSON Format = iota
+	// FormatCSV encodes each row as a CSV line without the trailing new line.
+	FormatCSV
+)
+
+// Message is a message to be published.
+type Message struct {
+	Topic string
+	Key   []byte
+	Value []byte
+}
+
+// Producer publishes the messages to Kafka.
+type Producer interface {
+	// Produce publishes the messages and returns after all of them are delivered,
+	// or returns an error if any message could not be delivered.
+	Produce(ctx context.Context, messages []Message) error
+}
+
+// Option configures the Writer.
+type Option func(*Writer)
+
+// WithFormat sets the encoding of the message values, the default is FormatJSON.
+func WithFormat(format Format) Option {
+	return func(w *Writer) {
+		w.format = format
+	}
+}
+
+// WithKeyColumn uses the value of the given header column as the message key, the messages have no key by default.
+func WithKeyColumn(name string) Option {
+	return func(w *Writer) {
+		w.keyColumn = name
+	}
+}
+
+// WithKeyIndex uses the value of the column at the given 0-based index as the message key, Eg: for inputs without a header.
+func WithKeyIndex(index int) Option {
+	return func(w *Writer) {
+		w.keyIndex = index
+	}
+}
+
+// WithBatchSize sets the no. of messages published together, the default is DefaultBatchSize.
+func WithBatchSize(n int) Option {
+	return func(w *Writer) {
+		if n > 0 {
+			w.batchSize = n
+		}
+	}
+}
+
+// WithChunkMessages publishes each chunk as a single message with the CSV content of the chunk, including the header, instead of a message per row.
+func WithChunkMessages() Option {
+	return func(w *Writer) {
+		w.chunkMessages = true
+	}
+}
+
+// WithKafkaSink publishes the rows to the topic instead of writing them to files, see Writer.
+func WithKafkaSink(producer Producer, topic string, opts ...Option) csvprocessor.Option { //nolint:revive
+	return func(c *csvprocessor.Processor) error {
+		if producer == nil {
+			return csvprocessor.ErrOutputWriterNil
+		}
+
+		if err := csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(io.Discard), nil
+		})(c); err != nil {
+			return err
+		}
+
+		return csvprocessor.WithWriterFactory(func(io.Writer) csvprocessor.CsvWriter {
+			return NewWriter(producer, topic, opts...)
+		})(c)
+	}
+}
+
+// Writer is a CsvWriter that publishes each row as a message to a Kafka topic.
+// The messages are published in batches, a batch is published when it is full, on Flush and on Close,
+// and the delivery errors are returned by the following Write, Error or Close, which stops the processing.
+type Writer struct {
+	producer Producer
+	topic    string
+
+	// Unexported fields
+	format        Format
+	keyColumn     string
+	keyIndex      int // index of the key column, -1 if the messages have no key.
+	batchSize     int
+	chunkMessages bool
+
+	header  []string
+	batch   []Message
+	buf     bytes.Buffer
+	encoder csvprocessor.CsvWriter // encodes the rows into buf.
+	err     error
+}
+
+// NewWriter creates a Writer that publishes to the topic.
+func NewWriter(producer Producer, topic string, opts ...Option) *Writer {
+	w := &Writer{producer: producer, topic: topic, keyIndex: -1, batchSize: DefaultBatchSize}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	w.newEncoder()
+	return w
+}
+
+func (w *Writer) newEncoder() {
+	if w.format == FormatJSON && !w.chunkMessages {
+		encoder := csvprocessor.NewJSONLinesWriter(&w.buf)
+		if w.header != nil {
+			_ = encoder.WriteHeader(w.header)
+		}
+
+		w.encoder = encoder
+		return
+	}
+
+	w.encoder = csv.NewWriter(&w.buf)
+}
+
+// WriteHeader sets the keys of the JSON values and resolves the key column.
+// In chunk messages, the header is written at the top of the message.
+func (w *Writer) WriteHeader(header []string) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	w.header = append([]string(nil), header...)
+	if w.keyColumn != "" {
+		w.keyIndex = -1
+		for i, name := range header {
+			if name == w.keyColumn {
+				w.keyIndex = i
+				break
+			}
+		}
+
+		if w.keyIndex == -1 {
+			w.err = ErrUnknownKeyColumn
+			return w.err
+		}
+	}
+
+	if w.chunkMessages {
+		return w.encode(header)
+	}
+
+	w.newEncoder()
+	return nil
+}
+
+// Write publishes the row, or in chunk messages, adds it to the chunk.
+func (w *Writer) Write(record []string) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	if w.chunkMessages {
+		return w.encode(record)
+	}
+
+	w.buf.Reset()
+	if err := w.encode(record); err != nil {
+		return err
+	}
+
+	message := Message{Topic: w.topic, Value: bytes.TrimSuffix(append([]byte(nil), w.buf.Bytes()...), []byte("\n"))}
+	if w.keyIndex >= 0 && w.keyIndex < len(record) {
+		message.Key = []byte(record[w.keyIndex])
+	}
+
+	w.batch = append(w.batch, message)
+	if len(w.batch) >= w.batchSize {
+		w.publish()
+	}
+
+	return w.err
+}
+
+func (w *Writer) encode(record []string) error {
+	if err := w.encoder.Write(record); err != nil {
+		w.err = err
+		return err
+	}
+
+	w.encoder.Flush()
+	w.err = w.encoder.Error()
+	return w.err
+}
+
+// publish publishes the pending messages.
+func (w *Writer) publish() {
+	if w.err != nil || len(w.batch) == 0 {
+		return
+	}
+
+	w.err = w.producer.Produce(context.Background(), w.batch)
+	w.batch = w.batch[:0]
+}
+
+// Flush publishes the pending messages, chunk messages are published only on Close.
+func (w *Writer) Flush() {
+	w.publish()
+}
+
+// Error reports any error that has occurred during a previous Write or Flush, including the delivery errors.
+func (w *Writer) Error() error {
+	return w.err
+}
+
+// Close publishes the pending messages, or in chunk messages, the message with the chunk.
+func (w *Writer) Close() error {
+	if w.chunkMessages && w.err == nil && w.buf.Len() > 0 {
+		w.batch = append(w.batch, Message{Topic: w.topic, Value: append([]byte(nil), w.buf.Bytes()...)})
+		w.buf.Reset()
+	}
+
+	w.publish()
+	return w.err
+}
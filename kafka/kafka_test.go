@@ -0,0 +1,147 @@
+package kafka_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+	"github.com/sivaramasubramanian/csvprocessor/kafka"
+)
+
+// fakeProducer records the published batches, and fails the batches after `failAfter` batches if set.
+type fakeProducer struct {
+	batches   [][]kafka.Message
+	failAfter int
+	err       error
+}
+
+func (p *fakeProducer) Produce(_ context.Context, messages []kafka.Message) error {
+	if p.err != nil && len(p.batches) >= p.failAfter {
+		return p.err
+	}
+
+	p.batches = append(p.batches, append([]kafka.Message(nil), messages...))
+	return nil
+}
+
+// message is a Message with string fields, for readable comparisons.
+type message struct {
+	topic, key, value string
+}
+
+func (p *fakeProducer) messages() [][]message {
+	var batches [][]message
+	for _, batch := range p.batches {
+		var messages []message
+		for _, m := range batch {
+			messages = append(messages, message{m.Topic, string(m.Key), string(m.Value)})
+		}
+
+		batches = append(batches, messages)
+	}
+
+	return batches
+}
+
+func process(tb testing.TB, input string, opts ...csvprocessor.Option) error {
+	tb.Helper()
+
+	proc, err := csvprocessor.New(append([]csvprocessor.Option{
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader(input))),
+		csvprocessor.WithChunkSize(3),
+		csvprocessor.WithLogger(tb.Logf),
+	}, opts...)...)
+	if err != nil {
+		tb.Fatalf("New() error = %v", err)
+	}
+
+	return proc.Process()
+}
+
+func TestWithKafkaSink(t *testing.T) {
+	input := "id,name\n1,alice\n2,\"bob, jr\"\n3,carol\n4,dave\n"
+
+	tests := []struct {
+		name string
+		opts []kafka.Option
+		skip bool // skip headers
+		want [][]message
+	}{
+		{
+			name: "json rows",
+			opts: []kafka.Option{kafka.WithKeyColumn("id"), kafka.WithBatchSize(2)},
+			want: [][]message{
+				{{"orders", "1", `{"id":"1","name":"alice"}`}, {"orders", "2", `{"id":"2","name":"bob, jr"}`}},
+				{{"orders", "3", `{"id":"3","name":"carol"}`}},
+				{{"orders", "4", `{"id":"4","name":"dave"}`}},
+			},
+		},
+		{
+			name: "csv rows keyed by index",
+			opts: []kafka.Option{kafka.WithFormat(kafka.FormatCSV), kafka.WithKeyIndex(1)},
+			want: [][]message{
+				{{"orders", "alice", "1,alice"}, {"orders", "bob, jr", `2,"bob, jr"`}, {"orders", "carol", "3,carol"}},
+				{{"orders", "dave", "4,dave"}},
+			},
+		},
+		{
+			name: "json rows without header",
+			skip: true,
+			want: [][]message{
+				{{"orders", "", `{"column_1":"id","column_2":"name"}`}, {"orders", "", `{"column_1":"1","column_2":"alice"}`}, {"orders", "", `{"column_1":"2","column_2":"bob, jr"}`}},
+				{{"orders", "", `{"column_1":"3","column_2":"carol"}`}, {"orders", "", `{"column_1":"4","column_2":"dave"}`}},
+			},
+		},
+		{
+			name: "chunk messages",
+			opts: []kafka.Option{kafka.WithChunkMessages()},
+			want: [][]message{
+				{{"orders", "", "id,name\n1,alice\n2,\"bob, jr\"\n3,carol\n"}},
+				{{"orders", "", "id,name\n4,dave\n"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			producer := &fakeProducer{}
+
+			err := process(t, input, csvprocessor.SkipHeaders(tt.skip), kafka.WithKafkaSink(producer, "orders", tt.opts...))
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if got := producer.messages(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("batches = %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithKafkaSink_Errors(t *testing.T) {
+	input := "id,name\n1,alice\n2,bob\n3,carol\n4,dave\n"
+	errDelivery := errors.New("delivery failed")
+
+	producer := &fakeProducer{failAfter: 1, err: errDelivery}
+	err := process(t, input, kafka.WithKafkaSink(producer, "orders", kafka.WithBatchSize(1)))
+	if !errors.Is(err, errDelivery) {
+		t.Errorf("Process() error = %v, want %v", err, errDelivery)
+	}
+
+	if len(producer.batches) != 1 {
+		t.Errorf("published %d batches, want 1", len(producer.batches))
+	}
+
+	err = process(t, input, kafka.WithKafkaSink(&fakeProducer{}, "orders", kafka.WithKeyColumn("email")))
+	if !errors.Is(err, kafka.ErrUnknownKeyColumn) {
+		t.Errorf("Process() error = %v, want %v", err, kafka.ErrUnknownKeyColumn)
+	}
+
+	if _, err := csvprocessor.New(csvprocessor.WithStdin(), kafka.WithKafkaSink(nil, "orders")); !errors.Is(err, csvprocessor.ErrOutputWriterNil) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrOutputWriterNil)
+	}
+}
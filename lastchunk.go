@@ -0,0 +1,73 @@
+package csvprocessor
+
+import "errors"
+
+// ErrInvalidMinLastChunkRows is returned when the min. no. of rows of the last chunk is not > 0 or is more than the chunk size.
+var ErrInvalidMinLastChunkRows = errors.New("csvprocessor: min. no. of rows of the last chunk must be > 0 and <= the chunk size")
+
+// WithMinLastChunkRows appends the rows of the last chunk to the previous chunk if it would have fewer than n rows,
+// so that there is no tiny chunk at the end. Eg: 2,050 rows with a chunk size of 1000 are written as 1000 and 1050 rows:
+//
+//	csvprocessor.WithChunkSize(1000),
+//	csvprocessor.WithMinLastChunkRows(100),
+//
+// When a chunk is full, up to n of the next rows are held in memory until it is known whether they are the end of the input,
+// so n must not be more than the chunk size. The rows are counted as they are read, before they are transformed, so the last
+// chunk can have fewer than n rows if the transformers filter out rows. It is not used with the shards, see WithRoundRobin().
+func WithMinLastChunkRows(n int) Option {
+	return func(c *Processor) error {
+		if n <= 0 {
+			return ErrInvalidMinLastChunkRows
+		}
+
+		c.minLastChunkRows = n
+		return nil
+	}
+}
+
+// validMinLastChunkRows checks that the rows held for the last chunk fit in a chunk.
+func (c *Processor) validMinLastChunkRows() error {
+	if c.minLastChunkRows > c.chunkSize {
+		return ErrInvalidMinLastChunkRows
+	}
+
+	return nil
+}
+
+// holdRow processes the row, unless the current chunk is full. Then the rows are held until there are enough rows
+// for a new chunk, see WithMinLastChunkRows().
+func (r *run) holdRow(row []string) error {
+	if len(r.heldRows) == 0 && !r.chunkFull() {
+		return r.processRow(row)
+	}
+
+	r.heldRows = append(r.heldRows, append([]string(nil), row...))
+	if len(r.heldRows) < r.c.minLastChunkRows {
+		return nil
+	}
+
+	return r.releaseRows(false)
+}
+
+// releaseRows processes the held rows, they are written to the current chunk if last is true.
+func (r *run) releaseRows(last bool) error {
+	rows := r.heldRows
+	r.heldRows = nil
+	r.lastChunk = last
+	defer func() {
+		r.lastChunk = false
+	}()
+
+	for _, row := range rows {
+		if err := r.processRow(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// chunkFull reports whether the current chunk has the max. no. of rows.
+func (r *run) chunkFull() bool {
+	return !r.out.singleChunk && !r.sharded() && !r.lastChunk && r.fileWriter != nil && r.currentChunk().Rows >= r.chunkLimit()
+}
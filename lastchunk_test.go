@@ -0,0 +1,95 @@
+package csvprocessor_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithMinLastChunkRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		minRows int
+		want    []string
+	}{
+		{
+			name:    "Test small last chunk is merged",
+			input:   "id\n1\n2\n3\n4\n5\n6\n7\n",
+			minRows: 2,
+			want:    []string{"id\n1\n2\n3\n", "id\n4\n5\n6\n7\n"},
+		},
+		{
+			name:    "Test last chunk with min. rows",
+			input:   "id\n1\n2\n3\n4\n5\n6\n7\n8\n",
+			minRows: 2,
+			want:    []string{"id\n1\n2\n3\n", "id\n4\n5\n6\n", "id\n7\n8\n"},
+		},
+		{
+			name:    "Test full last chunk",
+			input:   "id\n1\n2\n3\n4\n5\n6\n",
+			minRows: 3,
+			want:    []string{"id\n1\n2\n3\n", "id\n4\n5\n6\n"},
+		},
+		{
+			name:    "Test single chunk",
+			input:   "id\n1\n2\n",
+			minRows: 3,
+			want:    []string{"id\n1\n2\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := make([]strings.Builder, 4)
+			proc := newProcessor(t, strings.NewReader(tt.input), chunks,
+				csvprocessor.WithChunkSize(3),
+				csvprocessor.WithMinLastChunkRows(tt.minRows),
+				csvprocessor.WithTransformer(csvprocessor.AddChunkRowNoTransformer("n")),
+			)
+			if err := proc.Process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if len(proc.Stats().Chunks) != len(tt.want) {
+				t.Errorf("Stats().Chunks has %d chunks, want %d", len(proc.Stats().Chunks), len(tt.want))
+			}
+
+			for i := range chunks {
+				var want string
+				if i < len(tt.want) {
+					want = withChunkRowNums(tt.want[i])
+				}
+
+				if got := chunks[i].String(); got != want {
+					t.Errorf("chunk %d = %q, want %q", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+// withChunkRowNums adds the column "n" with the row no. in the chunk to the rows of the chunk.
+func withChunkRowNums(chunk string) string {
+	lines := strings.Split(strings.TrimSuffix(chunk, "\n"), "\n")
+	lines[0] = "n," + lines[0]
+	for i := 1; i < len(lines); i++ {
+		lines[i] = strconv.Itoa(i) + "," + lines[i]
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestWithMinLastChunkRows_Invalid(t *testing.T) {
+	for _, opt := range []csvprocessor.Option{
+		csvprocessor.WithMinLastChunkRows(0),
+		csvprocessor.WithMinLastChunkRows(4),
+	} {
+		_, err := csvprocessor.New(csvprocessor.WithInputReader(strings.NewReader("id\n")), csvprocessor.WithDryRun(true), csvprocessor.WithChunkSize(3), opt)
+		if !errors.Is(err, csvprocessor.ErrInvalidMinLastChunkRows) {
+			t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidMinLastChunkRows)
+		}
+	}
+}
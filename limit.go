@@ -0,0 +1,181 @@
+package csvprocessor
+
+import (
+	"errors"
+	"io"
+)
+
+// rangeReader is a CsvReader that skips the first few rows of the underlying reader and returns at most limit rows after that.
+// Once the limit is reached, no more rows are read from the underlying reader.
+type rangeReader struct {
+	reader     CsvReader
+	skip       int
+	limit      int // no limit if < 0
+	readHeader bool
+}
+
+func newRangeReader(reader CsvReader, skip, limit int, hasHeader bool) *rangeReader {
+	return &rangeReader{reader: reader, skip: skip, limit: limit, readHeader: hasHeader}
+}
+
+func (r *rangeReader) Read() ([]string, error) {
+	if r.readHeader {
+		r.readHeader = false
+		return r.reader.Read()
+	}
+
+	for r.skip > 0 {
+		_, err := r.reader.Read()
+		if !errors.Is(err, ErrNoMessage) {
+			// a row with an error is a skipped row too, ErrNoMessage is returned without reading a row.
+			r.skip--
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.limit == 0 {
+		return nil, io.EOF
+	}
+
+	row, err := r.reader.Read()
+	if err == nil && r.limit > 0 {
+		r.limit--
+	}
+
+	return row, err
+}
+
+// tailReader is a CsvReader that returns only the last n rows of the underlying reader.
+type tailReader struct {
+	reader    CsvReader
+	size      int
+	hasHeader bool
+
+	buffered bool
+	header   []string
+	rows     [][]string // circular buffer with the last n rows.
+	next     int        // index of the oldest row in rows, once the buffer is full.
+	err      error
+
+	budget *memoryBudget // bounds the buffered rows, see WithMaxMemory().
+}
+
+func newTailReader(reader CsvReader, size int, hasHeader bool) *tailReader {
+	return &tailReader{reader: reader, size: size, hasHeader: hasHeader}
+}
+
+func (t *tailReader) Read() ([]string, error) {
+	if !t.buffered {
+		t.buffered = true
+		t.err = t.buffer()
+		if t.err == nil && t.header != nil {
+			return t.header, nil
+		}
+	}
+
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	if len(t.rows) == 0 {
+		return nil, io.EOF
+	}
+
+	row := t.rows[0]
+	t.rows = t.rows[1:]
+	return row, nil
+}
+
+func (t *tailReader) buffer() error {
+	t.rows = make([][]string, 0, t.size)
+	for {
+		row, err := readWaiting(t.reader)
+		if errors.Is(err, io.EOF) {
+			// rotate the buffer so that the rows are in input order.
+			t.rows = append(t.rows[t.next:], t.rows[:t.next]...)
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// readers can reuse the row slice, so a copy is retained.
+		row = append([]string(nil), row...)
+		if t.hasHeader && t.header == nil {
+			t.header = row
+			continue
+		}
+
+		if t.size == 0 {
+			continue
+		}
+
+		if len(t.rows) == t.size {
+			t.budget.release(rowSize(t.rows[t.next]))
+		}
+
+		if !t.budget.reserve(rowSize(row)) {
+			return t.budget.exceeded("Tail()")
+		}
+
+		if len(t.rows) < t.size {
+			t.rows = append(t.rows, row)
+			continue
+		}
+
+		t.rows[t.next] = row
+		t.next = (t.next + 1) % t.size
+	}
+}
+
+// headerBlockReader is a CsvReader for inputs with a header spanning multiple rows,
+// the first header row is returned as the header and the remaining header rows are passed to onHeader.
+type headerBlockReader struct {
+	reader   CsvReader
+	rows     int // no. of header rows.
+	onHeader func(extra [][]string)
+
+	headerRead bool
+}
+
+func newHeaderBlockReader(reader CsvReader, rows int, onHeader func(extra [][]string)) *headerBlockReader {
+	return &headerBlockReader{reader: reader, rows: rows, onHeader: onHeader}
+}
+
+func (h *headerBlockReader) Read() ([]string, error) {
+	if h.headerRead {
+		return h.reader.Read()
+	}
+
+	h.headerRead = true
+	header, err := h.reader.Read()
+	if err != nil {
+		return header, err
+	}
+
+	// the header is copied as the reader can reuse it while reading the remaining header rows.
+	header = append([]string(nil), header...)
+	extra := make([][]string, 0, h.rows-1)
+	for len(extra) < h.rows-1 {
+		row, err := h.reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		extra = append(extra, append([]string(nil), row...))
+	}
+
+	if h.onHeader != nil {
+		h.onHeader(extra)
+	}
+
+	return header, nil
+}
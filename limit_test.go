@@ -0,0 +1,127 @@
+package csvprocessor_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+const numbersCSV = `n
+1
+2
+3
+4
+5
+6
+`
+
+func TestProcessor_RowRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		opt     []csvprocessor.Option
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Test skip rows",
+			opt:  []csvprocessor.Option{csvprocessor.WithSkipRows(4)},
+			want: "n\n5\n6\n",
+		},
+		{
+			name: "Test limit rows",
+			opt:  []csvprocessor.Option{csvprocessor.WithLimitRows(2)},
+			want: "n\n1\n2\n",
+		},
+		{
+			name: "Test skip and limit rows",
+			opt:  []csvprocessor.Option{csvprocessor.WithSkipRows(1), csvprocessor.WithLimitRows(2)},
+			want: "n\n2\n3\n",
+		},
+		{
+			name: "Test head",
+			opt:  []csvprocessor.Option{csvprocessor.Head(3)},
+			want: "n\n1\n2\n3\n",
+		},
+		{
+			name: "Test tail",
+			opt:  []csvprocessor.Option{csvprocessor.Tail(4)},
+			want: "n\n3\n4\n5\n6\n",
+		},
+		{
+			name: "Test tail without headers",
+			opt:  []csvprocessor.Option{csvprocessor.Tail(2), csvprocessor.SkipHeaders(true)},
+			want: "5\n6\n",
+		},
+		{
+			name: "Test tail larger than input",
+			opt:  []csvprocessor.Option{csvprocessor.Tail(10)},
+			want: numbersCSV,
+		},
+		{
+			name: "Test skip more than input",
+			opt:  []csvprocessor.Option{csvprocessor.WithSkipRows(10)},
+			want: "n\n",
+		},
+		{
+			name:    "Test invalid limit",
+			opt:     []csvprocessor.Option{csvprocessor.WithLimitRows(-1)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processString(t, numbersCSV, tt.opt...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessor_SkipRows_Errors(t *testing.T) {
+	t.Run("Test malformed row in the skipped rows", func(t *testing.T) {
+		output, err := processString(t, "n\n1\n2\"x\n3\n4\n5\n", csvprocessor.WithSkipRows(3))
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		if want := "n\n4\n5\n"; output != want {
+			t.Errorf("Process() output = %q, want %q", output, want)
+		}
+	})
+
+	t.Run("Test idle stream in the skipped rows", func(t *testing.T) {
+		rows := [][]string{{"n"}, {"1"}, nil, {"2"}, {"3"}, {"4"}}
+		reader := readerFunc(func() ([]string, error) {
+			if len(rows) == 0 {
+				return nil, io.EOF
+			}
+
+			row := rows[0]
+			rows = rows[1:]
+			if row == nil {
+				return nil, csvprocessor.ErrNoMessage
+			}
+
+			return row, nil
+		})
+
+		output, err := processString(t, "", csvprocessor.WithReader(reader), csvprocessor.WithSkipRows(2))
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		if want := "n\n3\n4\n"; output != want {
+			t.Errorf("Process() output = %q, want %q", output, want)
+		}
+	})
+}
@@ -1,6 +1,86 @@
 package csvprocessor
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Logger defines the logging interface used by csvprocessor.
 type Logger logFunc
 
 type logFunc func(string, ...any)
+
+// LogLevel represents the severity of a log message, the values are the same as the levels of log/slog.
+type LogLevel int
+
+// Log levels, see LeveledLogger.
+const (
+	LogDebug LogLevel = -4
+	LogInfo  LogLevel = 0
+	LogWarn  LogLevel = 4
+	LogError LogLevel = 8
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogDebug:
+		return "DEBUG"
+	case LogInfo:
+		return "INFO"
+	case LogWarn:
+		return "WARN"
+	case LogError:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// LeveledLogger is a structured logger with levels, see WithLeveledLogger().
+// keyvals are the alternating keys and values of the fields of the message, Eg: "chunk", 1, "rows", 1000.
+type LeveledLogger interface {
+	Log(level LogLevel, msg string, keyvals ...any)
+}
+
+// WithLeveledLogger sets a structured logger with levels for the processor, see WithSlogLogger() for log/slog.
+// The processor logs the progress at LogDebug and LogInfo, and the rows skipped after an error at LogWarn.
+func WithLeveledLogger(logger LeveledLogger) Option {
+	return func(c *Processor) error {
+		c.log = logger
+		return nil
+	}
+}
+
+// defaultLogger is the logger used unless WithLogger() or WithLeveledLogger() is used.
+type defaultLogger printfLogger
+
+func (l defaultLogger) Log(level LogLevel, msg string, keyvals ...any) {
+	printfLogger(l).Log(level, msg, keyvals...)
+}
+
+// printfLogger adapts a printf style Logger to LeveledLogger, the fields are appended to the message as key=value.
+type printfLogger Logger
+
+func (l printfLogger) Log(_ LogLevel, msg string, keyvals ...any) {
+	if l == nil {
+		return
+	}
+
+	var format strings.Builder
+	format.WriteString(strings.ReplaceAll(msg, "%", "%%"))
+
+	args := make([]any, 0, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key, value := keyvals[i], any("")
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+
+		format.WriteString(" ")
+		format.WriteString(strings.ReplaceAll(fmt.Sprint(key), "%", "%%"))
+		format.WriteString("=%v")
+		args = append(args, value)
+	}
+
+	l(format.String(), args...)
+}
@@ -0,0 +1,125 @@
+package csvprocessor_test
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+type logEntry struct {
+	level   csvprocessor.LogLevel
+	msg     string
+	keyvals []any
+}
+
+type testLeveledLogger struct {
+	entries []logEntry
+}
+
+func (l *testLeveledLogger) Log(level csvprocessor.LogLevel, msg string, keyvals ...any) {
+	l.entries = append(l.entries, logEntry{level: level, msg: msg, keyvals: keyvals})
+}
+
+func TestLoggers(t *testing.T) {
+	var printfLogs []string
+	leveled := &testLeveledLogger{}
+
+	tests := []struct {
+		name string
+		opt  csvprocessor.Option
+	}{
+		{
+			name: "printf logger",
+			opt: csvprocessor.WithLogger(func(format string, args ...any) {
+				printfLogs = append(printfLogs, fmt.Sprintf(format, args...))
+			}),
+		},
+		{
+			name: "leveled logger",
+			opt:  csvprocessor.WithLeveledLogger(leveled),
+		},
+		{
+			name: "nil logger",
+			opt:  csvprocessor.WithLogger(nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc, err := csvprocessor.New(
+				csvprocessor.WithInputReader(strings.NewReader("id,name\n1,100%\n2\n")),
+				csvprocessor.WithChunkSize(10),
+				csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+					return csvprocessor.NoOpCloser(io.Discard), nil
+				}),
+				tt.opt,
+			)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if err := proc.Process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+		})
+	}
+
+	wantPrintf := []string{
+		"0 rows processed \n",
+		"csvprocessor: row error error=csvprocessor: row 2: record on line 3: wrong number of fields",
+		"2 total rows updated",
+	}
+	if !reflect.DeepEqual(printfLogs, wantPrintf) {
+		t.Errorf("printf logs = %q, want %q", printfLogs, wantPrintf)
+	}
+
+	wantLevels := []csvprocessor.LogLevel{csvprocessor.LogDebug, csvprocessor.LogWarn, csvprocessor.LogInfo}
+	if len(leveled.entries) != len(wantLevels) {
+		t.Fatalf("leveled logs = %v, want %d entries", leveled.entries, len(wantLevels))
+	}
+
+	for i, entry := range leveled.entries {
+		if entry.level != wantLevels[i] {
+			t.Errorf("entry %d (%s) level = %v, want %v", i, entry.msg, entry.level, wantLevels[i])
+		}
+	}
+
+	if keyvals := leveled.entries[1].keyvals; len(keyvals) != 2 || keyvals[0] != "error" {
+		t.Errorf("entry 1 keyvals = %v, want the error", keyvals)
+	}
+}
+
+func TestDefaultLogger(t *testing.T) {
+	var logs strings.Builder
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	proc, err := csvprocessor.New(
+		csvprocessor.WithInputReader(strings.NewReader("id,name\n1,a\n2\n")),
+		csvprocessor.WithChunkSize(10),
+		csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(io.Discard), nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if strings.Contains(logs.String(), "row error") {
+		t.Errorf("logs = %q, want no row errors without a logger", logs.String())
+	}
+
+	if len(proc.Stats().Errors) != 1 {
+		t.Errorf("Stats().Errors = %v, want the row error", proc.Stats().Errors)
+	}
+}
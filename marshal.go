@@ -0,0 +1,75 @@
+package csvprocessor
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// UnmarshalRow decodes the row into the struct pointed to by v, mapping the columns to the fields using the header.
+// The fields are mapped like Typed(): by the `csv` tag or the field name, or by position if header is nil.
+// The fields without a column in the header are not changed.
+func UnmarshalRow(header, row []string, v any) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return fmt.Errorf("%w: %T is not a non-nil pointer to a struct", ErrUnsupportedType, v)
+	}
+
+	codec, err := codecFor(value.Elem().Type())
+	if err != nil {
+		return err
+	}
+
+	columns := codec.positions()
+	if header != nil {
+		columns = codec.columns(header)
+	}
+
+	return codec.decode(row, columns, value.Elem())
+}
+
+// MarshalRow encodes the fields of the struct (or pointer to a struct) v as a row, in the order of the fields.
+// Use MarshalHeader() for the matching header.
+func MarshalRow(v any) ([]string, error) {
+	value, codec, err := structValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	row := make([]string, len(codec.fields))
+	for i, field := range codec.fields {
+		if row[i], err = encodeField(value.FieldByIndex(field.index), field.layout); err != nil {
+			return nil, fmt.Errorf("csvprocessor: column %q: %w", field.name, err)
+		}
+	}
+
+	return row, nil
+}
+
+// MarshalHeader returns the column names of the struct (or pointer to a struct) v, in the order of the fields.
+func MarshalHeader(v any) ([]string, error) {
+	_, codec, err := structValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]string, len(codec.fields))
+	for i, field := range codec.fields {
+		header[i] = field.name
+	}
+
+	return header, nil
+}
+
+func structValue(v any) (reflect.Value, *structCodec, error) {
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return value, nil, fmt.Errorf("%w: %T is not a struct", ErrUnsupportedType, v)
+	}
+
+	codec, err := codecFor(value.Type())
+	return value, codec, err
+}
@@ -0,0 +1,99 @@
+package csvprocessor_test
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestUnmarshalRow(t *testing.T) {
+	note := "late"
+	tests := []struct {
+		name    string
+		header  []string
+		row     []string
+		want    order
+		wantErr error
+	}{
+		{
+			name:   "by header",
+			header: []string{"note", "order_id", "created_at", "amount", "ip"},
+			row:    []string{"late", "2", "2023-04-06", "3.5", "10.0.0.1"},
+			want:   order{ID: 2, Amount: 3.5, Created: time.Date(2023, 4, 6, 0, 0, 0, 0, time.UTC), Note: &note, IP: net.ParseIP("10.0.0.1")},
+		},
+		{
+			name: "by position",
+			row:  []string{"7", "1.25", "", "", "", "new"},
+			want: order{ID: 7, Amount: 1.25, Status: "new"},
+		},
+		{
+			name:    "invalid value",
+			header:  []string{"amount"},
+			row:     []string{"1,5"},
+			wantErr: strconv.ErrSyntax,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got order
+			err := csvprocessor.UnmarshalRow(tt.header, tt.row, &got)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UnmarshalRow() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalRow() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	var o order
+	for _, v := range []any{o, (*order)(nil), new(string)} {
+		if err := csvprocessor.UnmarshalRow(nil, []string{"1"}, v); !errors.Is(err, csvprocessor.ErrUnsupportedType) {
+			t.Errorf("UnmarshalRow(%T) error = %v, want %v", v, err, csvprocessor.ErrUnsupportedType)
+		}
+	}
+}
+
+func TestMarshalRow(t *testing.T) {
+	note := "late"
+	o := order{ID: 2, Amount: 3.5, Created: time.Date(2023, 4, 6, 0, 0, 0, 0, time.UTC), Note: &note, Status: "new", Ignored: "x"}
+
+	header, err := csvprocessor.MarshalHeader(o)
+	if err != nil {
+		t.Fatalf("MarshalHeader() error = %v", err)
+	}
+
+	if want := []string{"order_id", "amount", "created_at", "note", "ip", "Status"}; !reflect.DeepEqual(header, want) {
+		t.Errorf("MarshalHeader() = %q, want %q", header, want)
+	}
+
+	row, err := csvprocessor.MarshalRow(&o)
+	if err != nil {
+		t.Fatalf("MarshalRow() error = %v", err)
+	}
+
+	if want := []string{"2", "3.5", "2023-04-06", "late", "", "new"}; !reflect.DeepEqual(row, want) {
+		t.Errorf("MarshalRow() = %q, want %q", row, want)
+	}
+
+	var got order
+	if err := csvprocessor.UnmarshalRow(header, row, &got); err != nil {
+		t.Fatalf("UnmarshalRow() error = %v", err)
+	}
+
+	o.Ignored = ""
+	if !reflect.DeepEqual(got, o) {
+		t.Errorf("UnmarshalRow(MarshalRow()) = %+v, want %+v", got, o)
+	}
+
+	if _, err := csvprocessor.MarshalRow("x"); !errors.Is(err, csvprocessor.ErrUnsupportedType) {
+		t.Errorf("MarshalRow() error = %v, want %v", err, csvprocessor.ErrUnsupportedType)
+	}
+}
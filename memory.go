@@ -0,0 +1,134 @@
+package csvprocessor
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidMaxMemory is returned when the memory limit is not > 0.
+	ErrInvalidMaxMemory = errors.New("csvprocessor: max memory must be > 0")
+
+	// ErrMaxMemoryExceeded is returned when the rows that must be held in memory do not fit within the limit set by WithMaxMemory().
+	ErrMaxMemoryExceeded = errors.New("csvprocessor: max memory exceeded")
+)
+
+// minBufferSize is the smallest read/write buffer used when the buffers are sized to fit the memory limit.
+const minBufferSize = 4096
+
+// WithMaxMemory bounds the memory used by the processor to buffer the input and output, and the rows held in memory, Eg:
+//
+//	csvprocessor.WithMaxMemory(64 << 20) // 64 MB
+//
+// An eighth of the limit is shared by the read buffers of the inputs and another eighth is used for the write buffer,
+// the rest bounds the rows held in memory by WithSortBy(), Tail(), WithReservoirSample() and WithAggregation().
+// The sorter spills the sorted rows to temp files when the limit is reached,
+// the others stop with ErrMaxMemoryExceeded as the rows cannot be released until the entire input is read.
+//
+// The rows are read from the input only when the previous row has been written,
+// so a slow output applies backpressure to the reader without buffering more rows.
+//
+// The size of a row is estimated from the length of its values and the overhead of the slices and strings holding them,
+// the memory used by the transformers and the writers is not counted.
+func WithMaxMemory(bytes int64) Option {
+	return func(c *Processor) error {
+		if bytes <= 0 {
+			return ErrInvalidMaxMemory
+		}
+
+		c.maxMemory = bytes
+		return nil
+	}
+}
+
+// inputBufferSize returns the size of the read buffer of each input.
+func (c *Processor) inputBufferSize() int {
+	if c.maxMemory <= 0 {
+		return c.readBufferSize
+	}
+
+	inputs := int64(len(c.inputs))
+	if inputs == 0 {
+		inputs = 1
+	}
+
+	return bufferSize(c.readBufferSize, c.maxMemory/8/inputs)
+}
+
+// chunkBufferSize returns the size of the write buffer of each chunk, when the given number of chunks are open at once.
+func (c *Processor) chunkBufferSize(chunks int) int {
+	if c.maxMemory <= 0 {
+		return c.WriteBufferSize
+	}
+
+	if chunks == 0 {
+		chunks = 1
+	}
+
+	return bufferSize(c.WriteBufferSize, c.maxMemory/8/int64(chunks))
+}
+
+// bufferSize returns size capped to limit, but not less than minBufferSize.
+func bufferSize(size int, limit int64) int {
+	if int64(size) > limit {
+		size = int(limit)
+	}
+
+	if size < minBufferSize {
+		size = minBufferSize
+	}
+
+	return size
+}
+
+// memoryBudget tracks the estimated size of the rows held in memory during a run.
+// The methods can be called on a nil *memoryBudget, when WithMaxMemory() is not used.
+type memoryBudget struct {
+	limit int64
+	used  int64
+}
+
+// newMemoryBudget returns the budget for the rows held in memory, or nil if WithMaxMemory() is not used.
+func (c *Processor) newMemoryBudget() *memoryBudget {
+	if c.maxMemory <= 0 {
+		return nil
+	}
+
+	return &memoryBudget{limit: c.maxMemory - c.maxMemory/4}
+}
+
+// reserve reserves n bytes, it returns false if they do not fit within the limit.
+func (m *memoryBudget) reserve(n int64) bool {
+	if m == nil {
+		return true
+	}
+
+	if m.used+n > m.limit {
+		return false
+	}
+
+	m.used += n
+	return true
+}
+
+func (m *memoryBudget) release(n int64) {
+	if m != nil {
+		m.used -= n
+	}
+}
+
+// exceeded returns the error returned when the rows held by the given feature do not fit within the limit.
+func (m *memoryBudget) exceeded(feature string) error {
+	return fmt.Errorf("%w: the rows held by %s need more than %d bytes", ErrMaxMemoryExceeded, feature, m.limit)
+}
+
+// rowSize returns the estimated no. of bytes used by row in memory.
+func rowSize(row []string) int64 {
+	// 24 bytes for the slice header and 16 bytes for each string header.
+	size := int64(24 + 16*len(row))
+	for _, value := range row {
+		size += int64(len(value))
+	}
+
+	return size
+}
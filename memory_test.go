@@ -0,0 +1,74 @@
+package csvprocessor_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithMaxMemory(t *testing.T) {
+	// 100 rows in descending order, each row needs ~60 bytes in memory.
+	var input, sorted strings.Builder
+	input.WriteString("id,name\n")
+	sorted.WriteString("id,name\n")
+	for i := 99; i >= 0; i-- {
+		fmt.Fprintf(&input, "%02d,n%02d\n", i, i)
+		fmt.Fprintf(&sorted, "%02d,n%02d\n", 99-i, 99-i)
+	}
+
+	tests := []struct {
+		name    string
+		opts    []csvprocessor.Option
+		want    string
+		wantErr error
+	}{
+		{
+			name: "Test sort spills rows when the limit is reached",
+			opts: []csvprocessor.Option{csvprocessor.WithSortBy([]int{0}, csvprocessor.Ascending), csvprocessor.WithMaxMemory(1024)},
+			want: sorted.String(),
+		},
+		{
+			name: "Test tail within the limit",
+			opts: []csvprocessor.Option{csvprocessor.Tail(2), csvprocessor.WithMaxMemory(1024)},
+			want: "id,name\n01,n01\n00,n00\n",
+		},
+		{
+			name:    "Test tail exceeding the limit",
+			opts:    []csvprocessor.Option{csvprocessor.Tail(50), csvprocessor.WithMaxMemory(1024)},
+			wantErr: csvprocessor.ErrMaxMemoryExceeded,
+		},
+		{
+			name:    "Test reservoir sample exceeding the limit",
+			opts:    []csvprocessor.Option{csvprocessor.WithReservoirSample(50), csvprocessor.WithMaxMemory(1024)},
+			wantErr: csvprocessor.ErrMaxMemoryExceeded,
+		},
+		{
+			name: "Test aggregation exceeding the limit",
+			opts: []csvprocessor.Option{
+				csvprocessor.WithAggregation([]int{0}, csvprocessor.Aggregation{Func: csvprocessor.AggCount}),
+				csvprocessor.WithMaxMemory(1024),
+			},
+			wantErr: csvprocessor.ErrMaxMemoryExceeded,
+		},
+		{
+			name:    "Test invalid limit",
+			opts:    []csvprocessor.Option{csvprocessor.WithMaxMemory(0)},
+			wantErr: csvprocessor.ErrInvalidMaxMemory,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processString(t, input.String(), tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
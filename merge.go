@@ -0,0 +1,158 @@
+package csvprocessor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Merger recombines the chunk files into a single CSV file, it is the inverse of splitting. Eg:
+//
+//	chunks, _ := csvprocessor.ChunkFiles("orders_*.csv")
+//	merger, err := csvprocessor.NewMerger("orders.csv", chunks, csvprocessor.WithTransformer(transformer))
+//	if err != nil {
+//		return err
+//	}
+//
+//	err = merger.Merge()
+//
+// The chunks are read in the given order as a single input, so the header of each chunk must be the same
+// and it is written only once (see WithFileReaders()). The options of the processor, like the transformer
+// and the output delimiter, are applied to the merged rows. The output is always a single file.
+type Merger struct {
+	proc *Processor
+}
+
+// NewMerger creates a Merger that merges the input files into the output file, in the given order.
+// The output file is overwritten if it exists, unless WithOutputConflictPolicy(ConflictFail) is used.
+func NewMerger(output string, inputs []string, opts ...Option) (*Merger, error) {
+	if len(inputs) == 0 {
+		return nil, ErrNoInputFiles
+	}
+
+	if strings.TrimSpace(output) == "" {
+		return nil, ErrInvalidOutputFileFormat
+	}
+
+	mergeOpts := append([]Option{WithOutputConflictPolicy(ConflictOverwrite)}, opts...)
+	mergeOpts = append(mergeOpts, WithFileReaders(inputs...), withMergeOutput(output))
+	proc, err := New(mergeOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Merger{proc: proc}, nil
+}
+
+// withMergeOutput writes all the rows to a single output file.
+func withMergeOutput(output string) Option {
+	return func(c *Processor) error {
+		c.chunkSize = math.MaxInt32
+		c.newArchive = nil
+		c.outputChunkGenerator = func(int) (io.WriteCloser, error) {
+			return c.createOutputFile(output, false)
+		}
+
+		return nil
+	}
+}
+
+// Merge merges the input files into the output file.
+func (m *Merger) Merge() error {
+	// the chunking options, like WithChunkInterval() or WithRoundRobin(), would start new chunks in the same file.
+	return m.proc.process(context.Background(), runOutput{singleChunk: true})
+}
+
+// Stats returns the stats of the merge, see Processor.Stats().
+func (m *Merger) Stats() Stats {
+	return m.proc.Stats()
+}
+
+// ChunkFiles returns the files matching the glob pattern in the order of their chunk numbers,
+// Eg: "out_2.csv" comes before "out_10.csv". The chunk number is the last number in the file name,
+// the files are in lexical order if they have no number or their names differ in more than the number.
+func ChunkFiles(pattern string) ([]string, error) {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrNoInputFiles, pattern)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return chunkLess(files[i], files[j])
+	})
+
+	return files, nil
+}
+
+// chunkLess orders the file names by the text around their last number, and then by the number.
+func chunkLess(a, b string) bool {
+	prefixA, numA, suffixA := splitChunkNumber(a)
+	prefixB, numB, suffixB := splitChunkNumber(b)
+	if prefixA != prefixB || suffixA != suffixB || numA < 0 || numB < 0 || numA == numB {
+		return a < b
+	}
+
+	return numA < numB
+}
+
+// splitChunkNumber splits the file name around its last number, num is -1 if the name does not have a number.
+func splitChunkNumber(name string) (prefix string, num int, suffix string) {
+	end := strings.LastIndexAny(name, "0123456789") + 1
+	start := end
+	for start > 0 && name[start-1] >= '0' && name[start-1] <= '9' {
+		start--
+	}
+
+	num, err := strconv.Atoi(name[start:end])
+	if err != nil {
+		return name, -1, ""
+	}
+
+	return name[:start], num, name[end:]
+}
+
+// ReadManifest returns the files listed in the manifest, one path per line.
+// The empty lines and the lines starting with # are ignored, the relative paths are relative to the directory of the manifest.
+func ReadManifest(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var files []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(filepath.Dir(path), line)
+		}
+
+		files = append(files, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("csvprocessor: error while reading manifest: %w", err)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: manifest %q is empty", ErrNoInputFiles, path)
+	}
+
+	return files, nil
+}
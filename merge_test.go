@@ -0,0 +1,143 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestMerger(t *testing.T) {
+	dir := t.TempDir()
+	writeChunk := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		return path
+	}
+
+	chunks := []string{
+		writeChunk("out_1.csv", "id,name\n1,alice\n"),
+		writeChunk("out_2.csv", "id,name\n2,bob\n"),
+		writeChunk("out_10.csv", "id,name\n10,carol\n"),
+	}
+	mismatch := writeChunk("other.csv", "id,email\n3,x@example.com\n")
+	upper := func(ctx context.Context, row []string) []string {
+		row[1] = strings.ToUpper(row[1])
+		return row
+	}
+
+	tests := []struct {
+		name    string
+		inputs  []string
+		opts    []csvprocessor.Option
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "Test merge chunks",
+			inputs: chunks,
+			want:   "id,name\n1,alice\n2,bob\n10,carol\n",
+		},
+		{
+			name:   "Test merge with transformer",
+			inputs: chunks[:2],
+			opts:   []csvprocessor.Option{csvprocessor.WithTransformer(upper), csvprocessor.WithOutputDelimiter(';')},
+			want:   "id;NAME\n1;ALICE\n2;BOB\n",
+		},
+		{
+			name:   "Test merge ignores the chunking options",
+			inputs: chunks,
+			opts:   []csvprocessor.Option{csvprocessor.WithRoundRobin(2), csvprocessor.WithChunkInterval(time.Nanosecond)},
+			want:   "id,name\n1,alice\n2,bob\n10,carol\n",
+		},
+		{
+			name:    "Test header mismatch",
+			inputs:  []string{chunks[0], mismatch},
+			wantErr: csvprocessor.ErrHeaderMismatch,
+		},
+		{
+			name:    "Test no inputs",
+			wantErr: csvprocessor.ErrNoInputFiles,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(dir, "merged", "out.csv")
+			merger, err := csvprocessor.NewMerger(output, tt.inputs, append(tt.opts, csvprocessor.WithLogger(t.Logf))...)
+			if err == nil {
+				err = merger.Merge()
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Merge() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			got, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Merge() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"out_10.csv", "out_2.csv", "out_1.csv", "out_x.csv"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := csvprocessor.ChunkFiles(filepath.Join(dir, "out_*.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"out_1.csv", "out_2.csv", "out_10.csv", "out_x.csv"}
+	for i := range got {
+		got[i] = filepath.Base(got[i])
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ChunkFiles() = %v, want %v", got, want)
+	}
+
+	if _, err := csvprocessor.ChunkFiles(filepath.Join(dir, "missing_*.csv")); !errors.Is(err, csvprocessor.ErrNoInputFiles) {
+		t.Errorf("ChunkFiles() error = %v, want %v", err, csvprocessor.ErrNoInputFiles)
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "manifest.txt")
+	if err := os.WriteFile(manifest, []byte("# chunks\nout_2.csv\n\n  /data/out_1.csv  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := csvprocessor.ReadManifest(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{filepath.Join(dir, "out_2.csv"), "/data/out_1.csv"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadManifest() = %v, want %v", got, want)
+	}
+}
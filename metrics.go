@@ -0,0 +1,222 @@
+package csvprocessor
+
+import (
+	"sync"
+	"time"
+)
+
+// Counter is a metric that can only increase, prometheus.Counter implements it.
+type Counter interface {
+	Add(float64)
+}
+
+// Observer records the observations of a metric, prometheus.Histogram and prometheus.Summary implement it.
+type Observer interface {
+	Observe(float64)
+}
+
+// Metrics contains the metrics updated by the processor as the rows are processed, see WithMetrics().
+// The fields that are nil are not updated. The durations are observed in seconds.
+type Metrics struct {
+	// RowsRead is increased for each data row read from the input, see Stats.RowsRead.
+	RowsRead Counter
+	// RowsWritten is increased for each data row written to the output, see Stats.RowsWritten.
+	RowsWritten Counter
+	// RowsRejected is increased for each row written to the rejects writer, see Stats.RowsRejected.
+	RowsRejected Counter
+	// RowErrors is increased for each error in a row that did not stop the processing, see Stats.Errors.
+	RowErrors Counter
+	// ChunksWritten is increased for each chunk that is closed.
+	ChunksWritten Counter
+	// BytesWritten is increased by the no. of bytes written to each chunk when the chunk is closed.
+	BytesWritten Counter
+	// ChunkWriteDuration observes the time from the opening of each chunk till it is flushed and closed.
+	ChunkWriteDuration Observer
+	// TransformDuration observes the time taken by the transformer for each row.
+	TransformDuration Observer
+}
+
+// WithMetrics updates the given metrics as the rows are processed, Eg: to export them to Prometheus:
+//
+//	rows := promauto.NewCounterVec(prometheus.CounterOpts{Name: "csv_rows_total"}, []string{"status"})
+//	csvprocessor.WithMetrics(csvprocessor.Metrics{
+//		RowsRead:          rows.WithLabelValues("read"),
+//		RowsWritten:       rows.WithLabelValues("written"),
+//		TransformDuration: promauto.NewHistogram(prometheus.HistogramOpts{Name: "csv_transform_seconds"}),
+//	})
+//
+// The metrics are updated from the goroutine running Process().
+func WithMetrics(metrics Metrics) Option {
+	return func(c *Processor) error {
+		c.metrics = &metrics
+		return nil
+	}
+}
+
+// The methods below can be called on a nil *Metrics, when WithMetrics() is not used.
+
+func (m *Metrics) rowRead() {
+	if m != nil {
+		add(m.RowsRead, 1)
+	}
+}
+
+func (m *Metrics) rowWritten() {
+	if m != nil {
+		add(m.RowsWritten, 1)
+	}
+}
+
+func (m *Metrics) rowRejected() {
+	if m != nil {
+		add(m.RowsRejected, 1)
+	}
+}
+
+func (m *Metrics) rowErrors(n int) {
+	if m != nil {
+		add(m.RowErrors, float64(n))
+	}
+}
+
+func (m *Metrics) chunkWritten(chunk ChunkInfo, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	add(m.ChunksWritten, 1)
+	add(m.BytesWritten, float64(chunk.Bytes))
+	if m.ChunkWriteDuration != nil {
+		m.ChunkWriteDuration.Observe(duration.Seconds())
+	}
+}
+
+// timeTransform returns the function that observes the time since the call, or nil if TransformDuration is not set.
+func (m *Metrics) timeTransform() func() {
+	if m == nil || m.TransformDuration == nil {
+		return nil
+	}
+
+	start := time.Now()
+	return func() {
+		m.TransformDuration.Observe(time.Since(start).Seconds())
+	}
+}
+
+func add(counter Counter, n float64) {
+	if counter != nil {
+		counter.Add(n)
+	}
+}
+
+// addErrors records the errors in the rows that did not stop the processing, they are logged if a logger is set.
+func (r *run) addErrors(errs ...error) {
+	r.stats.Errors = append(r.stats.Errors, errs...)
+	r.c.metrics.rowErrors(len(errs))
+	if r.c.dryRun {
+		// the errors are logged at the end of the dry run.
+		return
+	}
+
+	if _, ok := r.c.log.(defaultLogger); ok {
+		// the errors are in the stats, they are not logged to the standard logger unless it is set explicitly.
+		return
+	}
+
+	for _, err := range errs {
+		r.c.log.Log(LogWarn, "csvprocessor: row error", "error", err)
+	}
+}
+
+// TransformerCall is a call of a transformer recorded by MetricsWrapper().
+type TransformerCall struct {
+	// Index is the position of the transformer in the chain wrapped by MetricsWrapper(), Eg: "2",
+	// or "2.0" for the first transformer of a chain nested at position 2. It is empty for the wrapped transformer.
+	Index string
+	// Func is the name of the transformer function, see TransformerProfile.Func.
+	Func string
+	// Duration is the time taken by the call, it includes the time of the nested transformers.
+	Duration time.Duration
+	// Dropped is true if the transformer filtered out the row.
+	Dropped bool
+}
+
+// TransformerRecorder records the calls of the transformers, see MetricsWrapper().
+type TransformerRecorder interface {
+	RecordTransform(call TransformerCall)
+}
+
+// TransformerRecorderFunc adapts a function to TransformerRecorder, Eg: to observe the calls in a Prometheus histogram:
+//
+//	seconds := promauto.NewHistogramVec(prometheus.HistogramOpts{Name: "csv_transformer_seconds"}, []string{"index"})
+//	csvprocessor.TransformerRecorderFunc(func(call csvprocessor.TransformerCall) {
+//		seconds.WithLabelValues(call.Index).Observe(call.Duration.Seconds())
+//	})
+type TransformerRecorderFunc func(call TransformerCall)
+
+// RecordTransform calls f(call).
+func (f TransformerRecorderFunc) RecordTransform(call TransformerCall) {
+	f(call)
+}
+
+// TransformerTimings is a TransformerRecorder that adds up the calls of each transformer, Eg: to find the slow transformer of a chain.
+// It is safe for concurrent use.
+type TransformerTimings struct {
+	mu      sync.Mutex
+	timings map[string]*TransformerTiming
+	order   []string // indexes of the transformers in the order they were first called.
+}
+
+// TransformerTiming is the summary of the calls of a transformer, see TransformerTimings.
+type TransformerTiming struct {
+	// Index and Func identify the transformer, see TransformerCall.
+	Index string
+	Func  string
+	// Calls is the no. of calls, and Dropped is the no. of calls that filtered out the row.
+	Calls   int
+	Dropped int
+	// Duration is the total time of the calls, and Max is the time of the slowest call.
+	Duration time.Duration
+	Max      time.Duration
+}
+
+// RecordTransform adds the call to the timing of its transformer.
+func (t *TransformerTimings) RecordTransform(call TransformerCall) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.timings == nil {
+		t.timings = make(map[string]*TransformerTiming)
+	}
+
+	timing, ok := t.timings[call.Index]
+	if !ok {
+		timing = &TransformerTiming{Index: call.Index, Func: call.Func}
+		t.timings[call.Index] = timing
+		t.order = append(t.order, call.Index)
+	}
+
+	timing.Calls++
+	timing.Duration += call.Duration
+	if call.Duration > timing.Max {
+		timing.Max = call.Duration
+	}
+
+	if call.Dropped {
+		timing.Dropped++
+	}
+}
+
+// Timings returns the timings of the transformers in the order they were first called,
+// the transformers of a chain are before the wrapped transformer as their calls end first.
+func (t *TransformerTimings) Timings() []TransformerTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timings := make([]TransformerTiming, len(t.order))
+	for i, index := range t.order {
+		timings[i] = *t.timings[index]
+	}
+
+	return timings
+}
@@ -0,0 +1,95 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+type testCounter float64
+
+func (c *testCounter) Add(n float64) {
+	*c += testCounter(n)
+}
+
+type testObserver []float64
+
+func (o *testObserver) Observe(v float64) {
+	*o = append(*o, v)
+}
+
+func TestWithMetrics(t *testing.T) {
+	var rowsRead, rowsWritten, rowsRejected, rowErrors, chunks, bytes testCounter
+	var chunkDurations, transformDurations testObserver
+
+	var rejects strings.Builder
+	proc, err := csvprocessor.New(
+		csvprocessor.WithInputReader(strings.NewReader("id\n1\n2\n3\n4\n5\n6,x\n")),
+		csvprocessor.WithChunkSize(2),
+		csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(io.Discard), nil
+		}),
+		csvprocessor.WithTransformer(func(_ context.Context, row []string) []string {
+			switch row[0] {
+			case "2":
+				// the processor returns the panics in the transformers as errors.
+				panic(errors.New("invalid row"))
+			case "3":
+				return nil
+			}
+
+			return row
+		}),
+		csvprocessor.WithRejectWriter(csv.NewWriter(&rejects)),
+		csvprocessor.WithMetrics(csvprocessor.Metrics{
+			RowsRead:           &rowsRead,
+			RowsWritten:        &rowsWritten,
+			RowsRejected:       &rowsRejected,
+			RowErrors:          &rowErrors,
+			ChunksWritten:      &chunks,
+			BytesWritten:       &bytes,
+			ChunkWriteDuration: &chunkDurations,
+			TransformDuration:  &transformDurations,
+		}),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	counters := []struct {
+		name    string
+		counter testCounter
+		want    testCounter
+	}{
+		{"RowsRead", rowsRead, 6},
+		{"RowsWritten", rowsWritten, 4},
+		{"RowsRejected", rowsRejected, 1},
+		{"RowErrors", rowErrors, 1},
+		{"ChunksWritten", chunks, 2},
+		{"BytesWritten", bytes, 16}, // "id\n1\n4\n" and "id\n5\n6,x\n"
+	}
+	for _, c := range counters {
+		if c.counter != c.want {
+			t.Errorf("%s = %v, want %v", c.name, c.counter, c.want)
+		}
+	}
+
+	if len(chunkDurations) != 2 {
+		t.Errorf("ChunkWriteDuration observations = %d, want 2", len(chunkDurations))
+	}
+
+	// 6 rows and the header of 2 chunks
+	if len(transformDurations) != 8 {
+		t.Errorf("TransformDuration observations = %d, want 8", len(transformDurations))
+	}
+}
@@ -0,0 +1,72 @@
+package csvprocessor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrHeaderMismatch is returned by MultiReader when the header of an input does not match the header of the first input.
+var ErrHeaderMismatch = errors.New("csvprocessor: header does not match the header of the first input")
+
+// MultiReader is a CsvReader that reads the given readers one after another as a single input.
+// If the inputs have headers, the header of each input must be the same as the header of the first input,
+// and only the header of the first input is returned.
+type MultiReader struct {
+	readers   []CsvReader
+	hasHeader bool
+
+	// Unexported fields
+	current    int      // index of the reader being read.
+	header     []string // header of the first input.
+	headerRead bool     // whether the header of the current reader has been read.
+}
+
+// NewMultiReader creates a MultiReader that reads the given readers in order.
+// If hasHeader is true, the first row of each reader is treated as header.
+func NewMultiReader(hasHeader bool, readers ...CsvReader) *MultiReader {
+	return &MultiReader{readers: readers, hasHeader: hasHeader}
+}
+
+// Read returns the next row from the current reader, moving to the next reader at EOF.
+func (m *MultiReader) Read() ([]string, error) {
+	for m.current < len(m.readers) {
+		row, err := m.readers[m.current].Read()
+		if errors.Is(err, io.EOF) {
+			m.current++
+			m.headerRead = false
+			continue
+		}
+
+		if err != nil || !m.hasHeader || m.headerRead {
+			return row, err
+		}
+
+		m.headerRead = true
+		if m.header == nil {
+			m.header = append([]string(nil), row...)
+			return row, nil
+		}
+
+		if !equalRows(m.header, row) {
+			return nil, fmt.Errorf("%w: input %d has header %v, expected %v", ErrHeaderMismatch, m.current+1, row, m.header)
+		}
+	}
+
+	return nil, io.EOF
+}
+
+// equalRows reports whether both rows have the same values.
+func equalRows(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
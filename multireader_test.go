@@ -0,0 +1,179 @@
+package csvprocessor_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestMultiReader(t *testing.T) {
+	tests := []struct {
+		name      string
+		inputs    []string
+		hasHeader bool
+		want      [][]string
+		wantErr   error
+	}{
+		{
+			name:      "Test inputs with same header",
+			inputs:    []string{"a,b\n1,2\n", "a,b\n3,4\n5,6\n", "a,b\n"},
+			hasHeader: true,
+			want:      [][]string{{"a", "b"}, {"1", "2"}, {"3", "4"}, {"5", "6"}},
+		},
+		{
+			name:      "Test inputs without header",
+			inputs:    []string{"1,2\n", "", "3,4\n"},
+			hasHeader: false,
+			want:      [][]string{{"1", "2"}, {"3", "4"}},
+		},
+		{
+			name:      "Test inputs with different headers",
+			inputs:    []string{"a,b\n1,2\n", "a,c\n3,4\n"},
+			hasHeader: true,
+			wantErr:   csvprocessor.ErrHeaderMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readers := make([]csvprocessor.CsvReader, len(tt.inputs))
+			for i, input := range tt.inputs {
+				readers[i] = csv.NewReader(strings.NewReader(input))
+			}
+
+			reader := csvprocessor.NewMultiReader(tt.hasHeader, readers...)
+			if tt.wantErr != nil {
+				var err error
+				for err == nil {
+					_, err = reader.Read()
+				}
+
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("MultiReader.Read() error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if actual := readAll(t, reader); !reflect.DeepEqual(actual, tt.want) {
+				t.Errorf("MultiReader.Read() = %v, want %v", actual, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessor_WithFileReaders(t *testing.T) {
+	dir := t.TempDir()
+	inputs := []string{"a,b\n1,2\n3,4\n", "a,b\n5,6\n"}
+	paths := make([]string, len(inputs))
+	for i, input := range inputs {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("input_%d.csv", i))
+		if err := os.WriteFile(paths[i], []byte(input), 0o600); err != nil {
+			t.Fatalf("unable to create input file: %v", err)
+		}
+	}
+
+	output := filepath.Join(dir, "output_%d.csv")
+	proc, err := csvprocessor.New(
+		csvprocessor.WithFileReaders(paths...),
+		csvprocessor.WithOutputFileFormat(output),
+		csvprocessor.WithChunkSize(2),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	for i, want := range []string{"a,b\n1,2\n3,4\n", "a,b\n5,6\n"} {
+		content, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("output_%d.csv", i+1)))
+		if err != nil {
+			t.Fatalf("unable to read output file: %v", err)
+		}
+
+		if string(content) != want {
+			t.Errorf("Process() output %d = %q, want %q", i+1, content, want)
+		}
+	}
+
+	if _, err := csvprocessor.New(csvprocessor.WithFileReaders(paths[0], filepath.Join(dir, "missing.csv"))); err == nil {
+		t.Errorf("New() expected error for missing input file")
+	}
+}
+
+func TestProcessor_WithInputGlob(t *testing.T) {
+	dir := t.TempDir()
+	for _, day := range []string{"2024-01-02", "2024-01-01", "2023-12-31"} {
+		if err := os.Mkdir(filepath.Join(dir, day), 0o700); err != nil {
+			t.Fatalf("unable to create input dir: %v", err)
+		}
+
+		for _, part := range []string{"2", "1"} {
+			content := fmt.Sprintf("day,part\n%s,%s\n", day, part)
+			if err := os.WriteFile(filepath.Join(dir, day, "orders_"+part+".csv"), []byte(content), 0o600); err != nil {
+				t.Fatalf("unable to create input file: %v", err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Test glob matches files in order",
+			pattern: filepath.Join(dir, "2024-*", "orders_*.csv"),
+			want:    "day,part\n2024-01-01,1\n2024-01-01,2\n2024-01-02,1\n2024-01-02,2\n",
+		},
+		{
+			name:    "Test glob without matches",
+			pattern: filepath.Join(dir, "2022-*", "orders_*.csv"),
+			wantErr: true,
+		},
+		{
+			name:    "Test invalid glob",
+			pattern: "[",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output strings.Builder
+			proc, err := csvprocessor.New(
+				csvprocessor.WithInputGlob(tt.pattern),
+				csvprocessor.WithWriterGenerator(func(i int) (io.WriteCloser, error) {
+					return csvprocessor.NoOpCloser(&output), nil
+				}),
+				csvprocessor.WithChunkSize(100),
+				csvprocessor.WithLogger(t.Logf),
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if err := proc.Process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if output.String() != tt.want {
+				t.Errorf("Process() output = %q, want %q", output.String(), tt.want)
+			}
+		})
+	}
+}
@@ -1,16 +1,20 @@
 package csvprocessor
 
 import (
-	"bufio"
 	"context"
 	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"math"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 // NewFileReader creates a new instance of CsvProcessor.
@@ -23,6 +27,16 @@ func NewFileReader(inputFile string, chunkSize int, outputFileFormat string, row
 	)
 }
 
+// NewStdinProcessor creates a new instance of CsvProcessor that reads the CSV from the standard input.
+func NewStdinProcessor(chunkSize int, outputFileFormat string, rowTransformer func(context.Context, []string) []string) (*Processor, error) {
+	return New(
+		WithStdin(),
+		WithOutputFileFormat(outputFileFormat),
+		WithTransformer(rowTransformer),
+		WithChunkSize(chunkSize),
+	)
+}
+
 func NewBufferReader(inputReader io.Reader, outputWriter io.WriteCloser) (*Processor, error) {
 	if inputReader == nil {
 		return nil, ErrInputReaderNil
@@ -46,9 +60,20 @@ func NewBufferReader(inputReader io.Reader, outputWriter io.WriteCloser) (*Proce
 type Option func(*Processor) error
 
 var defaultProcessor Processor = Processor{
-	WriteBufferSize: DefaultWriteBufferSize,
-	rowTransformer:  noOpTransformer,
-	log:             log.Default().Printf,
+	WriteBufferSize:    DefaultWriteBufferSize,
+	readBufferSize:     DefaultReadBufferSize,
+	fileMode:           permission,
+	dirMode:            dirPermission,
+	inputDelimiter:     ',',
+	outputDelimiter:    ',',
+	inputQuote:         '"',
+	outputQuote:        '"',
+	sortRunSize:        DefaultSortRunSize,
+	limitRows:          -1,
+	tailRows:           -1,
+	rowTransformer:     noOpTransformer,
+	log:                defaultLogger(log.Default().Printf),
+	archiveEntryFormat: DefaultZipEntryFormat,
 }
 
 func New(opts ...Option) (*Processor, error) {
@@ -71,23 +96,238 @@ func New(opts ...Option) (*Processor, error) {
 func WithReader(reader CsvReader) Option {
 	return func(c *Processor) error {
 		c.reader = reader
+		c.inputs = nil
 		return nil
 	}
 }
 
 // WithFileReader sets the filename from which the processor will read the data.
+// The file is closed after processing.
 func WithFileReader(inputFile string) Option {
+	return WithFileReaders(inputFile)
+}
+
+// WithFileReaders sets the files from which the processor will read the data, the files are processed as a single input in the given order.
+// If the files have headers (i.e SkipHeaders is false), all the files must have the same header and only the first header is written to the output.
+// The files are closed after processing.
+func WithFileReaders(inputFiles ...string) Option {
 	return func(c *Processor) error {
-		input, err := os.Open(inputFile)
+		if len(inputFiles) == 0 {
+			return ErrInputReaderNil
+		}
+
+		inputs := make([]io.Reader, 0, len(inputFiles))
+		for _, inputFile := range inputFiles {
+			input, err := os.Open(inputFile)
+			if err != nil {
+				c.inputs = inputs
+				c.closeInputs()
+				return err
+			}
+
+			inputs = append(inputs, input)
+		}
+
+		c.reader = nil
+		c.inputs = inputs
+		return nil
+	}
+}
+
+// WithStdin sets the standard input as the input from which the processor will read the data.
+// Standard input is not closed after processing.
+func WithStdin() Option {
+	return WithInputReader(nopReader{os.Stdin})
+}
+
+// WithInputReader sets the stream from which the processor will read the CSV data, Eg: a network stream or an object in cloud storage.
+// Unlike WithReader(), the input options like WithInputDelimiter() and WithInputEncoding() are applied to the stream.
+// If the stream implements io.Closer, it is closed after processing.
+func WithInputReader(input io.Reader) Option {
+	return func(c *Processor) error {
+		if input == nil {
+			return ErrInputReaderNil
+		}
+
+		c.reader = nil
+		c.inputs = []io.Reader{input}
+		return nil
+	}
+}
+
+// nopReader hides the Close() method of the wrapped reader, so that it is not closed by the processor.
+type nopReader struct {
+	io.Reader
+}
+
+// WithInputGlob reads the data from all the files matching the glob pattern (see filepath.Match for the syntax).
+// The matching files are processed in lexical order of their paths as a single input, see WithFileReaders().
+func WithInputGlob(pattern string) Option {
+	return func(c *Processor) error {
+		inputFiles, err := filepath.Glob(pattern)
 		if err != nil {
 			return err
 		}
 
-		var csvReader = csv.NewReader(bufio.NewReaderSize(input, DefaultReadBufferSize))
-		csvReader.LazyQuotes = true
-		csvReader.ReuseRecord = true
+		if len(inputFiles) == 0 {
+			return fmt.Errorf("%w: %q", ErrNoInputFiles, pattern)
+		}
+
+		sort.Strings(inputFiles)
+		return WithFileReaders(inputFiles...)(c)
+	}
+}
+
+// WithInputDelimiter sets the field delimiter of the input, the default is ','.
+// It applies to the inputs parsed by the processor (Eg: WithFileReader(), WithStdin()) and not to the CsvReader set using WithReader().
+func WithInputDelimiter(delimiter rune) Option {
+	return func(c *Processor) error {
+		if !validDelimiter(delimiter) {
+			return ErrInvalidDelimiter
+		}
+
+		c.inputDelimiter = delimiter
+		return nil
+	}
+}
+
+// WithTSVInput parses the input as tab separated values, it is the same as WithInputDelimiter('\t').
+func WithTSVInput() Option {
+	return WithInputDelimiter('\t')
+}
+
+// WithFixedWidthInput parses the inputs as fixed-width records with the given columns instead of CSV, see FixedWidthReader.
+// If the columns have names, they are the header of the input, so SkipHeaders() must not be set.
+// The input options like WithInputEncoding() are applied, but the delimiter and dialect options are ignored.
+func WithFixedWidthInput(columns ...FixedWidthColumn) Option {
+	return func(c *Processor) error {
+		if err := ValidateFixedWidthColumns(columns); err != nil {
+			return err
+		}
+
+		c.fixedWidthColumns = append([]FixedWidthColumn(nil), columns...)
+		return nil
+	}
+}
+
+// WithAutoDialect detects the delimiter, the quote character and the presence of header from the first input using DetectDialect(),
+// the detected delimiter and quote override WithInputDelimiter() and WithInputQuote(), and the first row is treated as data
+// if no header is detected. The dialect is detected for each run, and not for the CsvReader set using WithReader().
+func WithAutoDialect() Option {
+	return func(c *Processor) error {
+		c.autoDialect = true
+		return nil
+	}
+}
 
-		c.reader = csvReader
+// WithHeaderDetection detects whether the first row of the input is a header, overriding SkipHeaders(),
+// the first row is treated as data if its values look like the values of the following rows (numeric, same length or repeated).
+func WithHeaderDetection() Option {
+	return func(c *Processor) error {
+		c.headerDetection = true
+		return nil
+	}
+}
+
+// WithOutputDelimiter sets the field delimiter of the output chunks, the default is ','.
+// Eg: use '\t' to write the chunks as tab separated values.
+func WithOutputDelimiter(delimiter rune) Option {
+	return func(c *Processor) error {
+		if !validDelimiter(delimiter) {
+			return ErrInvalidDelimiter
+		}
+
+		c.outputDelimiter = delimiter
+		return nil
+	}
+}
+
+// WithQuoteMode sets how the fields are quoted in the output chunks, the default is QuoteMinimal.
+func WithQuoteMode(mode QuoteMode) Option {
+	return func(c *Processor) error {
+		if mode < QuoteMinimal || mode > QuoteNever {
+			return ErrInvalidQuoteMode
+		}
+
+		c.quoteMode = mode
+		return nil
+	}
+}
+
+// WithCRLF controls whether the output rows end with \r\n instead of \n, as needed by some Windows applications.
+func WithCRLF(useCRLF bool) Option {
+	return func(c *Processor) error {
+		c.useCRLF = useCRLF
+		return nil
+	}
+}
+
+// WithOutputBOM controls whether a byte order mark is written at the start of every chunk,
+// this helps applications like Excel to detect the encoding of the output files.
+// The BOM is in the output encoding (see WithOutputEncoding()), it is not written for the single byte encodings that do not have one.
+func WithOutputBOM(outputBOM bool) Option {
+	return func(c *Processor) error {
+		c.outputBOM = outputBOM
+		return nil
+	}
+}
+
+// WithInputEncoding sets the character encoding of the input files, the default is UTF-8.
+// Supported encodings are "utf-8", "iso-8859-1" (or "latin1"), "windows-1252", "utf-16le", "utf-16be" and "utf-16"
+// (in the byte order of the BOM, little endian without one). The BOM of the input is skipped.
+// The processing stops with ErrInvalidEncodedInput if the input is not valid in the encoding.
+// The encoding is not applied to the CsvReader set using WithReader().
+func WithInputEncoding(name string) Option {
+	return func(c *Processor) error {
+		enc, ok := lookupEncoding(name)
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrUnsupportedEncoding, name)
+		}
+
+		c.inputEncoding = enc
+		return nil
+	}
+}
+
+// WithReadBufferSize sets the size of the buffer used to read each input, the default is DefaultReadBufferSize.
+// It applies to the inputs read by the processor (Eg: WithFileReader(), WithInputReader(), WithStdin()),
+// use a smaller buffer when processing many small files and a larger one for huge files.
+// The CSV parser adds a 4 KB buffer of its own when the buffer is smaller than that.
+// It also applies to a JSONLinesReader passed to WithReader(), unless it was created with a *bufio.Reader.
+func WithReadBufferSize(size int) Option {
+	return func(c *Processor) error {
+		if size <= 0 {
+			return ErrInvalidBufferSize
+		}
+
+		c.readBufferSize = size
+		return nil
+	}
+}
+
+// WithWriteBufferSize sets the size of the buffer used to write each output chunk, the default is DefaultWriteBufferSize.
+func WithWriteBufferSize(size int) Option {
+	return func(c *Processor) error {
+		if size <= 0 {
+			return ErrInvalidBufferSize
+		}
+
+		c.WriteBufferSize = size
+		return nil
+	}
+}
+
+// WithOutputEncoding sets the character encoding of the output chunks, the default is UTF-8.
+// The supported encodings are the same as WithInputEncoding(),
+// the characters that cannot be represented in a single byte encoding are written as '?'.
+func WithOutputEncoding(name string) Option {
+	return func(c *Processor) error {
+		enc, ok := lookupEncoding(name)
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrUnsupportedEncoding, name)
+		}
+
+		c.outputEncoding = enc
 		return nil
 	}
 }
@@ -105,6 +345,29 @@ func WithTransformer(t CsvRowTransformer) Option {
 	}
 }
 
+// WithInPlaceTransformer sets a transformer that modifies the rows in place, it replaces the transformer set by WithTransformer().
+// The row passed to the transformer is reused for all the rows, so the values can be set and columns appended without
+// allocating a new row for every row, Eg:
+//
+//	csvprocessor.WithInPlaceTransformer(func(ctx context.Context, row *[]string) bool {
+//		(*row)[1] = strings.ToUpper((*row)[1])
+//		*row = append(*row, "constant")
+//		return true
+//	})
+//
+// The transformer must not retain the row after it returns, as it is overwritten by the next row.
+func WithInPlaceTransformer(t InPlaceTransformer) Option {
+	return func(c *Processor) error {
+		if t == nil {
+			c.rowTransformer = noOpTransformer
+			return nil
+		}
+
+		c.rowTransformer = t.rowTransformer()
+		return nil
+	}
+}
+
 // WithOutputFileFormat sets the output file format used to generate output file names.
 func WithOutputFileFormat(format string) Option {
 	return func(c *Processor) error {
@@ -112,7 +375,22 @@ func WithOutputFileFormat(format string) Option {
 			return ErrInvalidOutputFileFormat
 		}
 
-		c.outputChunkGenerator = splitFileGenerator(format)
+		c.outputChunkGenerator = c.splitFileGenerator(format)
+		return nil
+	}
+}
+
+// WithOutputPermissions sets the permissions of the output files created by WithOutputFileFormat() and WithZipOutput(),
+// and of their parent directories, which are created if missing. The defaults are 0644 for files and 0755 for directories.
+// As with os.OpenFile(), the permissions are masked by the umask of the process, and the permissions of existing files are not changed.
+func WithOutputPermissions(fileMode, dirMode fs.FileMode) Option {
+	return func(c *Processor) error {
+		if fileMode&^fs.ModePerm != 0 || dirMode&^fs.ModePerm != 0 {
+			return ErrInvalidPermissions
+		}
+
+		c.fileMode = fileMode
+		c.dirMode = dirMode
 		return nil
 	}
 }
@@ -125,6 +403,130 @@ func WithWriterGenerator(generator OutputChunkGenerator) Option {
 	}
 }
 
+// WithZipOutput writes all the chunks as entries of a single zip archive at the given path, instead of separate files.
+// The entries are named using DefaultZipEntryFormat, unless changed using WithZipEntryFormat().
+// It overrides WithOutputFileFormat() and WithWriterGenerator(). If the processing fails, the archive is removed.
+func WithZipOutput(path string) Option {
+	return func(c *Processor) error {
+		if strings.TrimSpace(path) == "" {
+			return ErrInvalidOutputFileFormat
+		}
+
+		c.newArchive = func(entryFormat string) (chunkArchive, error) {
+			return c.createZipArchive(path, entryFormat)
+		}
+
+		return nil
+	}
+}
+
+// WithTarGzOutput streams all the chunks as entries of a single gzip compressed tar archive to w, Eg: os.Stdout or an upload to object storage.
+// As the size of an entry is written before its content, each chunk is buffered in a temporary file until it is complete.
+// The entries are named like WithZipOutput(), and it overrides WithOutputFileFormat() and WithWriterGenerator().
+// w is not closed, if the processing fails and w has an Abort() method, it is called, Eg: to cancel the upload.
+func WithTarGzOutput(w io.Writer) Option {
+	return func(c *Processor) error {
+		if w == nil {
+			return ErrOutputWriterNil
+		}
+
+		c.newArchive = func(entryFormat string) (chunkArchive, error) {
+			return newTarGzArchive(w, entryFormat), nil
+		}
+
+		return nil
+	}
+}
+
+// WithZipEntryFormat sets the format used to generate the names of the entries in the archive written by WithZipOutput() or WithTarGzOutput().
+func WithZipEntryFormat(format string) Option {
+	return func(c *Processor) error {
+		if strings.TrimSpace(format) == "" {
+			return ErrInvalidOutputFileFormat
+		}
+
+		c.archiveEntryFormat = format
+		return nil
+	}
+}
+
+// WithWriterFactory sets the CsvWriterFactory that creates the CsvWriter for each output chunk,
+// this can be used to write the chunks in formats other than CSV. The delimiter and quoting options are not applied to these writers.
+func WithWriterFactory(factory CsvWriterFactory) Option {
+	return func(c *Processor) error {
+		if factory == nil {
+			return ErrWriterFactoryNil
+		}
+
+		c.writerFactory = factory
+		return nil
+	}
+}
+
+// WithJSONOutput writes each chunk as a JSON array of objects, with the header row as the keys. See JSONWriter.
+func WithJSONOutput() Option {
+	return WithWriterFactory(func(w io.Writer) CsvWriter {
+		return NewJSONArrayWriter(w)
+	})
+}
+
+// WithJSONLinesOutput writes each row as a JSON object in a separate line, with the header row as the keys. See JSONWriter.
+func WithJSONLinesOutput() Option {
+	return WithWriterFactory(func(w io.Writer) CsvWriter {
+		return NewJSONLinesWriter(w)
+	})
+}
+
+// WithXLSXOutput writes each chunk as an Excel (.xlsx) workbook with a single sheet, with the header as a bold first row. See XLSXWriter.
+func WithXLSXOutput() Option {
+	return WithWriterFactory(func(w io.Writer) CsvWriter {
+		return NewXLSXWriter(w)
+	})
+}
+
+// WithSQLOutput writes each chunk as INSERT statements into the given table, with at most batchSize rows per statement. See SQLWriter.
+// The names and the values are quoted as per the SQL dialect, see WithSQLDialect().
+func WithSQLOutput(table string, batchSize int) Option {
+	return func(c *Processor) error {
+		return WithWriterFactory(func(w io.Writer) CsvWriter {
+			writer := NewSQLWriter(w, table, batchSize)
+			writer.Dialect = c.sqlDialect
+			return writer
+		})(c)
+	}
+}
+
+// WithSQLDialect sets the SQL dialect of the statements written by WithSQLOutput() and WithDBSink(), the default is SQLStandard.
+// Eg: use SQLMySQL to quote the names with backticks and to escape the backslashes of the values for MySQL,
+// or SQLPostgres for the $1 placeholders of the prepared statements of PostgreSQL.
+func WithSQLDialect(dialect SQLDialect) Option {
+	return func(c *Processor) error {
+		if dialect < SQLStandard || dialect > SQLOracle {
+			return ErrInvalidSQLDialect
+		}
+
+		c.sqlDialect = dialect
+		return nil
+	}
+}
+
+// WithFixedWidthOutput writes each row as a fixed-width record with the given fields, see FixedWidthWriter.
+// The header is not written, and the records end with \r\n if WithCRLF() is set.
+func WithFixedWidthOutput(fields ...FixedWidthField) Option {
+	return func(c *Processor) error {
+		if err := ValidateFixedWidthFields(fields); err != nil {
+			return err
+		}
+
+		fields = append([]FixedWidthField(nil), fields...)
+		return WithWriterFactory(func(w io.Writer) CsvWriter {
+			writer := NewFixedWidthWriter(w, fields...)
+			writer.UseCRLF = c.useCRLF
+			return writer
+		})(c)
+	}
+}
+
 // WithChunkSize sets the chunk size (in no. of rows) for each split.
 func WithChunkSize(size int) Option {
 	return func(c *Processor) error {
@@ -133,10 +535,246 @@ func WithChunkSize(size int) Option {
 	}
 }
 
-// WithLogger sets the logger for processor.
+// WithChunkInterval rotates the chunk when it has been open for the given duration, in addition to the chunk size.
+// This is useful to archive a stream of rows in micro-batches, see MessageReader.
+// The interval is checked when a row is read, or when the reader returns ErrNoMessage while the stream is idle.
+func WithChunkInterval(interval time.Duration) Option {
+	return func(c *Processor) error {
+		if interval <= 0 {
+			return ErrInvalidChunkInterval
+		}
+
+		c.chunkInterval = interval
+		return nil
+	}
+}
+
+// WithKeepKeyGroups keeps the consecutive rows with the same values in the given 0-based key columns in the same chunk,
+// Eg: the lines of an order. A full chunk is closed only when the key changes, so a chunk can have more rows than the chunk size.
+// Eg: with a chunk size of 1000, the chunk is closed at the first row after the 1000th row with a different order ID:
+//
+//	csvprocessor.WithChunkSize(1000),
+//	csvprocessor.WithKeepKeyGroups(0),
+//
+// Only the consecutive rows are kept together, so the input should be ordered by the key columns, Eg: using WithSortBy().
+// The chunks are still closed by the chunk interval and the time window, see WithChunkInterval() and WithChunkByTimeColumn().
+func WithKeepKeyGroups(columns ...int) Option {
+	return func(c *Processor) error {
+		if len(columns) == 0 {
+			return ErrInvalidGroupColumns
+		}
+
+		for _, column := range columns {
+			if column < 0 {
+				return ErrInvalidGroupColumns
+			}
+		}
+
+		c.groupColumns = columns
+		return nil
+	}
+}
+
+// WithLogger sets the logger for processor, the fields of the messages are appended as key=value. See WithLeveledLogger().
 func WithLogger(logger Logger) Option {
 	return func(c *Processor) error {
-		c.log = logger
+		c.log = printfLogger(logger)
+		return nil
+	}
+}
+
+// WithSkipRows skips the first n data rows of the input, the header row is not counted.
+// Can be used to resume processing of a partially processed input.
+func WithSkipRows(n int) Option {
+	return func(c *Processor) error {
+		if n < 0 {
+			return ErrInvalidRowCount
+		}
+
+		c.skipRows = n
+		return nil
+	}
+}
+
+// WithLimitRows processes at most n data rows of the input (after skipping the rows from WithSkipRows()).
+// The rest of the input is not read.
+func WithLimitRows(n int) Option {
+	return func(c *Processor) error {
+		if n < 0 {
+			return ErrInvalidRowCount
+		}
+
+		c.limitRows = n
+		return nil
+	}
+}
+
+// Head processes only the first n data rows of the input, it is the same as WithLimitRows(n).
+func Head(n int) Option {
+	return WithLimitRows(n)
+}
+
+// Tail processes only the last n data rows of the input.
+// The entire input is read, holding the last n rows in memory.
+func Tail(n int) Option {
+	return func(c *Processor) error {
+		if n < 0 {
+			return ErrInvalidRowCount
+		}
+
+		c.tailRows = n
+		return nil
+	}
+}
+
+// WithSample processes a random sample of the input rows, each row is included with the given probability (0 < rate <= 1).
+// Use WithSeed() to get the same sample across runs.
+func WithSample(rate float64) Option {
+	return func(c *Processor) error {
+		if rate <= 0 || rate > 1 {
+			return ErrInvalidSampleRate
+		}
+
+		c.sampleRate = rate
+		return nil
+	}
+}
+
+// WithReservoirSample processes a uniform random sample of exactly n input rows (or all the rows, if the input has fewer rows).
+// The sampled rows are held in memory and processed in their input order.
+// Use WithSeed() to get the same sample across runs.
+func WithReservoirSample(n int) Option {
+	return func(c *Processor) error {
+		if n <= 0 {
+			return ErrInvalidSampleSize
+		}
+
+		c.sampleSize = n
+		return nil
+	}
+}
+
+// WithSeed sets the seed used for random sampling, the same seed produces the same sample for the same input.
+func WithSeed(seed int64) Option {
+	return func(c *Processor) error {
+		c.seed = seed
+		c.seeded = true
+		return nil
+	}
+}
+
+// WithAggregation groups the input rows by the groupBy columns and processes one row per group with the aggregated values.
+// See Aggregator for more details.
+func WithAggregation(groupBy []int, aggregations ...Aggregation) Option {
+	return func(c *Processor) error {
+		if groupBy == nil {
+			groupBy = []int{}
+		}
+
+		c.groupBy = groupBy
+		c.aggregations = aggregations
+		return nil
+	}
+}
+
+// WithSortBy sorts the input rows by the given columns before they are transformed and split into chunks.
+// Inputs larger than memory are sorted using temp files, see Sorter for more details.
+func WithSortBy(columns []int, order SortOrder) Option {
+	return func(c *Processor) error {
+		if len(columns) == 0 {
+			return ErrInvalidSortColumns
+		}
+
+		c.sortColumns = columns
+		c.sortOrder = order
+		return nil
+	}
+}
+
+// WithSortRunSize sets the max no. of rows held in memory while sorting the input, see WithSortBy().
+func WithSortRunSize(rows int) Option {
+	return func(c *Processor) error {
+		c.sortRunSize = rows
+		return nil
+	}
+}
+
+// WithSchemaValidation validates every input row against the schema before it is transformed.
+// Processing stops with a *ValidationError at the first violation, in a dry run all the violations are recorded in Processor.Stats().
+func WithSchemaValidation(schema Schema) Option {
+	return func(c *Processor) error {
+		validator, err := newValidator(schema)
+		if err != nil {
+			return err
+		}
+
+		c.validator = validator
+		return nil
+	}
+}
+
+// RejectReasonColumn is the name of the column added to the header of the rejects writer, with the reason for rejecting each row.
+const RejectReasonColumn = "reject_reason"
+
+// WithRejectWriter routes the rows that fail schema validation or transformation to the given writer instead of stopping the processing.
+// The original row is written along with an extra column with the reason for rejection.
+// A transformation fails if the transformer panics, if the panic value is an error it is used as the reason.
+// If the input has a header, it is written as the first row with RejectReasonColumn added.
+func WithRejectWriter(writer CsvWriter) Option {
+	return func(c *Processor) error {
+		c.rejectWriter = writer
+		return nil
+	}
+}
+
+// WithDryRun runs the entire read and transform pipeline without writing any output.
+// The row counts, chunk boundaries and errors (including schema violations) are logged at the end and are available from Processor.Stats().
+// An output file format or writer generator is not needed for a dry run.
+func WithDryRun(dryRun bool) Option {
+	return func(c *Processor) error {
+		c.dryRun = dryRun
+		return nil
+	}
+}
+
+// WithHeaderRows sets the no. of rows in the header of the input, the default is 1.
+// All the header rows are written at the top of every chunk and passed to the transformers with CtxIsHeader set to true,
+// CtxHeaderRows contains all the header rows and CtxHeaderRowNum the index of the current one.
+// The first header row is used as the column names, Eg: for the schema validation.
+func WithHeaderRows(n int) Option {
+	return func(c *Processor) error {
+		if n < 1 {
+			return ErrInvalidHeaderRows
+		}
+
+		c.headerRows = n
+		return nil
+	}
+}
+
+// WithSkipLeadingLines discards the first n lines of each input before it is parsed, Eg: the title and metadata lines
+// of exports that precede the header. The lines are not parsed as CSV, so they can contain quotes and any no. of fields.
+// It applies to the inputs read by the processor (Eg: WithFileReader(), WithStdin()) and not to the CsvReader set using WithReader().
+func WithSkipLeadingLines(n int) Option {
+	return func(c *Processor) error {
+		if n < 0 {
+			return ErrInvalidRowCount
+		}
+
+		c.skipLines = n
+		return nil
+	}
+}
+
+// WithNormalizeFieldCount sets how the rows with a different no. of fields than the header are handled, the default is FieldCountAny.
+// If there is no header, the no. of fields in the first row is used.
+func WithNormalizeFieldCount(mode FieldCountMode) Option {
+	return func(c *Processor) error {
+		if mode < FieldCountAny || mode > FieldCountReject {
+			return ErrInvalidFieldCountMode
+		}
+
+		c.fieldCountMode = mode
 		return nil
 	}
 }
@@ -151,18 +789,33 @@ func SkipHeaders(skip bool) Option {
 
 var (
 	ErrInputReaderNil             = errors.New("csvprocessor: input reader cannot be nil")
+	ErrNoInputFiles               = errors.New("csvprocessor: no input files match the pattern")
 	ErrOutputWriterNil            = errors.New("csvprocessor: output writer cannot be nil")
+	ErrWriterFactoryNil           = errors.New("csvprocessor: writer factory cannot be nil")
 	ErrOutputChunkGeneratorNotSet = errors.New("csvprocessor: function to generate output chunks not set")
 	ErrInvalidChunkSize           = errors.New("csvprocessor: ChunkSize for splitting must be >= 0, to prevent splitting use math.MaxInt as ChunkSize")
+	ErrInvalidChunkInterval       = errors.New("csvprocessor: chunk interval must be > 0")
 	ErrInvalidOutputFileFormat    = errors.New("csvprocessor: OutputFileFormat cannot be empty")
+	ErrInvalidDelimiter           = errors.New("csvprocessor: delimiter cannot be a quote, a new line or an invalid character")
+	ErrInvalidQuoteMode           = errors.New("csvprocessor: invalid quote mode")
+	ErrUnsupportedEncoding        = errors.New("csvprocessor: unsupported character encoding")
+	ErrInvalidSortColumns         = errors.New("csvprocessor: at least one column is needed for sorting")
+	ErrInvalidGroupColumns        = errors.New("csvprocessor: at least one key column >= 0 is needed for the groups")
+	ErrInvalidRowCount            = errors.New("csvprocessor: no. of rows must be >= 0")
+	ErrInvalidHeaderRows          = errors.New("csvprocessor: no. of header rows must be >= 1")
+	ErrInvalidFieldCountMode      = errors.New("csvprocessor: invalid field count mode")
+	ErrInvalidSampleRate          = errors.New("csvprocessor: sample rate must be > 0 and <= 1")
+	ErrInvalidSampleSize          = errors.New("csvprocessor: sample size must be > 0")
+	ErrInvalidBufferSize          = errors.New("csvprocessor: buffer size must be > 0")
+	ErrInvalidPermissions         = errors.New("csvprocessor: permissions must contain only the permission bits")
 )
 
 func validate(c *Processor) (*Processor, error) {
-	if c.reader == nil {
+	if c.reader == nil && len(c.inputs) == 0 {
 		return nil, ErrInputReaderNil
 	}
 
-	if c.outputChunkGenerator == nil {
+	if c.outputChunkGenerator == nil && c.newArchive == nil && !c.dryRun {
 		return nil, ErrOutputChunkGeneratorNotSet
 	}
 
@@ -170,5 +823,22 @@ func validate(c *Processor) (*Processor, error) {
 		return nil, ErrInvalidChunkSize
 	}
 
+	if err := c.validQuoteDelimiters(); err != nil {
+		return nil, err
+	}
+
+	if err := c.validShards(); err != nil {
+		return nil, err
+	}
+
+	if err := c.validMinLastChunkRows(); err != nil {
+		return nil, err
+	}
+
 	return c, nil
 }
+
+// validDelimiter reports whether the rune can be used as a field delimiter in encoding/csv.
+func validDelimiter(r rune) bool {
+	return r != 0 && r != '"' && r != '\r' && r != '\n' && utf8.ValidRune(r) && r != utf8.RuneError
+}
@@ -3,8 +3,11 @@ package csvprocessor_test
 import (
 	"context"
 	"encoding/csv"
+	"errors"
 	"io"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 
@@ -235,3 +238,453 @@ func TestNewBufferReader(t *testing.T) {
 		})
 	}
 }
+
+func TestNewStdinProcessor(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "stdin.csv")
+	if err := os.WriteFile(input, []byte(verySmallCSV), 0o600); err != nil {
+		t.Fatalf("unable to create input file: %v", err)
+	}
+
+	stdin, err := os.Open(input)
+	if err != nil {
+		t.Fatalf("unable to open input file: %v", err)
+	}
+	defer stdin.Close()
+
+	originalStdin := os.Stdin
+	os.Stdin = stdin
+	defer func() {
+		os.Stdin = originalStdin
+	}()
+
+	output := filepath.Join(dir, "output.csv")
+	proc, err := csvprocessor.NewStdinProcessor(100, output, nil)
+	if err != nil {
+		t.Fatalf("NewStdinProcessor() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("unable to read output file: %v", err)
+	}
+
+	if string(content) != verySmallCSV {
+		t.Errorf("Process() output = %q, want %q", content, verySmallCSV)
+	}
+
+	// stdin must not be closed by the processor
+	if _, err := stdin.Seek(0, io.SeekStart); err != nil {
+		t.Errorf("Process() closed stdin, error = %v", err)
+	}
+}
+
+func TestWithInputDelimiter(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opt     csvprocessor.Option
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "Test semicolon delimiter",
+			input: "a;b\n1;\"2;3\"\n",
+			opt:   csvprocessor.WithInputDelimiter(';'),
+			want:  "a,b\n1,2;3\n",
+		},
+		{
+			name:  "Test tsv input",
+			input: "a\tb\n1\t2,3\n",
+			opt:   csvprocessor.WithTSVInput(),
+			want:  "a,b\n1,\"2,3\"\n",
+		},
+		{
+			name:    "Test invalid delimiter",
+			input:   "a,b\n",
+			opt:     csvprocessor.WithInputDelimiter('"'),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processFile(t, tt.input, tt.opt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithOutputDelimiter(t *testing.T) {
+	tests := []struct {
+		name    string
+		opt     csvprocessor.Option
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Test pipe delimiter",
+			opt:  csvprocessor.WithOutputDelimiter('|'),
+			want: "a|b\n1|\"2|3\"\n4|5,6\n",
+		},
+		{
+			name: "Test tab delimiter",
+			opt:  csvprocessor.WithOutputDelimiter('\t'),
+			want: "a\tb\n1\t2|3\n4\t5,6\n",
+		},
+		{
+			name:    "Test invalid delimiter",
+			opt:     csvprocessor.WithOutputDelimiter('\n'),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processString(t, "a,b\n1,2|3\n4,\"5,6\"\n", tt.opt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithHeaderRows(t *testing.T) {
+	const input = "item,price\nname,usd\napple,1\nbanana,2\n"
+
+	unitSuffix := func(ctx context.Context, row []string) []string {
+		if isHeader, _ := ctx.Value(csvprocessor.CtxIsHeader).(bool); !isHeader {
+			return row
+		}
+
+		headers, _ := ctx.Value(csvprocessor.CtxHeaderRows).([][]string)
+		if i, _ := ctx.Value(csvprocessor.CtxHeaderRowNum).(int); i == 0 && len(headers) > 1 {
+			row[1] += " (" + headers[1][1] + ")"
+		}
+
+		return row
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		opt     []csvprocessor.Option
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "Test two header rows",
+			input: input,
+			opt:   []csvprocessor.Option{csvprocessor.WithHeaderRows(2)},
+			want:  "item,price\nname,usd\napple,1\nbanana,2\n",
+		},
+		{
+			name:  "Test header rows repeated in every chunk",
+			input: input,
+			opt:   []csvprocessor.Option{csvprocessor.WithHeaderRows(2), csvprocessor.WithChunkSize(1)},
+			want:  "item,price\nname,usd\napple,1\nitem,price\nname,usd\nbanana,2\n",
+		},
+		{
+			name:  "Test header rows in transformer context",
+			input: input,
+			opt:   []csvprocessor.Option{csvprocessor.WithHeaderRows(2), csvprocessor.WithTransformer(unitSuffix)},
+			want:  "item,price (usd)\nname,usd\napple,1\nbanana,2\n",
+		},
+		{
+			name:  "Test header rows longer than input",
+			input: "item,price\nname,usd\n",
+			opt:   []csvprocessor.Option{csvprocessor.WithHeaderRows(3)},
+			want:  "item,price\nname,usd\n",
+		},
+		{
+			name:    "Test invalid header rows",
+			input:   input,
+			opt:     []csvprocessor.Option{csvprocessor.WithHeaderRows(0)},
+			wantErr: csvprocessor.ErrInvalidHeaderRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processString(t, tt.input, tt.opt...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+// readSizeRecorder records the largest read from the underlying reader.
+type readSizeRecorder struct {
+	r       io.Reader
+	maxRead int
+}
+
+func (rec *readSizeRecorder) Read(p []byte) (int, error) {
+	if len(p) > rec.maxRead {
+		rec.maxRead = len(p)
+	}
+
+	return rec.r.Read(p)
+}
+
+func TestWithReadBufferSize(t *testing.T) {
+	input := "id,name\n" + strings.Repeat("1,a long value that does not fit in the buffer\n", 10)
+	tests := []struct {
+		name        string
+		opt         csvprocessor.Option
+		wantMaxRead int
+		wantErr     error
+	}{
+		{
+			name:        "Test default buffer size",
+			opt:         csvprocessor.WithLogger(noOpLogger),
+			wantMaxRead: csvprocessor.DefaultReadBufferSize,
+		},
+		{
+			name:        "Test small buffer size",
+			opt:         csvprocessor.WithReadBufferSize(8192),
+			wantMaxRead: 8192,
+		},
+		{
+			name:    "Test invalid buffer size",
+			opt:     csvprocessor.WithReadBufferSize(0),
+			wantErr: csvprocessor.ErrInvalidBufferSize,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output strings.Builder
+			recorder := &readSizeRecorder{r: strings.NewReader(input)}
+			proc, err := csvprocessor.New(
+				csvprocessor.WithInputReader(recorder),
+				csvprocessor.WithChunkSize(100),
+				csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+					return csvprocessor.NoOpCloser(&output), nil
+				}),
+				csvprocessor.WithLogger(t.Logf),
+				tt.opt,
+			)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("New() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if err := proc.Process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if output.String() != input {
+				t.Errorf("Process() output = %q, want %q", output.String(), input)
+			}
+
+			if recorder.maxRead != tt.wantMaxRead {
+				t.Errorf("max read size = %d, want %d", recorder.maxRead, tt.wantMaxRead)
+			}
+		})
+	}
+}
+
+// writeSizeRecorder records the largest write to the underlying writer.
+type writeSizeRecorder struct {
+	strings.Builder
+	maxWrite int
+}
+
+func (rec *writeSizeRecorder) Write(p []byte) (int, error) {
+	if len(p) > rec.maxWrite {
+		rec.maxWrite = len(p)
+	}
+
+	return rec.Builder.Write(p)
+}
+
+func TestWithWriteBufferSize(t *testing.T) {
+	input := "id,name\n" + strings.Repeat("1,a long value that does not fit in the buffer\n", 200)
+	tests := []struct {
+		name         string
+		opt          csvprocessor.Option
+		wantMaxWrite int
+		wantErr      error
+	}{
+		{
+			name:         "Test default buffer size",
+			opt:          csvprocessor.WithLogger(noOpLogger),
+			wantMaxWrite: len(input),
+		},
+		{
+			name:         "Test small buffer size",
+			opt:          csvprocessor.WithWriteBufferSize(4096),
+			wantMaxWrite: 4096,
+		},
+		{
+			name:    "Test invalid buffer size",
+			opt:     csvprocessor.WithWriteBufferSize(-1),
+			wantErr: csvprocessor.ErrInvalidBufferSize,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &writeSizeRecorder{}
+			proc, err := csvprocessor.New(
+				csvprocessor.WithInputReader(strings.NewReader(input)),
+				csvprocessor.WithChunkSize(1000),
+				csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+					return csvprocessor.NoOpCloser(recorder), nil
+				}),
+				csvprocessor.WithLogger(t.Logf),
+				tt.opt,
+			)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("New() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if err := proc.Process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if recorder.String() != input {
+				t.Errorf("Process() output = %q, want %q", recorder.String(), input)
+			}
+
+			if recorder.maxWrite != tt.wantMaxWrite {
+				t.Errorf("max write size = %d, want %d", recorder.maxWrite, tt.wantMaxWrite)
+			}
+		})
+	}
+}
+
+func TestWithOutputPermissions(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "nested", "dir")
+	proc, err := csvprocessor.New(
+		csvprocessor.WithInputReader(strings.NewReader("id\n1\n2\n")),
+		csvprocessor.WithChunkSize(1),
+		csvprocessor.WithOutputFileFormat(filepath.Join(outputDir, "out_%d.csv")),
+		csvprocessor.WithOutputPermissions(0o600, 0o700),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	dir, err := os.Stat(outputDir)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+
+	if perm := dir.Mode().Perm(); perm != 0o700 {
+		t.Errorf("output directory permissions = %o, want %o", perm, 0o700)
+	}
+
+	for _, name := range []string{"out_1.csv", "out_2.csv"} {
+		file, err := os.Stat(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Fatalf("Stat() error = %v", err)
+		}
+
+		if perm := file.Mode().Perm(); perm != 0o600 {
+			t.Errorf("%s permissions = %o, want %o", name, perm, 0o600)
+		}
+	}
+
+	if _, err := csvprocessor.New(csvprocessor.WithOutputPermissions(os.ModeDir|0o644, 0o755)); !errors.Is(err, csvprocessor.ErrInvalidPermissions) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidPermissions)
+	}
+}
+
+func TestWithSkipLeadingLines(t *testing.T) {
+	preamble := "Sales report \"Q1\"\nGenerated on: 2024-01-01, by: " + strings.Repeat("x", 100) + "\n"
+	dir := t.TempDir()
+	var inputs []string
+	for name, data := range map[string]string{"input_1.csv": "id,name\n1,a\n", "input_2.csv": "id,name\n2,b\n"} {
+		input := filepath.Join(dir, name)
+		if err := os.WriteFile(input, []byte(preamble+data), 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+
+		inputs = append(inputs, input)
+	}
+
+	sort.Strings(inputs)
+	tests := []struct {
+		name    string
+		opts    []csvprocessor.Option
+		want    string
+		wantErr error
+	}{
+		{
+			name: "Test leading lines of each input are skipped",
+			opts: []csvprocessor.Option{csvprocessor.WithSkipLeadingLines(2)},
+			want: "id,name\n1,a\n2,b\n",
+		},
+		{
+			name: "Test lines longer than the read buffer",
+			opts: []csvprocessor.Option{csvprocessor.WithSkipLeadingLines(2), csvprocessor.WithReadBufferSize(16)},
+			want: "id,name\n1,a\n2,b\n",
+		},
+		{
+			name: "Test more lines than the input",
+			opts: []csvprocessor.Option{csvprocessor.WithSkipLeadingLines(10)},
+			want: "",
+		},
+		{
+			name:    "Test invalid no. of lines",
+			opts:    []csvprocessor.Option{csvprocessor.WithSkipLeadingLines(-1)},
+			wantErr: csvprocessor.ErrInvalidRowCount,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output strings.Builder
+			proc, err := csvprocessor.New(append([]csvprocessor.Option{
+				csvprocessor.WithFileReaders(inputs...),
+				csvprocessor.WithChunkSize(10),
+				csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+					return csvprocessor.NoOpCloser(&output), nil
+				}),
+				csvprocessor.WithLogger(t.Logf),
+			}, tt.opts...)...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("New() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if err := proc.Process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if output.String() != tt.want {
+				t.Errorf("Process() output = %q, want %q", output.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package csvprocessor
+
+// ParquetSource represents a reader of the decoded rows of a Parquet file.
+// The package does not decode Parquet, the source wraps the reader of a Parquet library,
+// Eg: the GenericReader of github.com/parquet-go/parquet-go or the file reader of github.com/apache/arrow/go/parquet.
+type ParquetSource interface {
+	// Columns returns the names of the columns in the order of the values returned by Next.
+	Columns() []string
+	// Next returns the values of the next row, and io.EOF after the last row.
+	Next() ([]any, error)
+}
+
+// ParquetReader is a CsvReader that converts the typed rows of a ParquetSource to CSV rows, the Parquet file
+// is read and decoded by the source. The first row returned is the header with the column names, the values are formatted as below:
+// nil as an empty string, []byte as string, time.Time in RFC3339 format, floats without an exponent (unless very large or small)
+// and the other values using fmt.Sprint().
+type ParquetReader struct {
+	source ParquetSource
+
+	// Unexported fields
+	headerRead bool
+}
+
+// NewParquetReader creates a ParquetReader for the given source.
+func NewParquetReader(source ParquetSource) *ParquetReader {
+	return &ParquetReader{source: source}
+}
+
+// Read returns the header first and then the rows of the source.
+func (p *ParquetReader) Read() ([]string, error) {
+	if !p.headerRead {
+		p.headerRead = true
+		return append([]string(nil), p.source.Columns()...), nil
+	}
+
+	values, err := p.source.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	row := make([]string, len(values))
+	for i, value := range values {
+		row[i] = formatValue(value)
+	}
+
+	return row, nil
+}
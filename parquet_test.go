@@ -0,0 +1,68 @@
+package csvprocessor_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// parquetRows is an in-memory ParquetSource.
+type parquetRows struct {
+	columns []string
+	rows    [][]any
+	err     error
+}
+
+func (p *parquetRows) Columns() []string { return p.columns }
+
+func (p *parquetRows) Next() ([]any, error) {
+	if len(p.rows) == 0 {
+		if p.err != nil {
+			return nil, p.err
+		}
+
+		return nil, io.EOF
+	}
+
+	row := p.rows[0]
+	p.rows = p.rows[1:]
+	return row, nil
+}
+
+func TestParquetReader(t *testing.T) {
+	createdAt := time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)
+	source := &parquetRows{
+		columns: []string{"id", "name", "score", "ratio", "active", "created_at", "raw", "tiny", "duration"},
+		rows: [][]any{
+			{int64(1), "alice", float64(1_000_000), float32(0.5), true, createdAt, []byte("x"), 1e-9, time.Second},
+			{int32(2), nil, 2.25, nil, false, nil, nil, 1e21, nil},
+		},
+	}
+
+	want := [][]string{
+		{"id", "name", "score", "ratio", "active", "created_at", "raw", "tiny", "duration"},
+		{"1", "alice", "1000000", "0.5", "true", "2023-04-05T06:07:08Z", "x", "1e-09", "1s"},
+		{"2", "", "2.25", "", "false", "", "", "1e+21", ""},
+	}
+
+	if got := readAll(t, csvprocessor.NewParquetReader(source)); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParquetReader rows = %q, want %q", got, want)
+	}
+}
+
+func TestParquetReader_Error(t *testing.T) {
+	errRead := errors.New("corrupt page")
+	reader := csvprocessor.NewParquetReader(&parquetRows{columns: []string{"id"}, err: errRead})
+
+	if _, err := reader.Read(); err != nil {
+		t.Fatalf("Read() header error = %v", err)
+	}
+
+	if _, err := reader.Read(); !errors.Is(err, errRead) {
+		t.Errorf("Read() error = %v, want %v", err, errRead)
+	}
+}
@@ -0,0 +1,60 @@
+package csvprocessor
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// Reader returns a stream of the transformed output, so that the processor can be used where an io.Reader is expected,
+// Eg: as a HTTP response body or the input of an uploader. Eg:
+//
+//	out := proc.Reader()
+//	defer out.Close()
+//	_, err := io.Copy(w, out)
+//
+// All the rows are written as a single chunk in the output format of the processor (Eg: WithJSONOutput()),
+// the output files (Eg: WithOutputFileFormat()) are not used. The processing starts at the first Read and waits for the
+// output to be read, an error in the processing is returned by Read. Close stops the processing if the output is not read till the end.
+func (c *Processor) Reader() io.ReadCloser {
+	pr, pw := io.Pipe()
+	return &pipeReader{c: c, pr: pr, pw: pw}
+}
+
+// pipeReader runs the processing when it is read, the output is written to the pipe.
+type pipeReader struct {
+	c         *Processor
+	pr        *io.PipeReader
+	pw        *io.PipeWriter
+	startOnce sync.Once
+}
+
+func (p *pipeReader) Read(b []byte) (int, error) {
+	p.startOnce.Do(func() {
+		go p.process()
+	})
+
+	return p.pr.Read(b)
+}
+
+func (p *pipeReader) process() {
+	err := p.c.process(context.Background(), runOutput{
+		generator: func(int) (io.WriteCloser, error) {
+			return NoOpCloser(p.pw), nil
+		},
+		singleChunk: true,
+	})
+
+	// the reader gets io.EOF if err is nil.
+	_ = p.pw.CloseWithError(err)
+}
+
+// Close stops the processing, the inputs are closed if the processing has not started.
+func (p *pipeReader) Close() error {
+	p.startOnce.Do(func() {
+		_ = p.c.closeInputs()
+	})
+
+	// the processing fails with io.ErrClosedPipe at the next write.
+	return p.pr.Close()
+}
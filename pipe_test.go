@@ -0,0 +1,106 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestProcessor_Reader(t *testing.T) {
+	failingTransformer := func(_ context.Context, row []string) []string {
+		if row[0] == "3" {
+			panic(errors.New("invalid row"))
+		}
+
+		return row
+	}
+
+	tests := []struct {
+		name    string
+		opts    []csvprocessor.Option
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "single stream",
+			opts: []csvprocessor.Option{csvprocessor.WithTransformer(csvprocessor.AddRowNoTransformer("n"))},
+			want: "n,id,name\n1,1,a\n2,2,b\n3,3,c\n",
+		},
+		{
+			name: "output format",
+			opts: []csvprocessor.Option{csvprocessor.WithOutputDelimiter(';')},
+			want: "id;name\n1;a\n2;b\n3;c\n",
+		},
+		{
+			name:    "processing error",
+			opts:    []csvprocessor.Option{csvprocessor.WithTransformer(failingTransformer)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			opts := append([]csvprocessor.Option{
+				csvprocessor.WithInputReader(strings.NewReader("id,name\n1,a\n2,b\n3,c\n")),
+				csvprocessor.WithChunkSize(1),
+				csvprocessor.WithOutputFileFormat(filepath.Join(outputDir, "out_%d.csv")),
+				csvprocessor.WithLogger(t.Logf),
+			}, tt.opts...)
+			proc, err := csvprocessor.New(opts...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			out := proc.Reader()
+			defer out.Close()
+
+			got, err := io.ReadAll(out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadAll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("ReadAll() = %q, want %q", got, tt.want)
+			}
+
+			if matches, _ := filepath.Glob(filepath.Join(outputDir, "*")); len(matches) > 0 {
+				t.Errorf("output files are written: %v", matches)
+			}
+		})
+	}
+}
+
+func TestProcessor_Reader_Close(t *testing.T) {
+	proc, err := csvprocessor.New(
+		csvprocessor.WithInputReader(strings.NewReader("id\n"+strings.Repeat("1\n", 100000))),
+		csvprocessor.WithChunkSize(10),
+		csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(io.Discard), nil
+		}),
+		csvprocessor.WithLogger(noOpLogger),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	proc.WriteBufferSize = 16
+	out := proc.Reader()
+	buf := make([]byte, 8)
+	if _, err := io.ReadFull(out, buf); err != nil {
+		t.Fatalf("ReadFull() error = %v", err)
+	}
+
+	if err := out.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if _, err := out.Read(buf); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("Read() after Close() error = %v, want %v", err, io.ErrClosedPipe)
+	}
+}
@@ -0,0 +1,75 @@
+package csvprocessor
+
+import (
+	"bufio"
+	"io"
+	"sync"
+)
+
+// The read and write buffers are reused across the chunks and the runs, as allocating them for every input and chunk
+// dominates the allocations when there are many small chunks. See DefaultReadBufferSize and DefaultWriteBufferSize.
+// The rows are not pooled, the readers of the inputs reuse the row slice for the next row (Eg: csv.Reader.ReuseRecord),
+// and the copies of the rows kept by the processor are reused with WithZeroCopy().
+var (
+	readBufferPool  sync.Pool
+	writeBufferPool sync.Pool
+)
+
+// WithZeroCopy reuses the copies of the rows kept by the processor, instead of allocating a new copy for every row.
+// The row passed to the transformers is already reused for the next row, but the processor keeps a copy of every row
+// when WithRejectWriter() or WithRowErrorHandler() is used, and passes a copy of the header to the transformers for every chunk.
+//
+// Use it only if the transformers, the row error handler and the writers do not retain the rows after they return,
+// as the rows are overwritten by the next row.
+func WithZeroCopy() Option {
+	return func(c *Processor) error {
+		c.zeroCopy = true
+		return nil
+	}
+}
+
+// copyRow returns a copy of row, the copy is written to dst in the zero copy mode, see WithZeroCopy().
+func (r *run) copyRow(dst *[]string, row []string) []string {
+	if !r.c.zeroCopy {
+		return append([]string(nil), row...)
+	}
+
+	*dst = append((*dst)[:0], row...)
+	return *dst
+}
+
+// getReadBuffer returns a buffered reader of the given size for rd from the pool.
+func getReadBuffer(rd io.Reader, size int) *bufio.Reader {
+	if buf, ok := readBufferPool.Get().(*bufio.Reader); ok && buf.Size() == size {
+		buf.Reset(rd)
+		return buf
+	}
+
+	return bufio.NewReaderSize(rd, size)
+}
+
+// putReadBuffer returns the buffered reader to the pool, it must not be used afterwards.
+func putReadBuffer(buf *bufio.Reader) {
+	buf.Reset(nil)
+	readBufferPool.Put(buf)
+}
+
+// getWriteBuffer returns a buffered writer of the given size for w from the pool.
+func getWriteBuffer(w io.Writer, size int) *bufio.Writer {
+	if buf, ok := writeBufferPool.Get().(*bufio.Writer); ok && buf.Size() == size {
+		buf.Reset(w)
+		return buf
+	}
+
+	return bufio.NewWriterSize(w, size)
+}
+
+// putWriteBuffer returns the buffered writer to the pool, the unflushed data is discarded.
+func putWriteBuffer(buf *bufio.Writer) {
+	if buf == nil {
+		return
+	}
+
+	buf.Reset(nil)
+	writeBufferPool.Put(buf)
+}
@@ -0,0 +1,83 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithZeroCopy(t *testing.T) {
+	errOdd := errors.New("odd id")
+	var input strings.Builder
+	input.WriteString("id,name\n")
+	for i := 1; i <= 1000; i++ {
+		fmt.Fprintf(&input, "%d,name%d\n", i, i)
+	}
+
+	process := func(zeroCopy bool) (string, string) {
+		var output, rejects strings.Builder
+		rejectWriter := csv.NewWriter(&rejects)
+		opts := []csvprocessor.Option{
+			csvprocessor.WithRejectWriter(rejectWriter),
+			// the transformer modifies the row in-place before failing, the rejected rows must be the original rows.
+			csvprocessor.WithTransformer(func(ctx context.Context, row []string) []string {
+				row[1] = strings.ToUpper(row[1])
+				if isHeader, _ := ctx.Value(csvprocessor.CtxIsHeader).(bool); !isHeader && (row[0][len(row[0])-1]-'0')%2 == 1 {
+					// the processor returns the panics in the transformers as errors.
+					panic(errOdd)
+				}
+
+				return row
+			}),
+		}
+		if zeroCopy {
+			opts = append(opts, csvprocessor.WithZeroCopy())
+		}
+
+		proc, err := csvprocessor.New(append(opts,
+			csvprocessor.WithReader(csv.NewReader(strings.NewReader(input.String()))),
+			csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+				return csvprocessor.NoOpCloser(&output), nil
+			}),
+			csvprocessor.WithChunkSize(100),
+			csvprocessor.WithLogger(noOpLogger),
+		)...)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		if err := proc.Process(); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		rejectWriter.Flush()
+		return output.String(), rejects.String()
+	}
+
+	wantOutput, wantRejects := process(false)
+	output, rejects := process(true)
+	if output != wantOutput {
+		t.Errorf("Process() output with zero copy differs from the output without it")
+	}
+
+	if rejects != wantRejects {
+		t.Errorf("Process() rejects with zero copy differs from the rejects without it")
+	}
+
+	if !strings.Contains(rejects, "\n1,name1,") || !strings.Contains(output, "\n2,NAME2\n") {
+		t.Errorf("Process() output = %q..., rejects = %q..., want original rejected rows", output[:30], rejects[:30])
+	}
+
+	allocs := testing.AllocsPerRun(5, func() { process(false) })
+	zeroCopyAllocs := testing.AllocsPerRun(5, func() { process(true) })
+	// zero copy saves about one allocation per row, compare relatively as -race adds allocations of its own.
+	if zeroCopyAllocs > allocs*19/20 {
+		t.Errorf("allocations with zero copy = %v, want at least 5%% less than %v", zeroCopyAllocs, allocs)
+	}
+}
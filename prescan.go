@@ -0,0 +1,230 @@
+package csvprocessor
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var (
+	// ErrInputNotRewindable is returned when WithPreScan() or WithFirstPass() is used with an input that cannot be read twice,
+	// Eg: the standard input.
+	ErrInputNotRewindable = errors.New("csvprocessor: input cannot be read twice, use seekable inputs like files")
+	// ErrInvalidChunkCount is returned when the no. of chunks of WithChunkCount() is not > 0.
+	ErrInvalidChunkCount = errors.New("csvprocessor: no. of chunks must be > 0")
+)
+
+// WithPreScan counts the rows and bytes of the inputs in a first pass before processing them, so that:
+//   - the total no. of data rows is available to the transformers in CtxTotalRows.
+//   - the progress (see WithProgressInterval()) is estimated from the rows processed instead of the bytes read.
+//   - the output file format can have a second verb for the total no. of chunks, Eg: "orders_%d_of_%d.csv".
+//
+// The first pass parses the inputs without transforming them, so it doubles the read I/O. The inputs must be seekable,
+// Eg: files opened using WithFileReaders(), ErrInputNotRewindable is returned for the standard input, streams and WithReader().
+// The total counts the rows of the inputs after the header and footer rows, WithSkipRows(), WithLimitRows() and Tail(),
+// but before any other filtering, so it is an upper bound if the rows are sampled, aggregated or dropped by the transformers.
+// The total no. of chunks is estimated from the total rows and the chunk size, see WithChunkCount() for a fixed no. of chunks.
+func WithPreScan(preScan bool) Option {
+	return func(c *Processor) error {
+		c.preScan = preScan
+		return nil
+	}
+}
+
+// WithChunkCount splits the rows into the given no. of chunks of near-equal size instead of a fixed no. of rows per chunk,
+// Eg: a chunk for each of a fixed no. of workers. The rows of the inputs are counted using WithPreScan(), so the inputs
+// must be seekable, and the chunks differ by at most one row, Eg: 10 rows in 4 chunks are written as 3, 3, 2 and 2 rows.
+// The chunk size is not used, and the no. of chunks is less than n if there are fewer rows than n.
+//
+// The rows are counted before they are transformed, so the last chunks have fewer rows (or are not written) if rows are
+// filtered out by the transformers. Eg:
+//
+//	csvprocessor.WithFileReader("orders.csv"),
+//	csvprocessor.WithChunkCount(8),
+//	csvprocessor.WithOutputFileFormat("orders_%d_of_%d.csv"), // orders_1_of_8.csv ... orders_8_of_8.csv
+func WithChunkCount(n int) Option {
+	return func(c *Processor) error {
+		if n <= 0 {
+			return ErrInvalidChunkCount
+		}
+
+		c.chunkCount = n
+		c.preScan = true
+		if c.chunkSize == 0 {
+			c.chunkSize = math.MaxInt32
+		}
+
+		return nil
+	}
+}
+
+// inputScan is the result of the pre-scan of the inputs.
+type inputScan struct {
+	rows  int   // no. of data rows.
+	bytes int64 // no. of bytes of the inputs.
+}
+
+// chunks returns the estimated no. of chunks for the rows.
+func (s *inputScan) chunks(chunkSize int) int {
+	if s == nil || chunkSize <= 0 || s.rows == 0 {
+		return 1
+	}
+
+	return (s.rows-1)/chunkSize + 1
+}
+
+// countChunks returns the no. of chunks when the rows are split into count chunks, see WithChunkCount().
+func (s *inputScan) countChunks(count int) int {
+	if s.rows < count {
+		if s.rows == 0 {
+			return 1
+		}
+
+		return s.rows
+	}
+
+	return count
+}
+
+// chunkRows returns the no. of rows of the chunk with the given ID when the rows are split into count chunks.
+// The first rows % count chunks have one more row. If there are more rows than counted, Eg: the input grew after the pre-scan,
+// the chunks after the last one have the size of the last chunk, and at least a row.
+func (s *inputScan) chunkRows(count, chunkID int) int {
+	if last := s.countChunks(count); chunkID > last {
+		chunkID = last
+	}
+
+	rows := s.rows / count
+	if chunkID <= s.rows%count {
+		rows++
+	}
+
+	if rows == 0 {
+		rows = 1
+	}
+
+	return rows
+}
+
+// scanInputs counts the rows and bytes of the inputs and rewinds them, it returns nil if WithPreScan() is not used.
+func (c *Processor) scanInputs() (*inputScan, error) {
+	if !c.preScan {
+		return nil, nil //nolint:nilnil
+	}
+
+	offsets, err := c.inputOffsets()
+	if err != nil {
+		return nil, err
+	}
+
+	scan := &inputScan{}
+	for i, input := range c.inputs {
+		rows, err := c.countRows(i, &countingReader{r: input, n: &scan.bytes})
+		if err != nil {
+			return nil, fmt.Errorf("csvprocessor: error while pre-scanning input %d: %w", i+1, err)
+		}
+
+		scan.rows += rows
+	}
+
+	if err := c.rewindInputs(offsets); err != nil {
+		return nil, err
+	}
+
+	scan.rows -= c.skipRows
+	if c.limitRows >= 0 && scan.rows > c.limitRows {
+		scan.rows = c.limitRows
+	}
+
+	if c.tailRows >= 0 && scan.rows > c.tailRows {
+		scan.rows = c.tailRows
+	}
+
+	if scan.rows < 0 {
+		scan.rows = 0
+	}
+
+	return scan, nil
+}
+
+// countRows returns the no. of data rows of the i-th input, excluding the header and footer rows.
+// The input is parsed like in the processing, so that the dialect and the header detected from the first input are used.
+func (c *Processor) countRows(i int, input io.Reader) (int, error) {
+	reader, err := c.parseInput(i, input)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return 0, err
+		}
+
+		if row != nil {
+			rows++
+		}
+	}
+
+	excluded := c.footerRows
+	if c.inputHasHeader() {
+		excluded++
+		if c.headerRows > 1 {
+			excluded += c.headerRows - 1
+		}
+	}
+
+	if rows < excluded {
+		return 0, nil
+	}
+
+	return rows - excluded, nil
+}
+
+// inputOffsets returns the offsets of the inputs, so that they can be read again after rewindInputs().
+func (c *Processor) inputOffsets() ([]int64, error) {
+	if c.reader != nil {
+		return nil, fmt.Errorf("%w: the CsvReader set using WithReader()", ErrInputNotRewindable)
+	}
+
+	offsets := make([]int64, len(c.inputs))
+	for i, input := range c.inputs {
+		seeker, ok := input.(io.Seeker)
+		if !ok {
+			return nil, fmt.Errorf("%w: input %d is not seekable", ErrInputNotRewindable, i+1)
+		}
+
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, fmt.Errorf("%w: input %d: %v", ErrInputNotRewindable, i+1, err)
+		}
+
+		offsets[i] = offset
+	}
+
+	return offsets, nil
+}
+
+// rewindInputs seeks the inputs to the offsets returned by inputOffsets(), and releases the buffers used to read them.
+func (c *Processor) rewindInputs(offsets []int64) error {
+	for _, buf := range c.inputBuffers {
+		putReadBuffer(buf)
+	}
+
+	c.inputBuffers = nil
+	for i, input := range c.inputs {
+		if _, err := input.(io.Seeker).Seek(offsets[i], io.SeekStart); err != nil {
+			return fmt.Errorf("csvprocessor: error while rewinding input %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
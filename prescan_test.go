@@ -0,0 +1,287 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithPreScan(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []csvprocessor.Option
+		want  int
+	}{
+		{name: "Test rows", input: "id\n1\n2\n3\n4\n5\n", want: 5},
+		{name: "Test no header", input: "1\n2\n3\n", opts: []csvprocessor.Option{csvprocessor.SkipHeaders(true)}, want: 3},
+		{name: "Test header rows", input: "id\nunit\n1\n2\n", opts: []csvprocessor.Option{csvprocessor.WithHeaderRows(2)}, want: 2},
+		{name: "Test skip and limit", input: "id\n1\n2\n3\n4\n5\n", opts: []csvprocessor.Option{csvprocessor.WithSkipRows(1), csvprocessor.WithLimitRows(3)}, want: 3},
+		{name: "Test quoted new lines", input: "id,note\n1,\"a\nb\"\n2,c\n", want: 2},
+		{name: "Test empty", input: "id\n", want: 0},
+		{name: "Test auto dialect without header", input: "1;2\n3;4\n5;6\n", opts: []csvprocessor.Option{csvprocessor.WithAutoDialect()}, want: 3},
+		{name: "Test header detection without header", input: "1\n2\n3\n4\n", opts: []csvprocessor.Option{csvprocessor.WithHeaderDetection()}, want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var totals []int
+			recordTotal := func(ctx context.Context, row []string) []string {
+				total, _ := ctx.Value(csvprocessor.CtxTotalRows).(int)
+				totals = append(totals, total)
+				return row
+			}
+
+			opts := append(tt.opts, csvprocessor.WithPreScan(true), csvprocessor.WithTransformer(recordTotal))
+			output, err := processFile(t, tt.input, opts...)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			// the inputs are rewound after the pre-scan, so all the rows are processed.
+			rows := 0
+			for _, total := range totals {
+				if total != tt.want {
+					t.Errorf("CtxTotalRows = %d, want %d", total, tt.want)
+				}
+
+				rows++
+			}
+
+			if output == "" || rows == 0 {
+				t.Errorf("Process() output = %q with %d transformed rows", output, rows)
+			}
+		})
+	}
+}
+
+func TestWithPreScan_ChunkNames(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.csv")
+	writeTestFile(t, input, "id\n1\n2\n3\n4\n5\n")
+
+	proc, err := csvprocessor.New(
+		csvprocessor.WithFileReader(input),
+		csvprocessor.WithOutputFileFormat(filepath.Join(dir, "part_%d_of_%d.csv")),
+		csvprocessor.WithChunkSize(2),
+		csvprocessor.WithPreScan(true),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	want := map[string]string{"part_1_of_3.csv": "id\n1\n2\n", "part_2_of_3.csv": "id\n3\n4\n", "part_3_of_3.csv": "id\n5\n"}
+	for name, want := range want {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestWithPreScan_Progress(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "input.csv")
+	writeTestFile(t, input, "id\n"+strings.Repeat("1\n", 20))
+
+	logger := &testLeveledLogger{}
+	proc, err := csvprocessor.New(
+		csvprocessor.WithFileReader(input),
+		csvprocessor.WithChunkSize(100),
+		csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(io.Discard), nil
+		}),
+		csvprocessor.WithTransformer(func(_ context.Context, row []string) []string {
+			time.Sleep(2 * time.Millisecond)
+			return row
+		}),
+		csvprocessor.WithProgressInterval(5*time.Millisecond),
+		csvprocessor.WithLeveledLogger(logger),
+		csvprocessor.WithPreScan(true),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	for _, entry := range logger.entries {
+		if entry.msg != "csvprocessor: progress" {
+			continue
+		}
+
+		keyvals := map[any]any{}
+		for i := 0; i+1 < len(entry.keyvals); i += 2 {
+			keyvals[entry.keyvals[i]] = entry.keyvals[i+1]
+		}
+
+		// the input is read in a single buffered read, but the percentage is estimated from the rows processed.
+		rows, _ := keyvals["rows"].(int)
+		if percent, ok := keyvals["percent"].(float64); ok && percent != float64(rows*100/20) {
+			t.Errorf("progress log percent = %v after %d of 20 rows", percent, rows)
+		}
+
+		return
+	}
+
+	t.Fatalf("no progress logs in %v", logger.entries)
+}
+
+func TestWithPreScan_NotSupported(t *testing.T) {
+	if _, err := processString(t, "id\n1\n", csvprocessor.WithPreScan(true)); !errors.Is(err, csvprocessor.ErrInputNotRewindable) {
+		t.Errorf("Process() error = %v, want %v", err, csvprocessor.ErrInputNotRewindable)
+	}
+
+	stream := io.MultiReader(strings.NewReader("id\n1\n"))
+	if _, err := processString(t, "", csvprocessor.WithInputReader(stream), csvprocessor.WithPreScan(true)); !errors.Is(err, csvprocessor.ErrInputNotRewindable) {
+		t.Errorf("Process() error = %v, want %v", err, csvprocessor.ErrInputNotRewindable)
+	}
+}
+
+func TestWithChunkCount(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		count int
+		want  map[string]string
+	}{
+		{
+			name:  "Test near-equal chunks",
+			input: "id\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			count: 4,
+			want: map[string]string{
+				"part_1_of_4.csv": "id\n1\n2\n3\n",
+				"part_2_of_4.csv": "id\n4\n5\n6\n",
+				"part_3_of_4.csv": "id\n7\n8\n",
+				"part_4_of_4.csv": "id\n9\n10\n",
+			},
+		},
+		{
+			name:  "Test fewer rows than chunks",
+			input: "id\n1\n2\n",
+			count: 3,
+			want:  map[string]string{"part_1_of_2.csv": "id\n1\n", "part_2_of_2.csv": "id\n2\n"},
+		},
+		{
+			name:  "Test no rows",
+			input: "id\n",
+			count: 3,
+			want:  map[string]string{"part_1_of_1.csv": "id\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			input := filepath.Join(dir, "input.csv")
+			writeTestFile(t, input, tt.input)
+
+			proc, err := csvprocessor.New(
+				csvprocessor.WithFileReader(input),
+				csvprocessor.WithOutputFileFormat(filepath.Join(dir, "part_%d_of_%d.csv")),
+				csvprocessor.WithChunkSize(2),
+				csvprocessor.WithChunkCount(tt.count),
+			)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if err := proc.Process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if chunks := len(proc.Stats().Chunks); chunks != len(tt.want) {
+				t.Errorf("Stats().Chunks has %d chunks, want %d", chunks, len(tt.want))
+			}
+
+			for name, want := range tt.want {
+				got, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(got) != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("Test more rows than counted", func(t *testing.T) {
+		dir := t.TempDir()
+		input := &growingInput{inputs: []string{"id\n", "id\n1\n2\n"}}
+		proc, err := csvprocessor.New(
+			csvprocessor.WithInputReader(input),
+			csvprocessor.WithOutputFileFormat(filepath.Join(dir, "part_%d.csv")),
+			csvprocessor.WithChunkCount(2),
+		)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		if err := proc.Process(); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		for name, want := range map[string]string{"part_1.csv": "id\n1\n", "part_2.csv": "id\n2\n"} {
+			got, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != want {
+				t.Errorf("%s = %q, want %q", name, got, want)
+			}
+		}
+	})
+
+	if _, err := csvprocessor.New(csvprocessor.WithChunkCount(0)); !errors.Is(err, csvprocessor.ErrInvalidChunkCount) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidChunkCount)
+	}
+
+	if _, err := processString(t, "id\n1\n", csvprocessor.WithChunkCount(2)); !errors.Is(err, csvprocessor.ErrInputNotRewindable) {
+		t.Errorf("Process() error = %v, want %v", err, csvprocessor.ErrInputNotRewindable)
+	}
+}
+
+// growingInput is a seekable input that returns the next of its inputs each time it is rewound.
+type growingInput struct {
+	inputs []string
+	reader *strings.Reader
+}
+
+func (g *growingInput) Read(b []byte) (int, error) {
+	if g.reader == nil {
+		g.reader = strings.NewReader(g.inputs[0])
+	}
+
+	return g.reader.Read(b)
+}
+
+func (g *growingInput) Seek(offset int64, whence int) (int64, error) {
+	if g.reader == nil {
+		g.reader = strings.NewReader(g.inputs[0])
+	}
+
+	if whence == io.SeekStart && offset == 0 && len(g.inputs) > 1 {
+		g.inputs = g.inputs[1:]
+		g.reader = strings.NewReader(g.inputs[0])
+		return 0, nil
+	}
+
+	return g.reader.Seek(offset, whence)
+}
@@ -0,0 +1,210 @@
+package csvprocessor
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Profile is the breakdown of the time spent in each phase of a Process() run, see WithProfile().
+type Profile struct {
+	// Read is the time spent in reading the input rows, including the readers like WithSortBy() that consume the input.
+	Read time.Duration
+	// Transform is the time spent in the transformer, including the header rows.
+	Transform time.Duration
+	// Write is the time spent in creating the chunks, writing the rows to them, and flushing and closing them.
+	Write time.Duration
+	// Transformers contains the time spent in each transformer of ChainTransformers() and ChainInPlaceTransformers(),
+	// in the order they were first run.
+	Transformers []TransformerProfile
+}
+
+// TransformerProfile is the time spent in a transformer of a chain, see Profile.
+type TransformerProfile struct {
+	// Index is the position of the transformer in the chain, Eg: "2",
+	// or "2.0" for the first transformer of a chain nested at position 2 of the outer chain.
+	Index string
+	// Func is the name of the transformer function, the closures are named after the function returning them,
+	// Eg: "github.com/sivaramasubramanian/csvprocessor.AddRowNoTransformer.func1".
+	Func string
+	// Rows is the no. of rows, including the header rows, passed to the transformer.
+	Rows int
+	// Duration is the total time spent in the transformer, it includes the time of the nested transformers.
+	Duration time.Duration
+}
+
+// WithProfile records the time spent in reading, transforming and writing the rows, and in each transformer of a chain.
+// The breakdown is available in Stats.Profile, and it is logged at LogInfo at the end of the run.
+// Profiling adds the overhead of reading the clock a few times for every row.
+func WithProfile(enabled bool) Option {
+	return func(c *Processor) error {
+		c.profile = enabled
+		return nil
+	}
+}
+
+// profile phases.
+const (
+	phaseRead = iota
+	phaseTransform
+	phaseWrite
+)
+
+// profiler records the profile of a run.
+// The methods can be called on a nil *profiler, when WithProfile() is not used.
+type profiler struct {
+	profile Profile
+	path    []int          // position of the running transformer in the nested chains.
+	index   map[string]int // index of each transformer in profile.Transformers.
+}
+
+// newProfiler returns the profiler of the run, or nil if WithProfile() is not used.
+func (c *Processor) newProfiler() *profiler {
+	if !c.profile {
+		return nil
+	}
+
+	return &profiler{index: make(map[string]int)}
+}
+
+// start returns the start time of a phase.
+func (p *profiler) start() time.Time {
+	if p == nil {
+		return time.Time{}
+	}
+
+	return time.Now()
+}
+
+// end adds the time since start to the phase.
+func (p *profiler) end(phase int, start time.Time) {
+	if p == nil {
+		return
+	}
+
+	elapsed := time.Since(start)
+	switch phase {
+	case phaseRead:
+		p.profile.Read += elapsed
+	case phaseTransform:
+		p.profile.Transform += elapsed
+	case phaseWrite:
+		p.profile.Write += elapsed
+	}
+}
+
+// timeChain runs the n transformers of a chain, timing each of them for the profiler and the MetricsWrapper(), either can be nil.
+// transformer(i) returns the i-th transformer, and run(ctx, i) runs it with ctx and returns false if the row is dropped.
+func timeChain(ctx context.Context, p *profiler, m *metricsCtx, n int, transformer func(i int) any, run func(ctx context.Context, i int) bool) bool {
+	for i := 0; i < n; i++ {
+		memberCtx := ctx
+		var member *metricsCtx
+		if m != nil {
+			// every transformer gets its own context with its position, so that the nested chains record their own positions.
+			member = m.member(i)
+			memberCtx = member
+		}
+
+		if p != nil {
+			p.path = append(p.path, i)
+		}
+
+		start := time.Now()
+		kept := run(memberCtx, i)
+		elapsed := time.Since(start)
+		if p != nil {
+			p.record(transformer(i), elapsed)
+			p.path = p.path[:len(p.path)-1]
+		}
+
+		if member != nil {
+			member.record(transformer(i), elapsed, !kept)
+		}
+
+		if !kept {
+			return false
+		}
+	}
+
+	return true
+}
+
+// record adds the time spent in the running transformer.
+func (p *profiler) record(transformer any, elapsed time.Duration) {
+	index := chainIndex(p.path)
+	i, ok := p.index[index]
+	if !ok {
+		i = len(p.profile.Transformers)
+		p.index[index] = i
+		p.profile.Transformers = append(p.profile.Transformers, TransformerProfile{Index: index, Func: funcName(transformer)})
+	}
+
+	p.profile.Transformers[i].Rows++
+	p.profile.Transformers[i].Duration += elapsed
+}
+
+// chainIndex returns the index of the transformer at the position in the nested chains, see TransformerProfile.Index.
+func chainIndex(path []int) string {
+	parts := make([]string, len(path))
+	for i, pos := range path {
+		parts[i] = strconv.Itoa(pos)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// panicked resets the position in the chains after a transformer panics, as the chains did not return.
+func (p *profiler) panicked() {
+	if p != nil {
+		p.path = p.path[:0]
+	}
+}
+
+// result returns the profile of the run, or nil if WithProfile() is not used.
+func (p *profiler) result() *Profile {
+	if p == nil {
+		return nil
+	}
+
+	profile := p.profile
+	return &profile
+}
+
+// profilerOf returns the profiler of the run from the context passed to the transformers, if any.
+func profilerOf(ctx context.Context) *profiler {
+	for {
+		switch c := ctx.(type) {
+		case *csvCtx:
+			return c.profiler
+		case *metricsCtx:
+			// the context of MetricsWrapper() wraps the context of the run.
+			ctx = c.unwrap()
+		default:
+			return nil
+		}
+	}
+}
+
+// logProfile logs the profile of the run.
+func (c *Processor) logProfile(profile *Profile) {
+	if profile == nil {
+		return
+	}
+
+	c.log.Log(LogInfo, "csvprocessor: profile", "read", profile.Read, "transform", profile.Transform, "write", profile.Write)
+	for _, transformer := range profile.Transformers {
+		c.log.Log(LogInfo, "csvprocessor: profile transformer",
+			"index", transformer.Index, "func", transformer.Func, "rows", transformer.Rows, "duration", transformer.Duration)
+	}
+}
+
+func funcName(f any) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer()); fn != nil {
+		return fn.Name()
+	}
+
+	return ""
+}
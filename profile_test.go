@@ -0,0 +1,89 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func slowTransformer(_ context.Context, row []string) []string {
+	time.Sleep(5 * time.Millisecond)
+	return row
+}
+
+func TestWithProfile(t *testing.T) {
+	dropSecond := func(ctx context.Context, row []string) []string {
+		if rowNum, _ := ctx.Value(csvprocessor.CtxRowNum).(int); rowNum == 2 {
+			return nil
+		}
+
+		return row
+	}
+
+	newProcessor := func(opts ...csvprocessor.Option) *csvprocessor.Processor {
+		proc, err := csvprocessor.New(append([]csvprocessor.Option{
+			csvprocessor.WithInputReader(strings.NewReader("id\n1\n2\n3\n")),
+			csvprocessor.WithChunkSize(10),
+			csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+				return csvprocessor.NoOpCloser(io.Discard), nil
+			}),
+			csvprocessor.WithTransformer(csvprocessor.ChainTransformers(
+				dropSecond,
+				csvprocessor.ChainTransformers(csvprocessor.NoOpTransformer(), slowTransformer),
+			)),
+			csvprocessor.WithLogger(t.Logf),
+		}, opts...)...)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		if err := proc.Process(); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		return proc
+	}
+
+	if profile := newProcessor().Stats().Profile; profile != nil {
+		t.Errorf("Stats().Profile = %v, want nil without WithProfile()", profile)
+	}
+
+	profile := newProcessor(csvprocessor.WithProfile(true)).Stats().Profile
+	if profile == nil {
+		t.Fatalf("Stats().Profile = nil, want profile")
+	}
+
+	// the header and 2 of the 3 rows are passed to the nested chain.
+	want := []struct {
+		index string
+		fn    string
+		rows  int
+	}{
+		{index: "0", fn: "csvprocessor_test.TestWithProfile.", rows: 4},
+		{index: "1.0", fn: "csvprocessor.NoOpTransformer.", rows: 3},
+		{index: "1.1", fn: "csvprocessor_test.slowTransformer", rows: 3},
+		{index: "1", fn: "csvprocessor.ChainTransformers.", rows: 3},
+	}
+	if len(profile.Transformers) != len(want) {
+		t.Fatalf("Profile.Transformers = %+v, want %d transformers", profile.Transformers, len(want))
+	}
+
+	for i, transformer := range profile.Transformers {
+		if transformer.Index != want[i].index || !strings.Contains(transformer.Func, want[i].fn) || transformer.Rows != want[i].rows {
+			t.Errorf("Profile.Transformers[%d] = %+v, want index %s, func %s, rows %d", i, transformer, want[i].index, want[i].fn, want[i].rows)
+		}
+	}
+
+	slow := 3 * 5 * time.Millisecond
+	if profile.Transformers[2].Duration < slow || profile.Transformers[3].Duration < slow || profile.Transform < slow {
+		t.Errorf("Profile = %+v, want at least %v in the slow transformer and its chain", profile, slow)
+	}
+
+	if profile.Read <= 0 || profile.Write <= 0 {
+		t.Errorf("Profile.Read = %v, Profile.Write = %v, want > 0", profile.Read, profile.Write)
+	}
+}
@@ -0,0 +1,150 @@
+package csvprocessor
+
+import (
+	"errors"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+// ErrInvalidProgressInterval is returned when the progress interval is not > 0.
+var ErrInvalidProgressInterval = errors.New("csvprocessor: progress interval must be > 0")
+
+// WithProgressInterval logs the progress of the processing at the given interval, at LogInfo level. Eg:
+//
+//	csvprocessor: progress rows=1200000 rows_per_sec=40000 mb_read=96 mb_per_sec=3.2 percent=45.1 eta=36s
+//
+// The MB read and the rate are logged if the input is read using the processor (Eg: WithFileReader()) instead of a CsvReader,
+// and the percentage and the ETA are estimated from the bytes read if the total size of the input is known (Eg: for files),
+// or from the rows processed if the rows are counted using WithPreScan().
+func WithProgressInterval(interval time.Duration) Option {
+	return func(c *Processor) error {
+		if interval <= 0 {
+			return ErrInvalidProgressInterval
+		}
+
+		c.progressInterval = interval
+		return nil
+	}
+}
+
+// progress logs the progress of a run.
+type progress struct {
+	c          *Processor
+	ticker     *time.Ticker
+	start      time.Time
+	bytesRead  int64
+	totalBytes int64 // 0 if unknown.
+	totalRows  int   // 0 if unknown, see WithPreScan().
+}
+
+// newProgress returns the progress of the run, or nil if WithProgressInterval() is not used.
+func (c *Processor) newProgress(scan *inputScan) *progress {
+	if c.progressInterval <= 0 {
+		return nil
+	}
+
+	p := &progress{c: c, ticker: time.NewTicker(c.progressInterval), start: time.Now(), totalBytes: c.inputSize()}
+	if scan != nil {
+		p.totalRows = scan.rows
+		p.totalBytes = scan.bytes
+	}
+
+	return p
+}
+
+// counter returns the counter of the bytes read from the inputs, or nil if the progress is not logged.
+func (p *progress) counter() *int64 {
+	if p == nil {
+		return nil
+	}
+
+	return &p.bytesRead
+}
+
+// check logs the progress if the interval has elapsed.
+func (p *progress) check(rows int) {
+	if p == nil {
+		return
+	}
+
+	select {
+	case <-p.ticker.C:
+		p.log(rows)
+	default:
+	}
+}
+
+func (p *progress) log(rows int) {
+	elapsed := time.Since(p.start).Seconds()
+	keyvals := []any{"rows", rows, "rows_per_sec", round(float64(rows) / elapsed)}
+	if p.bytesRead > 0 {
+		mb := float64(p.bytesRead) / 1e6
+		keyvals = append(keyvals, "mb_read", round(mb), "mb_per_sec", round(mb/elapsed))
+	}
+
+	var done float64
+	switch {
+	case p.totalRows > 0:
+		done = math.Min(float64(rows)/float64(p.totalRows), 1)
+	case p.bytesRead > 0 && p.totalBytes > 0:
+		done = math.Min(float64(p.bytesRead)/float64(p.totalBytes), 1)
+	}
+
+	if done > 0 {
+		eta := time.Duration(elapsed * (1 - done) / done * float64(time.Second))
+		keyvals = append(keyvals, "percent", round(done*100), "eta", eta.Round(time.Second))
+	}
+
+	p.c.log.Log(LogInfo, "csvprocessor: progress", keyvals...)
+}
+
+func (p *progress) stop() {
+	if p != nil {
+		p.ticker.Stop()
+	}
+}
+
+// round rounds to 1 decimal place.
+func round(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// inputSize returns the total size of the inputs in bytes, or 0 if it is not known.
+func (c *Processor) inputSize() int64 {
+	if c.reader != nil {
+		return 0
+	}
+
+	var total int64
+	for _, input := range c.inputs {
+		switch input := input.(type) {
+		case *os.File:
+			info, err := input.Stat()
+			if err != nil || !info.Mode().IsRegular() {
+				return 0
+			}
+
+			total += info.Size()
+		case interface{ Size() int64 }:
+			total += input.Size()
+		default:
+			return 0
+		}
+	}
+
+	return total
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	*cr.n += int64(n)
+	return n, err
+}
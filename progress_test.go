@@ -0,0 +1,74 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithProgressInterval(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "input.csv")
+	writeTestFile(t, input, "id\n"+strings.Repeat("1\n", 20))
+
+	logger := &testLeveledLogger{}
+	proc, err := csvprocessor.New(
+		csvprocessor.WithFileReader(input),
+		csvprocessor.WithChunkSize(100),
+		csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(io.Discard), nil
+		}),
+		csvprocessor.WithTransformer(func(_ context.Context, row []string) []string {
+			time.Sleep(2 * time.Millisecond)
+			return row
+		}),
+		csvprocessor.WithProgressInterval(5*time.Millisecond),
+		csvprocessor.WithLeveledLogger(logger),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	var progress []logEntry
+	for _, entry := range logger.entries {
+		if entry.msg == "csvprocessor: progress" {
+			progress = append(progress, entry)
+		}
+	}
+
+	if len(progress) == 0 {
+		t.Fatalf("no progress logs in %v", logger.entries)
+	}
+
+	entry := progress[0]
+	if entry.level != csvprocessor.LogInfo {
+		t.Errorf("progress log level = %v, want %v", entry.level, csvprocessor.LogInfo)
+	}
+
+	var keys []string
+	for i := 0; i < len(entry.keyvals); i += 2 {
+		keys = append(keys, entry.keyvals[i].(string))
+	}
+
+	// the input is read in a single buffered read, so all the bytes are read at the first log.
+	if want := "rows rows_per_sec mb_read mb_per_sec percent eta"; strings.Join(keys, " ") != want {
+		t.Errorf("progress log keys = %q, want %q", strings.Join(keys, " "), want)
+	}
+
+	if percent := entry.keyvals[9]; percent != float64(100) {
+		t.Errorf("progress log percent = %v, want 100", percent)
+	}
+
+	if _, err := csvprocessor.New(csvprocessor.WithProgressInterval(0)); !errors.Is(err, csvprocessor.ErrInvalidProgressInterval) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidProgressInterval)
+	}
+}
@@ -0,0 +1,212 @@
+package csvprocessor
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidSecret is returned when the secret of a Pseudonymizer is shorter than MinSecretLength.
+	ErrInvalidSecret = fmt.Errorf("csvprocessor: secret must have at least %d bytes", MinSecretLength)
+	// ErrInvalidPseudonymStrategy is returned for an unknown PseudonymStrategy.
+	ErrInvalidPseudonymStrategy = errors.New("csvprocessor: invalid pseudonym strategy")
+)
+
+// MinSecretLength is the min. no. of bytes of the secret of a Pseudonymizer.
+const MinSecretLength = 16
+
+// PseudonymStrategy represents the kind of pseudonym that replaces a value, see Pseudonymizer.
+type PseudonymStrategy int
+
+const (
+	// PseudonymToken replaces the value with 16 hex characters (64 bits), Eg: "9f86d081884c7d65".
+	PseudonymToken PseudonymStrategy = iota
+	// PseudonymName replaces the value with a fake full name, Eg: "Maya K. Fischer".
+	// There are about 100,000 names, so different values can get the same name, do not use it for the key columns.
+	PseudonymName
+	// PseudonymEmail replaces the value with a fake email address at example.com, with 16 hex characters (64 bits)
+	// after the name, Eg: "maya.fischer.3e1f0a9c52d7b481@example.com".
+	PseudonymEmail
+	// PseudonymNumericID replaces the value with a number of the same no. of digits, with at least 18 digits (about 60 bits),
+	// so that it still fits in a 64-bit integer. Eg: "482019337561904217".
+	PseudonymNumericID
+)
+
+// minNumericIDDigits is the min. no. of digits of a PseudonymNumericID.
+const minNumericIDDigits = 18
+
+// String returns the name of the strategy, Eg: "numeric_id".
+func (s PseudonymStrategy) String() string {
+	switch s {
+	case PseudonymToken:
+		return "token"
+	case PseudonymName:
+		return "name"
+	case PseudonymEmail:
+		return "email"
+	case PseudonymNumericID:
+		return "numeric_id"
+	}
+
+	return "PseudonymStrategy(" + strconv.Itoa(int(s)) + ")"
+}
+
+// ParsePseudonymStrategy returns the strategy with the name returned by String().
+func ParsePseudonymStrategy(name string) (PseudonymStrategy, error) {
+	for strategy := PseudonymToken; strategy <= PseudonymNumericID; strategy++ {
+		if strategy.String() == name {
+			return strategy, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %q, must be one of token, name, email or numeric_id", ErrInvalidPseudonymStrategy, name)
+}
+
+// Pseudonymizer replaces values with deterministic pseudonyms, the same value is replaced by the same pseudonym
+// in every file and run that uses the same secret, so that the de-identified files can still be joined. Eg:
+//
+//	pseudonymizer, err := csvprocessor.NewPseudonymizer([]byte(os.Getenv("PSEUDONYM_SECRET")))
+//	pseudonymizer.Pseudonym(csvprocessor.PseudonymEmail, "bob@example.org") // "maya.fischer.3e1f0a9c52d7b481@example.com"
+//
+// The pseudonyms are derived from the HMAC-SHA256 of the value keyed by the secret, so they cannot be reversed
+// or recomputed without the secret. The same value gets the same pseudonym in every column with the same strategy.
+//
+// The pseudonyms are truncated hashes, so different values can get the same pseudonym. With n distinct values,
+// the probability of a collision is about n²/2^(bits+1): for 10 million values, about 3 in 1,000,000 with the 64 bits of
+// PseudonymToken and PseudonymEmail, and about 6 in 100,000 with the 60 bits of an 18-digit PseudonymNumericID.
+// PseudonymName has no such bound, see PseudonymName.
+// Check the pseudonyms of the key columns for duplicates if a collision cannot be tolerated.
+// A Pseudonymizer is safe for concurrent use.
+type Pseudonymizer struct {
+	secret []byte
+}
+
+// NewPseudonymizer creates a Pseudonymizer keyed by the secret, it must have at least MinSecretLength bytes.
+func NewPseudonymizer(secret []byte) (*Pseudonymizer, error) {
+	if len(secret) < MinSecretLength {
+		return nil, ErrInvalidSecret
+	}
+
+	return &Pseudonymizer{secret: append([]byte(nil), secret...)}, nil
+}
+
+// Pseudonym returns the pseudonym of the value, empty values are returned as they are.
+// The names and email addresses are compared without case and surrounding spaces, Eg: " Bob@Example.org"
+// and "bob@example.org" get the same pseudonym.
+func (p *Pseudonymizer) Pseudonym(strategy PseudonymStrategy, value string) string {
+	if value == "" {
+		return value
+	}
+
+	if strategy == PseudonymName || strategy == PseudonymEmail {
+		value = strings.ToLower(strings.TrimSpace(value))
+	}
+
+	digest := p.digest(strategy, value, 0)
+	switch strategy {
+	case PseudonymName:
+		return fakeName(digest)
+	case PseudonymEmail:
+		name := fakeName(digest)
+		first, last := name[:strings.IndexByte(name, ' ')], name[strings.LastIndexByte(name, ' ')+1:]
+		return strings.ToLower(first+"."+last) + "." + hex.EncodeToString(digest[8:16]) + "@example.com"
+	case PseudonymNumericID:
+		return p.numericID(strategy, value, digest)
+	case PseudonymToken:
+	}
+
+	return hex.EncodeToString(digest[:8])
+}
+
+// digest returns the HMAC of the value, counter is used to derive more bytes from the same value.
+func (p *Pseudonymizer) digest(strategy PseudonymStrategy, value string, counter uint32) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	var prefix [5]byte
+	// the strategy is part of the message, so the pseudonyms of different strategies are independent.
+	prefix[0] = byte(strategy)
+	binary.BigEndian.PutUint32(prefix[1:], counter)
+	_, _ = mac.Write(prefix[:])     //nolint:errcheck
+	_, _ = mac.Write([]byte(value)) //nolint:errcheck
+	return mac.Sum(nil)
+}
+
+// numericID returns the digits derived from the digest, without a leading zero.
+func (p *Pseudonymizer) numericID(strategy PseudonymStrategy, value string, digest []byte) string {
+	digits := len(value)
+	if digits < minNumericIDDigits {
+		digits = minNumericIDDigits
+	}
+
+	id := make([]byte, 0, digits)
+	for counter := uint32(1); len(id) < digits; counter++ {
+		for _, b := range digest {
+			// the bytes >= 250 are skipped, so that the digits are uniformly distributed.
+			if b >= 250 || (len(id) == 0 && b%10 == 0) {
+				continue
+			}
+
+			id = append(id, '0'+b%10)
+			if len(id) == digits {
+				break
+			}
+		}
+
+		digest = p.digest(strategy, value, counter)
+	}
+
+	return string(id)
+}
+
+func fakeName(digest []byte) string {
+	first := firstNames[int(digest[0])%len(firstNames)]
+	initial := 'A' + rune(digest[1]%26)
+	last := lastNames[int(binary.BigEndian.Uint16(digest[2:4]))%len(lastNames)]
+	return first + " " + string(initial) + ". " + last
+}
+
+// PseudonymizeTransformer replaces the values of the given columns by their pseudonyms, see Pseudonymizer.
+// columns maps the 0-based index of a column to the strategy of its pseudonyms, the header row is not modified. Eg:
+//
+//	csvprocessor.PseudonymizeTransformer(pseudonymizer, map[int]csvprocessor.PseudonymStrategy{0: csvprocessor.PseudonymNumericID, 2: csvprocessor.PseudonymEmail})
+func PseudonymizeTransformer(pseudonymizer *Pseudonymizer, columns map[int]PseudonymStrategy) CsvRowTransformer {
+	return func(ctx context.Context, row []string) []string {
+		isHeader, isBool := (ctx.Value(CtxIsHeader)).(bool)
+		if isBool && isHeader {
+			return row
+		}
+
+		for column, strategy := range columns {
+			if column >= 0 && column < len(row) {
+				row[column] = pseudonymizer.Pseudonym(strategy, row[column])
+			}
+		}
+
+		return row
+	}
+}
+
+var firstNames = []string{
+	"Aaron", "Abigail", "Adam", "Aisha", "Alan", "Alice", "Amir", "Ana", "Andre", "Anna", "Arjun", "Ava",
+	"Ben", "Bianca", "Carlos", "Chen", "Chloe", "Daniel", "Diana", "Elena", "Eli", "Emma", "Ethan", "Fatima",
+	"Felix", "Grace", "Hana", "Hugo", "Ines", "Isaac", "Ivy", "Jack", "Jade", "Javier", "Julia", "Kai",
+	"Karin", "Kenji", "Laila", "Leo", "Lina", "Lucas", "Maya", "Mateo", "Mei", "Nadia", "Nina", "Noah",
+	"Olga", "Omar", "Priya", "Quinn", "Rafael", "Rosa", "Sam", "Sara", "Tariq", "Tessa", "Theo", "Uma",
+	"Victor", "Wen", "Yara", "Zoe",
+}
+
+var lastNames = []string{
+	"Abbott", "Adeyemi", "Alvarez", "Andersen", "Bauer", "Becker", "Bianchi", "Brooks", "Castro", "Chang",
+	"Costa", "Dubois", "Duarte", "Ellis", "Eriksson", "Fischer", "Fontaine", "Garcia", "Greene", "Gupta",
+	"Hansen", "Hayes", "Ibrahim", "Ito", "Jansen", "Jensen", "Kaur", "Khan", "Kim", "Kowalski",
+	"Larsen", "Lopez", "Meyer", "Moreau", "Moreno", "Mwangi", "Nakamura", "Nguyen", "Novak", "Okafor",
+	"Olsen", "Park", "Patel", "Perez", "Popescu", "Quinn", "Rao", "Reyes", "Rossi", "Santos",
+	"Schmidt", "Silva", "Singh", "Sato", "Tanaka", "Torres", "Umar", "Vargas", "Wagner", "Walsh",
+	"Weber", "Yilmaz", "Young", "Zhang",
+}
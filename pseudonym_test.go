@@ -0,0 +1,141 @@
+package csvprocessor_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func TestPseudonymizer_Pseudonym(t *testing.T) {
+	pseudonymizer, err := csvprocessor.NewPseudonymizer([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("NewPseudonymizer() error = %v", err)
+	}
+
+	other, err := csvprocessor.NewPseudonymizer([]byte("another secret of 32 bytes......"))
+	if err != nil {
+		t.Fatalf("NewPseudonymizer() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		strategy csvprocessor.PseudonymStrategy
+		value    string
+		same     string // a value with the same pseudonym.
+		pattern  string
+	}{
+		{name: "Test token", strategy: csvprocessor.PseudonymToken, value: "c-42", pattern: `^[0-9a-f]{16}$`},
+		{name: "Test name", strategy: csvprocessor.PseudonymName, value: "Bob Smith", same: " bob smith ", pattern: `^[A-Z][a-z]+ [A-Z]\. [A-Z][a-z]+$`},
+		{name: "Test email", strategy: csvprocessor.PseudonymEmail, value: "Bob@Example.org", same: "bob@example.org", pattern: `^[a-z]+\.[a-z]+\.[0-9a-f]{16}@example\.com$`},
+		{name: "Test short numeric ID", strategy: csvprocessor.PseudonymNumericID, value: "42", pattern: `^[1-9][0-9]{17}$`},
+		{name: "Test long numeric ID", strategy: csvprocessor.PseudonymNumericID, value: "12345678901234567890123456789012345678901234567890", pattern: `^[1-9][0-9]{49}$`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pseudonymizer.Pseudonym(tt.strategy, tt.value)
+			if !regexp.MustCompile(tt.pattern).MatchString(got) {
+				t.Errorf("Pseudonym() = %q, want a match for %q", got, tt.pattern)
+			}
+
+			if again := pseudonymizer.Pseudonym(tt.strategy, tt.value); again != got {
+				t.Errorf("Pseudonym() = %q and %q for the same value", got, again)
+			}
+
+			if tt.same != "" && pseudonymizer.Pseudonym(tt.strategy, tt.same) != got {
+				t.Errorf("Pseudonym(%q) = %q, want %q", tt.same, pseudonymizer.Pseudonym(tt.strategy, tt.same), got)
+			}
+
+			if changed := pseudonymizer.Pseudonym(tt.strategy, tt.value+"1"); changed == got {
+				t.Errorf("Pseudonym() = %q for a different value", changed)
+			}
+
+			if keyed := other.Pseudonym(tt.strategy, tt.value); keyed == got {
+				t.Errorf("Pseudonym() = %q with a different secret", keyed)
+			}
+		})
+	}
+
+	if got := pseudonymizer.Pseudonym(csvprocessor.PseudonymEmail, ""); got != "" {
+		t.Errorf("Pseudonym() = %q for an empty value, want empty", got)
+	}
+}
+
+func TestPseudonymizeTransformer(t *testing.T) {
+	pseudonymizer, err := csvprocessor.NewPseudonymizer([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("NewPseudonymizer() error = %v", err)
+	}
+
+	columns := map[int]csvprocessor.PseudonymStrategy{0: csvprocessor.PseudonymNumericID, 2: csvprocessor.PseudonymEmail, 5: csvprocessor.PseudonymName}
+	orders, err := processString(t, "customer,amount,email\n7,10,bob@x.org\n8,20,\n7,30,bob@x.org\n",
+		csvprocessor.WithTransformer(csvprocessor.PseudonymizeTransformer(pseudonymizer, columns)))
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	// the same customer in another file gets the same pseudonym, so the files can be joined.
+	customers, err := processString(t, "id,email\n7,bob@x.org\n",
+		csvprocessor.WithTransformer(csvprocessor.PseudonymizeTransformer(pseudonymizer, map[int]csvprocessor.PseudonymStrategy{
+			0: csvprocessor.PseudonymNumericID, 1: csvprocessor.PseudonymEmail,
+		})))
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	id := pseudonymizer.Pseudonym(csvprocessor.PseudonymNumericID, "7")
+	email := pseudonymizer.Pseudonym(csvprocessor.PseudonymEmail, "bob@x.org")
+	other := pseudonymizer.Pseudonym(csvprocessor.PseudonymNumericID, "8")
+	if want := "customer,amount,email\n" + id + ",10," + email + "\n" + other + ",20,\n" + id + ",30," + email + "\n"; orders != want {
+		t.Errorf("Process() output = %q, want %q", orders, want)
+	}
+
+	if want := "id,email\n" + id + "," + email + "\n"; customers != want {
+		t.Errorf("Process() output = %q, want %q", customers, want)
+	}
+}
+
+func TestPseudonymizer_Errors(t *testing.T) {
+	if _, err := csvprocessor.NewPseudonymizer([]byte("short")); !errors.Is(err, csvprocessor.ErrInvalidSecret) {
+		t.Errorf("NewPseudonymizer() error = %v, want %v", err, csvprocessor.ErrInvalidSecret)
+	}
+
+	if _, err := csvprocessor.ParsePseudonymStrategy("phone"); !errors.Is(err, csvprocessor.ErrInvalidPseudonymStrategy) {
+		t.Errorf("ParsePseudonymStrategy() error = %v, want %v", err, csvprocessor.ErrInvalidPseudonymStrategy)
+	}
+
+	for strategy := csvprocessor.PseudonymToken; strategy <= csvprocessor.PseudonymNumericID; strategy++ {
+		if parsed, err := csvprocessor.ParsePseudonymStrategy(strategy.String()); err != nil || parsed != strategy {
+			t.Errorf("ParsePseudonymStrategy(%q) = %v, %v", strategy, parsed, err)
+		}
+	}
+}
+
+func TestPseudonymizeRegistry(t *testing.T) {
+	t.Setenv("TEST_PSEUDONYM_SECRET", testSecret)
+	transformer, err := csvprocessor.DefaultTransformerRegistry.New("pseudonymize",
+		[]byte(`{"secret_env": "TEST_PSEUDONYM_SECRET", "columns": {"1": "token"}}`))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	output, err := processString(t, "id,name\n1,alice\n", csvprocessor.WithTransformer(transformer))
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	pseudonymizer, _ := csvprocessor.NewPseudonymizer([]byte(testSecret)) //nolint:errcheck
+	if want := "id,name\n1," + pseudonymizer.Pseudonym(csvprocessor.PseudonymToken, "alice") + "\n"; output != want {
+		t.Errorf("Process() output = %q, want %q", output, want)
+	}
+
+	for _, params := range []string{`{"columns": {"1": "token"}}`, `{"secret": "` + testSecret + `", "columns": {"x": "token"}}`,
+		`{"secret": "` + testSecret + `", "columns": {"1": "phone"}}`} {
+		if _, err := csvprocessor.DefaultTransformerRegistry.New("pseudonymize", []byte(params)); err == nil {
+			t.Errorf("New(%s) error = nil, want an error", params)
+		}
+	}
+}
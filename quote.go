@@ -0,0 +1,286 @@
+package csvprocessor
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// ErrInvalidQuote is returned when the quote or the escape character is a new line, an invalid character or the delimiter.
+var ErrInvalidQuote = errors.New("csvprocessor: quote and escape characters cannot be a new line, an invalid character or the delimiter")
+
+// WithInputQuote sets the quote and escape characters of the input, the default is '"' with the quotes doubled inside the quoted fields.
+// The escape character makes the next character literal in both quoted and unquoted fields, Eg: with '\\' as escape,
+// `'it\'s',a\,b` is read as the fields "it's" and "a,b". An escaped n, r or t is read as a new line, a carriage return or a tab.
+// Use 0 as escape to double the quotes instead, Eg: WithInputQuote('\”, 0) reads 'it”s' as "it's".
+//
+// The inputs are parsed with QuotedReader instead of csv.Reader when the quote or escape is not the default.
+func WithInputQuote(quote, escape rune) Option {
+	return func(c *Processor) error {
+		quote, escape, err := validQuote(quote, escape)
+		if err != nil {
+			return err
+		}
+
+		c.inputQuote = quote
+		c.inputEscape = escape
+		return nil
+	}
+}
+
+// WithOutputQuote sets the quote and escape characters of the output chunks, the default is '"' with the quotes doubled inside the quoted fields.
+// The fields are quoted based on WithQuoteMode(), the quote and escape characters inside the quoted fields are escaped
+// with the escape character, Eg: with '\” as quote and '\\' as escape, the field "it's" is written as 'it\'s'.
+// Use 0 as escape to double the quotes instead.
+// The quote and escape characters are not used with QuoteNever, which always escapes with a backslash.
+func WithOutputQuote(quote, escape rune) Option {
+	return func(c *Processor) error {
+		quote, escape, err := validQuote(quote, escape)
+		if err != nil {
+			return err
+		}
+
+		c.outputQuote = quote
+		c.outputEscape = escape
+		return nil
+	}
+}
+
+// validQuote validates the quote and escape characters, an escape same as the quote is returned as 0 (the quotes are doubled).
+func validQuote(quote, escape rune) (rune, rune, error) {
+	if !validQuoteChar(quote) || (escape != 0 && !validQuoteChar(escape)) {
+		return 0, 0, ErrInvalidQuote
+	}
+
+	if escape == quote {
+		escape = 0
+	}
+
+	return quote, escape, nil
+}
+
+func validQuoteChar(r rune) bool {
+	return r != 0 && r != '\r' && r != '\n' && utf8.ValidRune(r) && r != utf8.RuneError
+}
+
+// validQuoteDelimiters returns ErrInvalidQuote if the delimiter is used as the quote or escape character.
+func (c *Processor) validQuoteDelimiters() error {
+	if c.inputQuote == c.inputDelimiter || c.inputEscape == c.inputDelimiter ||
+		c.outputQuote == c.outputDelimiter || c.outputEscape == c.outputDelimiter {
+		return ErrInvalidQuote
+	}
+
+	return nil
+}
+
+// customQuote reports whether the input is parsed with QuotedReader.
+func (c *Processor) customQuote() bool {
+	return c.quote() != '"' || c.inputEscape != 0
+}
+
+// QuotedReader is a CsvReader that parses the CSV dialects that csv.Reader does not support,
+// like a quote character other than '"' or the backslash escaped fields. Eg:
+//
+//	reader := csvprocessor.NewQuotedReader(input, ',', '\'', '\\')
+//
+// A field is quoted only if it starts with the quote, a quote inside a field that is not followed by the delimiter
+// or a new line is read as it is (like csv.Reader with LazyQuotes). \r\n is read as \n, a \r at the end of the input is dropped and the empty lines are skipped.
+type QuotedReader struct {
+	// Comma is the field delimiter, Quote is the quote character and Escape is the escape character (0 to double the quotes).
+	Comma, Quote, Escape rune
+	// FieldsPerRecord is the no. of fields expected in each record, same as csv.Reader.FieldsPerRecord.
+	FieldsPerRecord int
+	// ReuseRecord controls whether the slice returned by Read is reused by the next call, same as csv.Reader.ReuseRecord.
+	ReuseRecord bool
+
+	// Unexported fields
+	r      *bufio.Reader
+	line   int
+	buf    []byte
+	ends   []int
+	record []string
+
+	// the rune read after a quote in a quoted field, that is read again as part of the field.
+	pending    rune
+	hasPending bool
+}
+
+// NewQuotedReader creates a QuotedReader with the given delimiter, quote and escape characters.
+func NewQuotedReader(r io.Reader, comma, quote, escape rune) *QuotedReader {
+	reader, ok := r.(*bufio.Reader)
+	if !ok {
+		reader = bufio.NewReaderSize(r, DefaultReadBufferSize)
+	}
+
+	return &QuotedReader{Comma: comma, Quote: quote, Escape: escape, r: reader}
+}
+
+// Read reads the next record, it returns io.EOF at the end of the input.
+// If the record has an unexpected no. of fields, it is returned along with a *csv.ParseError wrapping csv.ErrFieldCount.
+func (q *QuotedReader) Read() ([]string, error) {
+	startLine := q.line + 1
+	if err := q.readRecord(); err != nil {
+		return nil, err
+	}
+
+	// the fields are sliced from a single string, as in csv.Reader.
+	line := string(q.buf)
+	record := q.record[:0]
+	if !q.ReuseRecord {
+		record = make([]string, 0, len(q.ends))
+	}
+
+	start := 0
+	for _, end := range q.ends {
+		record = append(record, line[start:end])
+		start = end
+	}
+
+	if q.ReuseRecord {
+		q.record = record
+	}
+
+	switch {
+	case q.FieldsPerRecord == 0:
+		q.FieldsPerRecord = len(record)
+	case q.FieldsPerRecord > 0 && len(record) != q.FieldsPerRecord:
+		return record, &csv.ParseError{StartLine: startLine, Line: q.line, Column: 1, Err: csv.ErrFieldCount}
+	}
+
+	return record, nil
+}
+
+// readRecord reads the fields of the next record into buf, ends has the end offset of each field.
+func (q *QuotedReader) readRecord() error {
+	q.buf = q.buf[:0]
+	q.ends = q.ends[:0]
+	fieldStart, quoted := true, false
+	for {
+		r, err := q.readRune()
+		if errors.Is(err, io.EOF) {
+			if len(q.ends) == 0 && fieldStart {
+				return io.EOF
+			}
+
+			// the last record need not end with a new line, an unterminated quoted field ends at EOF.
+			q.ends = append(q.ends, len(q.buf))
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if quoted {
+			switch {
+			case q.Escape != 0 && r == q.Escape:
+				q.readEscaped()
+			case r == q.Quote:
+				next, err := q.readRune()
+				switch {
+				case errors.Is(err, io.EOF):
+					// the record ends at EOF.
+					quoted = false
+				case err != nil:
+					return err
+				case next == q.Quote && q.Escape == 0:
+					q.buf = utf8.AppendRune(q.buf, r)
+				case next == q.Comma:
+					quoted = false
+					q.ends = append(q.ends, len(q.buf))
+					fieldStart = true
+				case next == '\n':
+					q.ends = append(q.ends, len(q.buf))
+					return nil
+				default:
+					// a quote in the middle of a quoted field is read as it is.
+					q.buf = utf8.AppendRune(q.buf, r)
+					q.pending, q.hasPending = next, true
+				}
+			default:
+				q.buf = utf8.AppendRune(q.buf, r)
+			}
+
+			continue
+		}
+
+		switch {
+		case r == q.Comma:
+			q.ends = append(q.ends, len(q.buf))
+			fieldStart = true
+			continue
+		case r == '\n':
+			if len(q.ends) == 0 && fieldStart {
+				// empty lines are skipped.
+				continue
+			}
+
+			q.ends = append(q.ends, len(q.buf))
+			return nil
+		case fieldStart && r == q.Quote:
+			quoted = true
+		case q.Escape != 0 && r == q.Escape:
+			q.readEscaped()
+		default:
+			q.buf = utf8.AppendRune(q.buf, r)
+		}
+
+		fieldStart = false
+	}
+}
+
+// readRune reads the next rune, counting the lines and reading \r\n as \n. A \r at the end of the input is dropped.
+func (q *QuotedReader) readRune() (rune, error) {
+	if q.hasPending {
+		q.hasPending = false
+		return q.pending, nil
+	}
+
+	r, _, err := q.r.ReadRune()
+	if err != nil {
+		return r, err
+	}
+
+	if r == '\r' {
+		next, err := q.r.Peek(1)
+		switch {
+		case err == nil && next[0] == '\n':
+			_, _ = q.r.ReadByte() //nolint:errcheck
+			r = '\n'
+		case errors.Is(err, io.EOF):
+			// like csv.Reader, a \r at the end of the input is dropped, in both quoted and unquoted fields.
+			return 0, io.EOF
+		}
+	}
+
+	if r == '\n' {
+		q.line++
+	}
+
+	return r, nil
+}
+
+// readEscaped reads the character after the escape character, an escape at the end of the input is read as it is.
+func (q *QuotedReader) readEscaped() {
+	r, err := q.readRune()
+	if err != nil {
+		q.buf = utf8.AppendRune(q.buf, q.Escape)
+		return
+	}
+
+	switch r {
+	case 'n':
+		r = '\n'
+	case 'r':
+		r = '\r'
+	case 't':
+		r = '\t'
+	}
+
+	q.buf = utf8.AppendRune(q.buf, r)
+}
+
+// compile time check to ensure QuotedReader implements CsvReader.
+var _ CsvReader = (*QuotedReader)(nil)
@@ -0,0 +1,172 @@
+package csvprocessor_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestQuotedReader(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		quote, escape rune
+		want          [][]string
+	}{
+		{
+			name:  "single quotes doubled",
+			input: "id,name\n1,'it''s'\n2,'a,b'\n",
+			quote: '\'',
+			want:  [][]string{{"id", "name"}, {"1", "it's"}, {"2", "a,b"}},
+		},
+		{
+			name:   "backslash escaped",
+			input:  "1,'it\\'s',a\\,b\r\n2,'x\\ny',c\\\\d",
+			quote:  '\'',
+			escape: '\\',
+			want:   [][]string{{"1", "it's", "a,b"}, {"2", "x\ny", "c\\d"}},
+		},
+		{
+			name:   "double quotes with backslash",
+			input:  "\"say \\\"hi\\\"\",\"multi\nline\"\n\n3,\"\"\n",
+			quote:  '"',
+			escape: '\\',
+			want:   [][]string{{"say \"hi\"", "multi\nline"}, {"3", ""}},
+		},
+		{
+			name:  "lazy quotes",
+			input: "a'b,'c'd',''\n",
+			quote: '\'',
+			want:  [][]string{{"a'b", "c'd", ""}},
+		},
+		{
+			name:  "unterminated quote",
+			input: "1,'abc",
+			quote: '\'',
+			want:  [][]string{{"1", "abc"}},
+		},
+		{
+			name:  "bare CR at the end",
+			input: "1,a\rb\r",
+			quote: '\'',
+			want:  [][]string{{"1", "a\rb"}},
+		},
+		{
+			name:  "bare CR at the end of a quoted field",
+			input: "1,'a'\r",
+			quote: '\'',
+			want:  [][]string{{"1", "a"}},
+		},
+		{
+			name:  "bare CR at the end of an unterminated quoted field",
+			input: "1,'aa\r",
+			quote: '\'',
+			want:  [][]string{{"1", "aa"}},
+		},
+		{
+			name:  "bare CR after an empty unterminated quoted field",
+			input: " ,'\r",
+			quote: '\'',
+			want:  [][]string{{" ", ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readAll(t, csvprocessor.NewQuotedReader(strings.NewReader(tt.input), ',', tt.quote, tt.escape))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuotedReader_CsvReader(t *testing.T) {
+	// with the default quote, QuotedReader reads the input like csv.Reader with LazyQuotes.
+	inputs := []string{
+		"id,name\n1,\"a,b\"\n2,\"say \"\"hi\"\"\"\n",
+		"a,b\r\n\r\n\nc,d\r\n",
+		"1,\"line1\r\nline2\nline3\",end\n",
+		"a\"b,\"c\"d\",e\n",
+		"1,\"abc",
+		"a,b\nc\rd,e\r",
+		"a,\"b\"\r",
+		"a,b\n\r",
+		"\"aa\r",
+		" ,\"\r",
+		"\"a\r\"\r",
+		"\"\"\r",
+	}
+
+	for _, input := range inputs {
+		csvReader := csv.NewReader(strings.NewReader(input))
+		csvReader.LazyQuotes = true
+		want := readAll(t, csvReader)
+
+		got := readAll(t, csvprocessor.NewQuotedReader(strings.NewReader(input), ',', '"', 0))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: rows = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestWithInputQuote(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opts    []csvprocessor.Option
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "Test single quoted input",
+			input: "id,name\n1,'a,b'\n2,'it''s'\n",
+			opts:  []csvprocessor.Option{csvprocessor.WithInputQuote('\'', 0)},
+			want:  "id,name\n1,\"a,b\"\n2,it's\n",
+		},
+		{
+			name:  "Test backslash escaped input and output",
+			input: "id,name\n1,'it\\'s'\n2,a\\,b\n",
+			opts:  []csvprocessor.Option{csvprocessor.WithInputQuote('\'', '\\'), csvprocessor.WithOutputQuote('\'', '\\')},
+			want:  "id,name\n1,'it\\'s'\n2,'a,b'\n",
+		},
+		{
+			name:  "Test output quote with quote all",
+			input: "id,name\n1,\"it's\"\n",
+			opts:  []csvprocessor.Option{csvprocessor.WithOutputQuote('\'', 0), csvprocessor.WithQuoteMode(csvprocessor.QuoteAll)},
+			want:  "'id','name'\n'1','it''s'\n",
+		},
+		{
+			name:  "Test field count errors are recorded",
+			input: "id,name\n1,'a'\n2\n",
+			opts:  []csvprocessor.Option{csvprocessor.WithInputQuote('\'', 0)},
+			want:  "id,name\n1,a\n2\n",
+		},
+		{
+			name:    "Test invalid quote",
+			opts:    []csvprocessor.Option{csvprocessor.WithInputQuote('\n', 0)},
+			wantErr: csvprocessor.ErrInvalidQuote,
+		},
+		{
+			name:    "Test quote same as delimiter",
+			opts:    []csvprocessor.Option{csvprocessor.WithOutputQuote(';', 0), csvprocessor.WithOutputDelimiter(';')},
+			wantErr: csvprocessor.ErrInvalidQuote,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processFile(t, tt.input, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
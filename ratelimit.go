@@ -0,0 +1,72 @@
+package csvprocessor
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// ErrInvalidRateLimit is returned when the rate limit is not > 0.
+var ErrInvalidRateLimit = errors.New("csvprocessor: rate limit must be > 0")
+
+// WithRateLimit limits the no. of data rows processed per second, Eg: so that the downstream sinks like databases
+// or message queues are not overwhelmed. The rows are limited using a token bucket that allows bursts of up to 100ms of rows.
+// The wait is stopped when the context passed to ProcessContext() is cancelled.
+func WithRateLimit(rowsPerSecond float64) Option {
+	return func(c *Processor) error {
+		if rowsPerSecond <= 0 || math.IsInf(rowsPerSecond, 0) || math.IsNaN(rowsPerSecond) {
+			return ErrInvalidRateLimit
+		}
+
+		c.rateLimit = rowsPerSecond
+		return nil
+	}
+}
+
+// tokenBucket limits the rate of the rows, each row takes a token.
+type tokenBucket struct {
+	rate   float64 // tokens added per second.
+	burst  float64 // max. no. of tokens.
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns the limiter of the run, or nil if WithRateLimit() is not used.
+func (c *Processor) newRateLimiter() *tokenBucket {
+	if c.rateLimit <= 0 {
+		return nil
+	}
+
+	burst := math.Max(1, c.rateLimit/10)
+	return &tokenBucket{rate: c.rateLimit, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// wait waits till a token is available, or returns ctx.Err() if ctx is cancelled.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return nil
+	}
+
+	delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		// the token added during the wait is taken.
+		b.tokens = 0
+		b.last = time.Now()
+		return nil
+	}
+}
@@ -0,0 +1,71 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func newRateLimitedProcessor(t *testing.T, rows int, rowsPerSecond float64) *csvprocessor.Processor {
+	t.Helper()
+
+	proc, err := csvprocessor.New(
+		csvprocessor.WithInputReader(strings.NewReader("id\n"+strings.Repeat("1\n", rows))),
+		csvprocessor.WithChunkSize(1000),
+		csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(io.Discard), nil
+		}),
+		csvprocessor.WithRateLimit(rowsPerSecond),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	return proc
+}
+
+func TestWithRateLimit(t *testing.T) {
+	// a burst of 20 rows, and 40 rows at 200 rows per second.
+	proc := newRateLimitedProcessor(t, 60, 200)
+
+	start := time.Now()
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("Process() took %v, want about 200ms", elapsed)
+	}
+
+	for _, rate := range []float64{0, -1} {
+		if _, err := csvprocessor.New(csvprocessor.WithRateLimit(rate)); !errors.Is(err, csvprocessor.ErrInvalidRateLimit) {
+			t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidRateLimit)
+		}
+	}
+}
+
+func TestWithRateLimitCancel(t *testing.T) {
+	proc := newRateLimitedProcessor(t, 5, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := proc.ProcessContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ProcessContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("ProcessContext() took %v after the context is cancelled", elapsed)
+	}
+
+	if stats := proc.Stats(); stats.RowsRead != 1 {
+		t.Errorf("Stats().RowsRead = %d, want 1", stats.RowsRead)
+	}
+}
@@ -0,0 +1,499 @@
+package csvprocessor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrUnknownTransformer is returned when a transformer is not registered, the error suggests the closest registered name.
+	ErrUnknownTransformer = errors.New("csvprocessor: unknown transformer")
+	// ErrInvalidTransformerName is returned when a transformer is registered with an empty name, or a name that is already registered.
+	ErrInvalidTransformerName = errors.New("csvprocessor: transformer name must be non-empty and unique")
+	// ErrInvalidTransformerParams is returned when the parameters of a transformer do not match its parameter schema.
+	ErrInvalidTransformerParams = errors.New("csvprocessor: invalid transformer params")
+)
+
+// TransformerFactory creates a transformer from its parameters, a JSON object. params is empty if no parameters are given.
+type TransformerFactory func(params json.RawMessage) (CsvRowTransformer, error)
+
+// ParamType is the JSON type of a transformer parameter, null values are accepted for all the types.
+type ParamType string
+
+const (
+	// ParamAny accepts values of any type.
+	ParamAny    ParamType = ""
+	ParamString ParamType = "string"
+	// ParamInt accepts numbers without a fraction.
+	ParamInt    ParamType = "integer"
+	ParamNumber ParamType = "number"
+	ParamBool   ParamType = "boolean"
+	ParamArray  ParamType = "array"
+	ParamObject ParamType = "object"
+)
+
+// TransformerParam describes a parameter of a registered transformer.
+type TransformerParam struct {
+	Name     string
+	Type     ParamType
+	Required bool
+	// Description is shown in the list of transformers, Eg: by the csvproc command.
+	Description string
+}
+
+// TransformerRegistry maps names to transformer factories, so that config files (see Config) and the command line can refer to
+// custom transformers compiled into the binary. It is safe for concurrent use.
+// DefaultTransformerRegistry has the built-in transformers, use NewTransformerRegistry() for an isolated registry.
+type TransformerRegistry struct {
+	mu           sync.RWMutex
+	transformers map[string]registeredTransformer
+}
+
+type registeredTransformer struct {
+	factory TransformerFactory
+	params  []TransformerParam
+}
+
+// DefaultTransformerRegistry is the registry used by RegisterTransformer() and by Config, unless Config.Registry is set.
+var DefaultTransformerRegistry = newBuiltinRegistry()
+
+// NewTransformerRegistry creates an empty registry, the built-in transformers can be added using RegisterBuiltinTransformers().
+func NewTransformerRegistry() *TransformerRegistry {
+	return &TransformerRegistry{transformers: map[string]registeredTransformer{}}
+}
+
+// RegisterTransformer registers the transformer factory in DefaultTransformerRegistry, see TransformerRegistry.Register().
+// It is usually called from an init() function of the package defining the transformer.
+func RegisterTransformer(name string, factory TransformerFactory, params ...TransformerParam) error {
+	return DefaultTransformerRegistry.Register(name, factory, params...)
+}
+
+// Register registers the transformer factory with the given name and parameter schema.
+// If a schema is given, the parameters are checked against it before calling the factory: unknown or missing required parameters,
+// and parameters of the wrong type are reported as ErrInvalidTransformerParams.
+func (r *TransformerRegistry) Register(name string, factory TransformerFactory, params ...TransformerParam) error {
+	if strings.TrimSpace(name) == "" {
+		return ErrInvalidTransformerName
+	}
+
+	if factory == nil {
+		return fmt.Errorf("%w: %q has a nil factory", ErrInvalidTransformerName, name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.transformers[name]; ok {
+		return fmt.Errorf("%w: %q is already registered", ErrInvalidTransformerName, name)
+	}
+
+	r.transformers[name] = registeredTransformer{factory: factory, params: append([]TransformerParam(nil), params...)}
+	return nil
+}
+
+// New creates the transformer registered with the given name, using the parameters.
+func (r *TransformerRegistry) New(name string, params json.RawMessage) (CsvRowTransformer, error) {
+	r.mu.RLock()
+	transformer, ok := r.transformers[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		if suggestion := r.suggest(name); suggestion != "" {
+			return nil, fmt.Errorf("%w: %q, did you mean %q?", ErrUnknownTransformer, name, suggestion)
+		}
+
+		return nil, fmt.Errorf("%w: %q, must be one of %s", ErrUnknownTransformer, name, strings.Join(r.Names(), ", "))
+	}
+
+	if err := checkParams(params, transformer.params); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidTransformerParams, name, err)
+	}
+
+	rowTransformer, err := transformer.factory(params)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidTransformerParams, name, err)
+	}
+
+	return rowTransformer, nil
+}
+
+// Names returns the names of the registered transformers, in sorted order.
+func (r *TransformerRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.transformers))
+	for name := range r.transformers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Params returns the parameter schema of the transformer registered with the given name.
+func (r *TransformerRegistry) Params(name string) ([]TransformerParam, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	transformer, ok := r.transformers[name]
+	return append([]TransformerParam(nil), transformer.params...), ok
+}
+
+// suggest returns the registered name closest to name, if it is close enough to be a typo.
+func (r *TransformerRegistry) suggest(name string) string {
+	best, bestDistance := "", len(name)/3+2
+	for _, candidate := range r.Names() {
+		if distance := editDistance(name, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	previous := make([]int, len(t)+1)
+	current := make([]int, len(t)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		current[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(t)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// checkParams checks the parameters against the schema, the parameters are not checked if there is no schema.
+func checkParams(params json.RawMessage, schema []TransformerParam) error {
+	if len(schema) == 0 {
+		return nil
+	}
+
+	values := map[string]json.RawMessage{}
+	if len(bytes.TrimSpace(params)) > 0 {
+		if err := json.Unmarshal(params, &values); err != nil {
+			return fmt.Errorf("params must be a JSON object: %v", err)
+		}
+	}
+
+	known := make(map[string]bool, len(schema))
+	for _, param := range schema {
+		known[param.Name] = true
+
+		value, ok := values[param.Name]
+		if !ok {
+			if param.Required {
+				return fmt.Errorf("missing required param %q", param.Name)
+			}
+
+			continue
+		}
+
+		if !hasParamType(value, param.Type) {
+			return fmt.Errorf("param %q must be of type %s, got %s", param.Name, param.Type, value)
+		}
+	}
+
+	for name := range values {
+		if !known[name] {
+			return fmt.Errorf("unknown param %q", name)
+		}
+	}
+
+	return nil
+}
+
+// hasParamType reports whether the JSON value is of the given type, null is accepted for all the types.
+func hasParamType(value json.RawMessage, paramType ParamType) bool {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 || string(value) == "null" {
+		return true
+	}
+
+	switch paramType {
+	case ParamString:
+		return value[0] == '"'
+	case ParamInt:
+		var i int64
+		return json.Unmarshal(value, &i) == nil
+	case ParamNumber:
+		var f float64
+		return json.Unmarshal(value, &f) == nil
+	case ParamBool:
+		return value[0] == 't' || value[0] == 'f'
+	case ParamArray:
+		return value[0] == '['
+	case ParamObject:
+		return value[0] == '{'
+	default:
+		return true
+	}
+}
+
+// RegisterBuiltinTransformers registers the built-in transformers of this package in the registry:
+// add_row_num, add_chunk_row_num, replace_values, add_constant_column, merge_columns, arithmetic, dedup, approx_dedup and pseudonymize.
+func RegisterBuiltinTransformers(r *TransformerRegistry) error {
+	for _, builtin := range builtinTransformers {
+		if err := r.Register(builtin.name, builtin.factory, builtin.params...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newBuiltinRegistry() *TransformerRegistry {
+	registry := NewTransformerRegistry()
+	if err := RegisterBuiltinTransformers(registry); err != nil {
+		panic(err)
+	}
+
+	return registry
+}
+
+var builtinTransformers = []struct {
+	name    string
+	factory TransformerFactory
+	params  []TransformerParam
+}{
+	{
+		name: "add_row_num",
+		factory: func(params json.RawMessage) (CsvRowTransformer, error) {
+			var p rowNoParams
+			err := decodeParams(params, &p)
+			return AddRowNoTransformerWithOptions(p.Column, p.options()), err
+		},
+		params: append([]TransformerParam{
+			{Name: "column", Type: ParamString, Required: true, Description: "name of the row no. column, added as the first column by default"},
+		}, rowNoOptionParams...),
+	},
+	{
+		name: "add_chunk_row_num",
+		factory: func(params json.RawMessage) (CsvRowTransformer, error) {
+			var p rowNoParams
+			err := decodeParams(params, &p)
+			return AddChunkRowNoTransformerWithOptions(p.Column, p.options()), err
+		},
+		params: append([]TransformerParam{
+			{Name: "column", Type: ParamString, Required: true, Description: "name of the row no. in chunk column, added as the first column by default"},
+		}, rowNoOptionParams...),
+	},
+	{
+		name: "replace_values",
+		factory: func(params json.RawMessage) (CsvRowTransformer, error) {
+			var p struct {
+				Replacements map[string]string `json:"replacements"`
+			}
+
+			err := decodeParams(params, &p)
+			return ReplaceValuesTransformer(p.Replacements), err
+		},
+		params: []TransformerParam{
+			{Name: "replacements", Type: ParamObject, Required: true, Description: "the values to replace and their replacements"},
+		},
+	},
+	{
+		name: "add_constant_column",
+		factory: func(params json.RawMessage) (CsvRowTransformer, error) {
+			var p struct {
+				Column string `json:"column"`
+				Value  string `json:"value"`
+				Index  int    `json:"index"`
+			}
+
+			err := decodeParams(params, &p)
+			return AddConstantColumnTransformer(p.Column, p.Value, p.Index), err
+		},
+		params: []TransformerParam{
+			{Name: "column", Type: ParamString, Required: true, Description: "name of the new column"},
+			{Name: "value", Type: ParamString, Description: "value of the new column"},
+			{Name: "index", Type: ParamInt, Description: "0-based position of the new column"},
+		},
+	},
+	{
+		name: "merge_columns",
+		factory: func(params json.RawMessage) (CsvRowTransformer, error) {
+			var p struct {
+				Column     string `json:"column"`
+				Separator  string `json:"separator"`
+				DropSource bool   `json:"drop_source"`
+				Columns    []int  `json:"columns"`
+			}
+
+			err := decodeParams(params, &p)
+			return MergeColumnsTransformer(p.Column, p.Separator, p.DropSource, p.Columns...), err
+		},
+		params: []TransformerParam{
+			{Name: "column", Type: ParamString, Required: true, Description: "name of the merged column"},
+			{Name: "separator", Type: ParamString, Description: "separator between the merged values"},
+			{Name: "drop_source", Type: ParamBool, Description: "drop the merged columns"},
+			{Name: "columns", Type: ParamArray, Required: true, Description: "0-based indices of the columns to merge"},
+		},
+	},
+	{
+		name: "arithmetic",
+		factory: func(params json.RawMessage) (CsvRowTransformer, error) {
+			var p struct {
+				Column       string `json:"column"`
+				Op           string `json:"op"`
+				Precision    int    `json:"precision"`
+				OnParseError string `json:"on_parse_error"`
+				Columns      []int  `json:"columns"`
+			}
+
+			if err := decodeParams(params, &p); err != nil {
+				return nil, err
+			}
+
+			ops := map[string]ArithmeticOp{"sum": OpSum, "difference": OpDifference, "product": OpProduct, "percentage": OpPercentage}
+			op, ok := ops[p.Op]
+			if !ok {
+				return nil, fmt.Errorf("unknown op %q, must be one of sum, difference, product or percentage", p.Op)
+			}
+
+			return ArithmeticTransformer(p.Column, op, p.Precision, p.OnParseError, p.Columns...), nil
+		},
+		params: []TransformerParam{
+			{Name: "column", Type: ParamString, Required: true, Description: "name of the result column, added as the last column"},
+			{Name: "op", Type: ParamString, Required: true, Description: "sum, difference, product or percentage"},
+			{Name: "precision", Type: ParamInt, Description: "no. of decimal places of the result"},
+			{Name: "on_parse_error", Type: ParamString, Description: "value of the result if a value is not a number"},
+			{Name: "columns", Type: ParamArray, Required: true, Description: "0-based indices of the operands"},
+		},
+	},
+	{
+		name: "dedup",
+		factory: func(params json.RawMessage) (CsvRowTransformer, error) {
+			var p struct {
+				Columns []int `json:"columns"`
+			}
+
+			err := decodeParams(params, &p)
+			return DedupTransformer(p.Columns...), err
+		},
+		params: []TransformerParam{
+			{Name: "columns", Type: ParamArray, Description: "0-based indices of the key columns, all the columns if empty"},
+		},
+	},
+	{
+		name: "approx_dedup",
+		factory: func(params json.RawMessage) (CsvRowTransformer, error) {
+			var p struct {
+				ExpectedRows      int     `json:"expected_rows"`
+				FalsePositiveRate float64 `json:"false_positive_rate"`
+				Columns           []int   `json:"columns"`
+			}
+
+			err := decodeParams(params, &p)
+			return ApproxDedupTransformer(p.ExpectedRows, p.FalsePositiveRate, p.Columns...), err
+		},
+		params: []TransformerParam{
+			{Name: "expected_rows", Type: ParamInt, Required: true, Description: "expected no. of distinct rows"},
+			{Name: "false_positive_rate", Type: ParamNumber, Required: true, Description: "rate of unique rows that may be dropped, Eg: 0.001"},
+			{Name: "columns", Type: ParamArray, Description: "0-based indices of the key columns, all the columns if empty"},
+		},
+	},
+	{
+		name: "pseudonymize",
+		factory: func(params json.RawMessage) (CsvRowTransformer, error) {
+			var p struct {
+				Secret    string            `json:"secret"`
+				SecretEnv string            `json:"secret_env"`
+				Columns   map[string]string `json:"columns"`
+			}
+
+			if err := decodeParams(params, &p); err != nil {
+				return nil, err
+			}
+
+			if p.SecretEnv != "" {
+				p.Secret = os.Getenv(p.SecretEnv)
+			}
+
+			pseudonymizer, err := NewPseudonymizer([]byte(p.Secret))
+			if err != nil {
+				return nil, err
+			}
+
+			columns := make(map[int]PseudonymStrategy, len(p.Columns))
+			for column, name := range p.Columns {
+				index, err := strconv.Atoi(column)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid column %q, must be a 0-based index", column)
+				}
+
+				if columns[index], err = ParsePseudonymStrategy(name); err != nil {
+					return nil, err
+				}
+			}
+
+			return PseudonymizeTransformer(pseudonymizer, columns), nil
+		},
+		params: []TransformerParam{
+			{Name: "secret", Type: ParamString, Description: "secret of the pseudonyms, at least 16 bytes"},
+			{Name: "secret_env", Type: ParamString, Description: "environment variable with the secret, used instead of secret"},
+			{Name: "columns", Type: ParamObject, Required: true, Description: `0-based index of each column and the strategy of its pseudonyms, Eg: {"0": "numeric_id", "2": "email"}`},
+		},
+	},
+}
+
+// rowNoParams are the parameters of add_row_num and add_chunk_row_num.
+type rowNoParams struct {
+	Column string `json:"column"`
+	Offset int    `json:"offset"`
+	Step   int    `json:"step"`
+	Width  int    `json:"width"`
+	Index  int    `json:"index"`
+}
+
+func (p rowNoParams) options() RowNoOptions {
+	return RowNoOptions{Offset: p.Offset, Step: p.Step, Width: p.Width, Index: p.Index}
+}
+
+var rowNoOptionParams = []TransformerParam{
+	{Name: "offset", Type: ParamInt, Description: "added to the row numbers, Eg: 1000 to number the rows from 1001"},
+	{Name: "step", Type: ParamInt, Description: "difference between consecutive row numbers (default 1)"},
+	{Name: "width", Type: ParamInt, Description: "min. no. of digits, the row numbers are padded with leading zeros"},
+	{Name: "index", Type: ParamInt, Description: "0-based position of the column, -1 for the last column"},
+}
+
+// decodeParams decodes the parameters of a transformer, unknown parameters are reported as errors to catch typos.
+func decodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
@@ -0,0 +1,179 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func upperCaseFactory(params json.RawMessage) (csvprocessor.CsvRowTransformer, error) {
+	var p struct {
+		Column int `json:"column"`
+	}
+
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, row []string) []string {
+		if p.Column < len(row) {
+			row[p.Column] = strings.ToUpper(row[p.Column])
+		}
+
+		return row
+	}, nil
+}
+
+func TestTransformerRegistry(t *testing.T) {
+	registry := csvprocessor.NewTransformerRegistry()
+	err := registry.Register("upper_case", upperCaseFactory,
+		csvprocessor.TransformerParam{Name: "column", Type: csvprocessor.ParamInt, Required: true})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if err := registry.Register("upper_case", upperCaseFactory); !errors.Is(err, csvprocessor.ErrInvalidTransformerName) {
+		t.Errorf("Register() duplicate error = %v, want %v", err, csvprocessor.ErrInvalidTransformerName)
+	}
+
+	if err := registry.Register(" ", upperCaseFactory); !errors.Is(err, csvprocessor.ErrInvalidTransformerName) {
+		t.Errorf("Register() empty name error = %v, want %v", err, csvprocessor.ErrInvalidTransformerName)
+	}
+
+	tests := []struct {
+		name        string
+		transformer string
+		params      string
+		want        []string
+		wantErr     error
+		wantMessage string
+	}{
+		{
+			name:        "registered",
+			transformer: "upper_case",
+			params:      `{"column": 1}`,
+			want:        []string{"a", "B"},
+		},
+		{
+			name:        "suggestion",
+			transformer: "uppercase",
+			wantErr:     csvprocessor.ErrUnknownTransformer,
+			wantMessage: `did you mean "upper_case"?`,
+		},
+		{
+			name:        "no suggestion",
+			transformer: "trim",
+			wantErr:     csvprocessor.ErrUnknownTransformer,
+			wantMessage: "must be one of upper_case",
+		},
+		{
+			name:        "missing required param",
+			transformer: "upper_case",
+			params:      `{}`,
+			wantErr:     csvprocessor.ErrInvalidTransformerParams,
+			wantMessage: `missing required param "column"`,
+		},
+		{
+			name:        "wrong param type",
+			transformer: "upper_case",
+			params:      `{"column": "name"}`,
+			wantErr:     csvprocessor.ErrInvalidTransformerParams,
+			wantMessage: `param "column" must be of type integer`,
+		},
+		{
+			name:        "unknown param",
+			transformer: "upper_case",
+			params:      `{"column": 1, "columns": [1]}`,
+			wantErr:     csvprocessor.ErrInvalidTransformerParams,
+			wantMessage: `unknown param "columns"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformer, err := registry.New(tt.transformer, json.RawMessage(tt.params))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("New() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if !strings.Contains(err.Error(), tt.wantMessage) {
+					t.Errorf("New() error = %q, want it to contain %q", err, tt.wantMessage)
+				}
+
+				return
+			}
+
+			ctx := context.WithValue(context.TODO(), csvprocessor.CtxIsHeader, false)
+			if got := transformer(ctx, []string{"a", "b"}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("transformer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultTransformerRegistry(t *testing.T) {
+	names := csvprocessor.DefaultTransformerRegistry.Names()
+	want := []string{"add_chunk_row_num", "add_constant_column", "add_row_num", "approx_dedup", "arithmetic", "dedup", "merge_columns", "pseudonymize", "replace_values"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Names() = %q, want %q", names, want)
+	}
+
+	params, ok := csvprocessor.DefaultTransformerRegistry.Params("add_row_num")
+	if !ok || len(params) != 5 || params[0].Name != "column" || !params[0].Required || params[1].Required {
+		t.Errorf("Params(add_row_num) = %v, %v", params, ok)
+	}
+
+	if err := csvprocessor.RegisterTransformer("dedup", upperCaseFactory); !errors.Is(err, csvprocessor.ErrInvalidTransformerName) {
+		t.Errorf("RegisterTransformer() error = %v, want %v", err, csvprocessor.ErrInvalidTransformerName)
+	}
+}
+
+func TestRowNoRegistry(t *testing.T) {
+	transformer, err := csvprocessor.DefaultTransformerRegistry.New("add_row_num", []byte(`{"column": "n", "offset": 10, "width": 4, "index": -1}`))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	output, err := processString(t, "a\nx\ny\n", csvprocessor.WithTransformer(transformer))
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if want := "a,n\nx,0011\ny,0012\n"; output != want {
+		t.Errorf("Process() output = %q, want %q", output, want)
+	}
+}
+
+func TestConfigRegistry(t *testing.T) {
+	registry := csvprocessor.NewTransformerRegistry()
+	if err := registry.Register("upper_case", upperCaseFactory); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := csvprocessor.ParseConfig(strings.NewReader(`{"transformers": [{"name": "upper_case", "params": {"column": 0}}]}`))
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+
+	config.Registry = registry
+	opts, err := config.Options()
+	if err != nil {
+		t.Fatalf("Options() error = %v", err)
+	}
+
+	// the last option is the transformer, the input and the output of the config are not used.
+	got, err := processString(t, "name\nalice\n", opts[len(opts)-1])
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if want := "NAME\nALICE\n"; got != want {
+		t.Errorf("Process() output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,173 @@
+package csvprocessor
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+)
+
+// errIteratorClosed stops the processing when the RowIterator is closed before the last row.
+var errIteratorClosed = errors.New("csvprocessor: row iterator closed")
+
+// RowIterator iterates over the transformed rows of a Processor, so that the rows can be consumed without a writer. Eg:
+//
+//	it := proc.Rows()
+//	defer it.Close()
+//	for it.Next() {
+//		fmt.Println(it.Row())
+//	}
+//
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+//
+// The rows are produced as they are consumed, the processing waits for Next() to be called. The header row is not returned
+// by Next(), use Header(). A RowIterator is not safe for concurrent use.
+type RowIterator struct {
+	c    *Processor
+	rows chan []string
+	done chan struct{} // closed by Close() to stop the processing.
+
+	// set by the processing before rows is closed.
+	header []string
+	err    error
+
+	started   bool
+	closed    bool
+	row       []string
+	closeOnce sync.Once
+}
+
+// Rows returns an iterator over the transformed rows, the output of the processor (Eg: WithOutputFileFormat()) is not used.
+// The processing starts at the first call to Next(), and Stats() has the stats of the run after the last row.
+func (c *Processor) Rows() *RowIterator {
+	return &RowIterator{c: c, rows: make(chan []string), done: make(chan struct{})}
+}
+
+// NewRowIterator creates a Processor with the options and returns an iterator over its transformed rows.
+// Unlike New(), the output and the chunk size are not needed, the rows are processed as a single chunk unless a chunk size is given.
+func NewRowIterator(opts ...Option) (*RowIterator, error) {
+	processor := defaultProcessor
+	for _, opt := range opts {
+		if err := opt(&processor); err != nil {
+			return nil, err
+		}
+	}
+
+	if processor.reader == nil && len(processor.inputs) == 0 {
+		return nil, ErrInputReaderNil
+	}
+
+	if processor.chunkSize <= 0 {
+		processor.chunkSize = math.MaxInt32
+	}
+
+	return processor.Rows(), nil
+}
+
+// Next advances to the next row, it returns false when there are no more rows or the processing failed, see Err().
+func (it *RowIterator) Next() bool {
+	if it.closed {
+		it.row = nil
+		return false
+	}
+
+	if !it.started {
+		it.started = true
+		go it.process()
+	}
+
+	row, ok := <-it.rows
+	it.row = row
+	return ok
+}
+
+func (it *RowIterator) process() {
+	err := it.c.process(context.Background(), runOutput{sink: iteratorSink{it}})
+	if !errors.Is(err, errIteratorClosed) {
+		it.err = err
+	}
+
+	close(it.rows)
+}
+
+// Row returns the current row, it is not modified by the next calls to Next().
+func (it *RowIterator) Row() []string {
+	return it.row
+}
+
+// Header returns the transformed header row, it is nil if the input has no header or before the first call to Next().
+func (it *RowIterator) Header() []string {
+	return it.header
+}
+
+// Err returns the error that stopped the processing, after Next() has returned false.
+func (it *RowIterator) Err() error {
+	return it.err
+}
+
+// Close stops the processing if it has not completed, it must be called if the rows are not consumed till the end.
+func (it *RowIterator) Close() error {
+	it.closeOnce.Do(func() {
+		it.closed = true
+		close(it.done)
+		if !it.started {
+			_ = it.c.closeInputs()
+			return
+		}
+
+		// wait for the processing to stop, so that the inputs are closed.
+		for range it.rows { //nolint:revive
+		}
+	})
+
+	return nil
+}
+
+// All returns a function that yields the rows, it can be used with range-over-func in Go 1.23 and later:
+//
+//	for row := range proc.Rows().All() { ... }
+//
+// The iterator is closed when the loop ends, check Err() after the loop.
+func (it *RowIterator) All() func(yield func([]string) bool) {
+	return func(yield func([]string) bool) {
+		defer it.Close()
+
+		for it.Next() {
+			if !yield(it.Row()) {
+				return
+			}
+		}
+	}
+}
+
+// iteratorSink is the CsvWriter to which the processing writes the rows, it passes them to the iterator.
+type iteratorSink struct {
+	it *RowIterator
+}
+
+func (s iteratorSink) WriteHeader(header []string) error {
+	if s.it.header == nil {
+		s.it.header = append([]string(nil), header...)
+	}
+
+	return nil
+}
+
+func (s iteratorSink) Write(record []string) error {
+	// the readers and transformers can reuse the row, so a copy is passed to the iterator.
+	row := append([]string(nil), record...)
+	select {
+	case s.it.rows <- row:
+		return nil
+	case <-s.it.done:
+		return errIteratorClosed
+	}
+}
+
+func (s iteratorSink) Flush() {}
+
+func (s iteratorSink) Error() error {
+	return nil
+}
@@ -0,0 +1,190 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestRowIterator(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		opts       []csvprocessor.Option
+		wantHeader []string
+		wantRows   [][]string
+		wantErr    bool
+	}{
+		{
+			name:       "transformed rows",
+			input:      "id,name\n1,alice\n2,NULL\n",
+			opts:       []csvprocessor.Option{csvprocessor.WithTransformer(csvprocessor.ReplaceValuesTransformer(map[string]string{"NULL": ""}))},
+			wantHeader: []string{"id", "name"},
+			wantRows:   [][]string{{"1", "alice"}, {"2", ""}},
+		},
+		{
+			name:  "filtered rows",
+			input: "id\n1\n2\n3\n",
+			opts: []csvprocessor.Option{csvprocessor.WithTransformer(func(_ context.Context, row []string) []string {
+				if row[0] == "2" {
+					return nil
+				}
+
+				return row
+			})},
+			wantHeader: []string{"id"},
+			wantRows:   [][]string{{"1"}, {"3"}},
+		},
+		{
+			name:     "without header",
+			input:    "1,alice\n2,bob\n",
+			opts:     []csvprocessor.Option{csvprocessor.SkipHeaders(true)},
+			wantRows: [][]string{{"1", "alice"}, {"2", "bob"}},
+		},
+		{
+			name:  "chunks",
+			input: "id\n1\n2\n3\n",
+			opts: []csvprocessor.Option{
+				csvprocessor.WithChunkSize(2),
+				csvprocessor.WithTransformer(csvprocessor.AddChunkRowNoTransformer("n")),
+			},
+			wantHeader: []string{"n", "id"},
+			wantRows:   [][]string{{"1", "1"}, {"2", "2"}, {"1", "3"}},
+		},
+		{
+			name:  "transformer error",
+			input: "id\n1\n2\n",
+			opts: []csvprocessor.Option{csvprocessor.WithTransformer(func(_ context.Context, row []string) []string {
+				if row[0] == "2" {
+					panic(errors.New("invalid row"))
+				}
+
+				return row
+			})},
+			wantHeader: []string{"id"},
+			wantRows:   [][]string{{"1"}},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]csvprocessor.Option{
+				csvprocessor.WithInputReader(strings.NewReader(tt.input)),
+				csvprocessor.WithLogger(t.Logf),
+			}, tt.opts...)
+			it, err := csvprocessor.NewRowIterator(opts...)
+			if err != nil {
+				t.Fatalf("NewRowIterator() error = %v", err)
+			}
+			defer it.Close()
+
+			var rows [][]string
+			for it.Next() {
+				rows = append(rows, it.Row())
+			}
+
+			if (it.Err() != nil) != tt.wantErr {
+				t.Fatalf("Err() = %v, wantErr %v", it.Err(), tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("rows = %q, want %q", rows, tt.wantRows)
+			}
+
+			if !reflect.DeepEqual(it.Header(), tt.wantHeader) {
+				t.Errorf("Header() = %q, want %q", it.Header(), tt.wantHeader)
+			}
+
+			if it.Next() {
+				t.Error("Next() = true after the last row")
+			}
+		})
+	}
+}
+
+func TestProcessor_Rows(t *testing.T) {
+	outputFormat := filepath.Join(t.TempDir(), "out_%d.csv")
+	proc, err := csvprocessor.New(
+		csvprocessor.WithInputReader(strings.NewReader("id\n1\n2\n3\n")),
+		csvprocessor.WithChunkSize(10),
+		csvprocessor.WithOutputFileFormat(outputFormat),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var rows [][]string
+	for row := range iterate(proc.Rows()) {
+		rows = append(rows, row)
+	}
+
+	if want := [][]string{{"1"}, {"2"}, {"3"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+
+	if _, err := os.Stat(strings.Replace(outputFormat, "%d", "1", 1)); !os.IsNotExist(err) {
+		t.Errorf("output file is written, Stat() error = %v", err)
+	}
+
+	if stats := proc.Stats(); stats.RowsWritten != 3 {
+		t.Errorf("Stats().RowsWritten = %d, want 3", stats.RowsWritten)
+	}
+}
+
+func TestRowIterator_Close(t *testing.T) {
+	it, err := csvprocessor.NewRowIterator(
+		csvprocessor.WithInputReader(strings.NewReader("id\n1\n2\n3\n")),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("NewRowIterator() error = %v", err)
+	}
+
+	var rows [][]string
+	it.All()(func(row []string) bool {
+		rows = append(rows, row)
+		return len(rows) < 2
+	})
+
+	if want := [][]string{{"1"}, {"2"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+
+	if it.Next() {
+		t.Error("Next() = true after Close()")
+	}
+
+	if err := it.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+
+	if err := it.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	if _, err := csvprocessor.NewRowIterator(); !errors.Is(err, csvprocessor.ErrInputReaderNil) {
+		t.Errorf("NewRowIterator() error = %v, want %v", err, csvprocessor.ErrInputReaderNil)
+	}
+}
+
+// iterate returns a channel with the rows, as range-over-func is not available in the go version of the module.
+func iterate(it *csvprocessor.RowIterator) <-chan []string {
+	rows := make(chan []string)
+	go func() {
+		defer close(rows)
+		it.All()(func(row []string) bool {
+			rows <- row
+			return true
+		})
+	}()
+
+	return rows
+}
@@ -0,0 +1,90 @@
+// Package s3 reads the input from and streams the output chunks to Amazon S3.
+//
+// The package does not import the AWS SDK, the S3 API calls are made through the Client interface.
+// Implement it with the SDK version used by the application, Eg: with GetObject of s3.Client and Upload of feature/s3/manager.Uploader:
+//
+//	proc, err := csvprocessor.New(
+//		s3.S3FileReader(client, "raw-data", "orders/2023.csv"),
+//		csvprocessor.WithWriterGenerator(s3.S3ChunkGenerator(client, "processed", "orders/part_%03d.csv")),
+//	)
+package s3
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+	"github.com/sivaramasubramanian/csvprocessor/internal/upload"
+)
+
+// Client represents the S3 operations used by this package.
+type Client interface {
+	// GetObject returns the content of the object, the content is read as a stream.
+	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	// PutObject uploads the content read from body till EOF.
+	// The size of the content is not known in advance, so use a multipart upload, Eg: manager.Uploader of the AWS SDK.
+	PutObject(ctx context.Context, bucket, key string, body io.Reader) error
+}
+
+// ErrNoClient is returned when the client is nil.
+var ErrNoClient = errors.New("s3: client is nil")
+
+// S3FileReader sets the S3 object as the input of the processor, the object is streamed without downloading it to disk.
+// The object is opened at the first read, so that it is not left open if the processor is not created or run.
+func S3FileReader(client Client, bucket, key string) csvprocessor.Option { //nolint:revive
+	return func(c *csvprocessor.Processor) error {
+		if client == nil {
+			return ErrNoClient
+		}
+
+		return csvprocessor.WithInputReader(&object{client: client, bucket: bucket, key: key})(c)
+	}
+}
+
+// S3ChunkGenerator returns an OutputChunkGenerator that streams each chunk to an S3 object as it is produced.
+// The key of each chunk is generated from prefixFormat using csvprocessor.ChunkName(), Eg: "exports/orders_%03d.csv".
+// The upload completes when the chunk is closed, and is aborted if the processing fails.
+func S3ChunkGenerator(client Client, bucket, prefixFormat string) csvprocessor.OutputChunkGenerator { //nolint:revive
+	return func(chunkID int) (io.WriteCloser, error) {
+		if client == nil {
+			return nil, ErrNoClient
+		}
+
+		key := csvprocessor.ChunkName(prefixFormat, chunkID)
+		return upload.NewWriter(func(body io.Reader) error {
+			return client.PutObject(context.Background(), bucket, key, body)
+		}), nil
+	}
+}
+
+// object is the content of an S3 object, it is opened at the first read.
+type object struct {
+	client      Client
+	bucket, key string
+
+	// Unexported fields
+	body io.ReadCloser
+	err  error
+}
+
+func (o *object) Read(p []byte) (int, error) {
+	if o.body == nil && o.err == nil {
+		o.body, o.err = o.client.GetObject(context.Background(), o.bucket, o.key)
+	}
+
+	if o.err != nil {
+		return 0, o.err
+	}
+
+	return o.body.Read(p)
+}
+
+// Close closes the object if it was opened.
+func (o *object) Close() error {
+	if o.body == nil {
+		return nil
+	}
+
+	return o.body.Close()
+}
@@ -0,0 +1,160 @@
+package s3_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+	"github.com/sivaramasubramanian/csvprocessor/s3"
+)
+
+// fakeS3 is an in-memory Client.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+	gets    int
+}
+
+func (f *fakeS3) GetObject(_ context.Context, bucket, key string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.gets++
+	content, ok := f.objects[bucket+"/"+key]
+	if !ok {
+		return nil, fmt.Errorf("NoSuchKey: %s/%s", bucket, key)
+	}
+
+	return io.NopCloser(bytes.NewBufferString(content)), nil
+}
+
+func (f *fakeS3) PutObject(_ context.Context, bucket, key string, body io.Reader) error {
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[bucket+"/"+key] = string(content)
+	return nil
+}
+
+func TestS3(t *testing.T) {
+	client := &fakeS3{objects: map[string]string{"in/orders.csv": "id\n1\n2\n3\n"}}
+
+	proc, err := csvprocessor.New(
+		s3.S3FileReader(client, "in", "orders.csv"),
+		csvprocessor.WithWriterGenerator(s3.S3ChunkGenerator(client, "out", "orders/part_%02d.csv")),
+		csvprocessor.WithChunkSize(2),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	want := map[string]string{
+		"in/orders.csv":          "id\n1\n2\n3\n",
+		"out/orders/part_01.csv": "id\n1\n2\n",
+		"out/orders/part_02.csv": "id\n3\n",
+	}
+	if !reflect.DeepEqual(client.objects, want) {
+		t.Errorf("objects = %q, want %q", client.objects, want)
+	}
+}
+
+func TestS3_Clients(t *testing.T) {
+	// the input and the output can be in different accounts.
+	source := &fakeS3{objects: map[string]string{"in/orders.csv": "id\n1\n"}}
+	target := &fakeS3{objects: map[string]string{}}
+
+	proc, err := csvprocessor.New(
+		s3.S3FileReader(source, "in", "orders.csv"),
+		csvprocessor.WithWriterGenerator(s3.S3ChunkGenerator(target, "out", "part_%02d.csv")),
+		csvprocessor.WithChunkSize(2),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if want := map[string]string{"out/part_01.csv": "id\n1\n"}; !reflect.DeepEqual(target.objects, want) {
+		t.Errorf("target objects = %q, want %q", target.objects, want)
+	}
+
+	if len(source.objects) != 1 {
+		t.Errorf("source objects = %q, want only the input", source.objects)
+	}
+}
+
+func TestS3_Abort(t *testing.T) {
+	client := &fakeS3{objects: map[string]string{"in/orders.csv": "id\n1\n2\n3\n"}}
+	errBadRow := errors.New("bad row")
+
+	proc, err := csvprocessor.New(
+		s3.S3FileReader(client, "in", "orders.csv"),
+		csvprocessor.WithWriterGenerator(s3.S3ChunkGenerator(client, "out", "part_%02d.csv")),
+		csvprocessor.WithChunkSize(10),
+		csvprocessor.WithTransformer(func(ctx context.Context, row []string) []string {
+			if row[0] == "3" {
+				panic(errBadRow)
+			}
+
+			return row
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); !errors.Is(err, errBadRow) {
+		t.Fatalf("Process() error = %v, want %v", err, errBadRow)
+	}
+
+	if _, ok := client.objects["out/part_01.csv"]; ok {
+		t.Errorf("partial chunk was uploaded")
+	}
+}
+
+func TestS3FileReader_Error(t *testing.T) {
+	client := &fakeS3{objects: map[string]string{}}
+
+	proc, err := csvprocessor.New(
+		s3.S3FileReader(client, "in", "missing.csv"),
+		csvprocessor.WithWriterGenerator(s3.S3ChunkGenerator(client, "out", "part_%02d.csv")),
+		csvprocessor.WithChunkSize(2),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err == nil {
+		t.Errorf("Process() error = nil, want an error for missing object")
+	}
+
+	// the object is not opened if the processor is not created.
+	client = &fakeS3{objects: map[string]string{"in/orders.csv": "id\n1\n"}}
+	if _, err := csvprocessor.New(s3.S3FileReader(client, "in", "orders.csv")); err == nil {
+		t.Errorf("New() error = nil, want an error for the missing generator")
+	}
+
+	if client.gets != 0 {
+		t.Errorf("GetObject() called %d times, want 0", client.gets)
+	}
+
+	if _, err := csvprocessor.New(s3.S3FileReader(nil, "in", "orders.csv")); !errors.Is(err, s3.ErrNoClient) {
+		t.Errorf("New() error = %v, want %v", err, s3.ErrNoClient)
+	}
+}
@@ -0,0 +1,131 @@
+package csvprocessor
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"sort"
+)
+
+// sampleReader is a CsvReader that returns each row of the underlying reader with the given probability.
+type sampleReader struct {
+	reader     CsvReader
+	rate       float64
+	random     *rand.Rand
+	readHeader bool
+}
+
+func newSampleReader(reader CsvReader, rate float64, random *rand.Rand, hasHeader bool) *sampleReader {
+	return &sampleReader{reader: reader, rate: rate, random: random, readHeader: hasHeader}
+}
+
+func (s *sampleReader) Read() ([]string, error) {
+	for {
+		row, err := s.reader.Read()
+		if err != nil {
+			return row, err
+		}
+
+		if s.readHeader {
+			s.readHeader = false
+			return row, nil
+		}
+
+		if s.random.Float64() < s.rate {
+			return row, nil
+		}
+	}
+}
+
+// reservoirReader is a CsvReader that returns a uniform random sample of n rows from the underlying reader.
+// The sampled rows are returned in the order they appear in the input.
+type reservoirReader struct {
+	reader    CsvReader
+	size      int
+	random    *rand.Rand
+	hasHeader bool
+
+	sampled bool
+	header  []string
+	rows    []sampledRow
+	err     error
+
+	budget *memoryBudget // bounds the sampled rows, see WithMaxMemory().
+}
+
+// sampledRow is a row in the reservoir along with its position in the input.
+type sampledRow struct {
+	index int
+	row   []string
+}
+
+func newReservoirReader(reader CsvReader, size int, random *rand.Rand, hasHeader bool) *reservoirReader {
+	return &reservoirReader{reader: reader, size: size, random: random, hasHeader: hasHeader}
+}
+
+func (r *reservoirReader) Read() ([]string, error) {
+	if !r.sampled {
+		r.sampled = true
+		r.err = r.sample()
+		if r.err == nil && r.header != nil {
+			return r.header, nil
+		}
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if len(r.rows) == 0 {
+		return nil, io.EOF
+	}
+
+	row := r.rows[0].row
+	r.rows = r.rows[1:]
+	return row, nil
+}
+
+func (r *reservoirReader) sample() error {
+	r.rows = make([]sampledRow, 0, r.size)
+	for index := 0; ; {
+		row, err := readWaiting(r.reader)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// readers can reuse the row slice, so a copy is retained.
+		if r.hasHeader && r.header == nil {
+			r.header = append([]string(nil), row...)
+			continue
+		}
+
+		index++
+		if len(r.rows) < r.size {
+			if !r.budget.reserve(rowSize(row)) {
+				return r.budget.exceeded("WithReservoirSample()")
+			}
+
+			r.rows = append(r.rows, sampledRow{index: index, row: append([]string(nil), row...)})
+			continue
+		}
+
+		if replace := r.random.Intn(index); replace < r.size {
+			r.budget.release(rowSize(r.rows[replace].row))
+			if !r.budget.reserve(rowSize(row)) {
+				return r.budget.exceeded("WithReservoirSample()")
+			}
+
+			r.rows[replace] = sampledRow{index: index, row: append([]string(nil), row...)}
+		}
+	}
+
+	sort.Slice(r.rows, func(i, j int) bool {
+		return r.rows[i].index < r.rows[j].index
+	})
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package csvprocessor_test
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestProcessor_Sample(t *testing.T) {
+	var input strings.Builder
+	input.WriteString("id\n")
+	for i := 1; i <= 1000; i++ {
+		fmt.Fprintf(&input, "%d\n", i)
+	}
+
+	tests := []struct {
+		name      string
+		opt       []csvprocessor.Option
+		minRows   int
+		maxRows   int
+		wantError bool
+	}{
+		{
+			name:    "Test sample rate",
+			opt:     []csvprocessor.Option{csvprocessor.WithSample(0.1), csvprocessor.WithSeed(42)},
+			minRows: 50,
+			maxRows: 150,
+		},
+		{
+			name:    "Test reservoir sample",
+			opt:     []csvprocessor.Option{csvprocessor.WithReservoirSample(25), csvprocessor.WithSeed(42)},
+			minRows: 25,
+			maxRows: 25,
+		},
+		{
+			name:    "Test reservoir sample larger than input",
+			opt:     []csvprocessor.Option{csvprocessor.WithReservoirSample(2000)},
+			minRows: 1000,
+			maxRows: 1000,
+		},
+		{
+			name:      "Test invalid sample rate",
+			opt:       []csvprocessor.Option{csvprocessor.WithSample(1.5)},
+			wantError: true,
+		},
+		{
+			name:      "Test invalid sample size",
+			opt:       []csvprocessor.Option{csvprocessor.WithReservoirSample(0)},
+			wantError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := func() string {
+				var output strings.Builder
+				opts := append([]csvprocessor.Option{
+					csvprocessor.WithReader(csv.NewReader(strings.NewReader(input.String()))),
+					csvprocessor.WithWriterGenerator(func(i int) (io.WriteCloser, error) {
+						return csvprocessor.NoOpCloser(&output), nil
+					}),
+					csvprocessor.WithChunkSize(10_000),
+					csvprocessor.WithLogger(t.Logf),
+				}, tt.opt...)
+
+				proc, err := csvprocessor.New(opts...)
+				if (err != nil) != tt.wantError {
+					t.Fatalf("New() error = %v, wantErr %v", err, tt.wantError)
+				}
+
+				if err != nil {
+					return ""
+				}
+
+				if err := proc.Process(); err != nil {
+					t.Fatalf("Process() error = %v", err)
+				}
+
+				return output.String()
+			}
+
+			first := run()
+			if tt.wantError {
+				return
+			}
+
+			rows := strings.Count(first, "\n") - 1
+			if rows < tt.minRows || rows > tt.maxRows {
+				t.Errorf("Process() sampled rows = %d, want between %d and %d", rows, tt.minRows, tt.maxRows)
+			}
+
+			if !strings.HasPrefix(first, "id\n") {
+				t.Errorf("Process() expected header in output, got = %q", first[:10])
+			}
+
+			if second := run(); first != second {
+				t.Errorf("Process() with same seed produced different samples")
+			}
+		})
+	}
+}
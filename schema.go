@@ -0,0 +1,246 @@
+package csvprocessor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ColumnType represents the type of values in a column.
+type ColumnType int
+
+const (
+	// TypeString accepts any value.
+	TypeString ColumnType = iota
+	// TypeInt accepts integers, Eg: -12.
+	TypeInt
+	// TypeFloat accepts decimal numbers, Eg: 1.5e3.
+	TypeFloat
+	// TypeBool accepts the values accepted by strconv.ParseBool(), Eg: true, 0.
+	TypeBool
+	// TypeTime accepts timestamps in the layout given by ColumnSchema.Layout.
+	TypeTime
+)
+
+// String returns the name of the column type.
+func (t ColumnType) String() string {
+	switch t {
+	case TypeString:
+		return "string"
+	case TypeInt:
+		return "int"
+	case TypeFloat:
+		return "float"
+	case TypeBool:
+		return "bool"
+	case TypeTime:
+		return "time"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText returns the name of the column type, so that it is encoded as a string in JSON.
+func (t ColumnType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText parses the name of the column type returned by String().
+func (t *ColumnType) UnmarshalText(text []byte) error {
+	for columnType := TypeString; columnType <= TypeTime; columnType++ {
+		if columnType.String() == string(text) {
+			*t = columnType
+			return nil
+		}
+	}
+
+	return fmt.Errorf("csvprocessor: unknown column type %q", text)
+}
+
+// Schema represents the expected structure of the rows in a CSV.
+// It can be encoded as JSON, Eg: {"columns": [{"name": "id", "type": "int", "required": true}]}.
+type Schema struct {
+	Columns []ColumnSchema `json:"columns"`
+}
+
+// ColumnSchema represents the constraints on the values of a column.
+// Empty values are only checked for Required, all the other constraints apply to non-empty values.
+type ColumnSchema struct {
+	// Name of the column, it is used to find the column in the header.
+	// If the input does not have a header, the columns are matched by their position in the schema.
+	Name string `json:"name"`
+	// Type of the values in the column.
+	Type ColumnType `json:"type"`
+	// Layout is the time layout used for TypeTime columns, defaults to time.RFC3339.
+	Layout string `json:"layout,omitempty"`
+	// Required columns must be present in the header and cannot have empty values.
+	Required bool `json:"required"`
+	// Pattern is a regular expression that the values must match.
+	Pattern string `json:"pattern,omitempty"`
+	// Enum is the list of allowed values.
+	Enum []string `json:"enum,omitempty"`
+	// MaxLength is the max no. of characters in the values, no limit if 0.
+	MaxLength int `json:"max_length,omitempty"`
+}
+
+// ValidationError represents a value or a header that does not match the Schema.
+type ValidationError struct {
+	// Row is the overall row number (see CtxRowNum) of the row, -1 for the header row.
+	Row int
+	// Column is the 0-based index of the column in the row, -1 if the column is missing in the header.
+	Column int
+	// ColumnName is the name of the column in the schema.
+	ColumnName string
+	// Value is the invalid value.
+	Value string
+	// Reason describes the violation.
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("csvprocessor: row %d, column %d (%s): %s", e.Row, e.Column, e.ColumnName, e.Reason)
+}
+
+// validator validates rows against a Schema.
+type validator struct {
+	columns []compiledColumn
+	indices []int // index of each schema column in the row, -1 if missing.
+}
+
+type compiledColumn struct {
+	ColumnSchema
+	pattern *regexp.Regexp
+	enum    map[string]struct{}
+}
+
+func newValidator(schema Schema) (*validator, error) {
+	v := &validator{
+		columns: make([]compiledColumn, len(schema.Columns)),
+		indices: make([]int, len(schema.Columns)),
+	}
+
+	for i, column := range schema.Columns {
+		compiled := compiledColumn{ColumnSchema: column}
+		if column.Pattern != "" {
+			pattern, err := regexp.Compile(column.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("csvprocessor: invalid pattern for column %q: %w", column.Name, err)
+			}
+
+			compiled.pattern = pattern
+		}
+
+		if column.Enum != nil {
+			compiled.enum = make(map[string]struct{}, len(column.Enum))
+			for _, val := range column.Enum {
+				compiled.enum[val] = struct{}{}
+			}
+		}
+
+		if compiled.Type == TypeTime && compiled.Layout == "" {
+			compiled.Layout = time.RFC3339
+		}
+
+		v.columns[i] = compiled
+		v.indices[i] = i
+	}
+
+	return v, nil
+}
+
+// validateHeader maps the schema columns to the columns in the header.
+func (v *validator) validateHeader(header []string) []error {
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		if _, ok := positions[name]; !ok {
+			positions[name] = i
+		}
+	}
+
+	var errs []error
+	for i, column := range v.columns {
+		index, ok := positions[column.Name]
+		if !ok {
+			index = -1
+			if column.Required {
+				errs = append(errs, &ValidationError{Row: -1, Column: -1, ColumnName: column.Name, Reason: "required column is missing in header"})
+			}
+		}
+
+		v.indices[i] = index
+	}
+
+	return errs
+}
+
+// validate returns the violations in the given row.
+func (v *validator) validate(rowNum int, row []string) []error {
+	var errs []error
+	for i, column := range v.columns {
+		index := v.indices[i]
+		if index < 0 {
+			continue
+		}
+
+		if reason := column.check(valueAt(row, index)); reason != "" {
+			errs = append(errs, &ValidationError{Row: rowNum, Column: index, ColumnName: column.Name, Value: valueAt(row, index), Reason: reason})
+		}
+	}
+
+	return errs
+}
+
+// check returns the reason why the value is invalid, or an empty string if it is valid.
+func (c *compiledColumn) check(val string) string {
+	if val == "" {
+		if c.Required {
+			return "value is required"
+		}
+
+		return ""
+	}
+
+	if reason := checkType(c.Type, c.Layout, val); reason != "" {
+		return reason
+	}
+
+	if c.MaxLength > 0 && utf8.RuneCountInString(val) > c.MaxLength {
+		return fmt.Sprintf("value %q is longer than %d characters", val, c.MaxLength)
+	}
+
+	if c.pattern != nil && !c.pattern.MatchString(val) {
+		return fmt.Sprintf("value %q does not match pattern %q", val, c.Pattern)
+	}
+
+	if c.enum != nil {
+		if _, ok := c.enum[val]; !ok {
+			return fmt.Sprintf("value %q is not one of [%s]", val, strings.Join(c.Enum, ", "))
+		}
+	}
+
+	return ""
+}
+
+func checkType(columnType ColumnType, layout, val string) string {
+	var err error
+	switch columnType {
+	case TypeInt:
+		_, err = strconv.ParseInt(val, 10, 64)
+	case TypeFloat:
+		_, err = strconv.ParseFloat(val, 64)
+	case TypeBool:
+		_, err = strconv.ParseBool(val)
+	case TypeTime:
+		_, err = time.Parse(layout, val)
+	case TypeString:
+	}
+
+	if err != nil {
+		return fmt.Sprintf("value %q is not a valid %s", val, columnType)
+	}
+
+	return ""
+}
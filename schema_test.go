@@ -0,0 +1,116 @@
+package csvprocessor_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+var usersSchema = csvprocessor.Schema{
+	Columns: []csvprocessor.ColumnSchema{
+		{Name: "id", Type: csvprocessor.TypeInt, Required: true},
+		{Name: "email", Pattern: `^[^@]+@[^@]+$`, MaxLength: 20},
+		{Name: "plan", Enum: []string{"free", "pro"}},
+		{Name: "joined", Type: csvprocessor.TypeTime, Layout: "2006-01-02"},
+	},
+}
+
+func TestProcessor_WithSchemaValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr *csvprocessor.ValidationError
+	}{
+		{
+			name:  "Test valid rows",
+			input: "plan,id,email,joined\nfree,1,a@b.com,2022-01-02\npro,2,,\n",
+		},
+		{
+			name:    "Test invalid int",
+			input:   "id,email\n1,a@b.com\nx,a@b.com\n",
+			wantErr: &csvprocessor.ValidationError{Row: 2, Column: 0, ColumnName: "id"},
+		},
+		{
+			name:    "Test required value",
+			input:   "email,id\na@b.com,\n",
+			wantErr: &csvprocessor.ValidationError{Row: 1, Column: 1, ColumnName: "id"},
+		},
+		{
+			name:    "Test pattern",
+			input:   "id,email\n1,abc\n",
+			wantErr: &csvprocessor.ValidationError{Row: 1, Column: 1, ColumnName: "email"},
+		},
+		{
+			name:    "Test max length",
+			input:   "id,email\n1,abcdefghijklmn@example.com\n",
+			wantErr: &csvprocessor.ValidationError{Row: 1, Column: 1, ColumnName: "email"},
+		},
+		{
+			name:    "Test enum",
+			input:   "id,plan\n1,free\n2,enterprise\n",
+			wantErr: &csvprocessor.ValidationError{Row: 2, Column: 1, ColumnName: "plan"},
+		},
+		{
+			name:    "Test time layout",
+			input:   "id,joined\n1,02/01/2022\n",
+			wantErr: &csvprocessor.ValidationError{Row: 1, Column: 1, ColumnName: "joined"},
+		},
+		{
+			name:    "Test missing required column",
+			input:   "email,plan\na@b.com,free\n",
+			wantErr: &csvprocessor.ValidationError{Row: -1, Column: -1, ColumnName: "id"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := processString(t, tt.input, csvprocessor.WithSchemaValidation(usersSchema))
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Process() error = %v, want nil", err)
+				}
+
+				return
+			}
+
+			var validationErr *csvprocessor.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Process() error = %v, want ValidationError", err)
+			}
+
+			if validationErr.Row != tt.wantErr.Row || validationErr.Column != tt.wantErr.Column || validationErr.ColumnName != tt.wantErr.ColumnName {
+				t.Errorf("Process() error = %+v, want %+v", validationErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProcessor_WithSchemaValidation_DryRun(t *testing.T) {
+	proc, err := csvprocessor.New(
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader("id,plan\nx,free\n2,gold\n3,pro\n"))),
+		csvprocessor.WithChunkSize(10),
+		csvprocessor.WithSchemaValidation(usersSchema),
+		csvprocessor.WithDryRun(true),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if stats := proc.Stats(); len(stats.Errors) != 2 || stats.RowsWritten != 3 {
+		t.Errorf("Stats() = %+v, want 2 errors and 3 rows", stats)
+	}
+}
+
+func TestWithSchemaValidation_InvalidPattern(t *testing.T) {
+	schema := csvprocessor.Schema{Columns: []csvprocessor.ColumnSchema{{Name: "id", Pattern: "("}}}
+	if _, err := processString(t, "id\n1\n", csvprocessor.WithSchemaValidation(schema)); err == nil {
+		t.Errorf("New() expected error for invalid pattern")
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/sivaramasubramanian/csvprocessor/script/lua
+
+go 1.18
+
+require (
+	github.com/sivaramasubramanian/csvprocessor v0.0.0
+	github.com/yuin/gopher-lua v1.1.1
+)
+
+replace github.com/sivaramasubramanian/csvprocessor => ../..
@@ -0,0 +1,168 @@
+// Package lua runs the row scripts of the script package with gopher-lua, a Lua 5.1 interpreter written in Go.
+//
+// It is a separate module so that only the programs using it depend on gopher-lua. Eg: to lowercase the emails
+// and drop the rows without one,
+//
+//	program, err := lua.Compile(`
+//		local email = string.lower(row.email)
+//		if email == "" then
+//			return false
+//		end
+//
+//		row.email = email
+//		return row
+//	`)
+//	if err != nil {
+//		return err
+//	}
+//
+//	csvprocessor.WithTransformer(script.Transformer(program))
+//
+// The globals and the results of the script are described in the script package, the lists are 1-based Lua tables.
+package lua
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sivaramasubramanian/csvprocessor/script"
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// Program is a compiled Lua script, it implements script.Program.
+// As the script is run in a single Lua state, a Program must not be shared by processors running concurrently.
+type Program struct {
+	proto *lua.FunctionProto
+	state *lua.LState
+}
+
+var _ script.Program = (*Program)(nil)
+
+// Compile compiles the Lua source, the script is run as the body of a function for each row.
+func Compile(source string) (*Program, error) {
+	chunk, err := parse.Parse(strings.NewReader(source), "script.lua")
+	if err != nil {
+		return nil, fmt.Errorf("lua: %w", err)
+	}
+
+	proto, err := lua.Compile(chunk, "script.lua")
+	if err != nil {
+		return nil, fmt.Errorf("lua: %w", err)
+	}
+
+	return &Program{proto: proto, state: lua.NewState()}, nil
+}
+
+// CompileProgram is like Compile, for script.Factory(). Eg:
+//
+//	csvprocessor.RegisterTransformer("lua", script.Factory(lua.CompileProgram), script.FactoryParams...)
+func CompileProgram(source string) (script.Program, error) {
+	return Compile(source)
+}
+
+// Run runs the script with the globals and returns the value it returns, see script.Program.
+func (p *Program) Run(ctx context.Context, globals map[string]any) (any, error) {
+	p.state.SetContext(ctx)
+	defer p.state.RemoveContext()
+
+	for name, value := range globals {
+		p.state.SetGlobal(name, toLua(p.state, value))
+	}
+
+	p.state.Push(p.state.NewFunctionFromProto(p.proto))
+	if err := p.state.PCall(0, 1, nil); err != nil {
+		return nil, err
+	}
+
+	result := p.state.Get(-1)
+	p.state.Pop(1)
+	return fromLua(result), nil
+}
+
+// Close closes the Lua state, the program cannot be run after it is closed.
+func (p *Program) Close() error {
+	p.state.Close()
+	return nil
+}
+
+// toLua converts the values of the globals to Lua values.
+func toLua(state *lua.LState, value any) lua.LValue {
+	switch value := value.(type) {
+	case nil:
+		return lua.LNil
+	case bool:
+		return lua.LBool(value)
+	case string:
+		return lua.LString(value)
+	case int:
+		return lua.LNumber(value)
+	case float64:
+		return lua.LNumber(value)
+	case []any:
+		table := state.CreateTable(len(value), 0)
+		for _, item := range value {
+			table.Append(toLua(state, item))
+		}
+
+		return table
+	case map[string]any:
+		table := state.CreateTable(0, len(value))
+		for key, item := range value {
+			table.RawSetString(key, toLua(state, item))
+		}
+
+		return table
+	default:
+		return lua.LString(fmt.Sprint(value))
+	}
+}
+
+// fromLua converts the result of the script to the Go values expected by script.Transformer(),
+// a table is a list if it has only the keys 1 to n, else it is a map.
+func fromLua(value lua.LValue) any {
+	switch value := value.(type) {
+	case lua.LBool:
+		return bool(value)
+	case lua.LString:
+		return string(value)
+	case lua.LNumber:
+		return float64(value)
+	case *lua.LTable:
+		return fromTable(value)
+	default:
+		if value == lua.LNil {
+			return nil
+		}
+
+		return value.String()
+	}
+}
+
+func fromTable(table *lua.LTable) any {
+	n := table.MaxN()
+	isList, keys := n > 0, 0
+	table.ForEach(func(key, _ lua.LValue) {
+		keys++
+		if _, ok := key.(lua.LNumber); !ok {
+			isList = false
+		}
+	})
+
+	if isList && keys == n {
+		list := make([]any, n)
+		for i := range list {
+			list[i] = fromLua(table.RawGetInt(i + 1))
+		}
+
+		return list
+	}
+
+	values := make(map[string]any, keys)
+	table.ForEach(func(key, value lua.LValue) {
+		values[key.String()] = fromLua(value)
+	})
+
+	return values
+}
@@ -0,0 +1,115 @@
+package lua_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+	"github.com/sivaramasubramanian/csvprocessor/script"
+	"github.com/sivaramasubramanian/csvprocessor/script/lua"
+)
+
+func process(input string, transformer csvprocessor.CsvRowTransformer) (string, error) {
+	var output strings.Builder
+	proc, err := csvprocessor.New(
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader(input))),
+		csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(&output), nil
+		}),
+		csvprocessor.WithChunkSize(math.MaxInt32),
+		csvprocessor.WithLogger(func(string, ...any) {}),
+		csvprocessor.WithTransformer(transformer),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	err = proc.Process()
+	return output.String(), err
+}
+
+func TestProgram(t *testing.T) {
+	input := "id,email\n1, Alice@Example.com\n2,\n3,bob@example.com\n"
+
+	tests := []struct {
+		name    string
+		source  string
+		opts    []script.Option
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "map result",
+			source: `
+				local email = string.lower(string.gsub(row.email, "^%s*(.-)%s*$", "%1"))
+				if email == "" then
+					return false
+				end
+
+				return {email = email, domain = string.match(email, "@(.*)$")}`,
+			opts: []script.Option{script.WithNewColumns("domain")},
+			want: "id,email,domain\n1,alice@example.com,example.com\n3,bob@example.com,example.com\n",
+		},
+		{
+			name:   "list result",
+			source: `return {meta.row_num, fields[1], meta.header[2]}`,
+			want:   "id,email\n1,1,email\n2,2,email\n3,3,email\n",
+		},
+		{
+			name:   "nil result",
+			source: `local unused = row.id`,
+			want:   "id,email\n1,\" Alice@Example.com\"\n2,\n3,bob@example.com\n",
+		},
+		{
+			name:    "runtime error",
+			source:  `error("bad row")`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := lua.Compile(tt.source)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+
+			defer program.Close()
+
+			got, err := process(input, script.Transformer(program, tt.opts...))
+			var rowErr *csvprocessor.RowError
+			if tt.wantErr != errors.As(err, &rowErr) {
+				t.Fatalf("Process() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Process() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	if _, err := lua.Compile(`return {`); err == nil {
+		t.Error("Compile() error = nil, want a syntax error")
+	}
+}
+
+func TestProgram_Cancelled(t *testing.T) {
+	program, err := lua.Compile(`while true do end`)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	defer program.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := program.Run(ctx, nil); err == nil {
+		t.Error("Run() error = nil, want the error of the cancelled context")
+	}
+}
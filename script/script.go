@@ -0,0 +1,253 @@
+// Package script runs a user-provided script for each row, so that one-off cleanups can be expressed without shipping Go code.
+//
+// The script is run through the Program interface. The Lua scripts are run by the script/lua module using gopher-lua:
+//
+//	program, err := lua.Compile(source)
+//
+// For the other languages, implement Program using an embedded interpreter. Eg: JavaScript with goja,
+//
+//	type gojaProgram struct {
+//		vm      *goja.Runtime
+//		program *goja.Program
+//	}
+//
+//	func compile(source string) (script.Program, error) {
+//		program, err := goja.Compile("transform.js", source, true)
+//		return gojaProgram{vm: goja.New(), program: program}, err
+//	}
+//
+//	func (p gojaProgram) Run(ctx context.Context, globals map[string]any) (any, error) {
+//		for name, value := range globals {
+//			if err := p.vm.Set(name, value); err != nil {
+//				return nil, err
+//			}
+//		}
+//
+//		result, err := p.vm.RunProgram(p.program)
+//		if err != nil {
+//			return nil, err
+//		}
+//
+//		return result.Export(), nil
+//	}
+//
+// # Globals
+//
+// The script is run with these globals:
+//
+//	row     the values of the row by column name, for inputs with a header.
+//	fields  the values of the row as a list.
+//	meta    row_num, chunk_num and header (the column names).
+//
+// The result of the script (the value of the last expression in JavaScript, the returned value in Lua) decides the row:
+// nil keeps the row unchanged, false drops it, a map sets the values of the columns by name and a list replaces the row.
+// Eg: to trim the emails and drop the rows without one,
+//
+//	row.email = row.email.trim().toLowerCase();
+//	row.email === "" ? false : row;
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// ErrInvalidResult is returned when the result of the script is not nil, false, a map or a list,
+// or a map has a column that is not in the header.
+var ErrInvalidResult = errors.New("script: invalid result")
+
+// Program is a compiled script, see the script/lua module and the package documentation for an implementation using goja.
+type Program interface {
+	// Run runs the script with the globals and returns its result, with the values converted to Go values
+	// (Eg: map[string]any for objects or tables, []any for arrays).
+	Run(ctx context.Context, globals map[string]any) (any, error)
+}
+
+// Option configures the transformer.
+type Option func(*transformer)
+
+// WithNewColumns adds the columns to the header, each row gets an empty value for them unless the script sets it by name.
+func WithNewColumns(names ...string) Option {
+	return func(t *transformer) {
+		t.newColumns = append(t.newColumns, names...)
+	}
+}
+
+type transformer struct {
+	program    Program
+	newColumns []string
+
+	// Unexported fields
+	header  []string
+	columns map[string]int
+}
+
+// Transformer returns a transformer that runs the program for each row, see the package documentation for the globals and the results.
+// The header row is not passed to the program. Errors from the program are returned from Process() as a csvprocessor.RowError.
+func Transformer(program Program, opts ...Option) csvprocessor.CsvRowTransformer {
+	t := &transformer{program: program}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return func(ctx context.Context, row []string) []string {
+		if isHeader, _ := ctx.Value(csvprocessor.CtxIsHeader).(bool); isHeader { //nolint:errcheck
+			return t.setHeader(row)
+		}
+
+		result, err := t.transform(ctx, row)
+		if err != nil {
+			// the processor returns the panics in the transformers as errors.
+			panic(err)
+		}
+
+		return result
+	}
+}
+
+func (t *transformer) setHeader(header []string) []string {
+	header = append(header, t.newColumns...)
+	t.header = append([]string(nil), header...)
+	t.columns = make(map[string]int, len(header))
+	for i, name := range header {
+		if _, ok := t.columns[name]; !ok {
+			t.columns[name] = i
+		}
+	}
+
+	return header
+}
+
+func (t *transformer) transform(ctx context.Context, row []string) ([]string, error) {
+	for range t.newColumns {
+		row = append(row, "")
+	}
+
+	fields := make([]any, len(row))
+	for i, value := range row {
+		fields[i] = value
+	}
+
+	header := make([]any, len(t.header))
+	namedRow := make(map[string]any, len(t.header))
+	for i, name := range t.header {
+		header[i] = name
+		if i < len(row) {
+			namedRow[name] = row[i]
+		}
+	}
+
+	rowNum, _ := ctx.Value(csvprocessor.CtxRowNum).(int)     //nolint:errcheck
+	chunkNum, _ := ctx.Value(csvprocessor.CtxChunkNum).(int) //nolint:errcheck
+
+	result, err := t.program.Run(ctx, map[string]any{
+		"row":    namedRow,
+		"fields": fields,
+		"meta":   map[string]any{"row_num": rowNum, "chunk_num": chunkNum, "header": header},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("script: %w", err)
+	}
+
+	switch result := result.(type) {
+	case nil:
+		return row, nil
+	case bool:
+		if !result {
+			return nil, nil
+		}
+
+		return row, nil
+	case map[string]any:
+		for name, value := range result {
+			i, ok := t.columns[name]
+			if !ok {
+				return nil, fmt.Errorf("%w: column %q is not in the header, add it using WithNewColumns()", ErrInvalidResult, name)
+			}
+
+			for i >= len(row) {
+				row = append(row, "")
+			}
+
+			row[i] = toString(value)
+		}
+
+		return row, nil
+	case []any:
+		values := make([]string, len(result))
+		for i, value := range result {
+			values[i] = toString(value)
+		}
+
+		return values, nil
+	case []string:
+		return result, nil
+	default:
+		return nil, fmt.Errorf("%w: %T, must be nil, false, a map or a list", ErrInvalidResult, result)
+	}
+}
+
+// toString formats the value returned by the script as a CSV field, nil is an empty string.
+func toString(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// Factory returns a csvprocessor.TransformerFactory that compiles the script given in the params, so that scripts can be used in
+// config files. The params are a JSON object with the script source or the path of a script file, and the new columns.
+// Eg: {"name": "script", "params": {"file": "scripts/clean.js", "new_columns": ["domain"]}}
+// Register it with FactoryParams:
+//
+//	csvprocessor.RegisterTransformer("script", script.Factory(compile), script.FactoryParams...)
+func Factory(compile func(source string) (Program, error)) csvprocessor.TransformerFactory {
+	return func(params json.RawMessage) (csvprocessor.CsvRowTransformer, error) {
+		var p struct {
+			Source     string   `json:"source"`
+			File       string   `json:"file"`
+			NewColumns []string `json:"new_columns"`
+		}
+
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+
+		if (p.Source == "") == (p.File == "") {
+			return nil, errors.New("one of source and file must be set")
+		}
+
+		source := p.Source
+		if p.File != "" {
+			data, err := os.ReadFile(p.File)
+			if err != nil {
+				return nil, err
+			}
+
+			source = string(data)
+		}
+
+		program, err := compile(source)
+		if err != nil {
+			return nil, err
+		}
+
+		return Transformer(program, WithNewColumns(p.NewColumns...)), nil
+	}
+}
+
+// FactoryParams is the parameter schema of Factory().
+var FactoryParams = []csvprocessor.TransformerParam{
+	{Name: "source", Type: csvprocessor.ParamString, Description: "source of the script, if file is not set"},
+	{Name: "file", Type: csvprocessor.ParamString, Description: "path of the script file, if source is not set"},
+	{Name: "new_columns", Type: csvprocessor.ParamArray, Description: "columns added to the header, which the script can set"},
+}
@@ -0,0 +1,166 @@
+package script_test
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+	"github.com/sivaramasubramanian/csvprocessor/script"
+)
+
+// programFunc is a Program written in Go, standing in for a compiled script.
+type programFunc func(globals map[string]any) (any, error)
+
+func (f programFunc) Run(_ context.Context, globals map[string]any) (any, error) {
+	return f(globals)
+}
+
+func process(input string, transformer csvprocessor.CsvRowTransformer) (string, error) {
+	var output strings.Builder
+	proc, err := csvprocessor.New(
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader(input))),
+		csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(&output), nil
+		}),
+		csvprocessor.WithChunkSize(math.MaxInt32),
+		csvprocessor.WithLogger(func(string, ...any) {}),
+		csvprocessor.WithTransformer(transformer),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	err = proc.Process()
+	return output.String(), err
+}
+
+func TestTransformer(t *testing.T) {
+	input := "id,email\n1, Alice@Example.com\n2,\n3,bob@example.com\n"
+
+	tests := []struct {
+		name    string
+		program programFunc
+		opts    []script.Option
+		want    string
+		wantErr error
+	}{
+		{
+			name: "map result",
+			program: func(globals map[string]any) (any, error) {
+				row := globals["row"].(map[string]any)
+				email := strings.ToLower(strings.TrimSpace(row["email"].(string)))
+				if email == "" {
+					return false, nil
+				}
+
+				_, domain, _ := strings.Cut(email, "@")
+				return map[string]any{"email": email, "domain": domain}, nil
+			},
+			opts: []script.Option{script.WithNewColumns("domain")},
+			want: "id,email,domain\n1,alice@example.com,example.com\n3,bob@example.com,example.com\n",
+		},
+		{
+			name: "list result",
+			program: func(globals map[string]any) (any, error) {
+				fields := globals["fields"].([]any)
+				meta := globals["meta"].(map[string]any)
+				return []any{meta["row_num"], fields[0], nil}, nil
+			},
+			want: "id,email\n1,1,\n2,2,\n3,3,\n",
+		},
+		{
+			name: "nil result",
+			program: func(globals map[string]any) (any, error) {
+				return nil, nil
+			},
+			want: "id,email\n1,\" Alice@Example.com\"\n2,\n3,bob@example.com\n",
+		},
+		{
+			name: "unknown column",
+			program: func(globals map[string]any) (any, error) {
+				return map[string]any{"domain": "x"}, nil
+			},
+			wantErr: script.ErrInvalidResult,
+		},
+		{
+			name: "invalid result",
+			program: func(globals map[string]any) (any, error) {
+				return 1, nil
+			},
+			wantErr: script.ErrInvalidResult,
+		},
+		{
+			name: "script error",
+			program: func(globals map[string]any) (any, error) {
+				return nil, io.ErrUnexpectedEOF
+			},
+			wantErr: io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := process(input, script.Transformer(tt.program, tt.opts...))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && got != tt.want {
+				t.Errorf("Process() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFactory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "clean.js")
+	if err := os.WriteFile(file, []byte("upper"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	compile := func(source string) (script.Program, error) {
+		if source != "upper" {
+			return nil, errors.New("syntax error")
+		}
+
+		return programFunc(func(globals map[string]any) (any, error) {
+			row := globals["row"].(map[string]any)
+			return map[string]any{"upper": strings.ToUpper(row["name"].(string))}, nil
+		}), nil
+	}
+
+	registry := csvprocessor.NewTransformerRegistry()
+	if err := registry.Register("script", script.Factory(compile), script.FactoryParams...); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, params := range []string{`{"source": "upper", "new_columns": ["upper"]}`, `{"file": "` + filepath.ToSlash(file) + `", "new_columns": ["upper"]}`} {
+		transformer, err := registry.New("script", json.RawMessage(params))
+		if err != nil {
+			t.Fatalf("New(%s) error = %v", params, err)
+		}
+
+		got, err := process("name\nalice\n", transformer)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		if want := "name,upper\nalice,ALICE\n"; got != want {
+			t.Errorf("Process() output = %q, want %q", got, want)
+		}
+	}
+
+	for _, params := range []string{`{}`, `{"source": "x", "file": "y"}`, `{"source": "lower"}`} {
+		if _, err := registry.New("script", json.RawMessage(params)); !errors.Is(err, csvprocessor.ErrInvalidTransformerParams) {
+			t.Errorf("New(%s) error = %v, want %v", params, err, csvprocessor.ErrInvalidTransformerParams)
+		}
+	}
+}
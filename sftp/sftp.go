@@ -0,0 +1,112 @@
+// Package sftp writes the output chunks to a SFTP server.
+//
+// The files are created, renamed and removed through the Client interface, a *sftp.Client of github.com/pkg/sftp
+// can implement it with Client.Create, Client.PosixRename and Client.Remove.
+package sftp
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// DefaultTempSuffix is appended to the path of a chunk while it is being written, unless changed using WithTempSuffix().
+const DefaultTempSuffix = ".part"
+
+// ErrAborted is returned by Close after the chunk is aborted.
+var ErrAborted = errors.New("sftp: chunk aborted")
+
+// Client represents the SFTP operations used by this package.
+type Client interface {
+	// Create creates or truncates the file at the given path and opens it for writing.
+	Create(path string) (io.WriteCloser, error)
+	// Rename renames the file, replacing the new path if it exists, Eg: using the posix-rename@openssh.com extension.
+	Rename(oldPath, newPath string) error
+	// Remove removes the file at the given path.
+	Remove(path string) error
+}
+
+// ChunkOption configures the files written by SFTPChunkGenerator.
+type ChunkOption func(*chunkConfig)
+
+type chunkConfig struct {
+	tempSuffix string
+}
+
+// WithTempSuffix sets the suffix of the temporary path to which a chunk is written before it is renamed, the default is DefaultTempSuffix.
+func WithTempSuffix(suffix string) ChunkOption {
+	return func(c *chunkConfig) {
+		c.tempSuffix = suffix
+	}
+}
+
+// SFTPChunkGenerator returns an OutputChunkGenerator that writes each chunk to the SFTP server.
+// The path of each file is generated from pathFormat using csvprocessor.ChunkName(), Eg: "/upload/orders_%03d.csv".
+// The chunk is written to a temporary path and renamed to its path when it is closed,
+// so that the partner never reads an incomplete file. If the processing fails, the temporary file is removed.
+func SFTPChunkGenerator(client Client, pathFormat string, opts ...ChunkOption) csvprocessor.OutputChunkGenerator { //nolint:revive
+	config := chunkConfig{tempSuffix: DefaultTempSuffix}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return func(chunkID int) (io.WriteCloser, error) {
+		path := csvprocessor.ChunkName(pathFormat, chunkID)
+		tempPath := path + config.tempSuffix
+
+		file, err := client.Create(tempPath)
+		if err != nil {
+			return nil, err
+		}
+
+		return &fileWriter{client: client, file: file, path: path, tempPath: tempPath}, nil
+	}
+}
+
+// fileWriter writes a chunk to the temporary path and renames it on Close.
+type fileWriter struct {
+	client   Client
+	file     io.WriteCloser
+	path     string
+	tempPath string
+
+	once sync.Once
+	err  error
+}
+
+func (f *fileWriter) Write(p []byte) (int, error) {
+	return f.file.Write(p)
+}
+
+// Close closes the temporary file and renames it to the path of the chunk.
+func (f *fileWriter) Close() error {
+	f.once.Do(func() {
+		if err := f.file.Close(); err != nil {
+			f.err = err
+			_ = f.client.Remove(f.tempPath)
+			return
+		}
+
+		if f.tempPath == f.path {
+			return
+		}
+
+		if err := f.client.Rename(f.tempPath, f.path); err != nil {
+			f.err = err
+			_ = f.client.Remove(f.tempPath)
+		}
+	})
+
+	return f.err
+}
+
+// Abort closes and removes the temporary file, it is called by the processor when the processing fails.
+func (f *fileWriter) Abort() {
+	f.once.Do(func() {
+		_ = f.file.Close()
+		_ = f.client.Remove(f.tempPath)
+		f.err = ErrAborted
+	})
+}
@@ -0,0 +1,151 @@
+package sftp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+	"github.com/sivaramasubramanian/csvprocessor/sftp"
+)
+
+// fakeSFTP is an in-memory Client, the content of a file is visible after it is closed.
+type fakeSFTP struct {
+	mu        sync.Mutex
+	files     map[string]string
+	renameErr error
+}
+
+func (f *fakeSFTP) Create(path string) (io.WriteCloser, error) {
+	return &fakeFile{fs: f, path: path}, nil
+}
+
+func (f *fakeSFTP) Rename(oldPath, newPath string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.renameErr != nil {
+		return f.renameErr
+	}
+
+	content, ok := f.files[oldPath]
+	if !ok {
+		return os.ErrNotExist
+	}
+
+	delete(f.files, oldPath)
+	f.files[newPath] = content
+	return nil
+}
+
+func (f *fakeSFTP) Remove(path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.files, path)
+	return nil
+}
+
+type fakeFile struct {
+	fs   *fakeSFTP
+	path string
+	buf  bytes.Buffer
+}
+
+func (f *fakeFile) Write(p []byte) (int, error) {
+	return f.buf.Write(p)
+}
+
+func (f *fakeFile) Close() error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	f.fs.files[f.path] = f.buf.String()
+	return nil
+}
+
+func process(tb testing.TB, generator csvprocessor.OutputChunkGenerator, opts ...csvprocessor.Option) error {
+	tb.Helper()
+
+	proc, err := csvprocessor.New(append([]csvprocessor.Option{
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader("id\n1\n2\n3\n"))),
+		csvprocessor.WithWriterGenerator(generator),
+		csvprocessor.WithChunkSize(2),
+	}, opts...)...)
+	if err != nil {
+		tb.Fatalf("New() error = %v", err)
+	}
+
+	return proc.Process()
+}
+
+func TestSFTPChunkGenerator(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []sftp.ChunkOption
+	}{
+		{"default temp suffix", nil},
+		{"custom temp suffix", []sftp.ChunkOption{sftp.WithTempSuffix(".tmp")}},
+		{"without temp file", []sftp.ChunkOption{sftp.WithTempSuffix("")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeSFTP{files: map[string]string{}}
+
+			if err := process(t, sftp.SFTPChunkGenerator(client, "/upload/orders_%02d.csv", tt.opts...)); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			want := map[string]string{
+				"/upload/orders_01.csv": "id\n1\n2\n",
+				"/upload/orders_02.csv": "id\n3\n",
+			}
+			if !reflect.DeepEqual(client.files, want) {
+				t.Errorf("files = %q, want %q", client.files, want)
+			}
+		})
+	}
+}
+
+func TestSFTPChunkGenerator_Abort(t *testing.T) {
+	client := &fakeSFTP{files: map[string]string{}}
+	errBadRow := errors.New("bad row")
+
+	err := process(t, sftp.SFTPChunkGenerator(client, "/upload/orders_%02d.csv"),
+		csvprocessor.WithTransformer(func(ctx context.Context, row []string) []string {
+			if row[0] == "3" {
+				panic(errBadRow)
+			}
+
+			return row
+		}),
+	)
+	if !errors.Is(err, errBadRow) {
+		t.Fatalf("Process() error = %v, want %v", err, errBadRow)
+	}
+
+	if len(client.files) != 0 {
+		t.Errorf("files = %q, want the partial chunk to be removed", client.files)
+	}
+}
+
+func TestSFTPChunkGenerator_RenameError(t *testing.T) {
+	errDenied := errors.New("permission denied")
+	client := &fakeSFTP{files: map[string]string{}, renameErr: errDenied}
+
+	if err := process(t, sftp.SFTPChunkGenerator(client, "/upload/orders_%02d.csv")); !errors.Is(err, errDenied) {
+		t.Fatalf("Process() error = %v, want %v", err, errDenied)
+	}
+
+	if len(client.files) != 0 {
+		t.Errorf("files = %q, want the temporary file to be removed", client.files)
+	}
+}
@@ -0,0 +1,203 @@
+package csvprocessor
+
+import (
+	"bufio"
+	"errors"
+	"hash/fnv"
+	"io"
+	"math"
+	"time"
+)
+
+var (
+	// ErrInvalidShards is returned when the no. of shards is not > 0, or the key column of WithShardByHash() is < 0.
+	ErrInvalidShards = errors.New("csvprocessor: no. of shards must be > 0 and the key column must be >= 0")
+	// ErrShardsNotSupported is returned when the shards are written to an archive (see WithZipOutput()) or chunked by time
+	// (see WithChunkByTimeColumn()), as they need all the shards to be open at the same time.
+	ErrShardsNotSupported = errors.New("csvprocessor: shards cannot be written to an archive or chunked by time")
+)
+
+// WithRoundRobin distributes the rows across the given no. of shards in turn, so that the shards have the same no. of rows
+// (or one less), Eg: to be read by parallel consumers. The chunk ID of a shard is its 1-based no., Eg:
+//
+//	csvprocessor.WithRoundRobin(4),
+//	csvprocessor.WithOutputFileFormat("orders_shard_%d.csv"), // orders_shard_1.csv ... orders_shard_4.csv
+//
+// All the shards are open while the rows are written, and every shard is written even if it has no rows.
+// The chunk size and the chunk interval are not used, and the shards cannot be written to an archive.
+// The rows filtered out by the transformers are not counted, so the shards are balanced by the rows written.
+func WithRoundRobin(shards int) Option {
+	return func(c *Processor) error {
+		if shards <= 0 {
+			return ErrInvalidShards
+		}
+
+		c.shards = shards
+		c.shardColumn = -1
+		if c.chunkSize == 0 {
+			c.chunkSize = math.MaxInt32
+		}
+
+		return nil
+	}
+}
+
+// WithShardByHash distributes the rows across the given no. of shards by the hash of the value in the 0-based key column,
+// so that all the rows with the same key are written to the same shard, Eg: for consumers that process the shards
+// independently but need all the rows of a customer. Eg:
+//
+//	csvprocessor.WithShardByHash(0, 8),
+//	csvprocessor.WithOutputFileFormat("orders_shard_%d.csv"),
+//
+// The shard of a key is the FNV-1a hash of the value modulo the no. of shards, so it is the same in every run with the same
+// no. of shards. The values are compared as they are, Eg: "A1" and "a1" can be in different shards.
+// The shards may not have the same no. of rows, see WithRoundRobin() for the other details of the shards.
+func WithShardByHash(column, shards int) Option {
+	return func(c *Processor) error {
+		if column < 0 {
+			return ErrInvalidShards
+		}
+
+		if err := WithRoundRobin(shards)(c); err != nil {
+			return err
+		}
+
+		c.shardColumn = column
+		return nil
+	}
+}
+
+// validShards checks that the options used with the shards can write to all of them at the same time.
+func (c *Processor) validShards() error {
+	if c.shards > 0 && (c.newArchive != nil || c.timeChunks != nil) {
+		return ErrShardsNotSupported
+	}
+
+	return nil
+}
+
+// chunkState is the state of an open chunk, saved while the rows are written to the other shards.
+type chunkState struct {
+	index       int // index of the chunk in Stats.Chunks.
+	id          int
+	opened      time.Time
+	outputFile  io.WriteCloser
+	fileWriter  CsvWriter
+	bytes       int64
+	hashes      *chunkHashes
+	writeBuffer *bufio.Writer
+	span        Span
+}
+
+// sharded reports whether the rows of the run are distributed across the shards.
+func (r *run) sharded() bool {
+	return r.c.shards > 0 && !r.out.singleChunk && r.out.sink == nil
+}
+
+// shardOf returns the 0-based shard of the row.
+func (r *run) shardOf(row []string) int {
+	if r.c.shardColumn < 0 {
+		return r.stats.RowsWritten % r.c.shards
+	}
+
+	return shardOfKey(valueAt(row, r.c.shardColumn), r.c.shards)
+}
+
+// shardOfKey returns the 0-based shard of the key, see WithShardByHash().
+func shardOfKey(key string, shards int) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key)) //nolint:errcheck
+	return int(hash.Sum32() % uint32(shards))
+}
+
+// openShards opens the chunks of all the shards, in the order of their IDs.
+func (r *run) openShards() error {
+	r.shards = make([]chunkState, r.c.shards)
+	for i := range r.shards {
+		r.shard = i
+		if err := r.openChunk(i + 1); err != nil {
+			return err
+		}
+
+		r.shards[i] = r.saveChunk()
+	}
+
+	return nil
+}
+
+// useShard makes the chunk of the given shard the current chunk, the shards are opened if needed.
+func (r *run) useShard(shard int) error {
+	if r.shards == nil {
+		if err := r.openShards(); err != nil {
+			return err
+		}
+	}
+
+	if shard == r.shard {
+		return nil
+	}
+
+	r.shards[r.shard] = r.saveChunk()
+	r.loadChunk(r.shards[shard])
+	r.shard = shard
+	return nil
+}
+
+func (r *run) saveChunk() chunkState {
+	return chunkState{
+		index:       r.chunkIndex,
+		id:          r.currentSplit,
+		opened:      r.chunkOpened,
+		outputFile:  r.outputFile,
+		fileWriter:  r.fileWriter,
+		bytes:       r.chunkBytes,
+		hashes:      r.chunkHashes,
+		writeBuffer: r.writeBuffer,
+		span:        r.chunkSpan,
+	}
+}
+
+func (r *run) loadChunk(state chunkState) {
+	r.chunkIndex = state.index
+	r.currentSplit = state.id
+	r.chunkOpened = state.opened
+	r.outputFile = state.outputFile
+	r.fileWriter = state.fileWriter
+	r.chunkBytes = state.bytes
+	r.chunkHashes = state.hashes
+	r.writeBuffer = state.writeBuffer
+	r.chunkSpan = state.span
+	r.ctx.setValue(CtxChunkNum, state.id)
+}
+
+// closeChunks closes the current chunk, or all the shards if the rows are sharded.
+func (r *run) closeChunks() error {
+	if r.shards == nil {
+		return r.closeChunk()
+	}
+
+	for i := range r.shards {
+		if err := r.useShard(i); err != nil {
+			return err
+		}
+
+		if err := r.closeChunk(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// abortChunks aborts the current chunk, or all the shards if the rows are sharded.
+func (r *run) abortChunks() {
+	if r.shards == nil {
+		r.abortChunk()
+		return
+	}
+
+	for i := range r.shards {
+		_ = r.useShard(i)
+		r.abortChunk()
+	}
+}
@@ -0,0 +1,166 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithRoundRobin(t *testing.T) {
+	dropRow3 := func(ctx context.Context, row []string) []string {
+		if row[0] == "3" {
+			return nil
+		}
+
+		return row
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		shards   int
+		opt      []csvprocessor.Option
+		want     []string
+		wantRows []int
+	}{
+		{
+			name:     "Test even shards",
+			input:    "id\n1\n2\n3\n4\n5\n6\n7\n",
+			shards:   3,
+			want:     []string{"id\n1\n4\n7\n", "id\n2\n5\n", "id\n3\n6\n"},
+			wantRows: []int{3, 2, 2},
+		},
+		{
+			name:     "Test filtered rows are not counted",
+			input:    "id\n1\n2\n3\n4\n5\n",
+			shards:   2,
+			opt:      []csvprocessor.Option{csvprocessor.WithTransformer(dropRow3)},
+			want:     []string{"id\n1\n4\n", "id\n2\n5\n"},
+			wantRows: []int{2, 2},
+		},
+		{
+			name:     "Test more shards than rows",
+			input:    "id\n1\n",
+			shards:   3,
+			want:     []string{"id\n1\n", "id\n", "id\n"},
+			wantRows: []int{1, 0, 0},
+		},
+		{
+			name:     "Test chunk row no. in each shard",
+			input:    "id\n1\n2\n3\n",
+			shards:   2,
+			opt:      []csvprocessor.Option{csvprocessor.WithTransformer(csvprocessor.AddChunkRowNoTransformer("n"))},
+			want:     []string{"n,id\n1,1\n2,3\n", "n,id\n1,2\n"},
+			wantRows: []int{2, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := make([]strings.Builder, tt.shards+1)
+			proc := newProcessor(t, strings.NewReader(tt.input), chunks, append(tt.opt, csvprocessor.WithRoundRobin(tt.shards))...)
+			if err := proc.Process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			for i := range tt.want {
+				if got := chunks[i].String(); got != tt.want[i] {
+					t.Errorf("shard %d = %q, want %q", i+1, got, tt.want[i])
+				}
+			}
+
+			if got := chunks[tt.shards].String(); got != "" {
+				t.Errorf("chunk %d = %q, want no more chunks", tt.shards+1, got)
+			}
+
+			stats := proc.Stats()
+			if len(stats.Chunks) != len(tt.wantRows) {
+				t.Fatalf("Stats().Chunks has %d chunks, want %d", len(stats.Chunks), len(tt.wantRows))
+			}
+
+			for i, rows := range tt.wantRows {
+				if chunk := stats.Chunks[i]; chunk.ID != i+1 || chunk.Rows != rows {
+					t.Errorf("Stats().Chunks[%d] = %+v, want ID %d with %d rows", i, chunk, i+1, rows)
+				}
+			}
+		})
+	}
+}
+
+func TestWithRoundRobin_Invalid(t *testing.T) {
+	if _, err := csvprocessor.New(csvprocessor.WithRoundRobin(0)); !errors.Is(err, csvprocessor.ErrInvalidShards) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidShards)
+	}
+
+	_, err := csvprocessor.New(
+		csvprocessor.WithInputReader(strings.NewReader("id\n1\n")),
+		csvprocessor.WithZipOutput(filepath.Join(t.TempDir(), "output.zip")),
+		csvprocessor.WithRoundRobin(2),
+	)
+	if !errors.Is(err, csvprocessor.ErrShardsNotSupported) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrShardsNotSupported)
+	}
+}
+
+func TestWithShardByHash(t *testing.T) {
+	var input strings.Builder
+	input.WriteString("customer,amount\n")
+	for i := 0; i < 60; i++ {
+		input.WriteString(string(rune('a'+i%12)) + "," + strconv.Itoa(i) + "\n")
+	}
+
+	process := func() []string {
+		chunks := make([]strings.Builder, 4)
+		proc := newProcessor(t, strings.NewReader(input.String()), chunks, csvprocessor.WithShardByHash(0, 4))
+		if err := proc.Process(); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		if len(proc.Stats().Chunks) != 4 {
+			t.Fatalf("Stats().Chunks has %d chunks, want 4", len(proc.Stats().Chunks))
+		}
+
+		shards := make([]string, len(chunks))
+		for i := range chunks {
+			shards[i] = chunks[i].String()
+		}
+
+		return shards
+	}
+
+	shards := process()
+	shardOf := map[string]int{}
+	rows := 0
+	for i, shard := range shards {
+		lines := strings.Split(strings.TrimSuffix(shard, "\n"), "\n")
+		if lines[0] != "customer,amount" {
+			t.Errorf("shard %d header = %q", i+1, lines[0])
+		}
+
+		for _, line := range lines[1:] {
+			rows++
+			key := strings.Split(line, ",")[0]
+			if other, ok := shardOf[key]; ok && other != i {
+				t.Errorf("key %q is in the shards %d and %d", key, other+1, i+1)
+			}
+
+			shardOf[key] = i
+		}
+	}
+
+	if rows != 60 || len(shardOf) != 12 {
+		t.Errorf("shards have %d rows with %d keys, want 60 rows with 12 keys", rows, len(shardOf))
+	}
+
+	if again := process(); strings.Join(again, "|") != strings.Join(shards, "|") {
+		t.Errorf("shards are different in another run: %q, want %q", again, shards)
+	}
+
+	if _, err := csvprocessor.New(csvprocessor.WithShardByHash(-1, 4)); !errors.Is(err, csvprocessor.ErrInvalidShards) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidShards)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build go1.21
+
+package csvprocessor
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithSlogLogger sets a log/slog logger for the processor, the log levels are mapped to the slog levels. See WithLeveledLogger().
+func WithSlogLogger(logger *slog.Logger) Option {
+	return WithLeveledLogger(slogLogger{logger})
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l slogLogger) Log(level LogLevel, msg string, keyvals ...any) {
+	l.logger.Log(context.Background(), slog.Level(level), msg, keyvals...)
+}
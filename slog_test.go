@@ -0,0 +1,51 @@
+//go:build go1.21
+
+package csvprocessor_test
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithSlogLogger(t *testing.T) {
+	var logs strings.Builder
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return attr
+		},
+	}))
+
+	proc, err := csvprocessor.New(
+		csvprocessor.WithInputReader(strings.NewReader("id,name\n1,a\n2\n")),
+		csvprocessor.WithChunkSize(10),
+		csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(io.Discard), nil
+		}),
+		csvprocessor.WithSlogLogger(logger),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	want := []string{
+		`level=DEBUG msg="0 rows processed \n"`,
+		`level=WARN msg="csvprocessor: row error" error="csvprocessor: row 2: record on line 3: wrong number of fields"`,
+		`level=INFO msg="2 total rows updated"`,
+	}
+	if got := strings.Split(strings.TrimSpace(logs.String()), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("logs = %q, want %q", got, want)
+	}
+}
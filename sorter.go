@@ -0,0 +1,466 @@
+package csvprocessor
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// SortOrder represents the order in which the rows are sorted by the Sorter.
+type SortOrder int
+
+const (
+	// Ascending sorts the rows in ascending order of the sort columns.
+	Ascending SortOrder = iota
+	// Descending sorts the rows in descending order of the sort columns.
+	Descending
+)
+
+// DefaultSortRunSize represents the default no. of rows that are sorted in memory before being spilled to a temp file.
+const DefaultSortRunSize = 100_000
+
+// sortFanIn is the max. no. of runs merged at once, more runs are merged in passes to bound the open files.
+const sortFanIn = 64
+
+// Sorter is a CsvReader that returns the rows of the underlying reader sorted by the given columns.
+// Inputs larger than memory are sorted by spilling sorted runs of rows to temp files and merging them,
+// at most 64 runs are merged at once. The runs are written in a binary format, so the rows are returned exactly as they are read.
+// Values are compared as strings, rows with equal keys retain their order from the input.
+//
+// The temp files are created in os.TempDir() and are removed by Close().
+type Sorter struct {
+	reader    CsvReader
+	columns   []int
+	order     SortOrder
+	runSize   int
+	hasHeader bool
+
+	// Unexported fields
+	header  []string   // header row, returned before the sorted rows.
+	sorted  bool       // whether the input has been sorted.
+	pending [][]string // sorted rows when the entire input fits in a single run.
+	runs    []string   // paths of the temp files with the sorted runs.
+	files   []*os.File // open runs, merged by merger.
+	merger  *runMerger // merges the sorted runs.
+	err     error      // error encountered while sorting.
+
+	budget *memoryBudget // bounds the rows of a run, see WithMaxMemory().
+}
+
+// NewSorter creates a Sorter that sorts the rows from reader by the given columns.
+// runSize is the max no. of rows held in memory, use DefaultSortRunSize if unsure.
+// If hasHeader is true, the first row is treated as header and returned as the first row without sorting.
+func NewSorter(reader CsvReader, columns []int, order SortOrder, runSize int, hasHeader bool) *Sorter {
+	if runSize <= 0 {
+		runSize = DefaultSortRunSize
+	}
+
+	return &Sorter{
+		reader:    reader,
+		columns:   columns,
+		order:     order,
+		runSize:   runSize,
+		hasHeader: hasHeader,
+	}
+}
+
+// Read returns the next row in sorted order.
+// The entire input is consumed on the first call to Read.
+func (s *Sorter) Read() ([]string, error) {
+	if !s.sorted {
+		s.sorted = true
+		s.err = s.sort()
+		if s.header != nil {
+			return s.header, s.err
+		}
+	}
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	if s.merger != nil {
+		return s.merger.next()
+	}
+
+	if len(s.pending) == 0 {
+		return nil, io.EOF
+	}
+
+	row := s.pending[0]
+	s.pending = s.pending[1:]
+	return row, nil
+}
+
+// Close removes the temp files created by the Sorter.
+func (s *Sorter) Close() error {
+	firstErr := closeRuns(s.files)
+	for _, run := range s.runs {
+		if err := os.Remove(run); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	s.files, s.runs = nil, nil
+	return firstErr
+}
+
+func (s *Sorter) sort() error {
+	rows := make([][]string, 0, s.runSize)
+	var runBytes int64 // estimated size of the rows reserved from the budget.
+	for {
+		row, err := readWaiting(s.reader)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// readers can reuse the row slice, so a copy is retained.
+		row = append([]string(nil), row...)
+		if s.hasHeader && s.header == nil {
+			s.header = row
+			continue
+		}
+
+		// the run is spilled when it reaches runSize rows, or when the row does not fit within the memory limit.
+		size := rowSize(row)
+		fits := s.budget.reserve(size)
+		if fits {
+			runBytes += size
+		}
+
+		rows = append(rows, row)
+		if len(rows) == s.runSize || !fits {
+			if err := s.spill(rows); err != nil {
+				return err
+			}
+
+			rows = rows[:0]
+			s.budget.release(runBytes)
+			runBytes = 0
+		}
+	}
+
+	if len(s.runs) == 0 {
+		s.sortRows(rows)
+		s.pending = rows
+		return nil
+	}
+
+	if len(rows) > 0 {
+		if err := s.spill(rows); err != nil {
+			return err
+		}
+	}
+
+	return s.mergeRuns()
+}
+
+// spill sorts the rows and writes them to a new temp file.
+func (s *Sorter) spill(rows [][]string) error {
+	s.sortRows(rows)
+
+	i := 0
+	return s.writeRun(func() ([]string, error) {
+		if i == len(rows) {
+			return nil, io.EOF
+		}
+
+		i++
+		return rows[i-1], nil
+	})
+}
+
+// writeRun writes the rows returned by next till io.EOF to a new temp file, and adds it to the runs.
+func (s *Sorter) writeRun(next func() ([]string, error)) error {
+	file, err := os.CreateTemp("", "csvprocessor_sort_*.run")
+	if err != nil {
+		return fmt.Errorf("csvprocessor: error while creating temp file for sorting: %w", err)
+	}
+
+	s.runs = append(s.runs, file.Name())
+	writer := newRunWriter(file)
+	for {
+		row, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			_ = file.Close()
+			return err
+		}
+
+		writer.write(row)
+	}
+
+	if err := writer.Flush(); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("csvprocessor: error while writing sorted rows to temp file: %w", err)
+	}
+
+	return file.Close()
+}
+
+// mergeRuns merges the runs in passes of sortFanIn runs till they can be merged at once by merger.
+func (s *Sorter) mergeRuns() error {
+	for len(s.runs) > sortFanIn {
+		runs := s.runs
+		s.runs = nil
+		for start := 0; start < len(runs); start += sortFanIn {
+			// the runs are merged in order, so that the rows with equal keys retain their order.
+			end := start + sortFanIn
+			if end > len(runs) {
+				end = len(runs)
+			}
+
+			if err := s.mergePass(runs[start:end]); err != nil {
+				s.runs = append(s.runs, runs[start:]...)
+				return err
+			}
+		}
+	}
+
+	merger, files, err := s.openRuns(s.runs)
+	s.files = files
+	s.merger = merger
+	return err
+}
+
+// mergePass merges the given runs into a new run and removes them.
+func (s *Sorter) mergePass(runs []string) error {
+	merger, files, err := s.openRuns(runs)
+	if err == nil {
+		err = s.writeRun(merger.next)
+	}
+
+	if closeErr := closeRuns(files); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, run := range runs {
+		if err := os.Remove(run); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// openRuns opens the runs and returns the merger of their rows, the files are returned even on errors to be closed.
+func (s *Sorter) openRuns(runs []string) (*runMerger, []*os.File, error) {
+	merger := &runMerger{less: s.less}
+	files := make([]*os.File, 0, len(runs))
+	for i, run := range runs {
+		file, err := os.Open(run)
+		if err != nil {
+			return nil, files, fmt.Errorf("csvprocessor: error while reading sorted rows from temp file: %w", err)
+		}
+
+		files = append(files, file)
+		if err := merger.add(i, newRunReader(file)); err != nil {
+			return nil, files, err
+		}
+	}
+
+	heap.Init(merger)
+	return merger, files, nil
+}
+
+func closeRuns(files []*os.File) error {
+	var firstErr error
+	for _, file := range files {
+		if err := file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+func (s *Sorter) sortRows(rows [][]string) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		return s.less(rows[i], rows[j])
+	})
+}
+
+func (s *Sorter) less(a, b []string) bool {
+	for _, column := range s.columns {
+		x, y := valueAt(a, column), valueAt(b, column)
+		if x == y {
+			continue
+		}
+
+		if s.order == Descending {
+			return x > y
+		}
+
+		return x < y
+	}
+
+	return false
+}
+
+// runMerger is a min-heap of the sorted runs used to do a k-way merge.
+type runMerger struct {
+	cursors []*runCursor
+	less    func(a, b []string) bool
+}
+
+// runCursor points to the current row of a sorted run.
+type runCursor struct {
+	id     int
+	row    []string
+	reader *runReader
+}
+
+func (m *runMerger) add(id int, reader *runReader) error {
+	row, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	m.cursors = append(m.cursors, &runCursor{id: id, row: row, reader: reader})
+	return nil
+}
+
+func (m *runMerger) next() ([]string, error) {
+	if len(m.cursors) == 0 {
+		return nil, io.EOF
+	}
+
+	cursor := m.cursors[0]
+	row := cursor.row
+
+	next, err := cursor.reader.Read()
+	switch {
+	case errors.Is(err, io.EOF):
+		heap.Pop(m)
+	case err != nil:
+		return nil, err
+	default:
+		cursor.row = next
+		heap.Fix(m, 0)
+	}
+
+	return row, nil
+}
+
+func (m *runMerger) Len() int { return len(m.cursors) }
+
+func (m *runMerger) Less(i, j int) bool {
+	a, b := m.cursors[i], m.cursors[j]
+	if m.less(a.row, b.row) {
+		return true
+	}
+
+	if m.less(b.row, a.row) {
+		return false
+	}
+
+	// rows with equal keys are returned in the order of the runs to keep the sort stable.
+	return a.id < b.id
+}
+
+func (m *runMerger) Swap(i, j int) { m.cursors[i], m.cursors[j] = m.cursors[j], m.cursors[i] }
+
+func (m *runMerger) Push(x any) { m.cursors = append(m.cursors, x.(*runCursor)) } //nolint:forcetypeassert
+
+func (m *runMerger) Pop() any {
+	last := m.cursors[len(m.cursors)-1]
+	m.cursors = m.cursors[:len(m.cursors)-1]
+	return last
+}
+
+// runWriter writes the rows of a sorted run, each row is written as the no. of fields followed by
+// the length and the bytes of each field, so that any value is read back exactly as it was written.
+type runWriter struct {
+	*bufio.Writer
+	size [binary.MaxVarintLen64]byte
+}
+
+func newRunWriter(w io.Writer) *runWriter {
+	return &runWriter{Writer: bufio.NewWriter(w)}
+}
+
+// write buffers the row, the errors are returned by Flush().
+func (w *runWriter) write(row []string) {
+	w.writeSize(len(row))
+	for _, field := range row {
+		w.writeSize(len(field))
+		_, _ = w.WriteString(field)
+	}
+}
+
+func (w *runWriter) writeSize(n int) {
+	_, _ = w.Write(w.size[:binary.PutUvarint(w.size[:], uint64(n))])
+}
+
+// runReader reads the rows written by runWriter.
+type runReader struct {
+	r *bufio.Reader
+}
+
+func newRunReader(r io.Reader) *runReader {
+	return &runReader{r: bufio.NewReader(r)}
+}
+
+// Read returns the next row, or io.EOF at the end of the run.
+func (r *runReader) Read() ([]string, error) {
+	fields, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		// io.EOF is returned only if no bytes of the row were read.
+		return nil, err
+	}
+
+	row := make([]string, fields)
+	for i := range row {
+		size, err := binary.ReadUvarint(r.r)
+		if err != nil {
+			return nil, runError(err)
+		}
+
+		field := make([]byte, size)
+		if _, err := io.ReadFull(r.r, field); err != nil {
+			return nil, runError(err)
+		}
+
+		row[i] = string(field)
+	}
+
+	return row, nil
+}
+
+// runError reports a run that ends within a row as truncated.
+func runError(err error) error {
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return fmt.Errorf("csvprocessor: error while reading sorted rows from temp file: %w", err)
+}
+
+// valueAt returns the value at the given index of the row or an empty string if the index is out of range.
+func valueAt(row []string, index int) string {
+	if index < 0 || index >= len(row) {
+		return ""
+	}
+
+	return row[index]
+}
@@ -0,0 +1,158 @@
+package csvprocessor_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+const unsortedCSV = `id,name
+3,c
+1,a
+4,d
+1,b
+2,e
+`
+
+func TestSorter(t *testing.T) {
+	type args struct {
+		columns   []int
+		order     csvprocessor.SortOrder
+		runSize   int
+		hasHeader bool
+	}
+	tests := []struct {
+		name string
+		args args
+		want [][]string
+	}{
+		{
+			name: "Test in-memory sort",
+			args: args{columns: []int{0}, order: csvprocessor.Ascending, runSize: 100, hasHeader: true},
+			want: [][]string{{"id", "name"}, {"1", "a"}, {"1", "b"}, {"2", "e"}, {"3", "c"}, {"4", "d"}},
+		},
+		{
+			name: "Test external sort with multiple runs",
+			args: args{columns: []int{0}, order: csvprocessor.Ascending, runSize: 2, hasHeader: true},
+			want: [][]string{{"id", "name"}, {"1", "a"}, {"1", "b"}, {"2", "e"}, {"3", "c"}, {"4", "d"}},
+		},
+		{
+			name: "Test descending sort with multiple runs",
+			args: args{columns: []int{0, 1}, order: csvprocessor.Descending, runSize: 2, hasHeader: true},
+			want: [][]string{{"id", "name"}, {"4", "d"}, {"3", "c"}, {"2", "e"}, {"1", "b"}, {"1", "a"}},
+		},
+		{
+			name: "Test sort without header",
+			args: args{columns: []int{1}, order: csvprocessor.Ascending, runSize: 3, hasHeader: false},
+			want: [][]string{{"1", "a"}, {"1", "b"}, {"3", "c"}, {"4", "d"}, {"2", "e"}, {"id", "name"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorter := csvprocessor.NewSorter(csv.NewReader(strings.NewReader(unsortedCSV)), tt.args.columns, tt.args.order, tt.args.runSize, tt.args.hasHeader)
+			defer sorter.Close()
+
+			actual := readAll(t, sorter)
+			if !reflect.DeepEqual(actual, tt.want) {
+				t.Errorf("Sorter.Read() = %v, want %v", actual, tt.want)
+			}
+		})
+	}
+}
+
+func TestSorter_Runs(t *testing.T) {
+	many := make([][]string, 200)
+	for i := range many {
+		many[i] = []string{strconv.Itoa(i % 10), strconv.Itoa(i)}
+	}
+
+	manySorted := append([][]string(nil), many...)
+	sort.SliceStable(manySorted, func(i, j int) bool { return manySorted[i][0] < manySorted[j][0] })
+
+	tests := []struct {
+		name string
+		rows [][]string
+		want [][]string
+	}{
+		{
+			name: "Test values are read back from the runs as they are",
+			rows: [][]string{{"b\r\nx"}, {""}, nil, {"a", ""}, {`c"`, "d,e"}},
+			want: [][]string{{""}, nil, {"a", ""}, {"b\r\nx"}, {`c"`, "d,e"}},
+		},
+		{
+			name: "Test more runs than merged at once",
+			rows: many,
+			want: manySorted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := tt.rows
+			reader := readerFunc(func() ([]string, error) {
+				if len(rows) == 0 {
+					return nil, io.EOF
+				}
+
+				row := rows[0]
+				rows = rows[1:]
+				return row, nil
+			})
+
+			sorter := csvprocessor.NewSorter(reader, []int{0}, csvprocessor.Ascending, 1, false)
+			defer sorter.Close()
+
+			actual := readAll(t, sorter)
+			if !reflect.DeepEqual(actual, tt.want) {
+				t.Errorf("Sorter.Read() = %q, want %q", actual, tt.want)
+			}
+		})
+	}
+}
+
+// readerFunc is a CsvReader that returns the rows returned by the function.
+type readerFunc func() ([]string, error)
+
+func (f readerFunc) Read() ([]string, error) {
+	return f()
+}
+
+func TestProcessor_WithSortBy(t *testing.T) {
+	output, err := processString(t, unsortedCSV,
+		csvprocessor.WithSortBy([]int{1}, csvprocessor.Descending),
+		csvprocessor.WithSortRunSize(2),
+	)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	want := "id,name\n2,e\n4,d\n3,c\n1,b\n1,a\n"
+	if output != want {
+		t.Errorf("Process() output = %q, want %q", output, want)
+	}
+}
+
+// readAll reads all the rows from the reader till EOF.
+func readAll(tb testing.TB, reader csvprocessor.CsvReader) [][]string {
+	tb.Helper()
+
+	var rows [][]string
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows
+		}
+
+		if err != nil {
+			tb.Fatalf("Read() error = %v", err)
+		}
+
+		rows = append(rows, append([]string(nil), row...))
+	}
+}
@@ -0,0 +1,195 @@
+package csvprocessor
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidSQLDialect is returned when the SQL dialect of WithSQLDialect() is not one of the SQLDialect constants.
+var ErrInvalidSQLDialect = errors.New("csvprocessor: invalid SQL dialect")
+
+// DefaultSQLBatchSize is the default no. of rows in each INSERT statement written by SQLWriter.
+const DefaultSQLBatchSize = 100
+
+// SQLDialect is the syntax used to quote the names and the values in the SQL statements, see WithSQLDialect().
+type SQLDialect int
+
+const (
+	// SQLStandard quotes the names with double quotes and the values with single quotes, which are escaped by doubling them,
+	// as per the SQL standard. Eg: for PostgreSQL, SQLite and Oracle.
+	SQLStandard SQLDialect = iota
+	// SQLMySQL quotes the names with backticks and escapes the backslashes and the single quotes of the values with a backslash,
+	// Eg: for MySQL and MariaDB, unless the NO_BACKSLASH_ESCAPES mode is set.
+	SQLMySQL
+	// SQLServer quotes the names with square brackets and the values like SQLStandard, Eg: for Microsoft SQL Server.
+	SQLServer
+	// SQLPostgres quotes the names and the values like SQLStandard, DBWriter uses the $1 placeholders of PostgreSQL.
+	SQLPostgres
+	// SQLOracle quotes the names and the values like SQLStandard, DBWriter uses the :1 placeholders of Oracle.
+	SQLOracle
+)
+
+// mysqlEscaper escapes the characters that MySQL interprets in the string literals.
+var mysqlEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\x00", `\0`, "\x1a", `\Z`)
+
+// quoteIdentifier quotes the table or column name.
+func (d SQLDialect) quoteIdentifier(name string) string {
+	switch d {
+	case SQLMySQL:
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	case SQLServer:
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+	default:
+		return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	}
+}
+
+// quoteTableName quotes each part of the table name, Eg: public.users as "public"."users".
+func (d SQLDialect) quoteTableName(table string) string {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = d.quoteIdentifier(part)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// quoteColumns returns the quoted column list of the INSERT statements, or an empty string if there are no columns.
+func (d SQLDialect) quoteColumns(columns []string) string {
+	if columns == nil {
+		return ""
+	}
+
+	quoted := make([]string, len(columns))
+	for i, name := range columns {
+		quoted[i] = d.quoteIdentifier(name)
+	}
+
+	return " (" + strings.Join(quoted, ", ") + ")"
+}
+
+// placeholder returns the placeholder of the i-th (1-based) argument of a prepared statement,
+// ? for the dialects without numbered placeholders and @p1 for SQL Server.
+func (d SQLDialect) placeholder(i int) string {
+	switch d {
+	case SQLPostgres:
+		return "$" + strconv.Itoa(i)
+	case SQLServer:
+		return "@p" + strconv.Itoa(i)
+	case SQLOracle:
+		return ":" + strconv.Itoa(i)
+	default:
+		return "?"
+	}
+}
+
+// quoteValue returns the value as a string literal.
+func (d SQLDialect) quoteValue(value string) string {
+	if d == SQLMySQL {
+		return "'" + mysqlEscaper.Replace(value) + "'"
+	}
+
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// SQLWriter is a CsvWriter that writes the rows as batched INSERT statements, with the header as the column names.
+// The values are written as string literals and the table and column names are quoted as per the Dialect. Eg:
+//
+//	INSERT INTO "users" ("id", "name") VALUES
+//	('1', 'alice'),
+//	('2', 'O''Brien');
+//
+// Close() must be called to complete the last statement.
+type SQLWriter struct {
+	w         *bufio.Writer
+	table     string
+	batchSize int
+
+	// Dialect is the syntax used to quote the names and the values, the default is SQLStandard.
+	// It must be set before the header or the first row is written.
+	Dialect SQLDialect
+
+	// Unexported fields
+	header []string // column names, nil if there is no header.
+	rows   int      // no. of rows in the current statement.
+	err    error
+}
+
+// NewSQLWriter creates a SQLWriter that inserts into the given table, with at most batchSize rows per statement.
+// The table name can be qualified with the schema, Eg: "public.users". If batchSize is <= 0, DefaultSQLBatchSize is used.
+func NewSQLWriter(w io.Writer, table string, batchSize int) *SQLWriter {
+	if batchSize <= 0 {
+		batchSize = DefaultSQLBatchSize
+	}
+
+	return &SQLWriter{w: bufio.NewWriter(w), table: table, batchSize: batchSize}
+}
+
+// WriteHeader sets the column names of the INSERT statements.
+// If WriteHeader is not called, the column names are not included in the statements.
+func (s *SQLWriter) WriteHeader(header []string) error {
+	s.header = append([]string{}, header...)
+	return nil
+}
+
+// Write adds the row to the current INSERT statement, a new statement is started once the batch is full.
+func (s *SQLWriter) Write(record []string) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	var buf strings.Builder
+	if s.rows == 0 {
+		buf.WriteString("INSERT INTO ")
+		buf.WriteString(s.Dialect.quoteTableName(s.table))
+		buf.WriteString(s.Dialect.quoteColumns(s.header))
+		buf.WriteString(" VALUES\n(")
+	} else {
+		buf.WriteString(",\n(")
+	}
+
+	for i, value := range record {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+
+		buf.WriteString(s.Dialect.quoteValue(value))
+	}
+
+	buf.WriteByte(')')
+
+	s.rows++
+	if s.rows >= s.batchSize {
+		buf.WriteString(";\n")
+		s.rows = 0
+	}
+
+	_, s.err = s.w.WriteString(buf.String())
+	return s.err
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (s *SQLWriter) Flush() {
+	if s.err == nil {
+		s.err = s.w.Flush()
+	}
+}
+
+// Error reports any error that has occurred during a previous Write or Flush.
+func (s *SQLWriter) Error() error {
+	return s.err
+}
+
+// Close completes the last INSERT statement and flushes the writer, the underlying writer is not closed.
+func (s *SQLWriter) Close() error {
+	if s.err == nil && s.rows > 0 {
+		_, s.err = s.w.WriteString(";\n")
+		s.rows = 0
+	}
+
+	s.Flush()
+	return s.err
+}
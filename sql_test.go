@@ -0,0 +1,76 @@
+package csvprocessor_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithSQLOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opt   []csvprocessor.Option
+		want  string
+	}{
+		{
+			name:  "Test single statement",
+			input: "id,name\n1,alice\n2,O'Brien\n",
+			opt:   []csvprocessor.Option{csvprocessor.WithSQLOutput("users", 10)},
+			want:  "INSERT INTO \"users\" (\"id\", \"name\") VALUES\n('1', 'alice'),\n('2', 'O''Brien');\n",
+		},
+		{
+			name:  "Test batches",
+			input: "id\n1\n2\n3\n",
+			opt:   []csvprocessor.Option{csvprocessor.WithSQLOutput("public.t", 2)},
+			want:  "INSERT INTO \"public\".\"t\" (\"id\") VALUES\n('1'),\n('2');\nINSERT INTO \"public\".\"t\" (\"id\") VALUES\n('3');\n",
+		},
+		{
+			name:  "Test full last batch",
+			input: "id\n1\n2\n",
+			opt:   []csvprocessor.Option{csvprocessor.WithSQLOutput("t", 2)},
+			want:  "INSERT INTO \"t\" (\"id\") VALUES\n('1'),\n('2');\n",
+		},
+		{
+			name:  "Test quoted identifiers without header",
+			input: "1,a\n",
+			opt:   []csvprocessor.Option{csvprocessor.WithSQLOutput(`my"table`, 0), csvprocessor.SkipHeaders(true)},
+			want:  "INSERT INTO \"my\"\"table\" VALUES\n('1', 'a');\n",
+		},
+		{
+			name:  "Test MySQL escaping",
+			input: "id,note\n1,O'Brien\\\n",
+			opt:   []csvprocessor.Option{csvprocessor.WithSQLOutput("db.t`1", 10), csvprocessor.WithSQLDialect(csvprocessor.SQLMySQL)},
+			want:  "INSERT INTO `db`.`t``1` (`id`, `note`) VALUES\n('1', 'O\\'Brien\\\\');\n",
+		},
+		{
+			name:  "Test SQL Server identifiers",
+			input: "id\n1\n",
+			opt:   []csvprocessor.Option{csvprocessor.WithSQLDialect(csvprocessor.SQLServer), csvprocessor.WithSQLOutput("dbo.t]", 10)},
+			want:  "INSERT INTO [dbo].[t]]] ([id]) VALUES\n('1');\n",
+		},
+		{
+			name:  "Test no rows",
+			input: "id\n",
+			opt:   []csvprocessor.Option{csvprocessor.WithSQLOutput("t", 2)},
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processString(t, tt.input, tt.opt...)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+
+	if _, err := csvprocessor.New(csvprocessor.WithSQLDialect(csvprocessor.SQLDialect(-1))); !errors.Is(err, csvprocessor.ErrInvalidSQLDialect) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidSQLDialect)
+	}
+}
@@ -0,0 +1,85 @@
+package csvprocessor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTransformerPanic is returned (wrapped in a *RowError) when the transformer panics with a value that is not an error.
+var ErrTransformerPanic = errors.New("csvprocessor: transformer panicked")
+
+// Stats represents the summary of a Process() run.
+type Stats struct {
+	// RowsRead is the no. of data rows read from the input, the header row is not counted.
+	RowsRead int
+	// RowsWritten is the no. of data rows written to the output, rows dropped by the transformer are not counted.
+	RowsWritten int
+	// RowsRejected is the no. of rows written to the rejects writer, see WithRejectWriter().
+	RowsRejected int
+	// Chunks contains the details of each output chunk in the order they were written.
+	Chunks []ChunkInfo
+	// Errors contains the errors in the input that did not stop the processing.
+	Errors []error
+	// Footer contains the footer rows of the inputs, see WithFooterRows().
+	Footer [][]string
+	// Columns contains the stats of each column of the output, it is nil unless WithColumnStats() is used.
+	Columns []ColumnStats
+	// DataProfile is the profiling report of the output, it is nil unless WithDataProfile() is used.
+	DataProfile *DataProfile
+	// Profile is the breakdown of the time spent in each phase, it is nil unless WithProfile(true) is used.
+	Profile *Profile
+}
+
+// ChunkInfo represents the details of an output chunk.
+type ChunkInfo struct {
+	// ID is the chunk ID passed to the OutputChunkGenerator.
+	ID int
+	// FirstRow and LastRow are the overall row numbers (see CtxRowNum) of the first and last rows in the chunk.
+	FirstRow int
+	LastRow  int
+	// Rows is the no. of data rows written to the chunk.
+	Rows int
+	// Bytes is the no. of bytes written to the output of the chunk, it is set when the chunk is closed.
+	Bytes int64
+	// Name is the name of the chunk, Eg: the file name for WithOutputFileFormat() or the entry name for WithZipOutput().
+	// It is empty if the writer returned by the OutputChunkGenerator does not have a `Name() string` method.
+	Name string
+	// Checksums contains the hex encoded digests of the chunk by the name of the algorithm, see WithChunkChecksums().
+	// It is set when the chunk is closed.
+	Checksums map[string]string
+	// Window is the start of the time window of the rows in the chunk, see WithChunkByTimeColumn().
+	Window time.Time
+}
+
+// RowError represents an error in a particular row of the input.
+type RowError struct {
+	// Row is the overall row number (see CtxRowNum) of the row.
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("csvprocessor: row %d: %v", e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Stats returns the summary of the last Process() run.
+func (c *Processor) Stats() Stats {
+	return c.stats
+}
+
+// logDryRun logs the summary of a dry run.
+func (c *Processor) logDryRun(stats Stats) {
+	c.log.Log(LogInfo, "csvprocessor: dry run", "rows_read", stats.RowsRead, "rows_written", stats.RowsWritten, "chunks", len(stats.Chunks), "errors", len(stats.Errors))
+	for _, chunk := range stats.Chunks {
+		c.log.Log(LogInfo, "csvprocessor: dry run chunk", "chunk", chunk.ID, "rows", chunk.Rows, "first_row", chunk.FirstRow, "last_row", chunk.LastRow)
+	}
+
+	for _, err := range stats.Errors {
+		c.log.Log(LogWarn, "csvprocessor: dry run row error", "error", err)
+	}
+}
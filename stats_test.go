@@ -0,0 +1,66 @@
+package csvprocessor_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestProcessor_DryRun(t *testing.T) {
+	proc, err := csvprocessor.New(
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader(numbersCSV))),
+		csvprocessor.WithChunkSize(4),
+		csvprocessor.WithDryRun(true),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	want := csvprocessor.Stats{
+		RowsRead:    6,
+		RowsWritten: 6,
+		Chunks: []csvprocessor.ChunkInfo{
+			{ID: 1, FirstRow: 1, LastRow: 4, Rows: 4, Bytes: 10},
+			{ID: 2, FirstRow: 5, LastRow: 6, Rows: 2, Bytes: 6},
+		},
+	}
+	if stats := proc.Stats(); !reflect.DeepEqual(stats, want) {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestProcessor_StatsErrors(t *testing.T) {
+	proc, err := csvprocessor.New(
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader("a,b\n1,2\n3,4,5\n1,2\n"))),
+		csvprocessor.WithChunkSize(4),
+		csvprocessor.WithDryRun(true),
+		csvprocessor.WithTransformer(csvprocessor.DedupTransformer()),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	stats := proc.Stats()
+	if stats.RowsRead != 3 || stats.RowsWritten != 2 {
+		t.Errorf("Stats() rows read = %d, written = %d, want 3 and 2", stats.RowsRead, stats.RowsWritten)
+	}
+
+	var rowErr *csvprocessor.RowError
+	if len(stats.Errors) != 1 || !errors.As(stats.Errors[0], &rowErr) || rowErr.Row != 2 {
+		t.Errorf("Stats() errors = %v, want 1 error in row 2", stats.Errors)
+	}
+}
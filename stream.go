@@ -0,0 +1,203 @@
+package csvprocessor
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNoMessage is returned by MessageReader when no message arrives within its PollInterval.
+// The processor does not stop on this error, it rotates the chunk if the interval set by WithChunkInterval() has elapsed and reads again.
+// The options that read all the rows before returning any (Eg: WithSortBy(), Tail(), WithAggregation()) wait till the stream ends.
+// Custom readers that wait for rows can return it for the same purpose.
+var ErrNoMessage = errors.New("csvprocessor: no message received")
+
+// readWaiting reads the next row, waiting through the ErrNoMessage of an idle stream,
+// it is used by the readers that read all the rows before returning any.
+func readWaiting(reader CsvReader) ([]string, error) {
+	for {
+		row, err := reader.Read()
+		if !errors.Is(err, ErrNoMessage) {
+			return row, err
+		}
+	}
+}
+
+// MessageFormat is the format of the messages read by MessageReader.
+type MessageFormat int
+
+const (
+	// MessageCSV is a message with a single CSV line.
+	MessageCSV MessageFormat = iota
+	// MessageJSON is a message with a JSON object, read like a line of JSONLinesReader.
+	MessageJSON
+)
+
+// MessageSource returns the messages to be processed, Eg: from a Kafka consumer or a NATS subscription.
+type MessageSource interface {
+	// Receive returns the next message, waiting till one arrives or ctx is done.
+	// It returns io.EOF when there are no more messages, Eg: the subscription is closed.
+	Receive(ctx context.Context) ([]byte, error)
+}
+
+// ChannelSource returns a MessageSource that receives the messages from the channel, it returns io.EOF when the channel is closed.
+func ChannelSource(ch <-chan []byte) MessageSource {
+	return channelSource(ch)
+}
+
+type channelSource <-chan []byte
+
+func (ch channelSource) Receive(ctx context.Context) ([]byte, error) {
+	select {
+	case message, ok := <-ch:
+		if !ok {
+			return nil, io.EOF
+		}
+
+		return message, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// MessageReader is a CsvReader that reads a row from each message of a MessageSource,
+// so that a stream of messages can be archived as chunks, see WithChunkInterval().
+// Messages that cannot be parsed are returned as *csv.ParseError, so that the processor records and skips them.
+// CSV messages are split using the delimiter set by WithInputDelimiter().
+type MessageReader struct {
+	// PollInterval is the max. time Read waits for a message before returning ErrNoMessage,
+	// so that the processor can rotate the chunks while the stream is idle. Read waits indefinitely if it is 0.
+	PollInterval time.Duration
+
+	source MessageSource
+	format MessageFormat
+	comma  rune // delimiter of the CSV messages, the input delimiter of the processor.
+
+	// Unexported fields
+	header     []string
+	index      map[string]int // index of each column in the header, for JSON messages.
+	headerRead bool
+	pending    []string // first JSON row, read to derive the header.
+	messages   int      // no. of messages received.
+}
+
+// NewMessageReader creates a MessageReader, if columns are given, they are returned as the header before the rows.
+// Without columns, the header of JSON messages is the keys of the first message, and CSV messages are returned as they are.
+func NewMessageReader(source MessageSource, format MessageFormat, columns ...string) *MessageReader {
+	m := &MessageReader{source: source, format: format, comma: ','}
+	if len(columns) > 0 {
+		m.setHeader(columns)
+	}
+
+	return m
+}
+
+func (m *MessageReader) setHeader(columns []string) {
+	m.header = append([]string(nil), columns...)
+	m.index = make(map[string]int, len(columns))
+	for i, column := range columns {
+		if _, ok := m.index[column]; !ok {
+			m.index[column] = i
+		}
+	}
+}
+
+// Read returns the header first, if any, and then a row for each message.
+func (m *MessageReader) Read() ([]string, error) {
+	if !m.headerRead {
+		if m.header == nil && m.format == MessageJSON {
+			keys, values, err := m.readJSON(false)
+			if err != nil {
+				return nil, err
+			}
+
+			m.setHeader(keys)
+			m.pending = values
+		}
+
+		m.headerRead = true
+		if m.header != nil {
+			return append([]string(nil), m.header...), nil
+		}
+	}
+
+	if m.pending != nil {
+		row := m.pending
+		m.pending = nil
+		return row, nil
+	}
+
+	if m.format == MessageCSV {
+		return m.readCSV()
+	}
+
+	keys, values, err := m.readJSON(true)
+	if err != nil {
+		return nil, err
+	}
+
+	row := make([]string, len(m.header))
+	for i, key := range keys {
+		if column, ok := m.index[key]; ok {
+			row[column] = values[i]
+		}
+	}
+
+	return row, nil
+}
+
+func (m *MessageReader) readCSV() ([]string, error) {
+	// the first row is not polled, as it could be the header.
+	message, err := m.receive(m.messages > 0)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(bytes.NewReader(message))
+	reader.Comma = m.comma
+	row, err := reader.Read()
+	if err != nil {
+		return nil, &csv.ParseError{StartLine: m.messages, Line: m.messages, Err: err}
+	}
+
+	return row, nil
+}
+
+func (m *MessageReader) readJSON(poll bool) ([]string, []string, error) {
+	message, err := m.receive(poll)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys, values, err := parseJSONObject(message)
+	if err != nil {
+		return nil, nil, &csv.ParseError{StartLine: m.messages, Line: m.messages, Err: err}
+	}
+
+	return keys, values, nil
+}
+
+// receive returns the next message, if poll is true, it waits at most PollInterval.
+func (m *MessageReader) receive(poll bool) ([]byte, error) {
+	ctx := context.Background()
+	if poll && m.PollInterval > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.PollInterval)
+		defer cancel()
+	}
+
+	message, err := m.source.Receive(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ErrNoMessage
+		}
+
+		return nil, err
+	}
+
+	m.messages++
+	return message, nil
+}
@@ -0,0 +1,207 @@
+package csvprocessor_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// messages returns a closed channel with the given messages.
+func messages(values ...string) <-chan []byte {
+	ch := make(chan []byte, len(values))
+	for _, value := range values {
+		ch <- []byte(value)
+	}
+
+	close(ch)
+	return ch
+}
+
+func TestMessageReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   csvprocessor.MessageFormat
+		columns  []string
+		messages []string
+		want     [][]string
+		wantErrs int
+	}{
+		{
+			name:     "csv with columns",
+			format:   csvprocessor.MessageCSV,
+			columns:  []string{"id", "name"},
+			messages: []string{"1,alice", `2,"bob, jr"`},
+			want:     [][]string{{"id", "name"}, {"1", "alice"}, {"2", "bob, jr"}},
+		},
+		{
+			name:     "csv without columns",
+			format:   csvprocessor.MessageCSV,
+			messages: []string{"id,name", "1,alice", `2,"bad`},
+			want:     [][]string{{"id", "name"}, {"1", "alice"}},
+			wantErrs: 1,
+		},
+		{
+			name:     "json without columns",
+			format:   csvprocessor.MessageJSON,
+			messages: []string{`{"id":1,"name":"alice"}`, `{"name":"bob","id":2,"extra":true}`, `[1]`},
+			want:     [][]string{{"id", "name"}, {"1", "alice"}, {"2", "bob"}},
+			wantErrs: 1,
+		},
+		{
+			name:     "json with columns",
+			format:   csvprocessor.MessageJSON,
+			columns:  []string{"name"},
+			messages: []string{`{"id":1,"name":"alice"}`},
+			want:     [][]string{{"name"}, {"alice"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := csvprocessor.NewMessageReader(csvprocessor.ChannelSource(messages(tt.messages...)), tt.format, tt.columns...)
+
+			var rows [][]string
+			errs := 0
+			for {
+				row, err := reader.Read()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				var parseErr *csv.ParseError
+				if errors.As(err, &parseErr) {
+					errs++
+					continue
+				}
+
+				if err != nil {
+					t.Fatalf("Read() error = %v", err)
+				}
+
+				rows = append(rows, row)
+			}
+
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("rows = %q, want %q", rows, tt.want)
+			}
+
+			if errs != tt.wantErrs {
+				t.Errorf("parse errors = %d, want %d", errs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestWithChunkInterval(t *testing.T) {
+	ch := make(chan []byte, 2)
+	ch <- []byte("1,alice")
+	ch <- []byte("2,bob")
+	go func() {
+		// the stream is idle for longer than the chunk interval before the last message.
+		time.Sleep(200 * time.Millisecond)
+		ch <- []byte("3,carol")
+		close(ch)
+	}()
+
+	reader := csvprocessor.NewMessageReader(csvprocessor.ChannelSource(ch), csvprocessor.MessageCSV, "id", "name")
+	reader.PollInterval = 10 * time.Millisecond
+
+	var chunks []string
+	proc, err := csvprocessor.New(
+		csvprocessor.WithReader(reader),
+		csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			chunks = append(chunks, "")
+			return csvprocessor.NoOpCloser(writerFunc(func(p []byte) (int, error) {
+				chunks[len(chunks)-1] += string(p)
+				return len(p), nil
+			})), nil
+		}),
+		csvprocessor.WithChunkSize(100),
+		csvprocessor.WithChunkInterval(50*time.Millisecond),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	want := []string{"id,name\n1,alice\n2,bob\n", "id,name\n3,carol\n"}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Errorf("chunks = %q, want %q", chunks, want)
+	}
+
+	if _, err := csvprocessor.New(csvprocessor.WithStdin(), csvprocessor.WithChunkInterval(0)); !errors.Is(err, csvprocessor.ErrInvalidChunkInterval) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidChunkInterval)
+	}
+}
+
+func TestMessageReader_Options(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		opts     []csvprocessor.Option
+		want     string
+	}{
+		{"input delimiter", []string{"1;alice", "2;bob"}, []csvprocessor.Option{csvprocessor.WithInputDelimiter(';')}, "id,name\n1,alice\n2,bob\n"},
+		{"sort an idle stream", []string{"2,bob", "1,alice"}, []csvprocessor.Option{csvprocessor.WithSortBy([]int{0}, csvprocessor.Ascending)}, "id,name\n1,alice\n2,bob\n"},
+		{"tail of an idle stream", []string{"1,alice", "2,bob"}, []csvprocessor.Option{csvprocessor.Tail(1)}, "id,name\n2,bob\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := make(chan []byte, len(tt.messages))
+			go func() {
+				for _, message := range tt.messages {
+					// the stream is idle for longer than the poll interval before each message.
+					time.Sleep(20 * time.Millisecond)
+					ch <- []byte(message)
+				}
+
+				close(ch)
+			}()
+
+			reader := csvprocessor.NewMessageReader(csvprocessor.ChannelSource(ch), csvprocessor.MessageCSV, "id", "name")
+			reader.PollInterval = time.Millisecond
+
+			var output string
+			opts := append([]csvprocessor.Option{
+				csvprocessor.WithReader(reader),
+				csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+					return csvprocessor.NoOpCloser(writerFunc(func(p []byte) (int, error) {
+						output += string(p)
+						return len(p), nil
+					})), nil
+				}),
+				csvprocessor.WithChunkSize(100),
+				csvprocessor.WithOutputDelimiter(','),
+				csvprocessor.WithLogger(t.Logf),
+			}, tt.opts...)
+			proc, err := csvprocessor.New(opts...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if err := proc.Process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if output != tt.want {
+				t.Errorf("output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
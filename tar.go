@@ -0,0 +1,95 @@
+package csvprocessor
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"time"
+)
+
+// tarGzArchive streams the chunks as entries of a gzip compressed tar archive.
+type tarGzArchive struct {
+	w           io.Writer
+	gzip        *gzip.Writer
+	tar         *tar.Writer
+	entryFormat string
+}
+
+func newTarGzArchive(w io.Writer, entryFormat string) *tarGzArchive {
+	gz := gzip.NewWriter(w)
+	return &tarGzArchive{w: w, gzip: gz, tar: tar.NewWriter(gz), entryFormat: entryFormat}
+}
+
+// chunk is the OutputChunkGenerator that buffers the chunk in a temporary file, it is added to the archive when closed.
+func (a *tarGzArchive) chunk(chunkID int) (io.WriteCloser, error) {
+	file, err := os.CreateTemp("", "csvprocessor-chunk-*")
+	if err != nil {
+		return nil, err
+	}
+
+	return &tarEntry{File: file, archive: a, name: ChunkName(a.entryFormat, chunkID)}, nil
+}
+
+// close completes the archive, the underlying writer is not closed.
+func (a *tarGzArchive) close() error {
+	if err := a.tar.Close(); err != nil {
+		return err
+	}
+
+	return a.gzip.Close()
+}
+
+// abort aborts the underlying writer if it supports it, as the data written cannot be removed.
+func (a *tarGzArchive) abort() error {
+	if aborter, ok := a.w.(interface{ Abort() }); ok {
+		aborter.Abort()
+	}
+
+	return nil
+}
+
+// tarEntry is the temporary file of a chunk.
+type tarEntry struct {
+	*os.File
+	archive *tarGzArchive
+	name    string
+}
+
+// Name returns the name of the entry in the archive, instead of the name of the temporary file.
+func (e *tarEntry) Name() string {
+	return e.name
+}
+
+// Close copies the chunk to the archive and removes the temporary file.
+func (e *tarEntry) Close() error {
+	defer e.remove()
+
+	size, err := e.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	if _, err := e.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	// the modified time is fixed, so that the archive is the same for the same input.
+	header := &tar.Header{Name: e.name, Mode: 0o644, Size: size, ModTime: time.Unix(0, 0), Typeflag: tar.TypeReg}
+	if err := e.archive.tar.WriteHeader(header); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(e.archive.tar, e.File)
+	return err
+}
+
+// Abort removes the temporary file without adding the chunk to the archive.
+func (e *tarEntry) Abort() {
+	e.remove()
+}
+
+func (e *tarEntry) remove() {
+	_ = e.File.Close()
+	_ = os.Remove(e.File.Name())
+}
@@ -0,0 +1,159 @@
+package csvprocessor_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// readTarGz returns the names and the content of the entries in the archive.
+func readTarGz(tb testing.TB, data []byte) ([]string, map[string]string) {
+	tb.Helper()
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		tb.Fatalf("invalid gzip: %v", err)
+	}
+
+	var names []string
+	files := map[string]string{}
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return names, files
+		}
+
+		if err != nil {
+			tb.Fatalf("invalid tar: %v", err)
+		}
+
+		content, _ := io.ReadAll(reader)
+		names = append(names, header.Name)
+		files[header.Name] = string(content)
+	}
+}
+
+func processTarGz(tb testing.TB, input string, opt ...csvprocessor.Option) ([]byte, error) {
+	tb.Helper()
+
+	var output bytes.Buffer
+	proc, err := csvprocessor.New(append([]csvprocessor.Option{
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader(input))),
+		csvprocessor.WithTarGzOutput(&output),
+		csvprocessor.WithChunkSize(2),
+		csvprocessor.WithLogger(tb.Logf),
+	}, opt...)...)
+	if err != nil {
+		tb.Fatalf("New() error = %v", err)
+	}
+
+	err = proc.Process()
+	return output.Bytes(), err
+}
+
+func TestWithTarGzOutput(t *testing.T) {
+	input := "id,name\n1,alice\n2,bob\n3,carol\n"
+
+	tests := []struct {
+		name      string
+		opts      []csvprocessor.Option
+		wantNames []string
+		want      map[string]string
+	}{
+		{
+			name:      "default entry names",
+			opts:      nil,
+			wantNames: []string{"part_00001.csv", "part_00002.csv"},
+			want: map[string]string{
+				"part_00001.csv": "id,name\n1,alice\n2,bob\n",
+				"part_00002.csv": "id,name\n3,carol\n",
+			},
+		},
+		{
+			name:      "custom entry names",
+			opts:      []csvprocessor.Option{csvprocessor.WithZipEntryFormat("users/%d.tsv"), csvprocessor.WithOutputDelimiter('\t')},
+			wantNames: []string{"users/1.tsv", "users/2.tsv"},
+			want: map[string]string{
+				"users/1.tsv": "id\tname\n1\talice\n2\tbob\n",
+				"users/2.tsv": "id\tname\n3\tcarol\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := processTarGz(t, input, tt.opts...)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			names, files := readTarGz(t, data)
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("entries = %q, want %q", names, tt.wantNames)
+			}
+
+			if !reflect.DeepEqual(files, tt.want) {
+				t.Errorf("content = %q, want %q", files, tt.want)
+			}
+
+			// the archive is the same when the same input is processed again.
+			if again, _ := processTarGz(t, input, tt.opts...); !bytes.Equal(data, again) {
+				t.Errorf("archive is not deterministic")
+			}
+		})
+	}
+}
+
+// abortBuffer is a bytes.Buffer that records whether it was aborted.
+type abortBuffer struct {
+	bytes.Buffer
+	aborted bool
+}
+
+func (b *abortBuffer) Abort() {
+	b.aborted = true
+}
+
+func TestWithTarGzOutput_Error(t *testing.T) {
+	errBadRow := errors.New("bad row")
+	output := &abortBuffer{}
+
+	proc, err := csvprocessor.New(
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader("id\n1\n2\n3\n"))),
+		csvprocessor.WithTarGzOutput(output),
+		csvprocessor.WithChunkSize(1),
+		csvprocessor.WithLogger(t.Logf),
+		csvprocessor.WithTransformer(func(ctx context.Context, row []string) []string {
+			if row[0] == "3" {
+				panic(errBadRow)
+			}
+
+			return row
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); !errors.Is(err, errBadRow) {
+		t.Fatalf("Process() error = %v, want %v", err, errBadRow)
+	}
+
+	if !output.aborted {
+		t.Errorf("output was not aborted")
+	}
+
+	if _, err := csvprocessor.New(csvprocessor.WithStdin(), csvprocessor.WithTarGzOutput(nil)); !errors.Is(err, csvprocessor.ErrOutputWriterNil) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrOutputWriterNil)
+	}
+}
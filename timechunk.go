@@ -0,0 +1,83 @@
+package csvprocessor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidTimeWindow is returned when the column of WithChunkByTimeColumn() is < 0 or the window is not > 0.
+	ErrInvalidTimeWindow = errors.New("csvprocessor: time column must be >= 0 and the window must be > 0")
+	// ErrInvalidTimeValue is returned for a row whose value in the time column does not match the layout of WithChunkByTimeColumn().
+	ErrInvalidTimeValue = errors.New("csvprocessor: invalid value in the time column")
+)
+
+// WithChunkByTimeColumn splits the rows into chunks by the time window of the timestamp in the given 0-based column,
+// Eg: to write a chunk per day or per hour for a date partitioned layout. The timestamps are parsed using the layout
+// (time.RFC3339 if it is empty), and the windows are aligned to UTC, Eg: a window of 24 * time.Hour starts at midnight UTC.
+//
+// A new chunk is started when the window of a row is different from the window of the current chunk, so the rows should be
+// ordered by time (Eg: using WithSortBy()), else a window is written to multiple chunks. The chunk size still limits the no.
+// of rows in a chunk, it is not limited if WithChunkSize() is not used. The start of the window of each chunk is in ChunkInfo.Window.
+//
+// The output file format can have a second verb for the start of the window, formatted as 2006-01-02 for windows of whole days,
+// 2006-01-02T15 for whole hours, 2006-01-02T15-04 for whole minutes and 2006-01-02T15-04-05 otherwise. Eg:
+//
+//	csvprocessor.WithChunkByTimeColumn(3, "2006-01-02 15:04:05", 24*time.Hour),
+//	csvprocessor.WithOutputFileFormat("orders/dt=%[2]s/part_%03[1]d.csv"), // orders/dt=2024-03-01/part_001.csv
+//
+// The rows with an invalid timestamp are handled like the rows that fail the schema validation, see WithRejectWriter().
+// No chunk is written for an input without data rows.
+func WithChunkByTimeColumn(column int, layout string, window time.Duration) Option {
+	return func(c *Processor) error {
+		if column < 0 || window <= 0 {
+			return ErrInvalidTimeWindow
+		}
+
+		if layout == "" {
+			layout = time.RFC3339
+		}
+
+		c.timeChunks = &timeChunks{column: column, layout: layout, window: window}
+		if c.chunkSize == 0 {
+			c.chunkSize = math.MaxInt32
+		}
+
+		return nil
+	}
+}
+
+// timeChunks is the time window chunking of WithChunkByTimeColumn().
+type timeChunks struct {
+	column int
+	layout string
+	window time.Duration
+}
+
+// windowOf returns the start of the window of the timestamp in the row.
+func (t *timeChunks) windowOf(row []string) (time.Time, error) {
+	value := valueAt(row, t.column)
+	timestamp, err := time.Parse(t.layout, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q in column %d does not match the layout %q", ErrInvalidTimeValue, value, t.column, t.layout)
+	}
+
+	return timestamp.UTC().Truncate(t.window), nil
+}
+
+// format returns the start of the window as used in the chunk names.
+func (t *timeChunks) format(start time.Time) string {
+	switch {
+	case t.window%(24*time.Hour) == 0:
+		return start.Format("2006-01-02")
+	case t.window%time.Hour == 0:
+		return start.Format("2006-01-02T15")
+	case t.window%time.Minute == 0:
+		return start.Format("2006-01-02T15-04")
+	}
+
+	return start.Format("2006-01-02T15-04-05")
+}
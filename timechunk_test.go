@@ -0,0 +1,173 @@
+package csvprocessor_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+const ordersByTime = "id,created\n" +
+	"1,2024-03-01 08:15:00\n" +
+	"2,2024-03-01 23:59:59\n" +
+	"3,2024-03-02 00:00:00\n" +
+	"4,2024-03-02 09:30:00\n" +
+	"5,2024-03-02 09:45:00\n" +
+	"6,2024-03-04 10:00:00\n"
+
+func TestWithChunkByTimeColumn(t *testing.T) {
+	tests := []struct {
+		name      string
+		window    time.Duration
+		chunkSize int
+		want      map[string]string
+		wantStart []time.Time
+	}{
+		{
+			name:   "Test daily chunks",
+			window: 24 * time.Hour,
+			want: map[string]string{
+				"dt=2024-03-01/part_1.csv": "id,created\n1,2024-03-01 08:15:00\n2,2024-03-01 23:59:59\n",
+				"dt=2024-03-02/part_2.csv": "id,created\n3,2024-03-02 00:00:00\n4,2024-03-02 09:30:00\n5,2024-03-02 09:45:00\n",
+				"dt=2024-03-04/part_3.csv": "id,created\n6,2024-03-04 10:00:00\n",
+			},
+			wantStart: []time.Time{
+				time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:   "Test hourly chunks",
+			window: time.Hour,
+			want: map[string]string{
+				"dt=2024-03-01T08/part_1.csv": "id,created\n1,2024-03-01 08:15:00\n",
+				"dt=2024-03-01T23/part_2.csv": "id,created\n2,2024-03-01 23:59:59\n",
+				"dt=2024-03-02T00/part_3.csv": "id,created\n3,2024-03-02 00:00:00\n",
+				"dt=2024-03-02T09/part_4.csv": "id,created\n4,2024-03-02 09:30:00\n5,2024-03-02 09:45:00\n",
+				"dt=2024-03-04T10/part_5.csv": "id,created\n6,2024-03-04 10:00:00\n",
+			},
+		},
+		{
+			name:      "Test chunk size within a window",
+			window:    24 * time.Hour,
+			chunkSize: 2,
+			want: map[string]string{
+				"dt=2024-03-01/part_1.csv": "id,created\n1,2024-03-01 08:15:00\n2,2024-03-01 23:59:59\n",
+				"dt=2024-03-02/part_2.csv": "id,created\n3,2024-03-02 00:00:00\n4,2024-03-02 09:30:00\n",
+				"dt=2024-03-02/part_3.csv": "id,created\n5,2024-03-02 09:45:00\n",
+				"dt=2024-03-04/part_4.csv": "id,created\n6,2024-03-04 10:00:00\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			input := filepath.Join(dir, "input.csv")
+			writeTestFile(t, input, ordersByTime)
+
+			opts := []csvprocessor.Option{
+				csvprocessor.WithFileReader(input),
+				csvprocessor.WithOutputFileFormat(filepath.Join(dir, "dt=%[2]s", "part_%[1]d.csv")),
+				csvprocessor.WithChunkByTimeColumn(1, "2006-01-02 15:04:05", tt.window),
+			}
+			if tt.chunkSize > 0 {
+				opts = append(opts, csvprocessor.WithChunkSize(tt.chunkSize))
+			}
+
+			proc, err := csvprocessor.New(opts...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if err := proc.Process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			for name, want := range tt.want {
+				got, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(got) != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+
+			chunks := proc.Stats().Chunks
+			if len(chunks) != len(tt.want) {
+				t.Errorf("Stats().Chunks has %d chunks, want %d", len(chunks), len(tt.want))
+			}
+
+			for i, start := range tt.wantStart {
+				if i < len(chunks) && !chunks[i].Window.Equal(start) {
+					t.Errorf("Stats().Chunks[%d].Window = %v, want %v", i, chunks[i].Window, start)
+				}
+			}
+		})
+	}
+}
+
+func TestWithChunkByTimeColumn_InvalidTime(t *testing.T) {
+	input := "id,created\n1,2024-03-01T08:15:00Z\n2,yesterday\n3,2024-03-01T09:00:00+05:30\n4,2024-03-02T01:00:00Z\n"
+
+	var rejects strings.Builder
+	rejectWriter := csv.NewWriter(&rejects)
+	chunks := make([]strings.Builder, 3)
+	proc := newProcessor(t, strings.NewReader(input), chunks,
+		csvprocessor.WithChunkByTimeColumn(1, "", 24*time.Hour),
+		csvprocessor.WithChunkSize(10),
+		csvprocessor.WithRejectWriter(rejectWriter),
+	)
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	// the windows are aligned to UTC, so 09:00 at +05:30 is on the same day as 08:15 UTC.
+	want := []string{
+		"id,created\n1,2024-03-01T08:15:00Z\n3,2024-03-01T09:00:00+05:30\n",
+		"id,created\n4,2024-03-02T01:00:00Z\n",
+		"",
+	}
+	for i := range want {
+		if got := chunks[i].String(); got != want[i] {
+			t.Errorf("chunk %d = %q, want %q", i+1, got, want[i])
+		}
+	}
+
+	if !strings.Contains(rejects.String(), "\n2,yesterday,") {
+		t.Errorf("Process() rejects = %q, want the row 2", rejects.String())
+	}
+
+	_, err := processString(t, input, csvprocessor.WithChunkByTimeColumn(1, "", 24*time.Hour))
+	var rowErr *csvprocessor.RowError
+	if !errors.Is(err, csvprocessor.ErrInvalidTimeValue) || !errors.As(err, &rowErr) || rowErr.Row != 2 {
+		t.Errorf("Process() error = %v, want %v in row 2", err, csvprocessor.ErrInvalidTimeValue)
+	}
+}
+
+func TestWithChunkByTimeColumn_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		column int
+		window time.Duration
+	}{
+		{name: "Test negative column", column: -1, window: time.Hour},
+		{name: "Test zero window", column: 0, window: 0},
+		{name: "Test negative window", column: 0, window: -time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := csvprocessor.New(csvprocessor.WithChunkByTimeColumn(tt.column, "", tt.window))
+			if !errors.Is(err, csvprocessor.ErrInvalidTimeWindow) {
+				t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidTimeWindow)
+			}
+		})
+	}
+}
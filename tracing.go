@@ -0,0 +1,134 @@
+package csvprocessor
+
+import (
+	"context"
+	"errors"
+)
+
+// Names of the spans started by the processor, see WithTracer().
+const (
+	SpanProcess  = "csvprocessor.Process"
+	SpanChunk    = "csvprocessor.Chunk"
+	SpanRowBatch = "csvprocessor.RowBatch"
+)
+
+// ErrInvalidBatchSize is returned when the no. of rows in a batch is not > 0.
+var ErrInvalidBatchSize = errors.New("csvprocessor: batch size must be > 0")
+
+// Tracer starts the spans of the processing, see WithTracer().
+type Tracer interface {
+	// Start starts a span as a child of the span in ctx, if any, and returns the context with the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span represents a traced operation started by a Tracer.
+type Span interface {
+	SetAttribute(key string, value any)
+	RecordError(err error)
+	End()
+}
+
+// WithTracer traces the processing using the tracer, Eg: an adapter for an OpenTelemetry trace.Tracer (see README.md).
+// A SpanProcess span is started for each run (as a child of the span in the context passed to ProcessContext()),
+// with a SpanChunk span for each chunk, and a SpanRowBatch span for each batch of rows if WithRowBatchSpans() is used.
+// The spans have the attributes like the chunk ID and the no. of rows, Eg: "csvprocessor.chunk.id".
+// The context passed to the transformers contains the SpanProcess span.
+func WithTracer(tracer Tracer) Option {
+	return func(c *Processor) error {
+		c.tracer = tracer
+		return nil
+	}
+}
+
+// WithRowBatchSpans starts a SpanRowBatch span for every batchSize rows read, see WithTracer().
+func WithRowBatchSpans(batchSize int) Option {
+	return func(c *Processor) error {
+		if batchSize <= 0 {
+			return ErrInvalidBatchSize
+		}
+
+		c.traceBatchSize = batchSize
+		return nil
+	}
+}
+
+// startSpan starts a span if the tracer is set, the returned span is nil otherwise.
+func (c *Processor) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if c.tracer == nil {
+		return ctx, nil
+	}
+
+	return c.tracer.Start(ctx, name)
+}
+
+// endProcessSpan ends the span of the run with the stats.
+func (c *Processor) endProcessSpan(span Span, err error) {
+	if span == nil {
+		return
+	}
+
+	span.SetAttribute("csvprocessor.rows_read", c.stats.RowsRead)
+	span.SetAttribute("csvprocessor.rows_written", c.stats.RowsWritten)
+	span.SetAttribute("csvprocessor.rows_rejected", c.stats.RowsRejected)
+	span.SetAttribute("csvprocessor.row_errors", len(c.stats.Errors))
+	span.SetAttribute("csvprocessor.chunks", len(c.stats.Chunks))
+	endSpan(span, err)
+}
+
+// startChunkSpan starts the span of the current chunk.
+func (r *run) startChunkSpan() {
+	_, r.chunkSpan = r.c.startSpan(r.spanCtx, SpanChunk)
+	if r.chunkSpan != nil {
+		r.chunkSpan.SetAttribute("csvprocessor.chunk.id", r.currentChunk().ID)
+	}
+}
+
+// endChunkSpan ends the span of the current chunk, if any.
+func (r *run) endChunkSpan(err error) {
+	if r.chunkSpan == nil {
+		return
+	}
+
+	chunk := r.currentChunk()
+	r.chunkSpan.SetAttribute("csvprocessor.chunk.name", chunk.Name)
+	r.chunkSpan.SetAttribute("csvprocessor.chunk.rows", chunk.Rows)
+	r.chunkSpan.SetAttribute("csvprocessor.chunk.bytes", chunk.Bytes)
+	endSpan(r.chunkSpan, err)
+	r.chunkSpan = nil
+}
+
+// traceRow starts a span for the batch of rows from the current row, if needed.
+func (r *run) traceRow() {
+	if r.c.traceBatchSize <= 0 || r.batchSpan != nil {
+		return
+	}
+
+	_, r.batchSpan = r.c.startSpan(r.spanCtx, SpanRowBatch)
+	r.batchFirstRow = r.currentRow
+}
+
+// endBatchSpan ends the span of the batch of rows if it is complete, or if force is true.
+func (r *run) endBatchSpan(force bool) {
+	if r.batchSpan == nil {
+		return
+	}
+
+	rows := r.currentRow - r.batchFirstRow + 1
+	if !force && rows < r.c.traceBatchSize {
+		return
+	}
+
+	r.batchSpan.SetAttribute("csvprocessor.batch.first_row", r.batchFirstRow)
+	r.batchSpan.SetAttribute("csvprocessor.batch.last_row", r.currentRow)
+	r.batchSpan.SetAttribute("csvprocessor.batch.rows", rows)
+	r.batchSpan.End()
+	r.batchSpan = nil
+}
+
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	span.End()
+}
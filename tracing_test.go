@@ -0,0 +1,163 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+type spanKey struct{}
+
+type testSpan struct {
+	name   string
+	parent string
+	attrs  map[string]any
+	err    error
+	ended  bool
+}
+
+func (s *testSpan) SetAttribute(key string, value any) {
+	s.attrs[key] = value
+}
+
+func (s *testSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *testSpan) End() {
+	s.ended = true
+}
+
+// testTracer records the spans, the parent of a span is the span in the context.
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (tr *testTracer) Start(ctx context.Context, name string) (context.Context, csvprocessor.Span) {
+	span := &testSpan{name: name, attrs: map[string]any{}}
+	if parent, ok := ctx.Value(spanKey{}).(*testSpan); ok {
+		span.parent = parent.name
+	}
+
+	tr.spans = append(tr.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func TestWithTracer(t *testing.T) {
+	tracer := &testTracer{}
+	var transformerSpan string
+	proc, err := csvprocessor.New(
+		csvprocessor.WithInputReader(strings.NewReader("id\n1\n2\n3\n")),
+		csvprocessor.WithChunkSize(2),
+		csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(io.Discard), nil
+		}),
+		csvprocessor.WithTransformer(func(ctx context.Context, row []string) []string {
+			transformerSpan = ctx.Value(spanKey{}).(*testSpan).name
+			return row
+		}),
+		csvprocessor.WithTracer(tracer),
+		csvprocessor.WithRowBatchSpans(2),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	want := []testSpan{
+		{name: csvprocessor.SpanProcess, attrs: map[string]any{
+			"csvprocessor.rows_read": 3, "csvprocessor.rows_written": 3, "csvprocessor.rows_rejected": 0,
+			"csvprocessor.row_errors": 0, "csvprocessor.chunks": 2,
+		}},
+		{name: csvprocessor.SpanChunk, parent: csvprocessor.SpanProcess, attrs: map[string]any{
+			"csvprocessor.chunk.id": 1, "csvprocessor.chunk.name": "", "csvprocessor.chunk.rows": 2, "csvprocessor.chunk.bytes": int64(7),
+		}},
+		{name: csvprocessor.SpanRowBatch, parent: csvprocessor.SpanProcess, attrs: map[string]any{
+			"csvprocessor.batch.first_row": 1, "csvprocessor.batch.last_row": 2, "csvprocessor.batch.rows": 2,
+		}},
+		{name: csvprocessor.SpanRowBatch, parent: csvprocessor.SpanProcess, attrs: map[string]any{
+			"csvprocessor.batch.first_row": 3, "csvprocessor.batch.last_row": 3, "csvprocessor.batch.rows": 1,
+		}},
+		{name: csvprocessor.SpanChunk, parent: csvprocessor.SpanProcess, attrs: map[string]any{
+			"csvprocessor.chunk.id": 2, "csvprocessor.chunk.name": "", "csvprocessor.chunk.rows": 1, "csvprocessor.chunk.bytes": int64(5),
+		}},
+	}
+	checkSpans(t, tracer.spans, want)
+
+	if transformerSpan != csvprocessor.SpanProcess {
+		t.Errorf("transformer context span = %q, want %q", transformerSpan, csvprocessor.SpanProcess)
+	}
+}
+
+func TestWithTracerError(t *testing.T) {
+	errTransform := errors.New("invalid row")
+	tracer := &testTracer{}
+	proc, err := csvprocessor.New(
+		csvprocessor.WithInputReader(strings.NewReader("id\n1\n2\n")),
+		csvprocessor.WithChunkSize(10),
+		csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(io.Discard), nil
+		}),
+		csvprocessor.WithTransformer(func(_ context.Context, row []string) []string {
+			if row[0] == "2" {
+				// the processor returns the panics in the transformers as errors.
+				panic(errTransform)
+			}
+
+			return row
+		}),
+		csvprocessor.WithTracer(tracer),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); !errors.Is(err, errTransform) {
+		t.Fatalf("Process() error = %v, want %v", err, errTransform)
+	}
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("no. of spans = %d, want 2", len(tracer.spans))
+	}
+
+	if process := tracer.spans[0]; !errors.Is(process.err, errTransform) || !process.ended {
+		t.Errorf("process span error = %v, ended = %v, want %v, true", process.err, process.ended, errTransform)
+	}
+
+	if chunk := tracer.spans[1]; chunk.attrs["csvprocessor.chunk.aborted"] != true || !chunk.ended {
+		t.Errorf("chunk span attributes = %v, ended = %v, want aborted and ended", chunk.attrs, chunk.ended)
+	}
+
+	if _, err := csvprocessor.New(csvprocessor.WithRowBatchSpans(0)); !errors.Is(err, csvprocessor.ErrInvalidBatchSize) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidBatchSize)
+	}
+}
+
+func checkSpans(t *testing.T, got []*testSpan, want []testSpan) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("no. of spans = %d, want %d", len(got), len(want))
+	}
+
+	for i, span := range got {
+		if !span.ended || span.err != nil {
+			t.Errorf("span %d (%s): ended = %v, error = %v", i, span.name, span.ended, span.err)
+		}
+
+		if span.name != want[i].name || span.parent != want[i].parent || !reflect.DeepEqual(span.attrs, want[i].attrs) {
+			t.Errorf("span %d = %s (parent %q) %v, want %s (parent %q) %v",
+				i, span.name, span.parent, span.attrs, want[i].name, want[i].parent, want[i].attrs)
+		}
+	}
+}
@@ -2,12 +2,16 @@ package csvprocessor
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"strconv"
+	"strings"
 )
 
 // CsvRowTransformer represents the transformer function that modifies each row in csv.
 // It takes a row as a slice of strings as input and produces the transformed row that will be written to the output file.
 // A context.Context with extra metadata about the row is also passed.
+// If the transformer returns nil, the row is dropped and not written to the output.
 type CsvRowTransformer func(context.Context, []string) []string
 
 // NoOpTransformer applies no transformations on the rows.
@@ -21,36 +25,89 @@ func NoOpTransformer() CsvRowTransformer {
 // AddRowNoTransformer adds a row number to each row.
 // This uses the overall row number across chunks, For chunk-wise row number use AddChunkRowNoTransformer().
 // If SkipHeaders is false, it will add a header column for the row number with the given columnName.
+// To change the numbering or the position of the column use AddRowNoTransformerWithOptions().
 func AddRowNoTransformer(columnName string) CsvRowTransformer {
+	return AddRowNoTransformerWithOptions(columnName, RowNoOptions{})
+}
+
+// AddChunkRowNoTransformer adds the row number within current chunk to each row, see CtxChunkRowNum.
+// If SkipHeaders is false, it will add a header column for the row number with the given columnName.
+// To change the numbering or the position of the column use AddChunkRowNoTransformerWithOptions().
+func AddChunkRowNoTransformer(columnName string) CsvRowTransformer {
+	return AddChunkRowNoTransformerWithOptions(columnName, RowNoOptions{})
+}
+
+// RowNoOptions configures the row numbers added by AddRowNoTransformerWithOptions() and AddChunkRowNoTransformerWithOptions().
+// The zero value numbers the rows 1, 2, 3... in the first column.
+type RowNoOptions struct {
+	// Offset is added to the row numbers, Eg: 1000 numbers the rows from 1001 to continue the numbering of an existing file.
+	Offset int
+	// Step is the difference between consecutive row numbers, Eg: 10 numbers the rows 1, 11, 21... (defaults to 1).
+	Step int
+	// Width pads the row numbers with leading zeros to at least Width digits, Eg: 5 writes 00042.
+	Width int
+	// Index is the 0-based position of the row number column, -1 or an index beyond the row adds it as the last column.
+	Index int
+}
+
+// format returns the row number n (1-based) formatted using the options.
+func (o RowNoOptions) format(n int) string {
+	step := o.Step
+	if step == 0 {
+		step = 1
+	}
+
+	return fmt.Sprintf("%0*d", o.Width, o.Offset+1+(n-1)*step)
+}
+
+// add adds the value at the position of the row number column.
+func (o RowNoOptions) add(row []string, value string) []string {
+	index := o.Index
+	if index < 0 || index > len(row) {
+		index = len(row)
+	}
+
+	return addToSliceAtIndex(row, value, index)
+}
+
+// AddRowNoTransformerWithOptions adds a row number to each row like AddRowNoTransformer(), numbered and positioned using the options.
+// Eg: csvprocessor.AddRowNoTransformerWithOptions("id", csvprocessor.RowNoOptions{Offset: 1000, Width: 6}) numbers the rows 001001, 001002...
+func AddRowNoTransformerWithOptions(columnName string, opts RowNoOptions) CsvRowTransformer {
 	return func(ctx context.Context, row []string) []string {
 		isHeader, isBool := (ctx.Value(CtxIsHeader)).(bool)
 		if isBool && isHeader {
-			return addToSliceAtIndex(row, columnName, 0)
+			return opts.add(row, columnName)
 		}
 
 		rowID, _ := ctx.Value(CtxRowNum).(int) //nolint:errcheck
 
-		return addToSliceAtIndex(row, strconv.Itoa(rowID), 0)
+		return opts.add(row, opts.format(rowID))
 	}
 }
 
-// AddChunkRowNoTransformer adds the row number within current chunk to each row.
-// If SkipHeaders is false, it will add a header column for the row number with the given columnName.
-func AddChunkRowNoTransformer(columnName string) CsvRowTransformer {
+// AddChunkRowNoTransformerWithOptions adds the row number within current chunk to each row like AddChunkRowNoTransformer(),
+// numbered and positioned using the options. The numbering restarts from Offset+1 in each chunk.
+func AddChunkRowNoTransformerWithOptions(columnName string, opts RowNoOptions) CsvRowTransformer {
 	return func(ctx context.Context, row []string) []string {
 		isHeader, isBool := (ctx.Value(CtxIsHeader)).(bool)
 		if isBool && isHeader {
-			return addToSliceAtIndex(row, columnName, 0)
+			return opts.add(row, columnName)
 		}
 
-		rowID, _ := ctx.Value(CtxRowNum).(int)          //nolint:errcheck
-		chunkSize, _ := (ctx.Value(CtxChunkSize)).(int) //nolint:errcheck
-		chunkRowID := (rowID % chunkSize)
-		if chunkRowID == 0 {
-			chunkRowID = chunkSize
+		chunkRowID, ok := ctx.Value(CtxChunkRowNum).(int)
+		if !ok {
+			// the row no. in the chunk is derived, when the transformer is called outside the processor.
+			rowID, _ := ctx.Value(CtxRowNum).(int)          //nolint:errcheck
+			chunkSize, _ := (ctx.Value(CtxChunkSize)).(int) //nolint:errcheck
+			if chunkSize > 0 {
+				chunkRowID = rowID % chunkSize
+				if chunkRowID == 0 {
+					chunkRowID = chunkSize
+				}
+			}
 		}
 
-		return addToSliceAtIndex(row, strconv.Itoa(chunkRowID), 0)
+		return opts.add(row, opts.format(chunkRowID))
 	}
 }
 
@@ -79,19 +136,231 @@ func AddConstantColumnTransformer(columnName, val string, columIndex int) CsvRow
 	}
 }
 
+// MergeColumnsTransformer concatenates the values of the given columns using separator and adds the result as a new column at the end of the row.
+// If dropSource is true, the source columns are removed from the row.
+// For header rows, the new column will be named columnName.
+// Eg: csvprocessor.MergeColumnsTransformer("key", "-", true, 0, 2) converts the row [a, b, c] to [b, a-c].
+func MergeColumnsTransformer(columnName, separator string, dropSource bool, columns ...int) CsvRowTransformer {
+	return func(ctx context.Context, row []string) []string {
+		merged := columnName
+		isHeader, isBool := (ctx.Value(CtxIsHeader)).(bool)
+		if !isBool || !isHeader {
+			values := make([]string, len(columns))
+			for i, column := range columns {
+				if column >= 0 && column < len(row) {
+					values[i] = row[column]
+				}
+			}
+
+			merged = strings.Join(values, separator)
+		}
+
+		if dropSource {
+			row = removeFromSlice(row, columns)
+		}
+
+		return append(row, merged)
+	}
+}
+
+// ArithmeticOp represents the operation performed by ArithmeticTransformer.
+type ArithmeticOp int
+
+const (
+	// OpSum adds the values of all the columns.
+	OpSum ArithmeticOp = iota
+	// OpDifference subtracts the values of the remaining columns from the value of the first column.
+	OpDifference
+	// OpProduct multiplies the values of all the columns.
+	OpProduct
+	// OpPercentage computes the value of the first column as a percentage of the value of the second column.
+	OpPercentage
+)
+
+// ArithmeticTransformer adds a new column at the end of the row, computed by applying op on the numeric values of the given columns.
+// The result is formatted with precision no. of decimal places.
+// If any of the values is not a valid number or the result is not finite (Eg: percentage of 0), onParseError is used as the value.
+// For header rows, the new column will be named columnName.
+func ArithmeticTransformer(columnName string, op ArithmeticOp, precision int, onParseError string, columns ...int) CsvRowTransformer {
+	return func(ctx context.Context, row []string) []string {
+		isHeader, isBool := (ctx.Value(CtxIsHeader)).(bool)
+		if isBool && isHeader {
+			return append(row, columnName)
+		}
+
+		values := make([]float64, len(columns))
+		for i, column := range columns {
+			if column < 0 || column >= len(row) {
+				return append(row, onParseError)
+			}
+
+			val, err := strconv.ParseFloat(strings.TrimSpace(row[column]), 64)
+			if err != nil {
+				return append(row, onParseError)
+			}
+
+			values[i] = val
+		}
+
+		result, ok := applyArithmeticOp(op, values)
+		if !ok || math.IsNaN(result) || math.IsInf(result, 0) {
+			return append(row, onParseError)
+		}
+
+		return append(row, strconv.FormatFloat(result, 'f', precision, 64))
+	}
+}
+
+// applyArithmeticOp returns the result of op on values and false if the op cannot be applied.
+func applyArithmeticOp(op ArithmeticOp, values []float64) (float64, bool) {
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	result := values[0]
+	switch op {
+	case OpSum:
+		for _, val := range values[1:] {
+			result += val
+		}
+	case OpDifference:
+		for _, val := range values[1:] {
+			result -= val
+		}
+	case OpProduct:
+		for _, val := range values[1:] {
+			result *= val
+		}
+	case OpPercentage:
+		if len(values) != 2 {
+			return 0, false
+		}
+
+		result = (values[0] / values[1]) * 100
+	default:
+		return 0, false
+	}
+
+	return result, true
+}
+
+// DedupTransformer drops the rows whose key has already been seen in the input.
+// The key of a row is made of the values of the given columns, if no columns are given the entire row is used as the key.
+// All the keys seen are held in memory, for large inputs with many unique keys use ApproxDedupTransformer().
+func DedupTransformer(columns ...int) CsvRowTransformer {
+	seen := make(map[string]struct{})
+
+	return func(ctx context.Context, row []string) []string {
+		isHeader, isBool := (ctx.Value(CtxIsHeader)).(bool)
+		if isBool && isHeader {
+			return row
+		}
+
+		key := rowKey(row, columns)
+		if _, ok := seen[key]; ok {
+			return nil
+		}
+
+		seen[key] = struct{}{}
+		return row
+	}
+}
+
+// ApproxDedupTransformer drops the rows whose key has already been seen in the input using a bounded amount of memory.
+// It is sized for expectedRows unique keys, and may wrongly drop unique rows at the given falsePositiveRate (Eg: 0.001).
+// Duplicate rows are always dropped.
+func ApproxDedupTransformer(expectedRows int, falsePositiveRate float64, columns ...int) CsvRowTransformer {
+	seen := newBloomFilter(expectedRows, falsePositiveRate)
+
+	return func(ctx context.Context, row []string) []string {
+		isHeader, isBool := (ctx.Value(CtxIsHeader)).(bool)
+		if isBool && isHeader {
+			return row
+		}
+
+		if seen.addIfAbsent(rowKey(row, columns)) {
+			return nil
+		}
+
+		return row
+	}
+}
+
 // ChainTransformers can be used to chain multiple transformers and run them one after another for each row.
 // Eg: csvprocessor.ChainTransformers(csvprocessor.AddRowNoTransformer("S.no"), csvprocessor.ReplaceValuesTransformer(valsMap))
 // Will add a 'S.no' row and then replace value based on the valsMap.
+// If a transformer filters out the row (returns nil), the remaining transformers are not run.
 func ChainTransformers(transformers ...CsvRowTransformer) CsvRowTransformer {
 	return func(ctx context.Context, row []string) []string {
+		if p, m := profilerOf(ctx), metricsOf(ctx); p != nil || m != nil {
+			timeChain(ctx, p, m, len(transformers), func(i int) any { return transformers[i] }, func(ctx context.Context, i int) bool {
+				row = transformers[i](ctx, row)
+				return row != nil
+			})
+
+			return row
+		}
+
 		for _, transformer := range transformers {
-			row = transformer(ctx, row)
+			if row = transformer(ctx, row); row == nil {
+				return nil
+			}
 		}
 
 		return row
 	}
 }
 
+// InPlaceTransformer represents a transformer that modifies the row in place instead of returning the transformed row,
+// Eg: by setting the values or appending columns to *row. It returns false if the row must be dropped.
+// See WithInPlaceTransformer().
+type InPlaceTransformer func(ctx context.Context, row *[]string) bool
+
+// ChainInPlaceTransformers chains multiple in-place transformers, they are run in the given order on the same row.
+// If a transformer drops the row (returns false), the remaining transformers are not run.
+func ChainInPlaceTransformers(transformers ...InPlaceTransformer) InPlaceTransformer {
+	return func(ctx context.Context, row *[]string) bool {
+		if p, m := profilerOf(ctx), metricsOf(ctx); p != nil || m != nil {
+			return timeChain(ctx, p, m, len(transformers), func(i int) any { return transformers[i] }, func(ctx context.Context, i int) bool {
+				return transformers[i](ctx, row)
+			})
+		}
+
+		for _, transformer := range transformers {
+			if !transformer(ctx, row) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// rowTransformer adapts the in-place transformer to CsvRowTransformer.
+// The data rows are copied into a buffer that is reused for all the rows, so that appending columns does not allocate
+// once the buffer has grown to the size of the transformed row.
+func (t InPlaceTransformer) rowTransformer() CsvRowTransformer {
+	var buffer []string
+	return func(ctx context.Context, row []string) []string {
+		if isHeader(ctx) {
+			// writers like JSONWriter retain the header, so it is not written to the buffer.
+			header := row
+			if !t(ctx, &header) {
+				return nil
+			}
+
+			return header
+		}
+
+		buffer = append(buffer[:0], row...)
+		if !t(ctx, &buffer) {
+			return nil
+		}
+
+		return buffer
+	}
+}
+
 // addToSliceAtIndex adds the given value at particular index and shifts the remaining elements to the left.
 func addToSliceAtIndex(slice []string, val string, index int) []string {
 	slice = append(slice, "")
@@ -100,3 +369,50 @@ func addToSliceAtIndex(slice []string, val string, index int) []string {
 
 	return slice
 }
+
+// removeFromSlice returns a new slice without the elements at the given indices.
+func removeFromSlice(slice []string, indices []int) []string {
+	drop := make(map[int]struct{}, len(indices))
+	for _, index := range indices {
+		drop[index] = struct{}{}
+	}
+
+	result := make([]string, 0, len(slice))
+	for i, val := range slice {
+		if _, ok := drop[i]; !ok {
+			result = append(result, val)
+		}
+	}
+
+	return result
+}
+
+// rowKey builds a key from the values of the given columns of the row, if no columns are given all the values are used.
+// Each value is prefixed with its length so that different rows cannot produce the same key.
+func rowKey(row []string, columns []int) string {
+	var key strings.Builder
+
+	appendValue := func(val string) {
+		key.WriteString(strconv.Itoa(len(val)))
+		key.WriteByte(':')
+		key.WriteString(val)
+	}
+
+	if len(columns) == 0 {
+		for _, val := range row {
+			appendValue(val)
+		}
+
+		return key.String()
+	}
+
+	for _, column := range columns {
+		if column >= 0 && column < len(row) {
+			appendValue(row[column])
+		} else {
+			key.WriteByte('-')
+		}
+	}
+
+	return key.String()
+}
@@ -2,7 +2,9 @@ package csvprocessor_test
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/sivaramasubramanian/csvprocessor"
@@ -102,6 +104,84 @@ func TestAddChunkRowNoTransformer(t *testing.T) {
 	}
 }
 
+func TestAddRowNoTransformerWithOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  csvprocessor.RowNoOptions
+		chunk bool
+		want  string
+	}{
+		{name: "Test default options", want: "n,a\n1,x\n2,y\nn,a\n3,z\n"},
+		{name: "Test offset", opts: csvprocessor.RowNoOptions{Offset: 1000}, want: "n,a\n1001,x\n1002,y\nn,a\n1003,z\n"},
+		{name: "Test step", opts: csvprocessor.RowNoOptions{Step: 10}, want: "n,a\n1,x\n11,y\nn,a\n21,z\n"},
+		{name: "Test width", opts: csvprocessor.RowNoOptions{Offset: 8, Width: 3}, want: "n,a\n009,x\n010,y\nn,a\n011,z\n"},
+		{name: "Test last column", opts: csvprocessor.RowNoOptions{Index: -1}, want: "a,n\nx,1\ny,2\na,n\nz,3\n"},
+		{name: "Test index beyond the row", opts: csvprocessor.RowNoOptions{Index: 5}, want: "a,n\nx,1\ny,2\na,n\nz,3\n"},
+		{name: "Test chunk row no", opts: csvprocessor.RowNoOptions{Offset: 100, Index: -1}, chunk: true, want: "a,n\nx,101\ny,102\na,n\nz,101\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformer := csvprocessor.AddRowNoTransformerWithOptions("n", tt.opts)
+			if tt.chunk {
+				transformer = csvprocessor.AddChunkRowNoTransformerWithOptions("n", tt.opts)
+			}
+
+			chunks := make([]strings.Builder, 2)
+			proc := newProcessor(t, strings.NewReader("a\nx\ny\nz\n"), chunks,
+				csvprocessor.WithTransformer(transformer), csvprocessor.WithChunkSize(2))
+			if err := proc.Process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if got := chunks[0].String() + chunks[1].String(); got != tt.want {
+				t.Errorf("Process() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddRowNoTransformerWithOptions_Middle(t *testing.T) {
+	transformer := csvprocessor.AddRowNoTransformerWithOptions("n", csvprocessor.RowNoOptions{Index: 1, Step: 2, Width: 2})
+	ctx := context.WithValue(context.TODO(), csvprocessor.CtxRowNum, 3)
+	if got, want := transformer(ctx, []string{"a", "b"}), []string{"a", "05", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AddRowNoTransformerWithOptions() = %v, want %v", got, want)
+	}
+}
+
+func TestAddChunkRowNoTransformer_Filtered(t *testing.T) {
+	var chunkRowNums []int
+	dropB := func(ctx context.Context, row []string) []string {
+		if n, ok := ctx.Value(csvprocessor.CtxChunkRowNum).(int); ok {
+			chunkRowNums = append(chunkRowNums, n)
+		}
+
+		if row[0] == "b" {
+			return nil
+		}
+
+		return row
+	}
+
+	chunks := make([]strings.Builder, 2)
+	proc := newProcessor(t, strings.NewReader("v\na\nb\nc\nd\ne\n"), chunks, csvprocessor.WithChunkSize(2),
+		csvprocessor.WithTransformer(csvprocessor.ChainTransformers(dropB, csvprocessor.AddChunkRowNoTransformer("n"))))
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	// the dropped row is not counted, and the first row of a chunk is transformed before the header of the chunk is written.
+	want := []string{"n,v\n1,a\n2,c\n", "n,v\n1,d\n2,e\n"}
+	for i := range want {
+		if got := chunks[i].String(); got != want[i] {
+			t.Errorf("chunk %d = %q, want %q", i+1, got, want[i])
+		}
+	}
+
+	if want := []int{-1, 1, 2, 2, 1, -1, 2}; !reflect.DeepEqual(chunkRowNums, want) {
+		t.Errorf("CtxChunkRowNum = %v, want %v", chunkRowNums, want)
+	}
+}
+
 func TestAddConstantColumnTransformer(t *testing.T) {
 	transformer := csvprocessor.AddConstantColumnTransformer("const column", "hello", 2)
 
@@ -241,3 +321,277 @@ func TestChainTransformers(t *testing.T) {
 		})
 	}
 }
+
+func TestChainTransformersStopsOnFilteredRow(t *testing.T) {
+	calls := 0
+	filter := func(ctx context.Context, row []string) []string { return nil }
+	count := func(ctx context.Context, row []string) []string {
+		calls++
+		return append(row, "x")
+	}
+
+	ctx := context.WithValue(context.TODO(), csvprocessor.CtxIsHeader, false)
+	if actual := csvprocessor.ChainTransformers(filter, count)(ctx, []string{"a"}); actual != nil {
+		t.Errorf("ChainTransformers() = %v, want nil", actual)
+	}
+
+	if calls != 0 {
+		t.Errorf("transformer after the filter was called %d times, want 0", calls)
+	}
+}
+
+func TestMergeColumnsTransformer(t *testing.T) {
+	type args struct {
+		ctx        context.Context //nolint:containedctx
+		dropSource bool
+		columns    []int
+		inputRow   []string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "Test merge columns",
+			args: args{
+				ctx:      context.WithValue(context.TODO(), csvprocessor.CtxIsHeader, false),
+				columns:  []int{0, 2},
+				inputRow: []string{"a", "b", "c"},
+			},
+			want: []string{"a", "b", "c", "a-c"},
+		},
+		{
+			name: "Test merge columns and drop source",
+			args: args{
+				ctx:        context.WithValue(context.TODO(), csvprocessor.CtxIsHeader, false),
+				dropSource: true,
+				columns:    []int{0, 2},
+				inputRow:   []string{"a", "b", "c"},
+			},
+			want: []string{"b", "a-c"},
+		},
+		{
+			name: "Test header row",
+			args: args{
+				ctx:        context.WithValue(context.TODO(), csvprocessor.CtxIsHeader, true),
+				dropSource: true,
+				columns:    []int{0, 1},
+				inputRow:   []string{"a", "b", "c"},
+			},
+			want: []string{"c", "key"},
+		},
+		{
+			name: "Test column out of range",
+			args: args{
+				ctx:      context.WithValue(context.TODO(), csvprocessor.CtxIsHeader, false),
+				columns:  []int{1, 5},
+				inputRow: []string{"a", "b"},
+			},
+			want: []string{"a", "b", "b-"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformer := csvprocessor.MergeColumnsTransformer("key", "-", tt.args.dropSource, tt.args.columns...)
+			actual := transformer(tt.args.ctx, tt.args.inputRow)
+			if !reflect.DeepEqual(actual, tt.want) {
+				t.Errorf("MergeColumnsTransformer() = %v, want %v", actual, tt.want)
+			}
+		})
+	}
+}
+
+func TestArithmeticTransformer(t *testing.T) {
+	type args struct {
+		ctx       context.Context //nolint:containedctx
+		op        csvprocessor.ArithmeticOp
+		precision int
+		columns   []int
+		inputRow  []string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "Test sum",
+			args: args{
+				ctx:       context.WithValue(context.TODO(), csvprocessor.CtxIsHeader, false),
+				op:        csvprocessor.OpSum,
+				precision: 2,
+				columns:   []int{0, 1, 2},
+				inputRow:  []string{"1", "2.5", " 3 "},
+			},
+			want: []string{"1", "2.5", " 3 ", "6.50"},
+		},
+		{
+			name: "Test difference",
+			args: args{
+				ctx:      context.WithValue(context.TODO(), csvprocessor.CtxIsHeader, false),
+				op:       csvprocessor.OpDifference,
+				columns:  []int{1, 0},
+				inputRow: []string{"4", "10"},
+			},
+			want: []string{"4", "10", "6"},
+		},
+		{
+			name: "Test product",
+			args: args{
+				ctx:       context.WithValue(context.TODO(), csvprocessor.CtxIsHeader, false),
+				op:        csvprocessor.OpProduct,
+				precision: 1,
+				columns:   []int{0, 1},
+				inputRow:  []string{"4", "2.5"},
+			},
+			want: []string{"4", "2.5", "10.0"},
+		},
+		{
+			name: "Test percentage",
+			args: args{
+				ctx:       context.WithValue(context.TODO(), csvprocessor.CtxIsHeader, false),
+				op:        csvprocessor.OpPercentage,
+				precision: 1,
+				columns:   []int{0, 1},
+				inputRow:  []string{"1", "3"},
+			},
+			want: []string{"1", "3", "33.3"},
+		},
+		{
+			name: "Test percentage of zero",
+			args: args{
+				ctx:      context.WithValue(context.TODO(), csvprocessor.CtxIsHeader, false),
+				op:       csvprocessor.OpPercentage,
+				columns:  []int{0, 1},
+				inputRow: []string{"1", "0"},
+			},
+			want: []string{"1", "0", "NaN"},
+		},
+		{
+			name: "Test invalid number",
+			args: args{
+				ctx:      context.WithValue(context.TODO(), csvprocessor.CtxIsHeader, false),
+				op:       csvprocessor.OpSum,
+				columns:  []int{0, 1},
+				inputRow: []string{"1", "abc"},
+			},
+			want: []string{"1", "abc", "NaN"},
+		},
+		{
+			name: "Test header row",
+			args: args{
+				ctx:      context.WithValue(context.TODO(), csvprocessor.CtxIsHeader, true),
+				op:       csvprocessor.OpSum,
+				columns:  []int{0, 1},
+				inputRow: []string{"a", "b"},
+			},
+			want: []string{"a", "b", "total"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformer := csvprocessor.ArithmeticTransformer("total", tt.args.op, tt.args.precision, "NaN", tt.args.columns...)
+			actual := transformer(tt.args.ctx, tt.args.inputRow)
+			if !reflect.DeepEqual(actual, tt.want) {
+				t.Errorf("ArithmeticTransformer() = %v, want %v", actual, tt.want)
+			}
+		})
+	}
+}
+
+func TestDedupTransformer(t *testing.T) {
+	rows := [][]string{{"1", "a"}, {"2", "a"}, {"1", "a"}, {"1", "b"}, {"2", "a"}}
+	tests := []struct {
+		name        string
+		transformer csvprocessor.CsvRowTransformer
+		want        [][]string
+	}{
+		{
+			name:        "Test dedup by entire row",
+			transformer: csvprocessor.DedupTransformer(),
+			want:        [][]string{{"1", "a"}, {"2", "a"}, nil, {"1", "b"}, nil},
+		},
+		{
+			name:        "Test dedup by key column",
+			transformer: csvprocessor.DedupTransformer(1),
+			want:        [][]string{{"1", "a"}, nil, nil, {"1", "b"}, nil},
+		},
+		{
+			name:        "Test approximate dedup by entire row",
+			transformer: csvprocessor.ApproxDedupTransformer(100, 0.0001),
+			want:        [][]string{{"1", "a"}, {"2", "a"}, nil, {"1", "b"}, nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := tt.transformer(context.WithValue(context.TODO(), csvprocessor.CtxIsHeader, true), []string{"id", "val"})
+			if !reflect.DeepEqual(header, []string{"id", "val"}) {
+				t.Errorf("Dedup header = %v, want %v", header, []string{"id", "val"})
+			}
+
+			for i, row := range rows {
+				actual := tt.transformer(context.WithValue(context.TODO(), csvprocessor.CtxIsHeader, false), row)
+				if !reflect.DeepEqual(actual, tt.want[i]) {
+					t.Errorf("Dedup row %d = %v, want %v", i, actual, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWithInPlaceTransformer(t *testing.T) {
+	var input strings.Builder
+	input.WriteString("id,name\n")
+	for i := 1; i <= 1000; i++ {
+		fmt.Fprintf(&input, "%d,name%d\n", i, i)
+	}
+
+	upper := func(_ context.Context, row *[]string) bool {
+		(*row)[1] = strings.ToUpper((*row)[1])
+		return true
+	}
+	addColumn := func(ctx context.Context, row *[]string) bool {
+		if isHeader, _ := ctx.Value(csvprocessor.CtxIsHeader).(bool); isHeader {
+			*row = append(*row, "source")
+			return true
+		}
+
+		// odd rows are dropped.
+		if rowNum, _ := ctx.Value(csvprocessor.CtxRowNum).(int); rowNum%2 == 1 {
+			return false
+		}
+
+		*row = append(*row, "csv")
+		return true
+	}
+
+	output, err := processString(t, input.String(),
+		csvprocessor.WithInPlaceTransformer(csvprocessor.ChainInPlaceTransformers(upper, addColumn)),
+	)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if want := "id,NAME,source\n2,NAME2,csv\n4,NAME4,csv\n"; !strings.HasPrefix(output, want) {
+		t.Errorf("Process() output = %q..., want prefix %q", output[:50], want)
+	}
+
+	if rows := strings.Count(output, "\n"); rows != 501 {
+		t.Errorf("Process() output rows = %d, want 501", rows)
+	}
+
+	// appending a column to the reused row does not allocate a new row for every row.
+	allocs := testing.AllocsPerRun(5, func() {
+		_, _ = processString(t, input.String(), csvprocessor.WithTransformer(csvprocessor.AddConstantColumnTransformer("source", "csv", 2)))
+	})
+	inPlaceAllocs := testing.AllocsPerRun(5, func() {
+		_, _ = processString(t, input.String(), csvprocessor.WithInPlaceTransformer(func(_ context.Context, row *[]string) bool {
+			*row = append(*row, "csv")
+			return true
+		}))
+	})
+	if inPlaceAllocs > allocs-1000 {
+		t.Errorf("allocations with in-place transformer = %v, want at least 1000 less than %v", inPlaceAllocs, allocs)
+	}
+}
@@ -0,0 +1,134 @@
+package csvprocessor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrInvalidMaxCells is returned when the max no. of cells to transpose is not > 0.
+	ErrInvalidMaxCells = errors.New("csvprocessor: max cells must be > 0")
+
+	// ErrTooManyCells is returned when the input to transpose has more cells than the limit.
+	ErrTooManyCells = errors.New("csvprocessor: too many cells to transpose")
+)
+
+// DefaultMaxTransposeCells is the default limit on the no. of cells of the input to transpose.
+const DefaultMaxTransposeCells = 1_000_000
+
+// WithTranspose swaps the rows and columns of the input, the first column becomes the header if the input has one.
+// Eg: "name,alice,bob\nage,30,40" is written as "name,age\nalice,30\nbob,40".
+// The entire input is held in memory, so the input can have at most maxCells cells (see DefaultMaxTransposeCells),
+// else ErrTooManyCells is returned. The rows can have a different no. of fields, the short rows are padded with empty values.
+func WithTranspose(maxCells int) Option {
+	return func(c *Processor) error {
+		if maxCells <= 0 {
+			return ErrInvalidMaxCells
+		}
+
+		c.transposeCells = maxCells
+		return nil
+	}
+}
+
+// Transpose writes the rows of the reader to the writer with the rows and columns swapped, see WithTranspose(). Eg:
+//
+//	err := csvprocessor.Transpose(csv.NewReader(input), csv.NewWriter(output), csvprocessor.DefaultMaxTransposeCells)
+//
+// The writer is flushed after the rows are written.
+func Transpose(reader CsvReader, writer CsvWriter, maxCells int) error {
+	if maxCells <= 0 {
+		return ErrInvalidMaxCells
+	}
+
+	transposed := newTransposeReader(reader, maxCells, nil)
+	for {
+		row, err := transposed.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return flushToFile(writer)
+}
+
+// transposeReader is a CsvReader that returns the columns of the reader as rows.
+type transposeReader struct {
+	reader   CsvReader
+	maxCells int
+	budget   *memoryBudget // bounds the rows held in memory, see WithMaxMemory().
+
+	read    bool
+	err     error // error encountered while reading the input.
+	rows    [][]string
+	columns int // no. of columns in the widest row.
+	next    int // index of the next column to return.
+	record  []string
+}
+
+func newTransposeReader(reader CsvReader, maxCells int, budget *memoryBudget) *transposeReader {
+	return &transposeReader{reader: reader, maxCells: maxCells, budget: budget}
+}
+
+func (t *transposeReader) Read() ([]string, error) {
+	if !t.read {
+		t.read = true
+		t.err = t.readAll()
+	}
+
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	if t.next >= t.columns {
+		return nil, io.EOF
+	}
+
+	t.record = t.record[:0]
+	for _, row := range t.rows {
+		t.record = append(t.record, valueAt(row, t.next))
+	}
+
+	t.next++
+	return t.record, nil
+}
+
+// readAll reads the entire input into memory.
+func (t *transposeReader) readAll() error {
+	cells := 0
+	for {
+		row, err := readWaiting(t.reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil && row == nil {
+			return err
+		}
+
+		// the rows with a different no. of fields are transposed, they are padded with empty values.
+		cells += len(row)
+		if cells > t.maxCells {
+			return fmt.Errorf("%w: the input has more than %d cells", ErrTooManyCells, t.maxCells)
+		}
+
+		if !t.budget.reserve(rowSize(row)) {
+			return t.budget.exceeded("WithTranspose()")
+		}
+
+		if len(row) > t.columns {
+			t.columns = len(row)
+		}
+
+		t.rows = append(t.rows, append([]string(nil), row...))
+	}
+}
@@ -0,0 +1,68 @@
+package csvprocessor_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithTranspose(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opts    []csvprocessor.Option
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "Test transpose",
+			input: "name,alice,bob\nage,30,40\n",
+			opts:  []csvprocessor.Option{csvprocessor.WithTranspose(csvprocessor.DefaultMaxTransposeCells)},
+			want:  "name,age\nalice,30\nbob,40\n",
+		},
+		{
+			name:  "Test transpose pads short rows",
+			input: "key,a,b,c\nvalue,1\n",
+			opts:  []csvprocessor.Option{csvprocessor.WithTranspose(csvprocessor.DefaultMaxTransposeCells)},
+			want:  "key,value\na,1\nb,\nc,\n",
+		},
+		{
+			name:    "Test too many cells",
+			input:   "a,b,c\nd,e,f\n",
+			opts:    []csvprocessor.Option{csvprocessor.WithTranspose(5)},
+			wantErr: csvprocessor.ErrTooManyCells,
+		},
+		{
+			name:    "Test invalid max cells",
+			opts:    []csvprocessor.Option{csvprocessor.WithTranspose(0)},
+			wantErr: csvprocessor.ErrInvalidMaxCells,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processFile(t, tt.input, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	var output strings.Builder
+	err := csvprocessor.Transpose(csv.NewReader(strings.NewReader("a,b\nc,d\ne,f\n")), csv.NewWriter(&output), 100)
+	if err != nil {
+		t.Fatalf("Transpose() error = %v", err)
+	}
+
+	if want := "a,c,e\nb,d,f\n"; output.String() != want {
+		t.Errorf("Transpose() output = %q, want %q", output.String(), want)
+	}
+}
@@ -0,0 +1,188 @@
+package csvprocessor
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WithFirstPass makes the processing two-pass: the collectors are run on all the rows of the input in a first pass,
+// then the input is read again and the rows are transformed and written in the second pass. So the transformers
+// can depend on the results of the first pass, Eg: to scale the values of the 3rd column by the max. value:
+//
+//	stats := csvprocessor.NewStatsCollector()
+//	proc, err := csvprocessor.New(
+//		csvprocessor.WithFileReader("orders.csv"),
+//		csvprocessor.WithFirstPass(stats.Transformer()),
+//		csvprocessor.WithTransformer(csvprocessor.RowsOnly(func(ctx context.Context, row []string) []string {
+//			amount, _ := strconv.ParseFloat(row[2], 64)
+//			row[2] = strconv.FormatFloat(amount/stats.Columns()[2].MaxNumber, 'f', 4, 64)
+//			return row
+//		})),
+//		...
+//	)
+//
+// The collectors are run in the given order on the header and the rows read using the same input options as the second pass
+// (Eg: WithSkipRows(), WithSortBy()), with the same context values as the transformers (CtxIsHeader, CtxRowNum, CtxHeader...).
+// The rows returned by the collectors are ignored, and the rows passed to them are reused, so they must be copied to be kept.
+// The rows that cannot be parsed are skipped in the first pass, they are handled in the second pass.
+//
+// Like WithPreScan(), the inputs must be seekable (Eg: files) else ErrInputNotRewindable is returned, and WithJoin() is not supported.
+// If the input is sampled, the same rows are sampled in both the passes.
+func WithFirstPass(collectors ...CsvRowTransformer) Option {
+	return func(c *Processor) error {
+		c.firstPass = nil
+		if len(collectors) > 0 {
+			c.firstPass = ChainTransformers(collectors...)
+		}
+
+		return nil
+	}
+}
+
+// runFirstPass runs the collectors of WithFirstPass() on the rows of the inputs and rewinds them.
+func (c *Processor) runFirstPass(ctx context.Context, scan *inputScan) (err error) {
+	if c.firstPass == nil {
+		return nil
+	}
+
+	if c.join != nil {
+		return fmt.Errorf("%w: the input of WithJoin() cannot be read twice", ErrInputNotRewindable)
+	}
+
+	offsets, err := c.inputOffsets()
+	if err != nil {
+		return err
+	}
+
+	reader, release, err := c.inputPipeline(nil)
+	defer func() {
+		releaseErr := release()
+		if err == nil {
+			err = releaseErr
+		}
+	}()
+
+	if err != nil {
+		return err
+	}
+
+	passCtx := newCtx(ctx)
+	if scan != nil {
+		passCtx.setValue(CtxTotalRows, scan.rows)
+	}
+
+	readHeader, rowNum := c.hasHeader(), 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("csvprocessor: error while reading input in the first pass: %w", err)
+		}
+
+		if readHeader {
+			readHeader = false
+			header := append([]string(nil), row...)
+			if len(header) > 0 {
+				header[0] = strings.TrimPrefix(header[0], utf8BOM)
+			}
+
+			setHeaderCtx(passCtx, header)
+			passCtx.setValue(CtxIsHeader, true)
+			passCtx.setValue(CtxRowNum, -1)
+			if err := c.collect(passCtx, -1, append([]string(nil), header...)); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		rowNum++
+		passCtx.setValue(CtxIsHeader, false)
+		passCtx.setValue(CtxRowNum, rowNum)
+		if err := c.collect(passCtx, rowNum, row); err != nil {
+			return err
+		}
+	}
+
+	return c.rewindInputs(offsets)
+}
+
+// collect runs the collectors on the row, a panic in the collectors is returned as an error.
+func (c *Processor) collect(ctx *csvCtx, rowNum int, row []string) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			panicErr, ok := recovered.(error)
+			if !ok {
+				panicErr = fmt.Errorf("%w: %v", ErrTransformerPanic, recovered)
+			}
+
+			err = &RowError{Row: rowNum, Err: panicErr}
+		}
+	}()
+
+	c.firstPass(ctx, row)
+	return nil
+}
+
+// StatsCollector collects the ColumnStats of the rows passed to its Transformer(), Eg: in the first pass (see WithFirstPass()),
+// so that the transformers of the second pass can use the min. and max. values, the lengths or the no. of distinct values.
+// The header row is used for the names of the columns.
+type StatsCollector struct {
+	columns columnCollector
+	result  []ColumnStats // cached result of Columns(), reset by every row.
+}
+
+// NewStatsCollector creates a StatsCollector.
+func NewStatsCollector() *StatsCollector {
+	return &StatsCollector{columns: columnCollector{columnStats: true}}
+}
+
+// Transformer returns the transformer that collects the stats of the rows, the rows are returned as they are.
+func (s *StatsCollector) Transformer() CsvRowTransformer {
+	return func(ctx context.Context, row []string) []string {
+		if isHeader(ctx) {
+			s.columns.setHeader(row)
+			return row
+		}
+
+		s.columns.add(row)
+		s.result = nil
+		return row
+	}
+}
+
+// Columns returns the stats of the columns of the rows collected so far, by the 0-based index of the column.
+func (s *StatsCollector) Columns() []ColumnStats {
+	if s.result == nil {
+		s.result = s.columns.result()
+	}
+
+	return s.result
+}
+
+// Column returns the stats of the column with the given name in the header, false if there is no such column.
+func (s *StatsCollector) Column(name string) (ColumnStats, bool) {
+	for _, column := range s.Columns() {
+		if column.Name == name {
+			return column, true
+		}
+	}
+
+	return ColumnStats{}, false
+}
@@ -0,0 +1,139 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithFirstPass(t *testing.T) {
+	stats := csvprocessor.NewStatsCollector()
+	scale := csvprocessor.RowsOnly(func(ctx context.Context, row []string) []string {
+		amount, _ := strconv.ParseFloat(row[1], 64) //nolint:errcheck
+		row[1] = strconv.FormatFloat(amount/stats.Columns()[1].MaxNumber, 'f', 2, 64)
+		return row
+	})
+
+	output, err := processFile(t, "id,amount\n1,10\n2,40\n3,20\n", csvprocessor.WithFirstPass(stats.Transformer()), csvprocessor.WithTransformer(scale))
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if want := "id,amount\n1,0.25\n2,1.00\n3,0.50\n"; output != want {
+		t.Errorf("Process() output = %q, want %q", output, want)
+	}
+
+	if amount, ok := stats.Column("amount"); !ok || amount.Count != 3 || amount.MinNumber != 10 {
+		t.Errorf("Column(amount) = %+v, %v", amount, ok)
+	}
+
+	if _, ok := stats.Column("price"); ok {
+		t.Errorf("Column(price) = true, want false")
+	}
+}
+
+func TestWithFirstPass_EnumEncoding(t *testing.T) {
+	// the first pass collects the distinct values, and the second pass replaces them by their index in the sorted values.
+	seen := map[string]bool{}
+	collect := csvprocessor.RowsOnly(func(ctx context.Context, row []string) []string {
+		seen[row[1]] = true
+		return row
+	})
+
+	var codes map[string]string
+	encode := csvprocessor.RowsOnly(func(ctx context.Context, row []string) []string {
+		if codes == nil {
+			values := make([]string, 0, len(seen))
+			for value := range seen {
+				values = append(values, value)
+			}
+
+			sort.Strings(values)
+			codes = map[string]string{}
+			for i, value := range values {
+				codes[value] = strconv.Itoa(i)
+			}
+		}
+
+		row[1] = codes[row[1]]
+		return row
+	})
+
+	output, err := processFile(t, "id,status\n1,paid\n2,new\n3,paid\n4,void\n", csvprocessor.WithFirstPass(collect), csvprocessor.WithTransformer(encode))
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if want := "id,status\n1,1\n2,0\n3,1\n4,2\n"; output != want {
+		t.Errorf("Process() output = %q, want %q", output, want)
+	}
+}
+
+func TestWithFirstPass_SameRows(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []csvprocessor.Option
+	}{
+		{name: "Test all rows"},
+		{name: "Test skip and limit", opts: []csvprocessor.Option{csvprocessor.WithSkipRows(2), csvprocessor.WithLimitRows(5)}},
+		{name: "Test sorted", opts: []csvprocessor.Option{csvprocessor.WithSortBy([]int{0}, csvprocessor.Descending)}},
+		{name: "Test sampled", opts: []csvprocessor.Option{csvprocessor.WithSample(0.5)}},
+		{name: "Test pre-scan", opts: []csvprocessor.Option{csvprocessor.WithPreScan(true)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var first, second []string
+			record := func(rows *[]string) csvprocessor.CsvRowTransformer {
+				return func(ctx context.Context, row []string) []string {
+					rowNum, _ := ctx.Value(csvprocessor.CtxRowNum).(int)
+					header, _ := ctx.Value(csvprocessor.CtxHeader).([]string)
+					*rows = append(*rows, strconv.Itoa(rowNum)+":"+strings.Join(row, "|")+":"+strings.Join(header, "|"))
+					return row
+				}
+			}
+
+			input := "id,v\n" + strings.Repeat("1,a\n2,b\n3,c\n4,d\n5,e\n6,f\n7,g\n8,h\n", 4)
+			opts := append(tt.opts, csvprocessor.WithFirstPass(record(&first)), csvprocessor.WithTransformer(record(&second)))
+			if _, err := processFile(t, input, opts...); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if len(first) < 2 || !reflect.DeepEqual(first, second) {
+				t.Errorf("first pass rows = %q, second pass rows = %q", first, second)
+			}
+		})
+	}
+}
+
+func TestWithFirstPass_Errors(t *testing.T) {
+	collect := csvprocessor.NoOpTransformer()
+	if _, err := processString(t, "id\n1\n", csvprocessor.WithFirstPass(collect)); !errors.Is(err, csvprocessor.ErrInputNotRewindable) {
+		t.Errorf("Process() error = %v, want %v", err, csvprocessor.ErrInputNotRewindable)
+	}
+
+	join := csvprocessor.WithJoin(csvprocessor.Join{Right: csv.NewReader(strings.NewReader("id\n1\n")), LeftKeys: []int{0}, RightKeys: []int{0}})
+	if _, err := processFile(t, "id\n1\n", join, csvprocessor.WithFirstPass(collect)); !errors.Is(err, csvprocessor.ErrInputNotRewindable) {
+		t.Errorf("Process() error = %v, want %v", err, csvprocessor.ErrInputNotRewindable)
+	}
+
+	panics := func(ctx context.Context, row []string) []string {
+		panic("collector")
+	}
+
+	output, err := processFile(t, "id\n1\n", csvprocessor.WithFirstPass(csvprocessor.RowsOnly(panics)))
+	var rowErr *csvprocessor.RowError
+	if !errors.As(err, &rowErr) || rowErr.Row != 1 || !errors.Is(err, csvprocessor.ErrTransformerPanic) {
+		t.Errorf("Process() error = %v, want %v in row 1", err, csvprocessor.ErrTransformerPanic)
+	}
+
+	if output != "" {
+		t.Errorf("Process() output = %q, want empty", output)
+	}
+}
@@ -0,0 +1,345 @@
+package csvprocessor
+
+import (
+	"context"
+	textencoding "encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrSkipRow can be returned by the function passed to Typed() to drop the row.
+	ErrSkipRow = errors.New("csvprocessor: skip row")
+	// ErrUnsupportedType is returned when a struct used for typed rows is not a struct, or has a field of an unsupported type.
+	ErrUnsupportedType = errors.New("csvprocessor: unsupported type for typed rows")
+)
+
+// Typed returns a transformer that decodes each row into a T, calls fn with it and encodes it back into the row,
+// so that transformers can work with named and typed fields instead of indexing the row.
+//
+// The struct fields are mapped to the columns by name using the `csv` tag, or the field name if there is no tag, Eg:
+//
+//	type Order struct {
+//		ID      int       `csv:"order_id"`
+//		Amount  float64   `csv:"amount"`
+//		Created time.Time `csv:"created_at,layout=2006-01-02"`
+//		Note    *string   `csv:"note"`   // nil for empty values.
+//		Ignored string    `csv:"-"`
+//	}
+//
+// If the input has no header (i.e SkipHeaders is true), the fields are mapped to the columns by their position.
+// The supported field types are string, bool, integers, floats, time.Time (RFC3339 unless a layout is given), pointers to them
+// and types implementing encoding.TextUnmarshaler and encoding.TextMarshaler. Empty values are decoded as the zero value.
+//
+// Only the fields changed by fn are encoded back, so the formatting of the other values is kept. The columns without a field
+// are not changed, and the header is not changed. If fn returns ErrSkipRow, the row is dropped.
+// Other errors, including the values that cannot be decoded, are returned from Process() as a RowError.
+func Typed[T any](fn func(ctx context.Context, record *T) error) CsvRowTransformer {
+	var zero T
+	codec, codecErr := codecFor(reflect.TypeOf(zero))
+	var columns []int
+
+	return func(ctx context.Context, row []string) []string {
+		if codecErr != nil {
+			// the processor returns the panics in the transformers as errors.
+			panic(codecErr)
+		}
+
+		if isHeader, _ := ctx.Value(CtxIsHeader).(bool); isHeader { //nolint:errcheck
+			// with multiple header rows, the first one has the column names.
+			if headerRowNum, _ := ctx.Value(CtxHeaderRowNum).(int); headerRowNum == 0 { //nolint:errcheck
+				columns = codec.columns(row)
+			}
+
+			return row
+		}
+
+		if columns == nil {
+			columns = codec.positions()
+		}
+
+		var record T
+		value := reflect.ValueOf(&record).Elem()
+		if err := codec.decode(row, columns, value); err != nil {
+			panic(err)
+		}
+
+		original := record
+		if err := fn(ctx, &record); err != nil {
+			if errors.Is(err, ErrSkipRow) {
+				return nil
+			}
+
+			panic(err)
+		}
+
+		row, err := codec.encodeChanged(row, columns, value, reflect.ValueOf(&original).Elem())
+		if err != nil {
+			panic(err)
+		}
+
+		return row
+	}
+}
+
+// structCodec maps the fields of a struct to the columns of a row.
+type structCodec struct {
+	fields []structField
+}
+
+type structField struct {
+	name   string
+	index  []int
+	layout string // layout of time.Time fields.
+}
+
+var (
+	structCodecs sync.Map // reflect.Type to *structCodec.
+
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*textencoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*textencoding.TextMarshaler)(nil)).Elem()
+)
+
+// codecFor returns the codec for the struct type, the codecs are cached.
+func codecFor(t reflect.Type) (*structCodec, error) {
+	if codec, ok := structCodecs.Load(t); ok {
+		return codec.(*structCodec), nil //nolint:forcetypeassert
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %v is not a struct", ErrUnsupportedType, t)
+	}
+
+	codec := &structCodec{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("csv")
+		if field.PkgPath != "" || tag == "-" {
+			// unexported or ignored field.
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		layout := time.RFC3339
+		for _, option := range strings.Split(options, ",") {
+			if strings.HasPrefix(option, "layout=") {
+				layout = strings.TrimPrefix(option, "layout=")
+			}
+		}
+
+		if !supportedType(field.Type) {
+			return nil, fmt.Errorf("%w: field %s of type %v", ErrUnsupportedType, field.Name, field.Type)
+		}
+
+		codec.fields = append(codec.fields, structField{name: name, index: field.Index, layout: layout})
+	}
+
+	structCodecs.Store(t, codec)
+	return codec, nil
+}
+
+func supportedType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch t.Kind() { //nolint:exhaustive
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// columns returns the index of the column of each field in the header, -1 if the header does not have the column.
+func (s *structCodec) columns(header []string) []int {
+	columns := make([]int, len(s.fields))
+	for i, field := range s.fields {
+		columns[i] = -1
+		for j, name := range header {
+			if strings.TrimSpace(name) == field.name {
+				columns[i] = j
+				break
+			}
+		}
+	}
+
+	return columns
+}
+
+// positions maps the fields to the columns by their position, for inputs without a header.
+func (s *structCodec) positions() []int {
+	columns := make([]int, len(s.fields))
+	for i := range columns {
+		columns[i] = i
+	}
+
+	return columns
+}
+
+// decode sets the fields of the struct from the values in the row.
+func (s *structCodec) decode(row []string, columns []int, value reflect.Value) error {
+	for i, field := range s.fields {
+		column := columns[i]
+		if column < 0 || column >= len(row) {
+			continue
+		}
+
+		if err := decodeField(row[column], value.FieldByIndex(field.index), field.layout); err != nil {
+			return fmt.Errorf("csvprocessor: column %q: %w", field.name, err)
+		}
+	}
+
+	return nil
+}
+
+// encodeChanged encodes the fields that are different from the original into the row.
+func (s *structCodec) encodeChanged(row []string, columns []int, value, original reflect.Value) ([]string, error) {
+	for i, field := range s.fields {
+		column := columns[i]
+		if column < 0 {
+			continue
+		}
+
+		fieldValue := value.FieldByIndex(field.index)
+		if reflect.DeepEqual(fieldValue.Interface(), original.FieldByIndex(field.index).Interface()) {
+			continue
+		}
+
+		encoded, err := encodeField(fieldValue, field.layout)
+		if err != nil {
+			return nil, fmt.Errorf("csvprocessor: column %q: %w", field.name, err)
+		}
+
+		for column >= len(row) {
+			row = append(row, "")
+		}
+
+		row[column] = encoded
+	}
+
+	return row, nil
+}
+
+func decodeField(value string, field reflect.Value, layout string) error {
+	if field.Kind() == reflect.Ptr {
+		if value == "" {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+
+	if value == "" && field.Kind() != reflect.String {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	if field.Type() == timeType {
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			return err
+		}
+
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if unmarshaler, ok := field.Addr().Interface().(textencoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(value))
+	}
+
+	switch field.Kind() { //nolint:exhaustive
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("%w: %v", ErrUnsupportedType, field.Type())
+	}
+
+	return nil
+}
+
+func encodeField(field reflect.Value, layout string) (string, error) {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return "", nil
+		}
+
+		field = field.Elem()
+	}
+
+	if field.Type() == timeType {
+		return field.Interface().(time.Time).Format(layout), nil //nolint:forcetypeassert
+	}
+
+	if field.CanAddr() && field.Addr().Type().Implements(textMarshalerType) {
+		field = field.Addr()
+	}
+
+	if field.Type().Implements(textMarshalerType) {
+		text, err := field.Interface().(textencoding.TextMarshaler).MarshalText() //nolint:forcetypeassert
+		return string(text), err
+	}
+
+	switch field.Kind() { //nolint:exhaustive
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return formatFloat(field.Float(), field.Type().Bits()), nil
+	default:
+		return "", fmt.Errorf("%w: %v", ErrUnsupportedType, field.Type())
+	}
+}
@@ -0,0 +1,131 @@
+package csvprocessor_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+type order struct {
+	ID      int       `csv:"order_id"`
+	Amount  float64   `csv:"amount"`
+	Created time.Time `csv:"created_at,layout=2006-01-02"`
+	Note    *string   `csv:"note"`
+	IP      net.IP    `csv:"ip"`
+	Status  string
+	Ignored string `csv:"-"`
+}
+
+func TestTyped(t *testing.T) {
+	input := "order_id,Status,amount,created_at,note,ip,extra\n" +
+		"1,new,1.50,2023-04-05,,10.0.0.1,x\n" +
+		"2,cancelled,3,2023-04-06,late,,y\n" +
+		"3,new,10,2023-04-07,,::1,z\n"
+
+	tests := []struct {
+		name    string
+		input   string
+		fn      func(ctx context.Context, o *order) error
+		opts    []csvprocessor.Option
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "unchanged values keep their format",
+			input: input,
+			fn: func(ctx context.Context, o *order) error {
+				return nil
+			},
+			want: input,
+		},
+		{
+			name:  "changed values are encoded",
+			input: input,
+			fn: func(ctx context.Context, o *order) error {
+				if o.Status == "cancelled" {
+					return csvprocessor.ErrSkipRow
+				}
+
+				if o.Note == nil {
+					note := "id " + strconv.Itoa(o.ID)
+					o.Note = &note
+				}
+
+				o.Amount *= 2
+				o.Created = o.Created.AddDate(0, 0, 1)
+				o.IP = net.ParseIP("192.168.0.1")
+				return nil
+			},
+			want: "order_id,Status,amount,created_at,note,ip,extra\n" +
+				"1,new,3,2023-04-06,id 1,192.168.0.1,x\n" +
+				"3,new,20,2023-04-08,id 3,192.168.0.1,z\n",
+		},
+		{
+			name:  "without header",
+			input: "7,5\n",
+			fn: func(ctx context.Context, o *order) error {
+				o.Amount++
+				return nil
+			},
+			opts: []csvprocessor.Option{csvprocessor.SkipHeaders(true)},
+			want: "7,6\n",
+		},
+		{
+			name:  "invalid value",
+			input: "order_id\nabc\n",
+			fn: func(ctx context.Context, o *order) error {
+				return nil
+			},
+			wantErr: strconv.ErrSyntax,
+		},
+		{
+			name:  "error from the function",
+			input: input,
+			fn: func(ctx context.Context, o *order) error {
+				return context.Canceled
+			},
+			wantErr: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := processString(t, tt.input, append(tt.opts, csvprocessor.WithTransformer(csvprocessor.Typed(tt.fn)))...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var rowErr *csvprocessor.RowError
+			if tt.wantErr != nil && !errors.As(err, &rowErr) {
+				t.Errorf("Process() error = %T, want a RowError", err)
+			}
+
+			if tt.wantErr == nil && got != tt.want {
+				t.Errorf("Process() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypedUnsupportedType(t *testing.T) {
+	type withMap struct {
+		Values map[string]string
+	}
+
+	transformers := []csvprocessor.CsvRowTransformer{
+		csvprocessor.Typed(func(ctx context.Context, s *string) error { return nil }),
+		csvprocessor.Typed(func(ctx context.Context, w *withMap) error { return nil }),
+	}
+
+	for _, transformer := range transformers {
+		_, err := processString(t, "a\n1\n", csvprocessor.WithTransformer(transformer))
+		if !errors.Is(err, csvprocessor.ErrUnsupportedType) {
+			t.Errorf("Process() error = %v, want %v", err, csvprocessor.ErrUnsupportedType)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Package wasm implements the host side of an ABI to run row transformers compiled to WebAssembly, so that pipelines
+// can be extended without recompiling the binary and with the sandboxing of the WebAssembly runtime.
+//
+// The modules are run with wazero by the wasm/wazero module, which loads a module from a path. Eg:
+//
+//	module, err := wazero.Load("plugins/mask.wasm")
+//	if err != nil {
+//		return err
+//	}
+//	defer module.Close()
+//
+//	csvprocessor.WithTransformer(wasm.Transformer(module, json.RawMessage(`{"columns": [2]}`)))
+//
+// Other runtimes can be used by implementing Module.
+//
+// # ABI
+//
+// The module must export its memory and these functions:
+//
+//	alloc(size i32) i32                  returns the offset of a buffer of size bytes in the memory.
+//	transform(offset i32, size i32) i64  transforms the row in the buffer, and returns the offset of the result in the
+//	                                     high 32 bits and its size in the low 32 bits. A size of 0 drops the row.
+//	configure(offset i32, size i32)      optional, called once before the first row with the params, if any.
+//
+// The row is passed to transform as a JSON object, Eg: {"row": ["1", "alice"], "header": false, "row_num": 1, "chunk_num": 1},
+// and the result is a JSON array of strings. The params are passed to configure as they are given, usually a JSON object.
+// The host does not free the buffers, so the module can reuse its memory between calls, Eg: by resetting a bump allocator in transform.
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// ErrABI is returned when the module does not follow the ABI, Eg: the result is outside the memory or is not a JSON array of strings.
+var ErrABI = errors.New("wasm: the module does not follow the ABI")
+
+// Module represents a WebAssembly module instance, Eg: a module loaded by the wasm/wazero module.
+type Module interface {
+	// Call calls the exported function with the params and returns its results.
+	Call(ctx context.Context, name string, params ...uint64) ([]uint64, error)
+	// Read returns size bytes of the memory at offset, or false if it is out of range.
+	Read(offset, size uint32) ([]byte, bool)
+	// Write writes the data to the memory at offset, or returns false if it is out of range.
+	Write(offset uint32, data []byte) bool
+}
+
+// input is the row passed to the transform function.
+type input struct {
+	Row      []string `json:"row"`
+	Header   bool     `json:"header"`
+	RowNum   int      `json:"row_num"`
+	ChunkNum int      `json:"chunk_num"`
+}
+
+// Transformer returns a transformer that calls the transform function of the module for each row.
+// If params is not empty, it is passed to the configure function of the module before the first row.
+// The module is called with the context of the row, and errors from the module are returned from Process() as a csvprocessor.RowError.
+// As a module instance is not safe for concurrent use, the transformer must not be shared by processors running concurrently.
+func Transformer(module Module, params json.RawMessage) csvprocessor.CsvRowTransformer {
+	configured := len(params) == 0 || string(params) == "null"
+
+	return func(ctx context.Context, row []string) []string {
+		if !configured {
+			configured = true
+			if err := configure(ctx, module, params); err != nil {
+				panic(err)
+			}
+		}
+
+		result, err := transform(ctx, module, row)
+		if err != nil {
+			// the processor returns the panics in the transformers as errors.
+			panic(err)
+		}
+
+		return result
+	}
+}
+
+func configure(ctx context.Context, module Module, params json.RawMessage) error {
+	offset, err := write(ctx, module, params)
+	if err != nil {
+		return err
+	}
+
+	if _, err := module.Call(ctx, "configure", uint64(offset), uint64(len(params))); err != nil {
+		return fmt.Errorf("wasm: configure: %w", err)
+	}
+
+	return nil
+}
+
+func transform(ctx context.Context, module Module, row []string) ([]string, error) {
+	isHeader, _ := ctx.Value(csvprocessor.CtxIsHeader).(bool) //nolint:errcheck
+	rowNum, _ := ctx.Value(csvprocessor.CtxRowNum).(int)      //nolint:errcheck
+	chunkNum, _ := ctx.Value(csvprocessor.CtxChunkNum).(int)  //nolint:errcheck
+
+	data, err := json.Marshal(input{Row: row, Header: isHeader, RowNum: rowNum, ChunkNum: chunkNum})
+	if err != nil {
+		return nil, err
+	}
+
+	offset, err := write(ctx, module, data)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := module.Call(ctx, "transform", uint64(offset), uint64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("wasm: transform: %w", err)
+	}
+
+	if len(results) != 1 {
+		return nil, fmt.Errorf("%w: transform returned %d results, want 1", ErrABI, len(results))
+	}
+
+	resultOffset, resultSize := uint32(results[0]>>32), uint32(results[0])
+	if resultSize == 0 {
+		return nil, nil
+	}
+
+	resultData, ok := module.Read(resultOffset, resultSize)
+	if !ok {
+		return nil, fmt.Errorf("%w: the result at %d of size %d is outside the memory", ErrABI, resultOffset, resultSize)
+	}
+
+	var result []string
+	if err := json.Unmarshal(resultData, &result); err != nil {
+		return nil, fmt.Errorf("%w: the result is not a JSON array of strings: %v", ErrABI, err)
+	}
+
+	if result == nil {
+		// a JSON null drops the row like a size of 0.
+		return nil, nil
+	}
+
+	return result, nil
+}
+
+// write allocates a buffer in the module and writes the data to it.
+func write(ctx context.Context, module Module, data []byte) (uint32, error) {
+	results, err := module.Call(ctx, "alloc", uint64(len(data)))
+	if err != nil {
+		return 0, fmt.Errorf("wasm: alloc: %w", err)
+	}
+
+	if len(results) != 1 {
+		return 0, fmt.Errorf("%w: alloc returned %d results, want 1", ErrABI, len(results))
+	}
+
+	offset := uint32(results[0])
+	if !module.Write(offset, data) {
+		return 0, fmt.Errorf("%w: the buffer at %d of size %d is outside the memory", ErrABI, offset, len(data))
+	}
+
+	return offset, nil
+}
+
+// Factory returns a csvprocessor.TransformerFactory that loads the module at the path given in the params, so that WebAssembly
+// transformers can be used in config files. The params are a JSON object with the path of the module, and the params passed to it.
+// Eg: {"name": "wasm", "params": {"path": "plugins/mask.wasm", "params": {"columns": [2]}}}
+// Register it with FactoryParams, Eg: with the loader of the wasm/wazero module,
+//
+//	csvprocessor.RegisterTransformer("wasm", wasm.Factory(wazero.LoadModule), wasm.FactoryParams...)
+func Factory(load func(path string) (Module, error)) csvprocessor.TransformerFactory {
+	return func(params json.RawMessage) (csvprocessor.CsvRowTransformer, error) {
+		var p struct {
+			Path   string          `json:"path"`
+			Params json.RawMessage `json:"params"`
+		}
+
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+
+		module, err := load(p.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		return Transformer(module, p.Params), nil
+	}
+}
+
+// FactoryParams is the parameter schema of Factory().
+var FactoryParams = []csvprocessor.TransformerParam{
+	{Name: "path", Type: csvprocessor.ParamString, Required: true, Description: "path of the WebAssembly module"},
+	{Name: "params", Type: csvprocessor.ParamAny, Description: "params passed to the configure function of the module"},
+}
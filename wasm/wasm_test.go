@@ -0,0 +1,216 @@
+package wasm_test
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+	"github.com/sivaramasubramanian/csvprocessor/wasm"
+)
+
+// fakeModule implements the ABI in Go: it upper-cases the configured column and drops the rows with "drop" in any column.
+type fakeModule struct {
+	memory []byte
+	next   uint32
+	column int
+
+	badResult bool
+	calls     []string
+}
+
+func newFakeModule() *fakeModule {
+	return &fakeModule{memory: make([]byte, 1<<16), column: 1}
+}
+
+func (m *fakeModule) Call(_ context.Context, name string, params ...uint64) ([]uint64, error) {
+	m.calls = append(m.calls, name)
+
+	switch name {
+	case "alloc":
+		offset := m.next
+		m.next += uint32(params[0])
+		return []uint64{uint64(offset)}, nil
+	case "configure":
+		var p struct {
+			Column int `json:"column"`
+		}
+
+		if err := json.Unmarshal(m.memory[params[0]:params[0]+params[1]], &p); err != nil {
+			return nil, err
+		}
+
+		m.column = p.Column
+		return nil, nil
+	case "transform":
+		var in struct {
+			Row    []string `json:"row"`
+			Header bool     `json:"header"`
+			RowNum int      `json:"row_num"`
+		}
+
+		if err := json.Unmarshal(m.memory[params[0]:params[0]+params[1]], &in); err != nil {
+			return nil, err
+		}
+
+		for i, value := range in.Row {
+			if value == "drop" {
+				return []uint64{0}, nil
+			}
+
+			if i == m.column && !in.Header {
+				in.Row[i] = strings.ToUpper(value)
+			}
+		}
+
+		if in.Header {
+			in.Row = append(in.Row, "row_num")
+		} else {
+			in.Row = append(in.Row, fmt.Sprint(in.RowNum))
+		}
+
+		result, _ := json.Marshal(in.Row) //nolint:errcheck
+		if m.badResult {
+			result = []byte(`{"row": 1}`)
+		}
+
+		offset := m.next
+		copy(m.memory[offset:], result)
+		m.next = 0 // reset the bump allocator.
+		return []uint64{uint64(offset)<<32 | uint64(len(result))}, nil
+	default:
+		return nil, fmt.Errorf("function %q is not exported", name)
+	}
+}
+
+func (m *fakeModule) Read(offset, size uint32) ([]byte, bool) {
+	if int(offset)+int(size) > len(m.memory) {
+		return nil, false
+	}
+
+	return m.memory[offset : offset+size], true
+}
+
+func (m *fakeModule) Write(offset uint32, data []byte) bool {
+	if int(offset)+len(data) > len(m.memory) {
+		return false
+	}
+
+	copy(m.memory[offset:], data)
+	return true
+}
+
+func process(input string, transformer csvprocessor.CsvRowTransformer) (string, error) {
+	var output strings.Builder
+	proc, err := csvprocessor.New(
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader(input))),
+		csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(&output), nil
+		}),
+		csvprocessor.WithChunkSize(math.MaxInt32),
+		csvprocessor.WithLogger(func(string, ...any) {}),
+		csvprocessor.WithTransformer(transformer),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	err = proc.Process()
+	return output.String(), err
+}
+
+func TestTransformer(t *testing.T) {
+	input := "id,name\n1,alice\n2,drop\n3,carol\n"
+
+	tests := []struct {
+		name      string
+		params    string
+		badResult bool
+		want      string
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "without params",
+			want:      "id,name,row_num\n1,ALICE,1\n3,CAROL,3\n",
+			wantCalls: 8, // alloc and transform for each row.
+		},
+		{
+			name:      "with params",
+			params:    `{"column": 0}`,
+			want:      "id,name,row_num\n1,alice,1\n3,carol,3\n",
+			wantCalls: 10,
+		},
+		{
+			name:      "null params",
+			params:    `null`,
+			want:      "id,name,row_num\n1,ALICE,1\n3,CAROL,3\n",
+			wantCalls: 8,
+		},
+		{
+			name:      "invalid result",
+			badResult: true,
+			wantErr:   wasm.ErrABI,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			module := newFakeModule()
+			module.badResult = tt.badResult
+
+			got, err := process(input, wasm.Transformer(module, json.RawMessage(tt.params)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("Process() output = %q, want %q", got, tt.want)
+			}
+
+			if len(module.calls) != tt.wantCalls {
+				t.Errorf("calls = %q, want %d calls", module.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestFactory(t *testing.T) {
+	registry := csvprocessor.NewTransformerRegistry()
+	var loaded string
+	err := registry.Register("wasm", wasm.Factory(func(path string) (wasm.Module, error) {
+		loaded = path
+		return newFakeModule(), nil
+	}), wasm.FactoryParams...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transformer, err := registry.New("wasm", json.RawMessage(`{"path": "mask.wasm", "params": {"column": 0}}`))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	got, err := process("id,name\nx,y\n", transformer)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if want := "id,name,row_num\nX,y,1\n"; got != want || loaded != "mask.wasm" {
+		t.Errorf("Process() output = %q, loaded %q, want %q, loaded mask.wasm", got, loaded, want)
+	}
+
+	if _, err := registry.New("wasm", json.RawMessage(`{"params": {}}`)); !errors.Is(err, csvprocessor.ErrInvalidTransformerParams) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidTransformerParams)
+	}
+}
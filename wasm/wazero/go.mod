@@ -0,0 +1,10 @@
+module github.com/sivaramasubramanian/csvprocessor/wasm/wazero
+
+go 1.18
+
+replace github.com/sivaramasubramanian/csvprocessor => ../..
+
+require (
+	github.com/sivaramasubramanian/csvprocessor v0.0.0
+	github.com/tetratelabs/wazero v1.3.1
+)
@@ -0,0 +1,104 @@
+// Package wazero runs the WebAssembly transformers of the wasm package with wazero, a WebAssembly runtime written in Go.
+//
+// It is a separate module so that only the programs using it depend on wazero. Eg: to run a module built for the ABI
+// described in the wasm package,
+//
+//	module, err := wazero.Load("plugins/mask.wasm")
+//	if err != nil {
+//		return err
+//	}
+//	defer module.Close()
+//
+//	csvprocessor.WithTransformer(wasm.Transformer(module, json.RawMessage(`{"columns": [2]}`)))
+//
+// The WASI functions are available to the modules, so modules built for wasm32-wasi can be loaded. The module is
+// initialized by its _initialize function if it exports one (Eg: a reactor built by TinyGo or Rust), _start is not called.
+package wazero
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sivaramasubramanian/csvprocessor/wasm"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// Module is a WebAssembly module instantiated with wazero, it implements wasm.Module.
+// As a module instance is not safe for concurrent use, a Module must not be shared by processors running concurrently.
+type Module struct {
+	runtime wazero.Runtime
+	module  api.Module
+	memory  api.Memory
+}
+
+var _ wasm.Module = (*Module)(nil)
+
+// Instantiate compiles and instantiates the WebAssembly binary in a new runtime.
+// A module that does not export its memory, alloc and transform is returned as a wasm.ErrABI error.
+// The module is closed when the context of a call is done, so a transformer stuck in a loop is stopped by cancelling the processing.
+func Instantiate(ctx context.Context, binary []byte) (*Module, error) {
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		_ = runtime.Close(ctx)
+		return nil, fmt.Errorf("wazero: %w", err)
+	}
+
+	module, err := runtime.InstantiateWithConfig(ctx, binary, wazero.NewModuleConfig().WithStartFunctions("_initialize"))
+	if err != nil {
+		_ = runtime.Close(ctx)
+		return nil, fmt.Errorf("wazero: %w", err)
+	}
+
+	memory := module.ExportedMemory("memory")
+	if memory == nil || module.ExportedFunction("alloc") == nil || module.ExportedFunction("transform") == nil {
+		_ = runtime.Close(ctx)
+		return nil, fmt.Errorf("%w: the module must export memory, alloc and transform", wasm.ErrABI)
+	}
+
+	return &Module{runtime: runtime, module: module, memory: memory}, nil
+}
+
+// Load reads the WebAssembly module at the path and instantiates it, see Instantiate().
+func Load(path string) (*Module, error) {
+	binary, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("wazero: %w", err)
+	}
+
+	return Instantiate(context.Background(), binary)
+}
+
+// LoadModule is like Load, for wasm.Factory(). Eg:
+//
+//	csvprocessor.RegisterTransformer("wasm", wasm.Factory(wazero.LoadModule), wasm.FactoryParams...)
+func LoadModule(path string) (wasm.Module, error) {
+	return Load(path)
+}
+
+// Call calls the exported function with the params and returns its results, see wasm.Module.
+func (m *Module) Call(ctx context.Context, name string, params ...uint64) ([]uint64, error) {
+	function := m.module.ExportedFunction(name)
+	if function == nil {
+		return nil, fmt.Errorf("%w: function %q is not exported", wasm.ErrABI, name)
+	}
+
+	return function.Call(ctx, params...)
+}
+
+// Read returns size bytes of the memory at offset, or false if it is out of range, see wasm.Module.
+func (m *Module) Read(offset, size uint32) ([]byte, bool) {
+	return m.memory.Read(offset, size)
+}
+
+// Write writes the data to the memory at offset, or returns false if it is out of range, see wasm.Module.
+func (m *Module) Write(offset uint32, data []byte) bool {
+	return m.memory.Write(offset, data)
+}
+
+// Close closes the module and its runtime, the module cannot be called after it is closed.
+func (m *Module) Close() error {
+	return m.runtime.Close(context.Background())
+}
@@ -0,0 +1,141 @@
+package wazero_test
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+	"github.com/sivaramasubramanian/csvprocessor/wasm"
+	"github.com/sivaramasubramanian/csvprocessor/wasm/wazero"
+)
+
+// paramsModule returns the params given to configure as the result of every row, and drops the rows if there are no params:
+//
+//	(module
+//	  (memory (export "memory") 1)
+//	  (global $next (mut i32) (i32.const 1024))
+//	  (global $base (mut i32) (i32.const 1024))
+//	  (global $params (mut i64) (i64.const 0))
+//	  (func (export "alloc") (param $size i32) (result i32) (local $offset i32)
+//	    (local.set $offset (global.get $next))
+//	    (global.set $next (i32.add (local.get $offset) (local.get $size)))
+//	    (local.get $offset))
+//	  (func (export "configure") (param $offset i32) (param $size i32)
+//	    (global.set $params (i64.or (i64.shl (i64.extend_i32_u (local.get $offset)) (i64.const 32))
+//	                                (i64.extend_i32_u (local.get $size))))
+//	    (global.set $base (global.get $next)))
+//	  (func (export "transform") (param i32 i32) (result i64)
+//	    (global.set $next (global.get $base))
+//	    (global.get $params)))
+var paramsModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, 0x01, 0x11, 0x03, 0x60, 0x01, 0x7f, 0x01, 0x7f,
+	0x60, 0x02, 0x7f, 0x7f, 0x00, 0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e, 0x03, 0x04, 0x03, 0x00, 0x01,
+	0x02, 0x05, 0x03, 0x01, 0x00, 0x01, 0x06, 0x12, 0x03, 0x7f, 0x01, 0x41, 0x80, 0x08, 0x0b, 0x7f,
+	0x01, 0x41, 0x80, 0x08, 0x0b, 0x7e, 0x01, 0x42, 0x00, 0x0b, 0x07, 0x2a, 0x04, 0x06, 0x6d, 0x65,
+	0x6d, 0x6f, 0x72, 0x79, 0x02, 0x00, 0x05, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x00, 0x00, 0x09, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x65, 0x00, 0x01, 0x09, 0x74, 0x72, 0x61, 0x6e, 0x73,
+	0x66, 0x6f, 0x72, 0x6d, 0x00, 0x02, 0x0a, 0x2d, 0x03, 0x0f, 0x01, 0x01, 0x7f, 0x23, 0x00, 0x22,
+	0x01, 0x20, 0x00, 0x6a, 0x24, 0x00, 0x20, 0x01, 0x0b, 0x12, 0x00, 0x20, 0x00, 0xad, 0x42, 0x20,
+	0x86, 0x20, 0x01, 0xad, 0x84, 0x24, 0x02, 0x23, 0x00, 0x24, 0x01, 0x0b, 0x08, 0x00, 0x23, 0x01,
+	0x24, 0x00, 0x23, 0x02, 0x0b,
+}
+
+func process(input string, transformer csvprocessor.CsvRowTransformer) (string, error) {
+	var output strings.Builder
+	proc, err := csvprocessor.New(
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader(input))),
+		csvprocessor.WithWriterGenerator(func(int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(&output), nil
+		}),
+		csvprocessor.WithChunkSize(math.MaxInt32),
+		csvprocessor.WithLogger(func(string, ...any) {}),
+		csvprocessor.WithTransformer(transformer),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	err = proc.Process()
+	return output.String(), err
+}
+
+func TestModule(t *testing.T) {
+	tests := []struct {
+		name   string
+		params json.RawMessage
+		want   string
+	}{
+		{"params result", json.RawMessage(`["a","b"]`), "a,b\na,b\na,b\n"},
+		{"dropped rows", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			module, err := wazero.Instantiate(context.Background(), paramsModule)
+			if err != nil {
+				t.Fatalf("Instantiate() error = %v", err)
+			}
+			defer module.Close()
+
+			got, err := process("id,name\n1,alice\n2,bob\n", wasm.Transformer(module, tt.params))
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Process() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadModule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "params.wasm")
+	if err := os.WriteFile(path, paramsModule, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := csvprocessor.NewTransformerRegistry()
+	if err := registry.Register("wasm", wasm.Factory(wazero.LoadModule), wasm.FactoryParams...); err != nil {
+		t.Fatal(err)
+	}
+
+	params, _ := json.Marshal(map[string]any{"path": path, "params": []string{"x"}}) //nolint:errcheck
+	transformer, err := registry.New("wasm", params)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	got, err := process("id\n1\n", transformer)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if want := "x\nx\n"; got != want {
+		t.Errorf("Process() output = %q, want %q", got, want)
+	}
+
+	if _, err := registry.New("wasm", json.RawMessage(`{"path": "missing.wasm"}`)); err == nil || !strings.Contains(err.Error(), "missing.wasm") {
+		t.Errorf("New() error = %v, want an error for missing.wasm", err)
+	}
+}
+
+func TestInstantiateErrors(t *testing.T) {
+	// an empty module does not export the functions of the ABI.
+	emptyModule := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	if _, err := wazero.Instantiate(context.Background(), emptyModule); !errors.Is(err, wasm.ErrABI) {
+		t.Errorf("Instantiate() error = %v, want %v", err, wasm.ErrABI)
+	}
+
+	if _, err := wazero.Instantiate(context.Background(), []byte("not wasm")); err == nil {
+		t.Errorf("Instantiate() error = nil, want an error")
+	}
+}
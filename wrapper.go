@@ -1,6 +1,15 @@
 package csvprocessor
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrTransformerTimeout is returned (wrapped in a *RowError) when the transformer wrapped by TimeoutWrapper() times out with TimeoutAbort.
+var ErrTransformerTimeout = errors.New("csvprocessor: transformer timed out")
 
 // PanicSafe wraps the transformer execution in a recover block.
 func PanicSafe(transformer CsvRowTransformer, log Logger) CsvRowTransformer {
@@ -26,3 +35,195 @@ func DebugWrapper(transformer CsvRowTransformer, log Logger) CsvRowTransformer {
 		return transformedRow
 	}
 }
+
+// HeaderOnly applies the transformer only to the header rows, the data rows are returned as they are.
+// Eg: csvprocessor.HeaderOnly(csvprocessor.ReplaceValuesTransformer(map[string]string{"amt": "amount"})) renames a column
+// without replacing the same value in the data rows.
+// The transformer must keep the no. of columns, else the header will not match the data rows.
+func HeaderOnly(transformer CsvRowTransformer) CsvRowTransformer {
+	return func(ctx context.Context, row []string) []string {
+		if !isHeader(ctx) {
+			return row
+		}
+
+		return transformer(ctx, row)
+	}
+}
+
+// RowsOnly applies the transformer only to the data rows, the header rows are returned as they are.
+// Eg: csvprocessor.RowsOnly(csvprocessor.ReplaceValuesTransformer(map[string]string{"NULL": ""})) does not modify
+// a column named NULL. The transformer must keep the no. of columns, else the data rows will not match the header.
+func RowsOnly(transformer CsvRowTransformer) CsvRowTransformer {
+	return func(ctx context.Context, row []string) []string {
+		if isHeader(ctx) {
+			return row
+		}
+
+		return transformer(ctx, row)
+	}
+}
+
+// TimeoutPolicy decides the result of a row whose transformation timed out, see TimeoutWrapper().
+type TimeoutPolicy int
+
+const (
+	// TimeoutSkip drops the row, like a transformer that filters it out.
+	TimeoutSkip TimeoutPolicy = iota
+	// TimeoutPassthrough returns the row as it is, without the changes of the transformer.
+	TimeoutPassthrough
+	// TimeoutAbort fails the row with ErrTransformerTimeout, which stops the processing unless the row is skipped or retried
+	// by the row error handler or written to the rejects writer, see WithRowErrorHandler() and WithRejectWriter().
+	TimeoutAbort
+)
+
+// TimeoutWrapper limits the time taken by the transformer for a row, Eg: for a lookup in a remote service that can hang.
+// The transformer runs in a goroutine, if it does not return within the timeout the policy decides the result of the row. Eg:
+//
+//	csvprocessor.TimeoutWrapper(lookupTransformer, 2*time.Second, csvprocessor.TimeoutPassthrough)
+//
+// The transformer gets a copy of the row and of the context values, and the context is cancelled at the timeout,
+// so the transformer should return when ctx.Done() is closed, else its goroutine keeps running until it returns.
+// The policy also applies to the header rows, use RowsOnly() to wrap only the data rows.
+// A panic in the transformer is raised again in the caller's goroutine, so that it is handled like the other transformers.
+func TimeoutWrapper(transformer CsvRowTransformer, timeout time.Duration, policy TimeoutPolicy) CsvRowTransformer {
+	type result struct {
+		row       []string
+		recovered any
+	}
+
+	return func(ctx context.Context, row []string) []string {
+		// the processor modifies its context and reuses the row for the next rows while the transformer may still be running.
+		timeoutCtx, cancel := context.WithTimeout(detachedCtx(ctx), timeout)
+		defer cancel()
+
+		done := make(chan result, 1)
+		go func(row []string) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					done <- result{recovered: recovered}
+				}
+			}()
+
+			done <- result{row: transformer(timeoutCtx, row)}
+		}(append([]string(nil), row...))
+
+		select {
+		case res := <-done:
+			if res.recovered != nil {
+				panic(res.recovered)
+			}
+
+			return res.row
+		case <-timeoutCtx.Done():
+		}
+
+		switch policy {
+		case TimeoutPassthrough:
+			return row
+		case TimeoutAbort:
+			panic(fmt.Errorf("%w after %v", ErrTransformerTimeout, timeout))
+		case TimeoutSkip:
+		}
+
+		return nil
+	}
+}
+
+// detachedCtx returns a context with a copy of the values of the current row, that is not modified by the processor
+// for the next rows. ctx can be the processor's context or a context wrapping it, Eg: by MetricsWrapper().
+func detachedCtx(ctx context.Context) context.Context {
+	// all the keys are copied, so the values of the processor's context are never read through the parent.
+	values := make(map[ctxKey]any, len(ctxKeys))
+	for _, key := range ctxKeys {
+		values[key] = ctx.Value(key)
+	}
+
+	parent := ctx
+	for {
+		m, ok := parent.(*metricsCtx)
+		if !ok {
+			break
+		}
+
+		parent = m.unwrap()
+	}
+
+	if c, ok := parent.(*csvCtx); ok {
+		parent = c.Context
+	}
+
+	// the profiler is not copied, as it is not safe for concurrent use.
+	return &csvCtx{Context: parent, m: values}
+}
+
+// MetricsWrapper records the time taken by each call of the transformer, and by each transformer of the chain if it is
+// a chain of ChainTransformers(), Eg: to find the slow transformer of a chain:
+//
+//	timings := &csvprocessor.TransformerTimings{}
+//	csvprocessor.WithTransformer(csvprocessor.MetricsWrapper(csvprocessor.ChainTransformers(parse, lookup, mask), timings)),
+//	...
+//	for _, timing := range timings.Timings() {
+//		fmt.Println(timing.Index, timing.Func, timing.Calls, timing.Duration)
+//	}
+//
+// The calls of the header rows are also recorded. The transformers of the chain are timed like WithProfile(), which records
+// the time of the chains in Stats.Profile without a wrapper.
+func MetricsWrapper(transformer CsvRowTransformer, recorder TransformerRecorder) CsvRowTransformer {
+	wrapper := &metricsWrapper{recorder: recorder, name: funcName(transformer), funcs: make(map[string]string)}
+	return func(ctx context.Context, row []string) []string {
+		mctx := &metricsCtx{Context: ctx, wrapper: wrapper}
+		start := time.Now()
+		row = transformer(mctx, row)
+		wrapper.recorder.RecordTransform(TransformerCall{Func: wrapper.name, Duration: time.Since(start), Dropped: row == nil})
+		return row
+	}
+}
+
+// metricsWrapper is the state of a MetricsWrapper() shared by its calls.
+type metricsWrapper struct {
+	recorder TransformerRecorder
+	name     string // name of the wrapped transformer.
+
+	mu    sync.Mutex
+	funcs map[string]string // names of the transformers of the chain by their index.
+}
+
+// metricsCtx is the context passed to the transformer wrapped by MetricsWrapper(), the chains record their transformers to it.
+// It is not modified after it is created, the transformers of a chain get a new context with their position, see member().
+type metricsCtx struct {
+	context.Context //nolint:containedctx
+	wrapper         *metricsWrapper
+	path            []int // position of the transformer in the nested chains.
+}
+
+// member returns the context of the i-th transformer of the chain run with m.
+func (m *metricsCtx) member(i int) *metricsCtx {
+	path := make([]int, len(m.path), len(m.path)+1)
+	copy(path, m.path)
+	return &metricsCtx{Context: m.Context, wrapper: m.wrapper, path: append(path, i)}
+}
+
+// unwrap returns the context wrapped by MetricsWrapper(), Eg: the processor's context.
+func (m *metricsCtx) unwrap() context.Context {
+	return m.Context
+}
+
+// metricsOf returns the context of the MetricsWrapper() running the chain, if any.
+func metricsOf(ctx context.Context) *metricsCtx {
+	m, _ := ctx.(*metricsCtx)
+	return m
+}
+
+// record records the call of the transformer of a chain at the position of m.
+func (m *metricsCtx) record(transformer any, elapsed time.Duration, dropped bool) {
+	index := chainIndex(m.path)
+	m.wrapper.mu.Lock()
+	name, ok := m.wrapper.funcs[index]
+	if !ok {
+		name = funcName(transformer)
+		m.wrapper.funcs[index] = name
+	}
+	m.wrapper.mu.Unlock()
+
+	m.wrapper.recorder.RecordTransform(TransformerCall{Index: index, Func: name, Duration: elapsed, Dropped: dropped})
+}
@@ -2,8 +2,12 @@ package csvprocessor_test
 
 import (
 	"context"
+	"errors"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/sivaramasubramanian/csvprocessor"
 )
@@ -81,3 +85,227 @@ func callCount(log csvprocessor.Logger, count *int) csvprocessor.Logger {
 		log(format, args...)
 	}
 }
+
+func TestHeaderOnlyRowsOnly(t *testing.T) {
+	rename := csvprocessor.ReplaceValuesTransformer(map[string]string{"amt": "amount"})
+	tests := []struct {
+		name        string
+		transformer csvprocessor.CsvRowTransformer
+		want        string
+	}{
+		{name: "Test all rows", transformer: rename, want: "id,amount\n1,amount\n"},
+		{name: "Test header only", transformer: csvprocessor.HeaderOnly(rename), want: "id,amount\n1,amt\n"},
+		{name: "Test rows only", transformer: csvprocessor.RowsOnly(rename), want: "id,amt\n1,amount\n"},
+		{
+			name:        "Test chain",
+			transformer: csvprocessor.ChainTransformers(csvprocessor.HeaderOnly(rename), csvprocessor.RowsOnly(csvprocessor.ReplaceValuesTransformer(map[string]string{"amt": ""}))),
+			want:        "id,amount\n1,\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processString(t, "id,amt\n1,amt\n", csvprocessor.WithTransformer(tt.transformer))
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeoutWrapper(t *testing.T) {
+	upper := func(ctx context.Context, row []string) []string {
+		if row[1] == "slow" {
+			<-ctx.Done()
+		}
+
+		for i := range row {
+			row[i] = strings.ToUpper(row[i])
+		}
+
+		return row
+	}
+
+	input := "id,name\n1,a\n2,slow\n3,c\n"
+	tests := []struct {
+		name    string
+		policy  csvprocessor.TimeoutPolicy
+		want    string
+		wantErr error
+	}{
+		{name: "Test skip", policy: csvprocessor.TimeoutSkip, want: "ID,NAME\n1,A\n3,C\n"},
+		{name: "Test passthrough", policy: csvprocessor.TimeoutPassthrough, want: "ID,NAME\n1,A\n2,slow\n3,C\n"},
+		{name: "Test abort", policy: csvprocessor.TimeoutAbort, wantErr: csvprocessor.ErrTransformerTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := processString(t, input, csvprocessor.WithTransformer(csvprocessor.TimeoutWrapper(upper, 50*time.Millisecond, tt.policy)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var rowErr *csvprocessor.RowError
+			if err != nil && (!errors.As(err, &rowErr) || rowErr.Row != 2) {
+				t.Errorf("Process() error = %v, want a RowError for row 2", err)
+			}
+
+			if err == nil && got != tt.want {
+				t.Errorf("Process() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	panicking := func(ctx context.Context, row []string) []string {
+		panic("lookup failed")
+	}
+
+	_, err := processString(t, input, csvprocessor.WithTransformer(csvprocessor.TimeoutWrapper(panicking, time.Second, csvprocessor.TimeoutSkip)))
+	if !errors.Is(err, csvprocessor.ErrTransformerPanic) {
+		t.Errorf("Process() error = %v, want %v", err, csvprocessor.ErrTransformerPanic)
+	}
+}
+
+func TestMetricsWrapper(t *testing.T) {
+	upper := func(ctx context.Context, row []string) []string {
+		row[1] = strings.ToUpper(row[1])
+		return row
+	}
+
+	slow := func(ctx context.Context, row []string) []string {
+		if row[1] == "B" {
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		return row
+	}
+
+	dropC := func(ctx context.Context, row []string) []string {
+		if row[1] == "C" {
+			return nil
+		}
+
+		return row
+	}
+
+	noop := func(ctx context.Context, row []string) []string { return row }
+
+	timings := &csvprocessor.TransformerTimings{}
+	var calls int
+	chain := csvprocessor.ChainTransformers(upper, slow, dropC, csvprocessor.ChainTransformers(noop, noop))
+	got, err := processString(t, "id,name\n1,a\n2,b\n3,c\n",
+		csvprocessor.WithProfile(true),
+		csvprocessor.WithTransformer(csvprocessor.ChainTransformers(
+			csvprocessor.MetricsWrapper(chain, timings),
+			csvprocessor.MetricsWrapper(noop, csvprocessor.TransformerRecorderFunc(func(call csvprocessor.TransformerCall) {
+				calls++
+			})),
+		)),
+	)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if want := "id,NAME\n1,A\n2,B\n"; got != want {
+		t.Errorf("Process() output = %q, want %q", got, want)
+	}
+
+	want := []struct {
+		index          string
+		calls, dropped int
+	}{
+		{index: "0", calls: 4},
+		{index: "1", calls: 4},
+		{index: "2", calls: 4, dropped: 1},
+		{index: "3.0", calls: 3},
+		{index: "3.1", calls: 3},
+		{index: "3", calls: 3},
+		{index: "", calls: 4, dropped: 1},
+	}
+	results := timings.Timings()
+	if len(results) != len(want) {
+		t.Fatalf("Timings() = %+v, want %d timings", results, len(want))
+	}
+
+	for i, w := range want {
+		if r := results[i]; r.Index != w.index || r.Calls != w.calls || r.Dropped != w.dropped || r.Func == "" {
+			t.Errorf("Timings()[%d] = %+v, want index %q with %d calls and %d dropped", i, r, w.index, w.calls, w.dropped)
+		}
+	}
+
+	if results[1].Max < 5*time.Millisecond || results[1].Duration < results[1].Max {
+		t.Errorf("Timings()[1] = %+v, want the slow call", results[1])
+	}
+
+	// the rows dropped by the first transformer are not passed to the second.
+	if calls != 3 {
+		t.Errorf("RecordTransform() called %d times, want 3", calls)
+	}
+}
+
+func TestTimeoutWrapper_DetachedContext(t *testing.T) {
+	type key string
+	rowNums := make(chan [2]any, 8)
+	slow := func(ctx context.Context, row []string) []string {
+		<-ctx.Done()
+		// the processor is reading the next rows while the timed out transformer is still running.
+		time.Sleep(time.Millisecond)
+		rowNums <- [2]any{row[0], ctx.Value(csvprocessor.CtxRowNum)}
+		return row
+	}
+
+	timeout := csvprocessor.TimeoutWrapper(slow, time.Millisecond, csvprocessor.TimeoutPassthrough)
+	wrapped := func(ctx context.Context, row []string) []string {
+		return timeout(context.WithValue(ctx, key("wrapped"), true), row)
+	}
+
+	if _, err := processString(t, "id\n1\n2\n3\n", csvprocessor.WithTransformer(csvprocessor.RowsOnly(wrapped))); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		got := <-rowNums
+		if want := got[0].(string); strconv.Itoa(got[1].(int)) != want {
+			t.Errorf("CtxRowNum = %v for the row %v", got[1], want)
+		}
+	}
+}
+
+func TestMetricsWrapper_TimeoutWrapper(t *testing.T) {
+	rowNums := make(chan [2]any, 8)
+	slow := func(ctx context.Context, row []string) []string {
+		<-ctx.Done()
+		time.Sleep(time.Millisecond)
+		rowNums <- [2]any{row[0], ctx.Value(csvprocessor.CtxRowNum)}
+		return row
+	}
+
+	timings := &csvprocessor.TransformerTimings{}
+	chain := csvprocessor.ChainTransformers(
+		csvprocessor.TimeoutWrapper(slow, time.Millisecond, csvprocessor.TimeoutPassthrough),
+		csvprocessor.ChainTransformers(csvprocessor.TimeoutWrapper(slow, time.Millisecond, csvprocessor.TimeoutPassthrough)),
+	)
+	wrapped := csvprocessor.MetricsWrapper(chain, timings)
+
+	if _, err := processString(t, "id\n1\n2\n3\n", csvprocessor.WithTransformer(csvprocessor.RowsOnly(wrapped))); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	for i := 0; i < 6; i++ {
+		got := <-rowNums
+		if want := got[0].(string); strconv.Itoa(got[1].(int)) != want {
+			t.Errorf("CtxRowNum = %v for the row %v", got[1], want)
+		}
+	}
+
+	var indexes []string
+	for _, call := range timings.Timings() {
+		indexes = append(indexes, call.Index)
+	}
+
+	if got, want := strings.Join(indexes, ","), "0,1.0,1,"; got != want {
+		t.Errorf("Timings() indexes = %v, want %v", got, want)
+	}
+}
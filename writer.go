@@ -0,0 +1,127 @@
+package csvprocessor
+
+import (
+	"bufio"
+	"strings"
+)
+
+// QuoteMode represents how the fields are quoted in the output chunks.
+type QuoteMode int
+
+const (
+	// QuoteMinimal quotes only the fields that contain the delimiter, quotes, new lines or leading spaces.
+	// This is the behaviour of csv.Writer from encoding/csv.
+	QuoteMinimal QuoteMode = iota
+	// QuoteAll quotes all the fields.
+	QuoteAll
+	// QuoteNever never quotes the fields, instead the delimiter, new lines and backslashes in the fields are escaped with a backslash.
+	// Eg: the field `a,b` is written as `a\,b` and a new line as `\n`.
+	QuoteNever
+)
+
+// delimitedWriter is a CsvWriter that supports the quote modes and the quote characters that csv.Writer does not support.
+type delimitedWriter struct {
+	w         *bufio.Writer
+	comma     rune
+	quote     rune
+	escape    rune // 0 if the quotes are doubled.
+	quoteMode QuoteMode
+	useCRLF   bool
+	err       error
+}
+
+func newDelimitedWriter(w *bufio.Writer, comma, quote, escape rune, quoteMode QuoteMode, useCRLF bool) *delimitedWriter {
+	return &delimitedWriter{w: w, comma: comma, quote: quote, escape: escape, quoteMode: quoteMode, useCRLF: useCRLF}
+}
+
+// Write writes a single record, the record may be buffered until Flush is called.
+func (d *delimitedWriter) Write(record []string) error {
+	if d.err != nil {
+		return d.err
+	}
+
+	for i, field := range record {
+		if i > 0 {
+			d.w.WriteRune(d.comma)
+		}
+
+		switch {
+		case d.quoteMode == QuoteNever:
+			d.writeEscaped(field)
+		case d.quoteMode == QuoteAll || d.needsQuotes(field):
+			d.writeQuoted(field)
+		default:
+			d.w.WriteString(field)
+		}
+	}
+
+	if d.useCRLF {
+		_, d.err = d.w.WriteString("\r\n")
+	} else {
+		_, d.err = d.w.WriteString("\n")
+	}
+
+	return d.err
+}
+
+// needsQuotes reports whether the field must be quoted in QuoteMinimal mode, as in csv.Writer.
+func (d *delimitedWriter) needsQuotes(field string) bool {
+	if field == "" {
+		return false
+	}
+
+	if field[0] == ' ' || field[0] == '\t' {
+		return true
+	}
+
+	return strings.ContainsAny(field, "\r\n") || strings.ContainsRune(field, d.comma) ||
+		strings.ContainsRune(field, d.quote) || (d.escape != 0 && strings.ContainsRune(field, d.escape))
+}
+
+// writeQuoted writes the quoted field, the quote and escape characters in the field are escaped or the quotes are doubled.
+func (d *delimitedWriter) writeQuoted(field string) {
+	d.w.WriteRune(d.quote)
+	for _, r := range field {
+		switch {
+		case d.escape != 0 && (r == d.quote || r == d.escape):
+			d.w.WriteRune(d.escape)
+		case d.escape == 0 && r == d.quote:
+			d.w.WriteRune(d.quote)
+		}
+
+		d.w.WriteRune(r)
+	}
+	d.w.WriteRune(d.quote)
+}
+
+// writeEscaped writes the field escaping the delimiter, new lines and backslashes.
+func (d *delimitedWriter) writeEscaped(field string) {
+	for _, r := range field {
+		switch r {
+		case '\\', d.comma:
+			d.w.WriteByte('\\')
+			d.w.WriteRune(r)
+		case '\n':
+			d.w.WriteString(`\n`)
+		case '\r':
+			d.w.WriteString(`\r`)
+		default:
+			d.w.WriteRune(r)
+		}
+	}
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (d *delimitedWriter) Flush() {
+	if d.err == nil {
+		d.err = d.w.Flush()
+	}
+}
+
+// Error reports any error that has occurred during a previous Write or Flush.
+func (d *delimitedWriter) Error() error {
+	return d.err
+}
+
+// compile time check to ensure delimitedWriter implements CsvWriter.
+var _ CsvWriter = (*delimitedWriter)(nil)
@@ -0,0 +1,135 @@
+package csvprocessor_test
+
+import (
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+func TestWithQuoteMode(t *testing.T) {
+	const input = "a,b\n\"x,y\",\"say \"\"hi\"\"\"\n\"line\nbreak\",back\\slash\n"
+	tests := []struct {
+		name    string
+		opt     []csvprocessor.Option
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Test quote minimal",
+			opt:  []csvprocessor.Option{csvprocessor.WithQuoteMode(csvprocessor.QuoteMinimal)},
+			want: input,
+		},
+		{
+			name: "Test quote all",
+			opt:  []csvprocessor.Option{csvprocessor.WithQuoteMode(csvprocessor.QuoteAll)},
+			want: "\"a\",\"b\"\n\"x,y\",\"say \"\"hi\"\"\"\n\"line\nbreak\",\"back\\slash\"\n",
+		},
+		{
+			name: "Test quote never",
+			opt:  []csvprocessor.Option{csvprocessor.WithQuoteMode(csvprocessor.QuoteNever)},
+			want: "a,b\nx\\,y,say \"hi\"\nline\\nbreak,back\\\\slash\n",
+		},
+		{
+			name: "Test quote never with pipe delimiter",
+			opt: []csvprocessor.Option{
+				csvprocessor.WithQuoteMode(csvprocessor.QuoteNever),
+				csvprocessor.WithOutputDelimiter('|'),
+			},
+			want: "a|b\nx,y|say \"hi\"\nline\\nbreak|back\\\\slash\n",
+		},
+		{
+			name:    "Test invalid quote mode",
+			opt:     []csvprocessor.Option{csvprocessor.WithQuoteMode(csvprocessor.QuoteMode(10))},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processString(t, input, tt.opt...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithCRLF(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  []csvprocessor.Option
+		want string
+	}{
+		{
+			name: "Test CRLF",
+			opt:  []csvprocessor.Option{csvprocessor.WithCRLF(true)},
+			want: "a,b\r\n1,2\r\n",
+		},
+		{
+			name: "Test CRLF with quote all",
+			opt:  []csvprocessor.Option{csvprocessor.WithCRLF(true), csvprocessor.WithQuoteMode(csvprocessor.QuoteAll)},
+			want: "\"a\",\"b\"\r\n\"1\",\"2\"\r\n",
+		},
+		{
+			name: "Test LF",
+			opt:  []csvprocessor.Option{csvprocessor.WithCRLF(false)},
+			want: "a,b\n1,2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := processString(t, "a,b\n1,2\n", tt.opt...)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if output != tt.want {
+				t.Errorf("Process() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithOutputBOM(t *testing.T) {
+	output, err := processString(t, "a,b\n1,2\n", csvprocessor.WithOutputBOM(true))
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if want := "\ufeffa,b\n1,2\n"; output != want {
+		t.Errorf("Process() output = %q, want %q", output, want)
+	}
+}
+
+func TestProcessor_InputBOM(t *testing.T) {
+	input := "\ufeffname,age\nbob,30\n"
+	schema := csvprocessor.Schema{Columns: []csvprocessor.ColumnSchema{
+		{Name: "name", Required: true},
+		{Name: "age", Type: csvprocessor.TypeInt},
+	}}
+
+	t.Run("Test BOM stripped from reader", func(t *testing.T) {
+		output, err := processString(t, input, csvprocessor.WithSchemaValidation(schema))
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		if want := "name,age\nbob,30\n"; output != want {
+			t.Errorf("Process() output = %q, want %q", output, want)
+		}
+	})
+
+	t.Run("Test BOM stripped from file", func(t *testing.T) {
+		output, err := processFile(t, input, csvprocessor.WithSchemaValidation(schema))
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		if want := "name,age\nbob,30\n"; output != want {
+			t.Errorf("Process() output = %q, want %q", output, want)
+		}
+	})
+}
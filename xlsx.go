@@ -0,0 +1,217 @@
+package csvprocessor
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// xlsxMaxRows is the maximum no. of rows in an Excel sheet.
+const xlsxMaxRows = 1 << 20
+
+// ErrXLSXRowLimit is returned by XLSXWriter when a chunk has more rows than an Excel sheet supports.
+var ErrXLSXRowLimit = errors.New("csvprocessor: too many rows for an Excel sheet")
+
+// static parts of the workbook, the sheet is written at the end as it is streamed.
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`},
+	// style 1 is used for the header: bold text with a grey background.
+	{"xl/styles.xml", xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>` +
+		`<fill><patternFill patternType="solid"><fgColor rgb="FFD9D9D9"/><bgColor indexed="64"/></patternFill></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/></cellXfs>` +
+		`</styleSheet>`},
+}
+
+const (
+	xlsxSheetStart = xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd   = `</sheetData></worksheet>`
+)
+
+// XLSXWriter is a CsvWriter that writes the rows as an Excel (.xlsx) workbook with a single sheet.
+// The rows are streamed to the underlying writer as inline strings, so the memory usage does not grow with the no. of rows.
+// The header is written as a bold first row. Close() must be called to complete the workbook.
+// Excel supports at most 1,048,576 rows per sheet including the header, so the chunk size must be smaller than that,
+// the rows after the limit are not written and ErrXLSXRowLimit is returned.
+type XLSXWriter struct {
+	zip *zip.Writer
+
+	// Unexported fields
+	sheet   *bufio.Writer // writer for the sheet, nil until the first row.
+	rows    int           // no. of rows written, including the header.
+	maxRows int
+	buf     []byte
+	err     error
+}
+
+// NewXLSXWriter creates a XLSXWriter that writes the workbook to w.
+func NewXLSXWriter(w io.Writer) *XLSXWriter {
+	return &XLSXWriter{zip: zip.NewWriter(w), maxRows: xlsxMaxRows}
+}
+
+// WriteHeader writes the header as a bold row.
+func (x *XLSXWriter) WriteHeader(header []string) error {
+	return x.writeRow(header, 1)
+}
+
+// Write writes the row to the sheet.
+func (x *XLSXWriter) Write(record []string) error {
+	return x.writeRow(record, 0)
+}
+
+func (x *XLSXWriter) writeRow(record []string, style int) error {
+	if x.err != nil {
+		return x.err
+	}
+
+	if x.sheet == nil {
+		if x.err = x.start(); x.err != nil {
+			return x.err
+		}
+	}
+
+	if x.rows == x.maxRows {
+		x.err = fmt.Errorf("%w: the limit is %d rows", ErrXLSXRowLimit, x.maxRows)
+		return x.err
+	}
+
+	x.rows++
+	rowNum := strconv.Itoa(x.rows)
+	buf := append(x.buf[:0], `<row r="`...)
+	buf = append(buf, rowNum...)
+	buf = append(buf, `">`...)
+	for i, value := range record {
+		buf = append(buf, `<c r="`...)
+		buf = appendColumnName(buf, i)
+		buf = append(buf, rowNum...)
+		if style > 0 {
+			buf = append(buf, `" s="`...)
+			buf = strconv.AppendInt(buf, int64(style), 10)
+		}
+
+		buf = append(buf, `" t="inlineStr"><is><t xml:space="preserve">`...)
+		buf = appendXMLText(buf, value)
+		buf = append(buf, `</t></is></c>`...)
+	}
+
+	buf = append(buf, `</row>`...)
+	x.buf = buf
+
+	_, x.err = x.sheet.Write(buf)
+	return x.err
+}
+
+// start writes the static parts of the workbook and the start of the sheet.
+func (x *XLSXWriter) start() error {
+	for _, part := range xlsxParts {
+		w, err := x.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(w, part.content); err != nil {
+			return err
+		}
+	}
+
+	w, err := x.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+
+	x.sheet = bufio.NewWriter(w)
+	_, err = x.sheet.WriteString(xlsxSheetStart)
+	return err
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (x *XLSXWriter) Flush() {
+	if x.err == nil && x.sheet != nil {
+		x.err = x.sheet.Flush()
+	}
+
+	if x.err == nil {
+		x.err = x.zip.Flush()
+	}
+}
+
+// Error reports any error that has occurred during a previous Write or Flush.
+func (x *XLSXWriter) Error() error {
+	return x.err
+}
+
+// Close completes the sheet and the workbook, the underlying writer is not closed.
+func (x *XLSXWriter) Close() error {
+	if x.err == nil && x.sheet == nil {
+		x.err = x.start()
+	}
+
+	if x.err == nil {
+		_, x.err = x.sheet.WriteString(xlsxSheetEnd)
+	}
+
+	if x.err == nil {
+		x.err = x.sheet.Flush()
+	}
+
+	if x.err == nil {
+		x.err = x.zip.Close()
+	}
+
+	return x.err
+}
+
+// appendColumnName appends the Excel column name of the given 0-based column index, Eg: A, B, ..., Z, AA.
+func appendColumnName(buf []byte, column int) []byte {
+	var name [8]byte
+	i := len(name)
+	for column++; column > 0; column = (column - 1) / 26 {
+		i--
+		name[i] = byte('A' + (column-1)%26)
+	}
+
+	return append(buf, name[i:]...)
+}
+
+// appendXMLText appends the XML escaped text, the characters that are not allowed in XML are replaced by U+FFFD.
+func appendXMLText(buf []byte, text string) []byte {
+	var escaped xmlBuffer
+	escaped.buf = buf
+	_ = xml.EscapeText(&escaped, []byte(text))
+
+	return escaped.buf
+}
+
+// xmlBuffer is a minimal io.Writer over a byte slice, used to avoid an allocation per cell.
+type xmlBuffer struct {
+	buf []byte
+}
+
+func (b *xmlBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
@@ -0,0 +1,46 @@
+package csvprocessor
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestXLSXWriter_RowLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		{name: "Test rows within the limit", input: "id\n1\n2\n"},
+		{name: "Test rows over the limit", input: "id\n1\n2\n3\n", wantErr: ErrXLSXRowLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc, err := New(
+				WithReader(csv.NewReader(strings.NewReader(tt.input))),
+				WithWriterGenerator(func(int) (io.WriteCloser, error) {
+					return NoOpCloser(io.Discard), nil
+				}),
+				WithChunkSize(10),
+				// the limit includes the header.
+				WithWriterFactory(func(w io.Writer) CsvWriter {
+					writer := NewXLSXWriter(w)
+					writer.maxRows = 3
+					return writer
+				}),
+				WithLogger(t.Logf),
+			)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if err := proc.Process(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package csvprocessor_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// readSheet returns the cell values and the style of the first cell of each row in the first sheet of the workbook.
+func readSheet(tb testing.TB, workbook []byte) ([][]string, []string) {
+	tb.Helper()
+
+	archive, err := zip.NewReader(bytes.NewReader(workbook), int64(len(workbook)))
+	if err != nil {
+		tb.Fatalf("zip.NewReader() error = %v", err)
+	}
+
+	sheet, err := archive.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		tb.Fatalf("Open() error = %v", err)
+	}
+	defer sheet.Close()
+
+	var worksheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref   string `xml:"r,attr"`
+				Style string `xml:"s,attr"`
+				Text  string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.NewDecoder(sheet).Decode(&worksheet); err != nil {
+		tb.Fatalf("xml.Decode() error = %v", err)
+	}
+
+	var rows [][]string
+	var styles []string
+	for _, row := range worksheet.Rows {
+		var values []string
+		for _, cell := range row.Cells {
+			values = append(values, cell.Text)
+		}
+
+		rows = append(rows, values)
+		styles = append(styles, row.Cells[0].Style)
+	}
+
+	return rows, styles
+}
+
+func TestWithXLSXOutput(t *testing.T) {
+	var output bytes.Buffer
+	proc, err := csvprocessor.New(
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader("id,name\n1, alice & bob \n2,\"<b>\nline\"\n"))),
+		csvprocessor.WithWriterGenerator(func(i int) (io.WriteCloser, error) {
+			return csvprocessor.NoOpCloser(&output), nil
+		}),
+		csvprocessor.WithChunkSize(math.MaxInt32),
+		csvprocessor.WithXLSXOutput(),
+		csvprocessor.WithLogger(t.Logf),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	rows, styles := readSheet(t, output.Bytes())
+	wantRows := [][]string{{"id", "name"}, {"1", " alice & bob "}, {"2", "<b>\nline"}}
+	if !reflect.DeepEqual(rows, wantRows) {
+		t.Errorf("sheet rows = %q, want %q", rows, wantRows)
+	}
+
+	if wantStyles := []string{"1", "", ""}; !reflect.DeepEqual(styles, wantStyles) {
+		t.Errorf("sheet styles = %q, want %q", styles, wantStyles)
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	t.Run("Test cell references", func(t *testing.T) {
+		var output bytes.Buffer
+		writer := csvprocessor.NewXLSXWriter(&output)
+		row := make([]string, 30)
+		for i := range row {
+			row[i] = "v"
+		}
+
+		if err := writer.Write(row); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+
+		if err := writer.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		archive, err := zip.NewReader(bytes.NewReader(output.Bytes()), int64(output.Len()))
+		if err != nil {
+			t.Fatalf("zip.NewReader() error = %v", err)
+		}
+
+		sheet, err := archive.Open("xl/worksheets/sheet1.xml")
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+
+		content, err := io.ReadAll(sheet)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+
+		for _, ref := range []string{`r="A1"`, `r="Z1"`, `r="AA1"`, `r="AD1"`} {
+			if !strings.Contains(string(content), ref) {
+				t.Errorf("sheet does not contain cell %s", ref)
+			}
+		}
+	})
+
+	t.Run("Test empty workbook", func(t *testing.T) {
+		var output bytes.Buffer
+		writer := csvprocessor.NewXLSXWriter(&output)
+		if err := writer.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		if rows, _ := readSheet(t, output.Bytes()); len(rows) != 0 {
+			t.Errorf("sheet rows = %q, want none", rows)
+		}
+	})
+}
@@ -0,0 +1,10 @@
+module github.com/sivaramasubramanian/csvprocessor/yamlconfig
+
+go 1.18
+
+require (
+	github.com/sivaramasubramanian/csvprocessor v0.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace github.com/sivaramasubramanian/csvprocessor => ..
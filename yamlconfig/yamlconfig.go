@@ -0,0 +1,113 @@
+// Package yamlconfig loads the pipeline configs of csvprocessor from YAML files, Eg:
+//
+//	input:
+//	  files: [orders.csv]
+//	  delimiter: ";"
+//	output:
+//	  file_format: orders_%03d.jsonl
+//	  format: jsonl
+//	chunk_size: 10000
+//	transformers:
+//	  - name: replace_values
+//	    params:
+//	      replacements: {"NULL": ""}
+//	  - name: add_row_num
+//	    params: {column: S.No}
+//
+// The keys are the same as in the JSON configs, see csvprocessor.Config.
+// It is a separate module so that only the programs using it depend on gopkg.in/yaml.v3.
+package yamlconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadConfig reads the pipeline config from the YAML file at path.
+func LoadConfig(path string) (*csvprocessor.Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	config, err := ParseConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// ParseConfig reads the pipeline config as YAML from r. As with csvprocessor.ParseConfig(), unknown fields are reported as errors.
+func ParseConfig(r io.Reader) (*csvprocessor.Config, error) {
+	var document any
+	if err := yaml.NewDecoder(r).Decode(&document); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = errors.New("empty document")
+		}
+
+		return nil, fmt.Errorf("%w: %v", csvprocessor.ErrInvalidConfig, err)
+	}
+
+	// the document is converted to JSON, so that it is decoded and checked like the JSON configs.
+	data, err := json.Marshal(jsonValue(document))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", csvprocessor.ErrInvalidConfig, err)
+	}
+
+	return csvprocessor.ParseConfig(strings.NewReader(string(data)))
+}
+
+// NewFromConfig creates a Processor from the pipeline config in the YAML file at path, see csvprocessor.NewFromConfig().
+func NewFromConfig(path string) (*csvprocessor.Processor, error) {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	opts, err := config.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	return csvprocessor.New(opts...)
+}
+
+// jsonValue converts the maps with non-string keys decoded from YAML, Eg: {0: zero}, to maps with string keys.
+func jsonValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, item := range value {
+			value[key] = jsonValue(item)
+		}
+
+		return value
+	case map[any]any:
+		converted := make(map[string]any, len(value))
+		for key, item := range value {
+			converted[fmt.Sprint(key)] = jsonValue(item)
+		}
+
+		return converted
+	case []any:
+		for i, item := range value {
+			value[i] = jsonValue(item)
+		}
+
+		return value
+	default:
+		return value
+	}
+}
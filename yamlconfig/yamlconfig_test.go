@@ -0,0 +1,96 @@
+package yamlconfig_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+	"github.com/sivaramasubramanian/csvprocessor/yamlconfig"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.csv")
+	writeTestFile(t, input, "id;name\n1;alice\n2;NULL\n3;carol\n")
+
+	config := `
+input:
+  files: [` + input + `]
+  delimiter: ";"
+output:
+  file_format: ` + filepath.Join(dir, "output_%d.csv") + `
+chunk_size: 2
+transformers:
+  - name: replace_values
+    params:
+      replacements: {"NULL": "", 3: three}
+  - name: add_constant_column
+    params: {column: src, value: erp}
+`
+	configPath := filepath.Join(dir, "pipeline.yaml")
+	writeTestFile(t, configPath, config)
+
+	proc, err := yamlconfig.NewFromConfig(configPath)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+
+	if err := proc.Process(); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	want := map[string]string{
+		"output_1.csv": "src,id,name\nerp,1,alice\nerp,2,\n",
+		"output_2.csv": "src,id,name\nerp,three,carol\n",
+	}
+	for name, want := range want {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		contains string
+	}{
+		{"unknown field", "output: {file_format: out_%d.csv}\nchunksize: 10\n", "chunksize"},
+		{"missing keys", "input: {files: [a.csv]}\n", "missing required keys: output.file_format, chunk_size"},
+		{"empty document", "", "empty document"},
+		{"invalid yaml", "chunk_size: [10\n", "line 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pipeline.yaml")
+			writeTestFile(t, path, tt.config)
+
+			_, err := yamlconfig.NewFromConfig(path)
+			if !errors.Is(err, csvprocessor.ErrInvalidConfig) {
+				t.Fatalf("NewFromConfig() error = %v, want %v", err, csvprocessor.ErrInvalidConfig)
+			}
+
+			if !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("NewFromConfig() error = %v, want it to contain %q", err, tt.contains)
+			}
+		})
+	}
+}
+
+func writeTestFile(tb testing.TB, path, data string) {
+	tb.Helper()
+
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		tb.Fatal(err)
+	}
+}
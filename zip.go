@@ -0,0 +1,89 @@
+package csvprocessor
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+)
+
+// DefaultZipEntryFormat is the format of the entry names in the archive written by WithZipOutput() or WithTarGzOutput().
+const DefaultZipEntryFormat = "part_%05d.csv"
+
+// chunkArchive writes the chunks as entries of an archive.
+type chunkArchive interface {
+	// chunk is the OutputChunkGenerator that adds an entry for each chunk.
+	chunk(chunkID int) (io.WriteCloser, error)
+	// close completes the archive after all the chunks are closed.
+	close() error
+	// abort releases the incomplete archive after an error.
+	abort() error
+}
+
+// newArchiveFunc creates the archive with the given format for the entry names.
+type newArchiveFunc func(entryFormat string) (chunkArchive, error)
+
+// zipArchive writes the chunks as entries of a zip archive.
+type zipArchive struct {
+	file        io.WriteCloser
+	path        string
+	zip         *zip.Writer
+	entryFormat string
+}
+
+func (c *Processor) createZipArchive(path, entryFormat string) (*zipArchive, error) {
+	file, err := c.createOutputFile(path, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zipArchive{file: file, path: path, zip: zip.NewWriter(file), entryFormat: entryFormat}, nil
+}
+
+// chunk is the OutputChunkGenerator that adds an entry for each chunk, the previous entry is completed by zip.Writer.
+func (a *zipArchive) chunk(chunkID int) (io.WriteCloser, error) {
+	// the modified time is not set, so that the archive is the same for the same input.
+	entry, err := a.zip.CreateHeader(&zip.FileHeader{Name: ChunkName(a.entryFormat, chunkID), Method: zip.Deflate})
+	if err != nil {
+		return nil, err
+	}
+
+	return &zipEntry{Writer: entry, zip: a.zip, name: ChunkName(a.entryFormat, chunkID)}, nil
+}
+
+// close completes the archive.
+func (a *zipArchive) close() error {
+	if err := a.zip.Close(); err != nil {
+		_ = a.abort()
+		return err
+	}
+
+	return a.file.Close()
+}
+
+// abort removes the incomplete archive.
+func (a *zipArchive) abort() error {
+	if aborter, ok := a.file.(interface{ Abort() }); ok {
+		// the archive is written to a temp file, see WithAtomicWrites().
+		aborter.Abort()
+		return nil
+	}
+
+	_ = a.file.Close()
+	return os.Remove(a.path)
+}
+
+// zipEntry flushes the compressed data of the entry to the archive on Close.
+type zipEntry struct {
+	io.Writer
+	zip  *zip.Writer
+	name string
+}
+
+// Name returns the name of the entry in the archive.
+func (e *zipEntry) Name() string {
+	return e.name
+}
+
+func (e *zipEntry) Close() error {
+	return e.zip.Flush()
+}
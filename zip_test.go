@@ -0,0 +1,151 @@
+package csvprocessor_test
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sivaramasubramanian/csvprocessor"
+)
+
+// readZip returns the names and the content of the entries in the archive.
+func readZip(tb testing.TB, path string) ([]string, map[string]string) {
+	tb.Helper()
+
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		tb.Fatalf("invalid zip: %v", err)
+	}
+	defer reader.Close()
+
+	var names []string
+	files := map[string]string{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		names = append(names, file.Name)
+		files[file.Name] = string(content)
+	}
+
+	return names, files
+}
+
+func TestWithZipOutput(t *testing.T) {
+	input := "id,name\n1,alice\n2,bob\n3,carol\n"
+
+	tests := []struct {
+		name      string
+		opts      []csvprocessor.Option
+		wantNames []string
+		want      map[string]string
+	}{
+		{
+			name:      "default entry names",
+			opts:      nil,
+			wantNames: []string{"part_00001.csv", "part_00002.csv"},
+			want: map[string]string{
+				"part_00001.csv": "id,name\n1,alice\n2,bob\n",
+				"part_00002.csv": "id,name\n3,carol\n",
+			},
+		},
+		{
+			name:      "custom entry names",
+			opts:      []csvprocessor.Option{csvprocessor.WithZipEntryFormat("users/%d.jsonl"), csvprocessor.WithJSONLinesOutput()},
+			wantNames: []string{"users/1.jsonl", "users/2.jsonl"},
+			want: map[string]string{
+				"users/1.jsonl": `{"id":"1","name":"alice"}` + "\n" + `{"id":"2","name":"bob"}` + "\n",
+				"users/2.jsonl": `{"id":"3","name":"carol"}` + "\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "chunks.zip")
+			proc, err := csvprocessor.New(append([]csvprocessor.Option{
+				csvprocessor.WithReader(csv.NewReader(strings.NewReader(input))),
+				csvprocessor.WithZipOutput(path),
+				csvprocessor.WithChunkSize(2),
+				csvprocessor.WithLogger(t.Logf),
+			}, tt.opts...)...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if err := proc.Process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			names, files := readZip(t, path)
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("entries = %q, want %q", names, tt.wantNames)
+			}
+
+			if !reflect.DeepEqual(files, tt.want) {
+				t.Errorf("content = %q, want %q", files, tt.want)
+			}
+
+			// the archive is the same when the same input is processed again.
+			first, _ := os.ReadFile(path)
+			proc, _ = csvprocessor.New(append([]csvprocessor.Option{
+				csvprocessor.WithReader(csv.NewReader(strings.NewReader(input))),
+				csvprocessor.WithZipOutput(path),
+				csvprocessor.WithChunkSize(2),
+				csvprocessor.WithLogger(t.Logf),
+			}, tt.opts...)...)
+			if err := proc.Process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if second, _ := os.ReadFile(path); string(first) != string(second) {
+				t.Errorf("archive is not deterministic")
+			}
+		})
+	}
+}
+
+func TestWithZipOutput_Error(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chunks.zip")
+	errBadRow := errors.New("bad row")
+
+	proc, err := csvprocessor.New(
+		csvprocessor.WithReader(csv.NewReader(strings.NewReader("id\n1\n2\n3\n"))),
+		csvprocessor.WithZipOutput(path),
+		csvprocessor.WithChunkSize(1),
+		csvprocessor.WithLogger(t.Logf),
+		csvprocessor.WithTransformer(func(ctx context.Context, row []string) []string {
+			if row[0] == "3" {
+				panic(errBadRow)
+			}
+
+			return row
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := proc.Process(); !errors.Is(err, errBadRow) {
+		t.Fatalf("Process() error = %v, want %v", err, errBadRow)
+	}
+
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("incomplete archive was not removed, Stat() error = %v", err)
+	}
+
+	if _, err := csvprocessor.New(csvprocessor.WithStdin(), csvprocessor.WithZipOutput(" ")); !errors.Is(err, csvprocessor.ErrInvalidOutputFileFormat) {
+		t.Errorf("New() error = %v, want %v", err, csvprocessor.ErrInvalidOutputFileFormat)
+	}
+}